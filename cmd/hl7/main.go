@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"hospital-management/internal/hl7"
+)
+
+const usage = `Usage: hl7 <command> [args]
+
+A local MLLP harness for the HL7 v2 interfaces.
+
+Commands:
+  send <addr> <file>...  send each message file (segments on separate lines,
+                         "-" for stdin) to the MLLP listener at addr and
+                         print the acknowledgement
+  listen <addr>          accept messages on addr, print them and answer AA;
+                         point HL7_OUTBOUND_ADDR here to watch SIU messages
+  sample <a04|a08>       print a sample ADT message to stdout
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "send":
+		if len(args) < 2 {
+			log.Fatal("send requires an address and at least one message file")
+		}
+		if !send(args[0], args[1:]) {
+			os.Exit(1)
+		}
+
+	case "listen":
+		if len(args) != 1 {
+			log.Fatal("listen requires an address")
+		}
+		listen(args[0])
+
+	case "sample":
+		if len(args) != 1 {
+			log.Fatal("sample requires a trigger event, a04 or a08")
+		}
+		msg, err := sample(strings.ToUpper(args[0]))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(msg)
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// send delivers the messages in order and reports whether all were
+// accepted.
+func send(addr string, files []string) bool {
+	client := &hl7.Client{Addr: addr, Timeout: 10 * time.Second}
+	defer client.Close()
+
+	accepted := true
+	for _, file := range files {
+		msg, err := readMessage(file)
+		if err != nil {
+			log.Printf("%s: %v", file, err)
+			accepted = false
+			continue
+		}
+		ack, err := client.Send(msg)
+		if err != nil {
+			log.Printf("%s: %v", file, err)
+			accepted = false
+			continue
+		}
+
+		code, text := hl7.AckCode(ack)
+		fmt.Printf("%s: %s %s\n%s\n\n", file, code, text, ack)
+		if code != hl7.AckAccept {
+			accepted = false
+		}
+	}
+	return accepted
+}
+
+func readMessage(file string) (*hl7.Message, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hl7.Parse(data)
+}
+
+func listen(addr string) {
+	server := &hl7.Server{
+		Addr: addr,
+		Handler: hl7.HandlerFunc(func(remoteAddr string, msg *hl7.Message) *hl7.Message {
+			code, event := msg.Type()
+			fmt.Printf("%s %s^%s from %s\n%s\n\n", time.Now().Format(time.RFC3339), code, event, remoteAddr, msg)
+			return hl7.Acknowledge(msg, hl7.AckAccept)
+		}),
+	}
+	log.Printf("Listening for MLLP connections on %s", addr)
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// sample returns an example ADT message. The A08 identifies the patient by
+// phone number so that it updates the patient the A04 registered.
+func sample(event string) (string, error) {
+	var address string
+	switch event {
+	case "A04":
+		address = "1 High Street^^Springfield^^12345"
+	case "A08":
+		address = "22 Park Road^^Springfield^^12345"
+	default:
+		return "", fmt.Errorf("no sample for ADT^%s; use a04 or a08", event)
+	}
+
+	now := time.Now()
+	msh := hl7.NewMSH(hl7.Header{
+		SendingApplication:   "LEGACY-PAS",
+		SendingFacility:      "MAIN",
+		ReceivingApplication: "HOSPITAL-MANAGEMENT",
+	}, "ADT", event, "ADT_A01", now)
+	msg := hl7.NewMessage(msh)
+	msg.Add(
+		hl7.NewSegment("EVN", event, hl7.FormatTimestamp(now)),
+		hl7.NewSegment("PID", "1", "", "PAS-1001^^^PAS^PI", "", "Doe^Jane^^^^^L", "", "19850214", "F", "", "", address, "", "555-0100^PRN^PH~^NET^Internet^jane.doe@example.com"),
+		hl7.NewSegment("PV1", "1", "O"),
+	)
+	return msg.String(), nil
+}
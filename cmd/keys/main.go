@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gorm.io/gorm"
+
+	"hospital-management/internal/config"
+	"hospital-management/internal/database"
+	"hospital-management/internal/encryption"
+)
+
+const usage = `Usage: keys <command> [args]
+
+Manages the keys sensitive patient fields are encrypted with.
+
+Commands:
+  generate          print a new random key for ENCRYPTION_KEYS or
+                    BLIND_INDEX_KEY
+  add <file> <id>   add a master key to a keystore file and make it the
+                    active one, creating the file if it does not exist
+  status            count the values of each encrypted column by the key
+                    they are encrypted with
+  rotate [batch]    re-encrypt values that are plaintext or encrypted with
+                    another key than the active one and refresh blind
+                    indexes, batch rows at a time (default 500)
+  decrypt [batch]   write every encrypted value back in plain text, before
+                    rolling back migration 020 or 024
+
+After upgrading to a version that encrypts more columns, run rotate to
+encrypt the rows written before.
+
+To rotate the master key, add a new key and make it the active one, restart
+the servers, run rotate, and remove the old key once status no longer
+lists it.
+`
+
+const defaultBatchSize = 500
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Load environment variables from .env file if present
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: could not load .env file: %v", err)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	switch command {
+	case "generate":
+		key, err := encryption.GenerateKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(key)
+		return
+
+	case "add":
+		if len(args) != 2 {
+			log.Fatal("add requires a keystore file and a key ID")
+		}
+		if err := encryption.AddKey(args[0], args[1]); err != nil {
+			log.Fatalf("Failed to add key: %v", err)
+		}
+		fmt.Printf("Added key %s to %s and made it the active key\n", args[1], args[0])
+		return
+
+	case "status", "rotate", "decrypt":
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.New()
+	keyring, err := encryption.Load(cfg.EncryptionKeystoreFile, cfg.EncryptionKeys, cfg.EncryptionActiveKeyID, cfg.BlindIndexKey)
+	if err != nil {
+		log.Fatalf("Failed to load encryption keys: %v", err)
+	}
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	switch command {
+	case "status":
+		status(db, keyring)
+
+	case "rotate":
+		fmt.Printf("Encrypting with key %s\n", keyring.ActiveKeyID())
+		stats, err := encryption.Rotate(db, keyring, encryption.Tables, parseBatchSize(args), printProgress)
+		printStats(stats)
+		if err != nil {
+			log.Fatalf("Rotation failed: %v", err)
+		}
+
+	case "decrypt":
+		stats, err := encryption.Decrypt(db, keyring, encryption.Tables, parseBatchSize(args), printProgress)
+		printStats(stats)
+		if err != nil {
+			log.Fatalf("Decryption failed: %v", err)
+		}
+	}
+}
+
+func status(db *gorm.DB, keyring *encryption.Keyring) {
+	statuses, err := encryption.Status(db, encryption.Tables)
+	if err != nil {
+		log.Fatalf("Failed to read encryption status: %v", err)
+	}
+	fmt.Printf("Active key: %s\nLoaded keys: %s\n\n", keyring.ActiveKeyID(), strings.Join(keyring.KeyIDs(), ", "))
+	for _, s := range statuses {
+		ids := make([]string, 0, len(s.Keys))
+		for id := range s.Keys {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+
+		counts := make([]string, 0, len(ids))
+		for _, id := range ids {
+			name := id
+			if name == "" {
+				name = "plaintext"
+			}
+			counts = append(counts, fmt.Sprintf("%s=%d", name, s.Keys[id]))
+		}
+		if len(counts) == 0 {
+			counts = append(counts, "empty")
+		}
+		fmt.Printf("%-40s  %s\n", s.Table+"."+s.Column, strings.Join(counts, "  "))
+	}
+}
+
+func printProgress(stats encryption.RewriteStats) {
+	log.Printf("%s: %d rows scanned, %d rewritten", stats.Table, stats.Scanned, stats.Rewritten)
+}
+
+func printStats(stats []encryption.RewriteStats) {
+	for _, s := range stats {
+		fmt.Printf("%-24s  %d rows scanned, %d rewritten\n", s.Table, s.Scanned, s.Rewritten)
+	}
+}
+
+// parseBatchSize reads the optional batch size argument.
+func parseBatchSize(args []string) int {
+	if len(args) == 0 {
+		return defaultBatchSize
+	}
+	size, err := strconv.Atoi(args[0])
+	if err != nil || size < 1 {
+		log.Fatalf("Invalid batch size %q", args[0])
+	}
+	return size
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/joho/godotenv"
+
+	"hospital-management/internal/config"
+	"hospital-management/internal/database"
+)
+
+const usage = `Usage: migrate <command> [args]
+
+Commands:
+  up [n]         apply all pending migrations, or only the next n
+  down [n]       roll back the last n applied migrations (default 1)
+  status         list migrations and whether they are applied
+  baseline [v]   mark migrations up to v (default 3) as applied without
+                 running them, for a schema that already has them
+  create <name>  write an empty up/down pair to MIGRATIONS_DIR
+                 (default internal/database/migrations)
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	// Load environment variables from .env file if present
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: could not load .env file: %v", err)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+
+	if command == "create" {
+		if len(args) != 1 {
+			log.Fatal("create requires a migration name")
+		}
+		dir := os.Getenv("MIGRATIONS_DIR")
+		if dir == "" {
+			dir = "internal/database/migrations"
+		}
+		upPath, downPath, err := database.CreateMigration(dir, args[0])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		fmt.Printf("Created %s\nCreated %s\n", upPath, downPath)
+		return
+	}
+
+	cfg := config.New()
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer sqlDB.Close()
+
+	migrator, err := database.NewMigrator(sqlDB)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx, parseSteps(args))
+		for _, m := range applied {
+			fmt.Printf("Applied %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+
+	case "down":
+		rolledBack, err := migrator.Down(ctx, parseSteps(args))
+		for _, m := range rolledBack {
+			fmt.Printf("Rolled back %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+
+	case "baseline":
+		version := database.LegacyBaseline
+		if len(args) > 0 {
+			v, err := strconv.Atoi(args[0])
+			if err != nil || v < 1 {
+				log.Fatalf("Invalid version %q", args[0])
+			}
+			version = v
+		}
+		recorded, err := migrator.Baseline(ctx, version)
+		for _, m := range recorded {
+			fmt.Printf("Marked %03d_%s as applied\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Baseline failed: %v", err)
+		}
+		if len(recorded) == 0 {
+			fmt.Println("Nothing to baseline")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, s := range statuses {
+			appliedAt := "-"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d  %-40s  %-9s  %s\n", s.Version, s.Name, s.State, appliedAt)
+		}
+
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// parseSteps reads the optional step count argument; 0 means "default".
+func parseSteps(args []string) int {
+	if len(args) == 0 {
+		return 0
+	}
+	steps, err := strconv.Atoi(args[0])
+	if err != nil || steps < 1 {
+		log.Fatalf("Invalid step count %q", args[0])
+	}
+	return steps
+}
@@ -2,15 +2,21 @@ package main
 
 import (
 	"log"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
 
+	"hospital-management/internal/auth"
 	"hospital-management/internal/config"
+	"hospital-management/internal/database"
+	"hospital-management/internal/drugs"
+	"hospital-management/internal/encryption"
 	"hospital-management/internal/handlers"
+	"hospital-management/internal/hl7"
 	"hospital-management/internal/models"
+	"hospital-management/internal/mrn"
 	"hospital-management/internal/repository"
 	"hospital-management/internal/service"
 )
@@ -24,60 +30,302 @@ func main() {
 	// Initialize configuration
 	cfg := config.New()
 
+	// Initialize authentication and the role/permission policy
+	jwtManager, err := auth.NewJWTManager(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize token service: %v", err)
+	}
+	auth.InitializeJWT(jwtManager)
+	if cfg.RBACPolicyFile != "" {
+		policy, err := auth.LoadPolicy(cfg.RBACPolicyFile)
+		if err != nil {
+			log.Fatalf("Failed to load RBAC policy: %v", err)
+		}
+		auth.InitializePolicy(policy)
+	}
+
+	clinicLocation, err := time.LoadLocation(cfg.ClinicTimezone)
+	if err != nil {
+		log.Fatalf("Invalid clinic timezone: %v", err)
+	}
+
+	drugCatalogue, err := drugs.LoadCatalogue(cfg.DrugCatalogueFile)
+	if err != nil {
+		log.Fatalf("Failed to load drug catalogue: %v", err)
+	}
+
+	mrnGenerator, err := mrn.NewGenerator(cfg.MRNPrefix, cfg.MRNFacility, int(cfg.MRNDigits))
+	if err != nil {
+		log.Fatalf("Invalid MRN settings: %v", err)
+	}
+
+	keyring, err := encryption.Load(cfg.EncryptionKeystoreFile, cfg.EncryptionKeys, cfg.EncryptionActiveKeyID, cfg.BlindIndexKey)
+	if err != nil {
+		log.Fatalf("Failed to load encryption keys: %v", err)
+	}
+	encryption.Use(keyring)
+
 	// Initialize database connection using GORM
-	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
+	db, err := database.NewConnection(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	// Run database migrations
-	if err := db.AutoMigrate(
-		&models.User{},
-		&models.Patient{},
-		&models.Appointment{},
-	); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Apply pending schema migrations
+	if cfg.RunMigrations {
+		if err := database.Migrate(db); err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
 	}
 
 	// Initialize repositories and services
 	userRepo := repository.NewUserRepository(db)
 	patientRepo := repository.NewPatientRepository(db)
 	appointmentRepo := repository.NewAppointmentRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	availabilityRepo := repository.NewAvailabilityRepository(db)
+	doctorRepo := repository.NewDoctorRepository(db)
+	noteRepo := repository.NewEncounterNoteRepository(db)
+	recordRepo := repository.NewPatientRecordRepository(db)
+	prescriptionRepo := repository.NewPrescriptionRepository(db)
+	observationRepo := repository.NewObservationRepository(db)
+	importRepo := repository.NewImportJobRepository(db)
+	mergeRepo := repository.NewPatientMergeRepository(db)
+	identifierRepo := repository.NewPatientIdentifierRepository(db)
+	consentRepo := repository.NewConsentRepository(db)
 
-	authService := service.NewAuthService(userRepo)
-	patientService := service.NewPatientService(patientRepo)
-	appointmentService := service.NewAppointmentService(appointmentRepo, patientRepo, userRepo)
+	authService := service.NewAuthService(userRepo, sessionRepo, jwtManager, cfg.RefreshTokenExpiry)
+	auth.InitializeSessionChecker(authService)
+	admin, err := authService.BootstrapAdmin(cfg.BootstrapAdminEmail, cfg.BootstrapAdminPassword)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	} else if admin != nil {
+		log.Printf("Created admin account %s", admin.Email)
+	}
+	auditService := service.NewAuditService(auditRepo)
+	patientService := service.NewPatientService(patientRepo, mergeRepo, auditService, mrnGenerator)
+	go func() {
+		assigned, err := patientService.AssignMissingMRNs()
+		if err != nil {
+			log.Printf("Failed to assign MRNs to existing patients: %v", err)
+		}
+		if assigned > 0 {
+			log.Printf("Assigned MRNs to %d existing patients", assigned)
+		}
+	}()
+	doctorService := service.NewDoctorService(doctorRepo, userRepo)
+	availabilityService := service.NewAvailabilityService(availabilityRepo, appointmentRepo, userRepo, clinicLocation)
+	appointmentService := service.NewAppointmentService(appointmentRepo, patientRepo, userRepo, auditService, availabilityService, clinicLocation)
+	queueService := service.NewQueueService(appointmentRepo, appointmentService, clinicLocation)
+	noteService := service.NewEncounterNoteService(noteRepo, appointmentRepo, auditService)
+	recordService := service.NewPatientRecordService(recordRepo, patientRepo, auditService)
+	prescriptionService := service.NewPrescriptionService(prescriptionRepo, appointmentRepo, patientRepo, userRepo, recordRepo, auditService, drugCatalogue, clinicLocation)
+	observationService := service.NewObservationService(observationRepo, patientRepo, appointmentRepo, auditService)
+	importService := service.NewImportService(importRepo, patientRepo, patientService)
+	exportService := service.NewExportService(patientRepo, appointmentRepo, consentRepo, auditService)
+	mergeService := service.NewPatientMergeService(patientRepo, mergeRepo, auditService)
+	identifierService := service.NewPatientIdentifierService(identifierRepo, patientRepo, auditService, mrnGenerator)
+	consentService := service.NewConsentService(consentRepo, patientRepo, auditService)
+	if cfg.DuplicateScanInterval > 0 {
+		go mergeService.RunDuplicateScans(cfg.DuplicateScanInterval)
+	}
+
+	// HL7 v2 interfaces over MLLP: ADT messages in, SIU messages out
+	hl7Header := hl7.Header{
+		SendingApplication:   cfg.HL7Application,
+		SendingFacility:      cfg.HL7Facility,
+		ReceivingApplication: cfg.HL7RemoteApp,
+		ReceivingFacility:    cfg.HL7RemoteFacility,
+	}
+	if cfg.HL7OutboundAddr != "" {
+		hl7Client := &hl7.Client{Addr: cfg.HL7OutboundAddr, Timeout: 30 * time.Second}
+		service.NewHL7OutboundService(appointmentService, hl7Client, patientRepo, userRepo, consentRepo, hl7Header, cfg.HL7AssigningAuthority, clinicLocation)
+	}
+	if cfg.HL7ListenAddr != "" {
+		hl7User, err := userRepo.GetByID(cfg.HL7UserID)
+		if err != nil {
+			log.Fatalf("HL7_USER_ID must name the user inbound HL7 changes are made as: %v", err)
+		}
+		hl7Actor := models.Actor{UserID: hl7User.ID, Role: hl7User.Role}
+		hl7Inbound := service.NewHL7InboundService(patientService, patientRepo, hl7Actor, cfg.HL7AssigningAuthority, clinicLocation)
+		hl7Server := &hl7.Server{Addr: cfg.HL7ListenAddr, Handler: hl7Inbound, IdleTimeout: 10 * time.Minute}
+		go func() {
+			log.Printf("HL7 listener starting on %s", cfg.HL7ListenAddr)
+			if err := hl7Server.ListenAndServe(); err != nil {
+				log.Fatalf("HL7 listener failed: %v", err)
+			}
+		}()
+	}
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	patientHandler := handlers.NewPatientHandler(patientService)
 	appointmentHandler := handlers.NewAppointmentHandler(appointmentService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	availabilityHandler := handlers.NewAvailabilityHandler(availabilityService)
+	doctorHandler := handlers.NewDoctorHandler(doctorService)
+	queueHandler := handlers.NewQueueHandler(queueService)
+	webHandler := handlers.NewWebHandler(queueService)
+	noteHandler := handlers.NewEncounterNoteHandler(noteService)
+	recordHandler := handlers.NewPatientRecordHandler(recordService)
+	prescriptionHandler := handlers.NewPrescriptionHandler(prescriptionService)
+	observationHandler := handlers.NewObservationHandler(observationService)
+	bulkHandler := handlers.NewBulkHandler(importService, exportService)
+	mergeHandler := handlers.NewPatientMergeHandler(mergeService)
+	identifierHandler := handlers.NewPatientIdentifierHandler(identifierService)
+	consentHandler := handlers.NewConsentHandler(consentService)
+	fhirHandler := handlers.NewFHIRHandler(patientService, doctorService, appointmentService, clinicLocation)
 
 	// Setup Gin router and API routes
 	router := gin.Default()
 	api := router.Group("/api/v1")
 
-	// Auth routes
+	// Public auth routes
 	api.POST("/login", authHandler.Login)
-	api.POST("/register", authHandler.Register)
-	// api.POST("/logout", authHandler.Logout) // Uncomment if implemented
+	api.POST("/refresh", authHandler.Refresh)
+
+	// Everything below requires a valid token and a role granted the
+	// permission named on each route.
+	protected := api.Group("")
+	protected.Use(auth.RequireAuthAPI())
+
+	protected.POST("/logout", authHandler.Logout)
+	protected.POST("/register", auth.RequirePermission(auth.PermUsersManage), authHandler.Register)
 
 	// Patient routes
-	// Patient routes
-	patients := api.Group("/patients")
-	patients.GET("", patientHandler.GetPatients)
-	patients.POST("", patientHandler.CreatePatient)
-	patients.GET(":id", patientHandler.GetPatientByID) // ✅ Fix here
-	patients.PUT(":id", patientHandler.UpdatePatient)
-	patients.DELETE(":id", patientHandler.DeletePatient)
+	patients := protected.Group("/patients")
+	patients.GET("", auth.RequirePermission(auth.PermPatientsRead), patientHandler.GetPatients)
+	patients.POST("", auth.RequirePermission(auth.PermPatientsWrite), patientHandler.CreatePatient)
+	patients.GET("search", auth.RequirePermission(auth.PermPatientsRead), patientHandler.SearchPatients)
+	patients.GET("by-identifier", auth.RequirePermission(auth.PermPatientsRead), identifierHandler.GetPatientByIdentifier)
+	patients.POST("import", auth.RequirePermission(auth.PermPatientsImport), bulkHandler.ImportPatients)
+	patients.GET("export", auth.RequirePermission(auth.PermDataExport), bulkHandler.ExportPatients)
+	patients.GET("duplicates", auth.RequirePermission(auth.PermPatientsMerge), mergeHandler.GetDuplicateCandidates)
+	patients.POST("duplicates/:candidateId/dismiss", auth.RequirePermission(auth.PermPatientsMerge), mergeHandler.DismissCandidate)
+	patients.GET(":id", auth.RequirePermission(auth.PermPatientsRead), patientHandler.GetPatientByID)
+	patients.PUT(":id", auth.RequirePermission(auth.PermPatientsWrite), patientHandler.UpdatePatient)
+	patients.DELETE(":id", auth.RequirePermission(auth.PermPatientsDelete), patientHandler.DeletePatient)
+	patients.GET(":id/duplicates", auth.RequirePermission(auth.PermPatientsMerge), mergeHandler.GetDuplicates)
+	patients.POST(":id/merge", auth.RequirePermission(auth.PermPatientsMerge), mergeHandler.MergePatient)
+	patients.GET(":id/merges", auth.RequirePermission(auth.PermPatientsMerge), mergeHandler.GetPatientMerges)
+	patients.GET(":id/identifiers", auth.RequirePermission(auth.PermPatientsRead), identifierHandler.GetIdentifiers)
+	patients.POST(":id/identifiers", auth.RequirePermission(auth.PermPatientsWrite), identifierHandler.AddIdentifier)
+	patients.DELETE(":id/identifiers/:recordId", auth.RequirePermission(auth.PermPatientsWrite), identifierHandler.DeleteIdentifier)
+	patients.GET(":id/consents", auth.RequirePermission(auth.PermPatientsRead), consentHandler.GetConsents)
+	patients.POST(":id/consents", auth.RequirePermission(auth.PermPatientsWrite), consentHandler.RecordConsent)
+	patients.GET(":id/consents/status", auth.RequirePermission(auth.PermPatientsRead), consentHandler.GetConsentStatus)
+	patients.POST(":id/consents/:recordId/revoke", auth.RequirePermission(auth.PermPatientsWrite), consentHandler.RevokeConsent)
+	patients.GET(":id/notes", auth.RequirePermission(auth.PermNotesRead), noteHandler.GetPatientNotes)
+	patients.GET(":id/allergies", auth.RequirePermission(auth.PermPatientsRead), recordHandler.GetAllergies)
+	patients.POST(":id/allergies", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.CreateAllergy)
+	patients.PUT(":id/allergies/:recordId", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.UpdateAllergy)
+	patients.DELETE(":id/allergies/:recordId", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.DeleteAllergy)
+	patients.GET(":id/medications", auth.RequirePermission(auth.PermPatientsRead), recordHandler.GetMedications)
+	patients.POST(":id/medications", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.CreateMedication)
+	patients.PUT(":id/medications/:recordId", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.UpdateMedication)
+	patients.DELETE(":id/medications/:recordId", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.DeleteMedication)
+	patients.GET(":id/problems", auth.RequirePermission(auth.PermPatientsRead), recordHandler.GetProblems)
+	patients.POST(":id/problems", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.CreateProblem)
+	patients.PUT(":id/problems/:recordId", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.UpdateProblem)
+	patients.DELETE(":id/problems/:recordId", auth.RequirePermission(auth.PermPatientsWrite), recordHandler.DeleteProblem)
+	patients.GET(":id/legacy-notes", auth.RequirePermission(auth.PermPatientsRead), recordHandler.GetLegacyNotes)
+	patients.GET(":id/prescriptions", auth.RequirePermission(auth.PermPrescriptionsRead), prescriptionHandler.GetPatientPrescriptions)
+	patients.POST(":id/prescriptions/check", auth.RequirePermission(auth.PermPrescriptionsWrite), prescriptionHandler.CheckPrescription)
+	patients.GET(":id/observations", auth.RequirePermission(auth.PermObservationsRead), observationHandler.GetObservations)
+	patients.POST(":id/observations", auth.RequirePermission(auth.PermObservationsWrite), observationHandler.RecordObservations)
+	patients.GET(":id/observations/latest", auth.RequirePermission(auth.PermObservationsRead), observationHandler.GetLatest)
+	patients.GET(":id/observations/trend", auth.RequirePermission(auth.PermObservationsRead), observationHandler.GetTrends)
+	patients.POST(":id/observations/:recordId/entered-in-error", auth.RequirePermission(auth.PermObservationsWrite), observationHandler.MarkEnteredInError)
 
 	// Appointment routes
-	appointments := api.Group("/appointments")
-	appointments.GET("", appointmentHandler.GetAppointments)
-	appointments.POST("", appointmentHandler.CreateAppointment)
-	appointments.GET(":id", appointmentHandler.GetAppointmentByID)
-	appointments.PUT(":id", appointmentHandler.UpdateAppointment)
-	appointments.DELETE(":id", appointmentHandler.DeleteAppointment)
+	appointments := protected.Group("/appointments")
+	appointments.GET("", auth.RequirePermission(auth.PermAppointmentsRead), appointmentHandler.GetAppointments)
+	appointments.POST("", auth.RequirePermission(auth.PermAppointmentsBook), appointmentHandler.CreateAppointment)
+	appointments.GET("export", auth.RequirePermission(auth.PermDataExport), bulkHandler.ExportAppointments)
+	appointments.GET(":id", auth.RequirePermission(auth.PermAppointmentsRead), appointmentHandler.GetAppointmentByID)
+	appointments.PUT(":id", auth.RequirePermission(auth.PermAppointmentsBook), appointmentHandler.UpdateAppointment)
+	appointments.POST("series", auth.RequirePermission(auth.PermAppointmentsBook), appointmentHandler.CreateAppointmentSeries)
+	appointments.GET("series/:seriesId", auth.RequirePermission(auth.PermAppointmentsRead), appointmentHandler.GetAppointmentSeries)
+	appointments.PUT(":id/series", auth.RequirePermission(auth.PermAppointmentsBook), appointmentHandler.UpdateAppointmentSeries)
+	appointments.POST(":id/series/cancel", auth.RequirePermission(auth.PermAppointmentsBook), appointmentHandler.CancelAppointmentSeries)
+	appointments.POST(":id/check-in", auth.RequirePermission(auth.PermAppointmentsCheckIn), queueHandler.CheckIn)
+	appointments.PATCH(":id/status", auth.RequireAnyPermission(auth.PermAppointmentsBook, auth.PermAppointmentsCheckIn, auth.PermAppointmentsClinical), appointmentHandler.UpdateAppointmentStatus)
+	appointments.DELETE(":id", auth.RequirePermission(auth.PermAppointmentsDelete), appointmentHandler.DeleteAppointment)
+	appointments.GET(":id/notes", auth.RequirePermission(auth.PermNotesRead), noteHandler.GetAppointmentNotes)
+	appointments.POST(":id/notes", auth.RequirePermission(auth.PermNotesWrite), noteHandler.CreateNote)
+	appointments.GET(":id/prescriptions", auth.RequirePermission(auth.PermPrescriptionsRead), prescriptionHandler.GetAppointmentPrescriptions)
+	appointments.POST(":id/prescriptions", auth.RequirePermission(auth.PermPrescriptionsWrite), prescriptionHandler.CreatePrescription)
+
+	// Encounter notes. Drafts are edited and signed by their author; signed
+	// notes only take amendments.
+	notes := protected.Group("/notes")
+	notes.GET(":id", auth.RequirePermission(auth.PermNotesRead), noteHandler.GetNote)
+	notes.PUT(":id", auth.RequirePermission(auth.PermNotesWrite), noteHandler.UpdateNote)
+	notes.POST(":id/sign", auth.RequirePermission(auth.PermNotesWrite), noteHandler.SignNote)
+	notes.POST(":id/amendments", auth.RequirePermission(auth.PermNotesWrite), noteHandler.AmendNote)
+
+	// Prescriptions. Drugs are checked against the patient's allergies and
+	// current medications when prescribed.
+	prescriptions := protected.Group("/prescriptions")
+	prescriptions.GET(":id", auth.RequirePermission(auth.PermPrescriptionsRead), prescriptionHandler.GetPrescription)
+	prescriptions.GET(":id/pdf", auth.RequirePermission(auth.PermPrescriptionsRead), prescriptionHandler.PrintPrescription)
+	prescriptions.POST(":id/cancel", auth.RequirePermission(auth.PermPrescriptionsWrite), prescriptionHandler.CancelPrescription)
+	protected.GET("/drugs", auth.RequirePermission(auth.PermPrescriptionsRead), prescriptionHandler.SearchDrugs)
+	protected.GET("/observation-types", auth.RequirePermission(auth.PermObservationsRead), observationHandler.GetObservationTypes)
+
+	// Merges are kept so they can be undone.
+	protected.POST("/patient-merges/:id/unmerge", auth.RequirePermission(auth.PermPatientsMerge), mergeHandler.UnmergePatient)
+
+	// Bulk patient imports run in the background; poll the job for progress.
+	protected.GET("/imports/:id", auth.RequirePermission(auth.PermPatientsImport), bulkHandler.GetImportJob)
+
+	// Doctor directory and availability routes. A doctor's ID is their user
+	// ID, the same ID appointments use as doctor_id.
+	doctors := protected.Group("/doctors")
+	doctors.GET("", auth.RequirePermission(auth.PermAppointmentsRead), doctorHandler.GetDoctors)
+	doctors.POST("", auth.RequirePermission(auth.PermDoctorsManage), doctorHandler.CreateDoctor)
+	doctors.GET(":id", auth.RequirePermission(auth.PermAppointmentsRead), doctorHandler.GetDoctorByID)
+	doctors.PUT(":id", auth.RequirePermission(auth.PermDoctorsManage), doctorHandler.UpdateDoctor)
+	doctors.DELETE(":id", auth.RequirePermission(auth.PermDoctorsManage), doctorHandler.DeleteDoctor)
+	doctors.GET(":id/availability", auth.RequirePermission(auth.PermAppointmentsRead), availabilityHandler.GetAvailability)
+	doctors.PUT(":id/availability", auth.RequirePermission(auth.PermAvailabilityManage), availabilityHandler.SetWeeklyTemplate)
+	doctors.POST(":id/availability/exceptions", auth.RequirePermission(auth.PermAvailabilityManage), availabilityHandler.AddException)
+	doctors.DELETE(":id/availability/exceptions/:exceptionId", auth.RequirePermission(auth.PermAvailabilityManage), availabilityHandler.RemoveException)
+	doctors.GET(":id/slots", auth.RequirePermission(auth.PermAppointmentsRead), availabilityHandler.GetSlots)
+	doctors.GET(":id/queue", auth.RequirePermission(auth.PermAppointmentsRead), queueHandler.GetQueue)
+
+	// Live queue for waiting-room displays and the doctor dashboard
+	api.GET("/doctors/:id/queue/stream", auth.RequireAuthStream(), auth.RequirePermission(auth.PermAppointmentsRead), queueHandler.StreamQueue)
+
+	// Audit trail
+	protected.GET("/audit", auth.RequirePermission(auth.PermAuditRead), auditHandler.GetAuditLog)
+
+	// FHIR R4 facade over the same services. The CapabilityStatement is
+	// public; resources need a token and the matching REST permission.
+	fhirAPI := router.Group("/fhir/R4")
+	fhirAPI.GET("/metadata", fhirHandler.Metadata)
+	fhirResources := fhirAPI.Group("")
+	fhirResources.Use(auth.RequireAuthAPI())
+	fhirResources.GET("/Patient", auth.RequirePermission(auth.PermPatientsRead), fhirHandler.SearchPatients)
+	fhirResources.POST("/Patient", auth.RequirePermission(auth.PermPatientsWrite), fhirHandler.CreatePatient)
+	fhirResources.GET("/Patient/:id", auth.RequirePermission(auth.PermPatientsRead), fhirHandler.ReadPatient)
+	fhirResources.GET("/Practitioner", auth.RequirePermission(auth.PermAppointmentsRead), fhirHandler.SearchPractitioners)
+	fhirResources.POST("/Practitioner", auth.RequirePermission(auth.PermDoctorsManage), fhirHandler.CreatePractitioner)
+	fhirResources.GET("/Practitioner/:id", auth.RequirePermission(auth.PermAppointmentsRead), fhirHandler.ReadPractitioner)
+	fhirResources.GET("/Appointment", auth.RequirePermission(auth.PermAppointmentsRead), fhirHandler.SearchAppointments)
+	fhirResources.POST("/Appointment", auth.RequirePermission(auth.PermAppointmentsBook), fhirHandler.CreateAppointment)
+	fhirResources.GET("/Appointment/:id", auth.RequirePermission(auth.PermAppointmentsRead), fhirHandler.ReadAppointment)
+
+	// Web pages for doctors. The login page stores the access token in the
+	// auth_token cookie, which these routes and the queue stream accept.
+	router.LoadHTMLGlob("web/templates/*/*.html")
+	router.GET("/", func(c *gin.Context) { c.Redirect(http.StatusFound, "/login") })
+	router.GET("/login", webHandler.LoginPage)
+	doctorPages := router.Group("/doctor")
+	doctorPages.Use(auth.RequireAuth(models.RoleDoctor))
+	doctorPages.GET("/dashboard", webHandler.DoctorDashboard)
+	doctorPages.GET("/patients", webHandler.DoctorPatients)
 
 	// Start server
 	port := cfg.Port
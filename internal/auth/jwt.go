@@ -2,50 +2,93 @@ package auth
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
+	"hospital-management/internal/config"
 	"hospital-management/internal/models"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
+// JWTManager issues and verifies access tokens. Tokens are signed with the
+// active key and carry its ID in the "kid" header; any configured key may
+// verify a token, so old keys can stay active while they are rotated out.
 type JWTManager struct {
-	secretKey []byte
+	keys        map[string][]byte
+	signingKey  string
+	issuer      string
+	audience    string
+	tokenExpiry time.Duration
 }
 
-func NewJWTManager(secretKey string) *JWTManager {
-	return &JWTManager{
-		secretKey: []byte(secretKey),
+// NewJWTManager builds a JWTManager from the JWT settings in cfg.
+func NewJWTManager(cfg *config.Config) (*JWTManager, error) {
+	if len(cfg.JWTKeys) == 0 {
+		return nil, errors.New("no JWT keys configured")
+	}
+	if _, ok := cfg.JWTKeys[cfg.JWTActiveKeyID]; !ok {
+		return nil, fmt.Errorf("active JWT key %q is not configured", cfg.JWTActiveKeyID)
+	}
+
+	keys := make(map[string][]byte, len(cfg.JWTKeys))
+	for kid, secret := range cfg.JWTKeys {
+		keys[kid] = []byte(secret)
 	}
+
+	return &JWTManager{
+		keys:        keys,
+		signingKey:  cfg.JWTActiveKeyID,
+		issuer:      cfg.JWTIssuer,
+		audience:    cfg.JWTAudience,
+		tokenExpiry: cfg.JWTExpiry,
+	}, nil
 }
 
-func (j *JWTManager) GenerateToken(user *models.User) (string, error) {
+// GenerateToken issues an access token for user bound to the given session.
+// It returns the signed token and its expiry time.
+func (j *JWTManager) GenerateToken(user *models.User, sessionID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(j.tokenExpiry)
 	claims := &Claims{
-		UserID:   int(user.ID),
-		Username: user.Name,
-		Role:     string(user.Role),
+		UserID:    user.ID,
+		Username:  user.Name,
+		Role:      user.Role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    j.issuer,
+			Audience:  jwt.ClaimStrings{j.audience},
+			Subject:   fmt.Sprint(user.ID),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secretKey)
+	token.Header["kid"] = j.signingKey
+	signed, err := token.SignedString(j.keys[j.signingKey])
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
 }
 
 func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return j.secretKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, j.keyFunc,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(j.issuer),
+		jwt.WithAudience(j.audience),
+		jwt.WithExpirationRequired(),
+	)
 
 	if err != nil {
 		return nil, err
@@ -62,3 +105,18 @@ func (j *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 
 	return claims, nil
 }
+
+// keyFunc selects the verification key named by the token's "kid" header.
+func (j *JWTManager) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, errors.New("token has no key id")
+	}
+
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"hospital-management/internal/config"
+	"hospital-management/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testIssuer   = "hospital-management"
+	testAudience = "hospital-management-api"
+)
+
+func newTestJWTManager(t *testing.T, keys map[string]string, active string) *JWTManager {
+	t.Helper()
+	manager, err := NewJWTManager(&config.Config{
+		JWTKeys:        keys,
+		JWTActiveKeyID: active,
+		JWTIssuer:      testIssuer,
+		JWTAudience:    testAudience,
+		JWTExpiry:      15 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return manager
+}
+
+// validClaims are the claims GenerateToken would issue.
+func validClaims() *Claims {
+	now := time.Now()
+	return &Claims{
+		UserID:    3,
+		Username:  "dr.house",
+		Role:      models.RoleDoctor,
+		SessionID: "session-1",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    testIssuer,
+			Audience:  jwt.ClaimStrings{testAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	}
+}
+
+// signToken signs claims with key under the given kid header; an empty kid
+// leaves the header out.
+func signToken(t *testing.T, method jwt.SigningMethod, key interface{}, kid string, claims *Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestGenerateAndValidateToken(t *testing.T) {
+	manager := newTestJWTManager(t, map[string]string{"k1": "secret-one"}, "k1")
+	user := &models.User{ID: 3, Name: "dr.house", Role: models.RoleDoctor}
+
+	token, expiresAt, err := manager.GenerateToken(user, "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := manager.ValidateToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 3 || claims.Username != "dr.house" || claims.Role != models.RoleDoctor || claims.SessionID != "session-1" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if !claims.ExpiresAt.Time.Equal(expiresAt.Truncate(time.Second)) {
+		t.Errorf("exp = %v, want %v", claims.ExpiresAt.Time, expiresAt)
+	}
+	parsed, _, _ := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if parsed.Header["kid"] != "k1" || parsed.Method.Alg() != "HS256" {
+		t.Errorf("header = %v, want HS256 with kid k1", parsed.Header)
+	}
+}
+
+func TestValidateTokenAcceptsRetiredKey(t *testing.T) {
+	keys := map[string]string{"2025": "old-secret", "2026": "new-secret"}
+	before := newTestJWTManager(t, keys, "2025")
+	token, _, err := before.GenerateToken(&models.User{ID: 3, Role: models.RoleDoctor}, "session-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// After rotation, tokens signed with the retired key are still accepted
+	// while it stays listed...
+	after := newTestJWTManager(t, keys, "2026")
+	if _, err := after.ValidateToken(token); err != nil {
+		t.Fatalf("token signed with a retired but listed key: %v", err)
+	}
+
+	// ...and rejected once it is removed.
+	removed := newTestJWTManager(t, map[string]string{"2026": "new-secret"}, "2026")
+	if _, err := removed.ValidateToken(token); err == nil {
+		t.Fatal("token signed with a removed key was accepted")
+	}
+}
+
+func TestValidateTokenRejects(t *testing.T) {
+	manager := newTestJWTManager(t, map[string]string{"k1": "secret-one", "k2": "secret-two"}, "k1")
+	key := []byte("secret-one")
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{"unknown kid", func() string { return signToken(t, jwt.SigningMethodHS256, key, "k9", validClaims()) }},
+		{"missing kid", func() string { return signToken(t, jwt.SigningMethodHS256, key, "", validClaims()) }},
+		{"key of another kid", func() string { return signToken(t, jwt.SigningMethodHS256, key, "k2", validClaims()) }},
+		{"wrong issuer", func() string {
+			claims := validClaims()
+			claims.Issuer = "someone-else"
+			return signToken(t, jwt.SigningMethodHS256, key, "k1", claims)
+		}},
+		{"no issuer", func() string {
+			claims := validClaims()
+			claims.Issuer = ""
+			return signToken(t, jwt.SigningMethodHS256, key, "k1", claims)
+		}},
+		{"wrong audience", func() string {
+			claims := validClaims()
+			claims.Audience = jwt.ClaimStrings{"another-api"}
+			return signToken(t, jwt.SigningMethodHS256, key, "k1", claims)
+		}},
+		{"missing exp", func() string {
+			claims := validClaims()
+			claims.ExpiresAt = nil
+			return signToken(t, jwt.SigningMethodHS256, key, "k1", claims)
+		}},
+		{"expired", func() string {
+			claims := validClaims()
+			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+			return signToken(t, jwt.SigningMethodHS256, key, "k1", claims)
+		}},
+		{"HS384", func() string { return signToken(t, jwt.SigningMethodHS384, key, "k1", validClaims()) }},
+		{"HS512", func() string { return signToken(t, jwt.SigningMethodHS512, key, "k1", validClaims()) }},
+		{"alg none", func() string {
+			return signToken(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "k1", validClaims())
+		}},
+		{"tampered payload", func() string {
+			token := signToken(t, jwt.SigningMethodHS256, key, "k1", validClaims())
+			other := validClaims()
+			other.Role = models.RoleAdmin
+			forged := signToken(t, jwt.SigningMethodHS256, []byte("attacker"), "k1", other)
+			// Keep the genuine signature on the forged claims.
+			return forged[:len(forged)-43] + token[len(token)-43:]
+		}},
+		{"garbage", func() string { return "not.a.token" }},
+	}
+	for _, tt := range tests {
+		if _, err := manager.ValidateToken(tt.token()); err == nil {
+			t.Errorf("%s: token was accepted", tt.name)
+		}
+	}
+}
+
+func TestNewJWTManagerRequiresActiveKey(t *testing.T) {
+	if _, err := NewJWTManager(&config.Config{}); err == nil {
+		t.Error("a manager without keys was created")
+	}
+	if _, err := NewJWTManager(&config.Config{JWTKeys: map[string]string{"k1": "secret"}, JWTActiveKeyID: "k2"}); err == nil {
+		t.Error("a manager whose active key is not listed was created")
+	}
+}
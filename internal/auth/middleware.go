@@ -1,16 +1,40 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 var jwtManager *JWTManager
 
-func InitializeJWT(secretKey string) {
-	jwtManager = NewJWTManager(secretKey)
+var policy = DefaultPolicy()
+
+// SessionChecker reports whether the session an access token was issued for
+// is still active, so revoked sessions are rejected before the token expires.
+type SessionChecker interface {
+	IsSessionActive(sessionID string) (bool, error)
+}
+
+var sessionChecker SessionChecker
+
+// InitializeJWT sets the token manager used by the authentication middleware.
+func InitializeJWT(manager *JWTManager) {
+	jwtManager = manager
+}
+
+// InitializeSessionChecker enables the revocation check in the authentication
+// middleware.
+func InitializeSessionChecker(checker SessionChecker) {
+	sessionChecker = checker
+}
+
+// InitializePolicy replaces the role/permission matrix used by RequirePermission.
+func InitializePolicy(p Policy) {
+	policy = p
 }
 
 func RequireAuthAPI() gin.HandlerFunc {
@@ -29,16 +53,41 @@ func RequireAuthAPI() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := jwtManager.ValidateToken(tokenString)
+		claims, err := validateToken(tokenString)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		setClaims(c, claims)
+		c.Next()
+	}
+}
+
+// RequireAuthStream authenticates long-lived read-only streams. Browsers'
+// EventSource cannot send an Authorization header, so the auth_token cookie
+// used by the web pages is accepted as well as a bearer token.
+func RequireAuthStream() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if tokenString == "" {
+			tokenString, _ = c.Cookie("auth_token")
+		}
+		if tokenString == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization required"})
+			c.Abort()
+			return
+		}
+
+		claims, err := validateToken(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+			c.Abort()
+			return
+		}
+
+		setClaims(c, claims)
 		c.Next()
 	}
 }
@@ -54,7 +103,7 @@ func RequireAuth(role string) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := jwtManager.ValidateToken(cookie)
+		claims, err := validateToken(cookie)
 		if err != nil {
 			c.Redirect(http.StatusFound, "/login")
 			c.Abort()
@@ -67,14 +116,66 @@ func RequireAuth(role string) gin.HandlerFunc {
 			return
 		}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		setClaims(c, claims)
 		c.Next()
 	}
 }
 
-func RequireRole(role string) gin.HandlerFunc {
+// validateToken verifies the token signature and claims, then checks that
+// its session has not been revoked.
+func validateToken(tokenString string) (*Claims, error) {
+	claims, err := jwtManager.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if sessionChecker != nil {
+		active, err := sessionChecker.IsSessionActive(claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if !active {
+			return nil, errors.New("session has been revoked")
+		}
+	}
+
+	return claims, nil
+}
+
+func setClaims(c *gin.Context, claims *Claims) {
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	c.Set("role", claims.Role)
+	c.Set("session_id", claims.SessionID)
+	c.Set("token_expires_at", claims.ExpiresAt.Time)
+}
+
+// StillAuthenticated reports whether the token a request was authenticated
+// with is still unexpired and its session still active. Long-lived streams
+// call it periodically, since the middleware only checks once when they
+// connect.
+func StillAuthenticated(c *gin.Context) bool {
+	expiresAt, ok := c.Get("token_expires_at")
+	if !ok {
+		return false
+	}
+	if expiry, ok := expiresAt.(time.Time); !ok || !time.Now().Before(expiry) {
+		return false
+	}
+
+	if sessionChecker != nil {
+		active, err := sessionChecker.IsSessionActive(c.GetString("session_id"))
+		if err != nil || !active {
+			return false
+		}
+	}
+
+	return true
+}
+
+// RequireRole allows the request through if the authenticated user has any of
+// the given roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userRole, exists := c.Get("role")
 		if !exists {
@@ -83,12 +184,78 @@ func RequireRole(role string) gin.HandlerFunc {
 			return
 		}
 
-		if userRole.(string) != role {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		for _, role := range roles {
+			if userRole.(string) == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// RequirePermission allows the request through only if the authenticated
+// user's role is granted every one of the given permissions.
+func RequirePermission(perms ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("role"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
 			return
 		}
 
+		for _, perm := range perms {
+			if !HasPermission(c, perm) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+				c.Abort()
+				return
+			}
+		}
+
 		c.Next()
 	}
 }
+
+// RequireAnyPermission allows the request through if the authenticated user's
+// role is granted at least one of the given permissions.
+func RequireAnyPermission(perms ...Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get("role"); !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		for _, perm := range perms {
+			if HasPermission(c, perm) {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// HasPermission reports whether the authenticated user's role is granted perm.
+// Handlers use it for checks that depend on the request body.
+func HasPermission(c *gin.Context, perm Permission) bool {
+	role, ok := c.Get("role")
+	if !ok {
+		return false
+	}
+	roleStr, ok := role.(string)
+	if !ok {
+		return false
+	}
+	return policy.Allows(roleStr, perm)
+}
+
+// Allowed reports whether role is granted perm by the active policy. Services
+// use it for checks that depend on the state of the data being changed.
+func Allowed(role string, perm Permission) bool {
+	return policy.Allows(role, perm)
+}
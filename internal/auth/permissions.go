@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"hospital-management/internal/models"
+)
+
+// Permission names a single action a role may perform on the API.
+type Permission string
+
+const (
+	PermPatientsRead   Permission = "patients:read"
+	PermPatientsWrite  Permission = "patients:write"
+	PermPatientsDelete Permission = "patients:delete"
+	PermPatientsImport Permission = "patients:import"
+	PermPatientsMerge  Permission = "patients:merge"
+
+	PermAppointmentsRead     Permission = "appointments:read"
+	PermAppointmentsBook     Permission = "appointments:book"
+	PermAppointmentsClinical Permission = "appointments:clinical"
+	PermAppointmentsDelete   Permission = "appointments:delete"
+	PermAppointmentsCheckIn  Permission = "appointments:checkin"
+
+	PermNotesRead  Permission = "notes:read"
+	PermNotesWrite Permission = "notes:write"
+
+	PermPrescriptionsRead  Permission = "prescriptions:read"
+	PermPrescriptionsWrite Permission = "prescriptions:write"
+
+	PermObservationsRead  Permission = "observations:read"
+	PermObservationsWrite Permission = "observations:write"
+
+	PermAvailabilityManage Permission = "availability:manage"
+	PermDoctorsManage      Permission = "doctors:manage"
+
+	PermUsersManage Permission = "users:manage"
+	PermAuditRead   Permission = "audit:read"
+	PermDataExport  Permission = "data:export"
+
+	// PermAll grants every permission. It is only meant for the admin role.
+	PermAll Permission = "*"
+)
+
+// AllPermissions lists every permission known to the API.
+var AllPermissions = []Permission{
+	PermPatientsRead,
+	PermPatientsWrite,
+	PermPatientsDelete,
+	PermPatientsImport,
+	PermPatientsMerge,
+	PermAppointmentsRead,
+	PermAppointmentsBook,
+	PermAppointmentsClinical,
+	PermAppointmentsDelete,
+	PermAppointmentsCheckIn,
+	PermNotesRead,
+	PermNotesWrite,
+	PermPrescriptionsRead,
+	PermPrescriptionsWrite,
+	PermObservationsRead,
+	PermObservationsWrite,
+	PermAvailabilityManage,
+	PermDoctorsManage,
+	PermUsersManage,
+	PermAuditRead,
+	PermDataExport,
+}
+
+// Policy maps a role to the permissions it is granted.
+type Policy map[string][]Permission
+
+// DefaultPolicy returns the built-in role/permission matrix.
+func DefaultPolicy() Policy {
+	return Policy{
+		models.RoleAdmin: {PermAll},
+		models.RoleDoctor: {
+			PermPatientsRead,
+			PermPatientsWrite,
+			PermAppointmentsRead,
+			PermAppointmentsClinical,
+			PermNotesRead,
+			PermNotesWrite,
+			PermPrescriptionsRead,
+			PermPrescriptionsWrite,
+			PermObservationsRead,
+			PermObservationsWrite,
+		},
+		models.RoleNurse: {
+			PermPatientsRead,
+			PermAppointmentsRead,
+			PermAppointmentsCheckIn,
+			PermNotesRead,
+			PermPrescriptionsRead,
+			PermObservationsRead,
+			PermObservationsWrite,
+		},
+		models.RoleReceptionist: {
+			PermPatientsRead,
+			PermPatientsWrite,
+			PermAppointmentsRead,
+			PermAppointmentsBook,
+			PermAppointmentsCheckIn,
+			PermAvailabilityManage,
+		},
+		models.RoleStaff: {
+			PermAppointmentsRead,
+		},
+		models.RolePatient: {},
+	}
+}
+
+// LoadPolicy reads a role/permission matrix from a JSON file of the form
+// {"role": ["permission", ...]}.
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// Validate ensures every permission in the policy is known.
+func (p Policy) Validate() error {
+	known := make(map[Permission]bool, len(AllPermissions)+1)
+	known[PermAll] = true
+	for _, perm := range AllPermissions {
+		known[perm] = true
+	}
+
+	for role, perms := range p {
+		for _, perm := range perms {
+			if !known[perm] {
+				return fmt.Errorf("role %q has unknown permission %q", role, perm)
+			}
+		}
+	}
+	return nil
+}
+
+// Allows reports whether role is granted perm.
+func (p Policy) Allows(role string, perm Permission) bool {
+	for _, granted := range p[role] {
+		if granted == PermAll || granted == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Matrix expands the policy into role -> permission -> allowed, covering every
+// known permission. It is useful for reviewing or asserting the configuration.
+func (p Policy) Matrix() map[string]map[Permission]bool {
+	matrix := make(map[string]map[Permission]bool, len(p))
+	for role := range p {
+		row := make(map[Permission]bool, len(AllPermissions))
+		for _, perm := range AllPermissions {
+			row[perm] = p.Allows(role, perm)
+		}
+		matrix[role] = row
+	}
+	return matrix
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"testing"
+
+	"hospital-management/internal/models"
+)
+
+// TestDefaultPolicyMatrix pins the built-in role/permission matrix. Granting
+// or revoking a permission must be a deliberate change to this table.
+func TestDefaultPolicyMatrix(t *testing.T) {
+	granted := map[string][]Permission{
+		models.RoleDoctor: {
+			PermPatientsRead,
+			PermPatientsWrite,
+			PermAppointmentsRead,
+			PermAppointmentsClinical,
+			PermNotesRead,
+			PermNotesWrite,
+			PermPrescriptionsRead,
+			PermPrescriptionsWrite,
+			PermObservationsRead,
+			PermObservationsWrite,
+		},
+		models.RoleNurse: {
+			PermPatientsRead,
+			PermAppointmentsRead,
+			PermAppointmentsCheckIn,
+			PermNotesRead,
+			PermPrescriptionsRead,
+			PermObservationsRead,
+			PermObservationsWrite,
+		},
+		models.RoleReceptionist: {
+			PermPatientsRead,
+			PermPatientsWrite,
+			PermAppointmentsRead,
+			PermAppointmentsBook,
+			PermAppointmentsCheckIn,
+			PermAvailabilityManage,
+		},
+		models.RoleStaff: {
+			PermAppointmentsRead,
+		},
+		models.RolePatient: {},
+	}
+
+	matrix := DefaultPolicy().Matrix()
+	for role, perms := range granted {
+		want := make(map[Permission]bool, len(perms))
+		for _, perm := range perms {
+			want[perm] = true
+		}
+		row, ok := matrix[role]
+		if !ok {
+			t.Errorf("role %q is missing from the default policy", role)
+			continue
+		}
+		for _, perm := range AllPermissions {
+			if row[perm] != want[perm] {
+				t.Errorf("role %q permission %q: got %v, want %v", role, perm, row[perm], want[perm])
+			}
+		}
+	}
+
+	for role := range matrix {
+		if _, ok := granted[role]; !ok && role != models.RoleAdmin {
+			t.Errorf("unexpected role %q in the default policy", role)
+		}
+	}
+}
+
+func TestDefaultPolicyAdminHasEveryPermission(t *testing.T) {
+	policy := DefaultPolicy()
+	for _, perm := range AllPermissions {
+		if !policy.Allows(models.RoleAdmin, perm) {
+			t.Errorf("admin is not granted %q", perm)
+		}
+	}
+}
+
+func TestOnlyAdminManagesUsers(t *testing.T) {
+	policy := DefaultPolicy()
+	for role := range policy {
+		if got := policy.Allows(role, PermUsersManage); got != (role == models.RoleAdmin) {
+			t.Errorf("role %q users:manage = %v", role, got)
+		}
+	}
+}
+
+func TestPolicyDeniesUnknownRole(t *testing.T) {
+	policy := DefaultPolicy()
+	for _, perm := range AllPermissions {
+		if policy.Allows("janitor", perm) {
+			t.Errorf("unknown role is granted %q", perm)
+		}
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	if err := DefaultPolicy().Validate(); err != nil {
+		t.Fatalf("default policy is invalid: %v", err)
+	}
+
+	bad := Policy{models.RoleDoctor: {PermPatientsRead, "patients:destroy"}}
+	if err := bad.Validate(); err == nil {
+		t.Fatal("expected an unknown permission to be rejected")
+	}
+}
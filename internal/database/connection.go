@@ -1,8 +1,10 @@
 package database
 
 import (
+	"context"
+	"log"
+
 	"hospital-management/internal/config"
-	"hospital-management/internal/models"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -14,10 +16,24 @@ func NewConnection(cfg *config.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&models.User{}, &models.Patient{}, &models.Appointment{})
+	return db, nil
+}
+
+// Migrate applies every pending embedded migration.
+func Migrate(db *gorm.DB) error {
+	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return db, nil
+	migrator, err := NewMigrator(sqlDB)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background(), 0)
+	for _, m := range applied {
+		log.Printf("Applied migration %03d_%s", m.Version, m.Name)
+	}
+	return err
 }
@@ -0,0 +1 @@
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_double_booking;
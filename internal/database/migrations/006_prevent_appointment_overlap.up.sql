@@ -0,0 +1,9 @@
+-- Backstop for the application-level conflict check: the database refuses
+-- two overlapping active appointments for the same doctor.
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE appointments ADD CONSTRAINT appointments_no_double_booking
+    EXCLUDE USING gist (
+        doctor_id WITH =,
+        tsrange(date_time, date_time + duration * interval '1 minute') WITH &&
+    ) WHERE (status = 'scheduled');
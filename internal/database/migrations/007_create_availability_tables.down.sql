@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS availability_exceptions;
+DROP TABLE IF EXISTS availability_rules;
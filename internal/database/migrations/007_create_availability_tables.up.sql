@@ -0,0 +1,25 @@
+CREATE TABLE availability_rules (
+    id SERIAL PRIMARY KEY,
+    doctor_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    weekday SMALLINT NOT NULL CHECK (weekday BETWEEN 0 AND 6),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('shift', 'break')),
+    start_time VARCHAR(5) NOT NULL,
+    end_time VARCHAR(5) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_availability_rules_doctor_id ON availability_rules(doctor_id);
+
+CREATE TABLE availability_exceptions (
+    id SERIAL PRIMARY KEY,
+    doctor_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('unavailable', 'available')),
+    start_time VARCHAR(5),
+    end_time VARCHAR(5),
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_availability_exceptions_doctor_date ON availability_exceptions(doctor_id, date);
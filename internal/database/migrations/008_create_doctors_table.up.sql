@@ -0,0 +1,18 @@
+CREATE TABLE doctors (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER UNIQUE NOT NULL REFERENCES users(id),
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(100) UNIQUE NOT NULL,
+    phone VARCHAR(20),
+    specialization VARCHAR(100) NOT NULL,
+    experience INTEGER DEFAULT 0,
+    license_number VARCHAR(50) UNIQUE NOT NULL,
+    department VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_doctors_specialization ON doctors(specialization);
+CREATE INDEX idx_doctors_department ON doctors(department);
+CREATE INDEX idx_doctors_deleted_at ON doctors(deleted_at);
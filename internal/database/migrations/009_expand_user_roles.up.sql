@@ -0,0 +1,4 @@
+-- The application recognises more roles than the original schema allowed.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_role_check;
+ALTER TABLE users ADD CONSTRAINT users_role_check
+    CHECK (role IN ('admin', 'doctor', 'nurse', 'receptionist', 'staff', 'patient'));
@@ -0,0 +1,24 @@
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_double_booking;
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+
+UPDATE appointments SET status = 'scheduled' WHERE status IN ('requested', 'confirmed', 'checked_in', 'in_progress');
+UPDATE appointments SET status = 'cancelled' WHERE status = 'no_show';
+
+ALTER TABLE appointments
+    DROP COLUMN scheduled_at,
+    DROP COLUMN confirmed_at,
+    DROP COLUMN checked_in_at,
+    DROP COLUMN started_at,
+    DROP COLUMN completed_at,
+    DROP COLUMN cancelled_at,
+    DROP COLUMN no_show_at,
+    DROP COLUMN status_reason;
+
+ALTER TABLE appointments ADD CONSTRAINT appointments_status_check
+    CHECK (status IN ('scheduled', 'completed', 'cancelled'));
+
+ALTER TABLE appointments ADD CONSTRAINT appointments_no_double_booking
+    EXCLUDE USING gist (
+        doctor_id WITH =,
+        tsrange(date_time, date_time + duration * interval '1 minute') WITH &&
+    ) WHERE (status = 'scheduled');
@@ -0,0 +1,25 @@
+-- Appointments follow an explicit lifecycle; record when each status was
+-- entered and why an appointment was cancelled or missed.
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_status_check;
+ALTER TABLE appointments ADD CONSTRAINT appointments_status_check
+    CHECK (status IN ('requested', 'scheduled', 'confirmed', 'checked_in', 'in_progress', 'completed', 'cancelled', 'no_show'));
+
+ALTER TABLE appointments
+    ADD COLUMN scheduled_at TIMESTAMP,
+    ADD COLUMN confirmed_at TIMESTAMP,
+    ADD COLUMN checked_in_at TIMESTAMP,
+    ADD COLUMN started_at TIMESTAMP,
+    ADD COLUMN completed_at TIMESTAMP,
+    ADD COLUMN cancelled_at TIMESTAMP,
+    ADD COLUMN no_show_at TIMESTAMP,
+    ADD COLUMN status_reason TEXT;
+
+UPDATE appointments SET scheduled_at = created_at;
+
+-- Every status that occupies the doctor's time takes part in the overlap check.
+ALTER TABLE appointments DROP CONSTRAINT IF EXISTS appointments_no_double_booking;
+ALTER TABLE appointments ADD CONSTRAINT appointments_no_double_booking
+    EXCLUDE USING gist (
+        doctor_id WITH =,
+        tsrange(date_time, date_time + duration * interval '1 minute') WITH &&
+    ) WHERE (status IN ('scheduled', 'confirmed', 'checked_in', 'in_progress'));
@@ -0,0 +1,2 @@
+ALTER TABLE appointments DROP COLUMN IF EXISTS series_id;
+DROP TABLE IF EXISTS appointment_series;
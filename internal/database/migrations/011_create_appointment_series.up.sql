@@ -0,0 +1,22 @@
+CREATE TABLE appointment_series (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    doctor_id INTEGER NOT NULL REFERENCES users(id),
+    starts_at TIMESTAMP NOT NULL,
+    duration INTEGER NOT NULL,
+    frequency VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly')),
+    repeat_interval INTEGER NOT NULL DEFAULT 1 CHECK (repeat_interval > 0),
+    count INTEGER NOT NULL DEFAULT 0,
+    until TIMESTAMP,
+    notes TEXT,
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((count > 0) <> (until IS NOT NULL))
+);
+
+CREATE INDEX idx_appointment_series_patient ON appointment_series(patient_id);
+CREATE INDEX idx_appointment_series_doctor ON appointment_series(doctor_id);
+
+ALTER TABLE appointments ADD COLUMN series_id INTEGER REFERENCES appointment_series(id) ON DELETE SET NULL;
+CREATE INDEX idx_appointments_series ON appointments(series_id, date_time);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS encounter_note_amendments;
+DROP TABLE IF EXISTS encounter_note_revisions;
+DROP TABLE IF EXISTS encounter_notes;
+DROP FUNCTION IF EXISTS encounter_note_history_append_only();
+DROP FUNCTION IF EXISTS encounter_notes_protect_signed();
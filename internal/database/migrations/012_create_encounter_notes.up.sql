@@ -0,0 +1,93 @@
+CREATE TABLE encounter_notes (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    appointment_id INTEGER REFERENCES appointments(id) ON DELETE SET NULL,
+    author_id INTEGER NOT NULL REFERENCES users(id),
+    subjective TEXT NOT NULL DEFAULT '',
+    objective TEXT NOT NULL DEFAULT '',
+    assessment TEXT NOT NULL DEFAULT '',
+    plan TEXT NOT NULL DEFAULT '',
+    status VARCHAR(10) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'signed')),
+    version INTEGER NOT NULL DEFAULT 1,
+    signed_at TIMESTAMP,
+    signed_by INTEGER REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((status = 'signed') = (signed_at IS NOT NULL))
+);
+
+CREATE INDEX idx_encounter_notes_patient ON encounter_notes(patient_id, created_at);
+CREATE INDEX idx_encounter_notes_appointment ON encounter_notes(appointment_id);
+
+CREATE TABLE encounter_note_revisions (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES encounter_notes(id),
+    version INTEGER NOT NULL,
+    subjective TEXT NOT NULL DEFAULT '',
+    objective TEXT NOT NULL DEFAULT '',
+    assessment TEXT NOT NULL DEFAULT '',
+    plan TEXT NOT NULL DEFAULT '',
+    edited_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (note_id, version)
+);
+
+CREATE TABLE encounter_note_amendments (
+    id SERIAL PRIMARY KEY,
+    note_id INTEGER NOT NULL REFERENCES encounter_notes(id),
+    author_id INTEGER NOT NULL REFERENCES users(id),
+    section VARCHAR(20) NOT NULL CHECK (section IN ('subjective', 'objective', 'assessment', 'plan', 'general')),
+    content TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_encounter_note_amendments_note ON encounter_note_amendments(note_id);
+
+-- A signed note is part of the legal record: it can no longer be changed or
+-- removed, and revisions and amendments are append-only. Patients with notes
+-- therefore cannot be hard-deleted. Only the link to a deleted appointment
+-- may be cleared.
+CREATE FUNCTION encounter_notes_protect_signed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND
+       ROW(NEW.patient_id, NEW.author_id, NEW.subjective, NEW.objective, NEW.assessment, NEW.plan,
+           NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.patient_id, OLD.author_id, OLD.subjective, OLD.objective, OLD.assessment, OLD.plan,
+           OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF OLD.status = 'signed' THEN
+        RAISE EXCEPTION 'encounter note % is signed and cannot be changed', OLD.id;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER encounter_notes_signed_immutable
+    BEFORE UPDATE OR DELETE ON encounter_notes
+    FOR EACH ROW EXECUTE FUNCTION encounter_notes_protect_signed();
+
+CREATE FUNCTION encounter_note_history_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER encounter_note_revisions_no_update_delete
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON encounter_note_revisions
+    FOR EACH STATEMENT EXECUTE FUNCTION encounter_note_history_append_only();
+
+CREATE TRIGGER encounter_note_amendments_no_update_delete
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON encounter_note_amendments
+    FOR EACH STATEMENT EXECUTE FUNCTION encounter_note_history_append_only();
+
+-- Carry the free-text clinical fields over as drafts for the treating doctor
+-- to review and sign.
+INSERT INTO encounter_notes (patient_id, appointment_id, author_id, assessment, plan, status, version, created_at, updated_at)
+SELECT patient_id, id, doctor_id, COALESCE(diagnosis, ''), COALESCE(treatment, ''), 'draft', 1, updated_at, updated_at
+FROM appointments
+WHERE COALESCE(diagnosis, '') <> '' OR COALESCE(treatment, '') <> '';
@@ -0,0 +1,14 @@
+ALTER TABLE patients
+    ADD COLUMN medical_history TEXT,
+    ADD COLUMN allergies TEXT,
+    ADD COLUMN medications TEXT;
+
+UPDATE patients p SET
+    medical_history = (SELECT string_agg(content, E'\n' ORDER BY id) FROM patient_legacy_notes n WHERE n.patient_id = p.id AND n.field = 'medical_history'),
+    allergies = (SELECT string_agg(content, E'\n' ORDER BY id) FROM patient_legacy_notes n WHERE n.patient_id = p.id AND n.field = 'allergies'),
+    medications = (SELECT string_agg(content, E'\n' ORDER BY id) FROM patient_legacy_notes n WHERE n.patient_id = p.id AND n.field = 'medications');
+
+DROP TABLE IF EXISTS patient_legacy_notes;
+DROP TABLE IF EXISTS problems;
+DROP TABLE IF EXISTS medications;
+DROP TABLE IF EXISTS allergies;
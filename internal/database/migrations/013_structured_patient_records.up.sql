@@ -0,0 +1,67 @@
+CREATE TABLE allergies (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    substance TEXT NOT NULL,
+    reaction TEXT NOT NULL DEFAULT '',
+    severity VARCHAR(20) NOT NULL CHECK (severity IN ('mild', 'moderate', 'severe', 'life_threatening')),
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive', 'resolved')),
+    recorded_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_allergies_patient ON allergies(patient_id);
+
+CREATE TABLE medications (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    dose TEXT NOT NULL DEFAULT '',
+    route TEXT NOT NULL DEFAULT '',
+    frequency TEXT NOT NULL DEFAULT '',
+    start_date DATE,
+    stop_date DATE,
+    recorded_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (stop_date IS NULL OR start_date IS NULL OR stop_date >= start_date)
+);
+
+CREATE INDEX idx_medications_patient ON medications(patient_id);
+
+CREATE TABLE problems (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    condition TEXT NOT NULL,
+    onset DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'inactive', 'resolved')),
+    recorded_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_problems_patient ON problems(patient_id);
+
+-- The free-text fields cannot be split into structured entries reliably, so
+-- their content is kept verbatim as legacy notes before the columns go.
+CREATE TABLE patient_legacy_notes (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    field VARCHAR(30) NOT NULL,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_patient_legacy_notes_patient ON patient_legacy_notes(patient_id);
+
+INSERT INTO patient_legacy_notes (patient_id, field, content)
+SELECT id, 'medical_history', medical_history FROM patients WHERE COALESCE(medical_history, '') <> ''
+UNION ALL
+SELECT id, 'allergies', allergies FROM patients WHERE COALESCE(allergies, '') <> ''
+UNION ALL
+SELECT id, 'medications', medications FROM patients WHERE COALESCE(medications, '') <> '';
+
+ALTER TABLE patients
+    DROP COLUMN medical_history,
+    DROP COLUMN allergies,
+    DROP COLUMN medications;
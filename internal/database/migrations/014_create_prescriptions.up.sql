@@ -0,0 +1,29 @@
+CREATE TABLE prescriptions (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id),
+    appointment_id INTEGER NOT NULL REFERENCES appointments(id),
+    prescriber_id INTEGER NOT NULL REFERENCES users(id),
+    drug_code VARCHAR(50) NOT NULL DEFAULT '',
+    drug_name TEXT NOT NULL,
+    dose TEXT NOT NULL,
+    route TEXT NOT NULL DEFAULT '',
+    frequency TEXT NOT NULL,
+    duration_days INTEGER NOT NULL DEFAULT 0 CHECK (duration_days >= 0),
+    quantity TEXT NOT NULL DEFAULT '',
+    refills INTEGER NOT NULL DEFAULT 0 CHECK (refills >= 0),
+    instructions TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    alerts JSONB NOT NULL DEFAULT '[]',
+    override_reason TEXT,
+    cancelled_at TIMESTAMP,
+    cancelled_by INTEGER REFERENCES users(id),
+    cancel_reason TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- A blocking alert can only be overridden with a reason.
+    CHECK (override_reason IS NOT NULL OR NOT jsonb_path_exists(alerts, '$[*] ? (@.severity == "block")')),
+    CHECK ((status = 'cancelled') = (cancelled_at IS NOT NULL))
+);
+
+CREATE INDEX idx_prescriptions_patient ON prescriptions(patient_id, status);
+CREATE INDEX idx_prescriptions_appointment ON prescriptions(appointment_id);
@@ -0,0 +1,23 @@
+CREATE TABLE observations (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    appointment_id INTEGER REFERENCES appointments(id) ON DELETE SET NULL,
+    code VARCHAR(30) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    unit VARCHAR(20) NOT NULL,
+    reference_low DOUBLE PRECISION,
+    reference_high DOUBLE PRECISION,
+    flag VARCHAR(20) NOT NULL CHECK (flag IN ('normal', 'low', 'high', 'critical_low', 'critical_high')),
+    status VARCHAR(20) NOT NULL DEFAULT 'final' CHECK (status IN ('final', 'entered_in_error')),
+    note TEXT NOT NULL DEFAULT '',
+    error_reason TEXT,
+    observed_at TIMESTAMP NOT NULL,
+    recorded_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((status = 'entered_in_error') = (error_reason IS NOT NULL))
+);
+
+-- Trend queries read one patient's codes over a time range.
+CREATE INDEX idx_observations_patient_code_time ON observations(patient_id, code, observed_at);
+CREATE INDEX idx_observations_appointment ON observations(appointment_id);
@@ -0,0 +1,22 @@
+CREATE TABLE import_jobs (
+    id SERIAL PRIMARY KEY,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('csv', 'ndjson')),
+    file_name TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    created_rows INTEGER NOT NULL DEFAULT 0,
+    duplicate_rows INTEGER NOT NULL DEFAULT 0,
+    invalid_rows INTEGER NOT NULL DEFAULT 0,
+    row_errors JSONB NOT NULL DEFAULT '[]',
+    row_errors_truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT,
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    CHECK ((status = 'failed') = (error IS NOT NULL))
+);
+
+-- Jobs left pending or running by a restart are found by status.
+CREATE INDEX idx_import_jobs_status ON import_jobs(status);
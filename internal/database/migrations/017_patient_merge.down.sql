@@ -0,0 +1,24 @@
+CREATE OR REPLACE FUNCTION encounter_notes_protect_signed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND
+       ROW(NEW.patient_id, NEW.author_id, NEW.subjective, NEW.objective, NEW.assessment, NEW.plan,
+           NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.patient_id, OLD.author_id, OLD.subjective, OLD.objective, OLD.assessment, OLD.plan,
+           OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF OLD.status = 'signed' THEN
+        RAISE EXCEPTION 'encounter note % is signed and cannot be changed', OLD.id;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS patient_merges;
+DROP TABLE IF EXISTS duplicate_candidates;
+DROP INDEX IF EXISTS idx_patients_merged_into;
+DROP INDEX IF EXISTS idx_patients_date_of_birth;
+ALTER TABLE patients DROP COLUMN IF EXISTS merged_into_id;
@@ -0,0 +1,70 @@
+-- A merged patient is kept, pointing at the chart it was merged into, so the
+-- merge can be undone.
+ALTER TABLE patients ADD COLUMN merged_into_id INTEGER REFERENCES patients(id);
+
+CREATE INDEX idx_patients_date_of_birth ON patients(date_of_birth);
+CREATE INDEX idx_patients_merged_into ON patients(merged_into_id);
+
+CREATE TABLE duplicate_candidates (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    candidate_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    score INTEGER NOT NULL CHECK (score BETWEEN 0 AND 100),
+    reasons JSONB NOT NULL DEFAULT '[]',
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'merged')),
+    source VARCHAR(10) NOT NULL CHECK (source IN ('create', 'scan')),
+    reviewed_by INTEGER REFERENCES users(id),
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (patient_id < candidate_id),
+    CHECK ((status = 'open') = (reviewed_at IS NULL)),
+    UNIQUE (patient_id, candidate_id)
+);
+
+CREATE INDEX idx_duplicate_candidates_status ON duplicate_candidates(status, score);
+CREATE INDEX idx_duplicate_candidates_candidate ON duplicate_candidates(candidate_id);
+
+CREATE TABLE patient_merges (
+    id SERIAL PRIMARY KEY,
+    survivor_id INTEGER NOT NULL REFERENCES patients(id),
+    merged_id INTEGER NOT NULL REFERENCES patients(id),
+    moved JSONB NOT NULL DEFAULT '{}',
+    merged_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    unmerged_by INTEGER REFERENCES users(id),
+    unmerged_at TIMESTAMP,
+    CHECK (survivor_id <> merged_id),
+    CHECK ((unmerged_by IS NULL) = (unmerged_at IS NULL))
+);
+
+CREATE INDEX idx_patient_merges_survivor ON patient_merges(survivor_id);
+CREATE INDEX idx_patient_merges_merged ON patient_merges(merged_id);
+
+-- Signed notes stay immutable, except that a merge or unmerge may move them
+-- to another patient. The merge transaction sets hms.patient_merge locally.
+CREATE OR REPLACE FUNCTION encounter_notes_protect_signed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND
+       ROW(NEW.patient_id, NEW.author_id, NEW.subjective, NEW.objective, NEW.assessment, NEW.plan,
+           NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.patient_id, OLD.author_id, OLD.subjective, OLD.objective, OLD.assessment, OLD.plan,
+           OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE' AND current_setting('hms.patient_merge', true) = 'on' AND
+       ROW(NEW.author_id, NEW.subjective, NEW.objective, NEW.assessment, NEW.plan,
+           NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.author_id, OLD.subjective, OLD.objective, OLD.assessment, OLD.plan,
+           OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF OLD.status = 'signed' THEN
+        RAISE EXCEPTION 'encounter note % is signed and cannot be changed', OLD.id;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
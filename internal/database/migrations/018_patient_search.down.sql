@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_patients_last_name_metaphone;
+DROP INDEX IF EXISTS idx_patients_first_name_metaphone;
+DROP INDEX IF EXISTS idx_patients_phone_digits_trgm;
+DROP INDEX IF EXISTS idx_patients_email_trgm;
+DROP INDEX IF EXISTS idx_patients_last_name_trgm;
+DROP INDEX IF EXISTS idx_patients_first_name_trgm;
+DROP INDEX IF EXISTS idx_patients_search_vector;
+ALTER TABLE patients DROP COLUMN IF EXISTS phone_digits, DROP COLUMN IF EXISTS search_vector;
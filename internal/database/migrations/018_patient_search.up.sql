@@ -0,0 +1,21 @@
+-- Patient search: full-text prefix matching on names and email, trigram
+-- similarity for typos and partial names, double metaphone for names that
+-- sound alike, and phone numbers compared by their digits only.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE EXTENSION IF NOT EXISTS fuzzystrmatch;
+
+ALTER TABLE patients
+    ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+        to_tsvector('simple', first_name || ' ' || last_name || ' ' || COALESCE(email, ''))
+    ) STORED,
+    ADD COLUMN phone_digits VARCHAR(20) GENERATED ALWAYS AS (
+        regexp_replace(phone, '[^0-9]', '', 'g')
+    ) STORED;
+
+CREATE INDEX idx_patients_search_vector ON patients USING gin (search_vector);
+CREATE INDEX idx_patients_first_name_trgm ON patients USING gin (lower(first_name) gin_trgm_ops);
+CREATE INDEX idx_patients_last_name_trgm ON patients USING gin (lower(last_name) gin_trgm_ops);
+CREATE INDEX idx_patients_email_trgm ON patients USING gin (lower(email) gin_trgm_ops);
+CREATE INDEX idx_patients_phone_digits_trgm ON patients USING gin (phone_digits gin_trgm_ops);
+CREATE INDEX idx_patients_first_name_metaphone ON patients (dmetaphone(first_name));
+CREATE INDEX idx_patients_last_name_metaphone ON patients (dmetaphone(last_name));
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS patient_identifiers;
+DROP INDEX IF EXISTS idx_patients_mrn;
+ALTER TABLE patients DROP COLUMN IF EXISTS mrn;
+DROP TABLE IF EXISTS mrn_sequences;
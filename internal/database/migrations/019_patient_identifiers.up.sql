@@ -0,0 +1,26 @@
+-- Medical record numbers are drawn from one counter per facility. Rows are
+-- created on first use.
+CREATE TABLE mrn_sequences (
+    facility VARCHAR(10) PRIMARY KEY,
+    last_value BIGINT NOT NULL CHECK (last_value > 0)
+);
+
+-- Existing patients have no MRN until the startup backfill assigns one.
+ALTER TABLE patients ADD COLUMN mrn VARCHAR(32);
+CREATE UNIQUE INDEX idx_patients_mrn ON patients(mrn);
+
+-- Identifiers issued by other systems: national IDs, insurance member IDs
+-- and the IDs other applications know the patient by. A value belongs to
+-- at most one patient within its system.
+CREATE TABLE patient_identifiers (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('national_id', 'insurance', 'external')),
+    system VARCHAR(100) NOT NULL CHECK (system <> ''),
+    value VARCHAR(100) NOT NULL CHECK (value <> ''),
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (system, value)
+);
+
+CREATE INDEX idx_patient_identifiers_patient ON patient_identifiers(patient_id);
@@ -0,0 +1,12 @@
+-- Run `keys decrypt` first: encrypted phone numbers do not fit back into
+-- VARCHAR(20), and other encrypted values would be left unreadable.
+DROP INDEX IF EXISTS idx_patients_phone_index;
+ALTER TABLE patients DROP COLUMN IF EXISTS phone_index;
+ALTER TABLE patients ALTER COLUMN phone TYPE VARCHAR(20);
+CREATE INDEX idx_patients_phone ON patients(phone);
+
+ALTER TABLE patients
+    ADD COLUMN phone_digits VARCHAR(20) GENERATED ALWAYS AS (
+        regexp_replace(phone, '[^0-9]', '', 'g')
+    ) STORED;
+CREATE INDEX idx_patients_phone_digits_trgm ON patients USING gin (phone_digits gin_trgm_ops);
@@ -0,0 +1,15 @@
+-- Phone numbers, addresses, legacy patient notes and the legacy appointment
+-- diagnosis and treatment are encrypted by the application. Rows written
+-- before this migration stay in plain text, and are still read, until
+-- `keys rotate` encrypts them.
+--
+-- An encrypted phone number no longer fits in VARCHAR(20) and can no longer
+-- be searched by its digits, so the generated digits column and the plain
+-- phone index give way to a blind index: a keyed hash of the digits.
+DROP INDEX IF EXISTS idx_patients_phone_digits_trgm;
+ALTER TABLE patients DROP COLUMN IF EXISTS phone_digits;
+DROP INDEX IF EXISTS idx_patients_phone;
+
+ALTER TABLE patients ALTER COLUMN phone TYPE TEXT;
+ALTER TABLE patients ADD COLUMN phone_index VARCHAR(64);
+CREATE INDEX idx_patients_phone_index ON patients(phone_index);
@@ -0,0 +1,22 @@
+-- Each row is one decision a patient made on a consent form. The latest one
+-- of each type signed so far is in effect; earlier ones are history.
+CREATE TABLE patient_consents (
+    id SERIAL PRIMARY KEY,
+    patient_id INTEGER NOT NULL REFERENCES patients(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL CHECK (type IN ('treatment', 'data_sharing', 'sms', 'email', 'research')),
+    version VARCHAR(50) NOT NULL CHECK (version <> ''),
+    granted BOOLEAN NOT NULL,
+    signed_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    revoked_by INTEGER REFERENCES users(id),
+    revocation_reason TEXT,
+    recorded_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (expires_at IS NULL OR expires_at > signed_at),
+    CHECK ((revoked_at IS NULL) = (revoked_by IS NULL)),
+    CHECK (revoked_at IS NULL OR granted)
+);
+
+-- The decision in effect is found by patient and type, newest first.
+CREATE INDEX idx_patient_consents_effective ON patient_consents(patient_id, type, signed_at DESC, id DESC);
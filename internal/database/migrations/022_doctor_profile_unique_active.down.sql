@@ -0,0 +1,9 @@
+-- Fails if a deleted profile shares a user, email or license number with
+-- another profile; remove the deleted duplicates first.
+DROP INDEX IF EXISTS idx_doctors_user_id;
+DROP INDEX IF EXISTS idx_doctors_email;
+DROP INDEX IF EXISTS idx_doctors_license_number;
+
+ALTER TABLE doctors ADD CONSTRAINT doctors_user_id_key UNIQUE (user_id);
+ALTER TABLE doctors ADD CONSTRAINT doctors_email_key UNIQUE (email);
+ALTER TABLE doctors ADD CONSTRAINT doctors_license_number_key UNIQUE (license_number);
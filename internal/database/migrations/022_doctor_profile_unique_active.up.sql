@@ -0,0 +1,9 @@
+-- Doctor profiles are soft-deleted, so uniqueness only applies to profiles
+-- that are not deleted; otherwise a doctor could never get a profile again.
+ALTER TABLE doctors DROP CONSTRAINT IF EXISTS doctors_user_id_key;
+ALTER TABLE doctors DROP CONSTRAINT IF EXISTS doctors_email_key;
+ALTER TABLE doctors DROP CONSTRAINT IF EXISTS doctors_license_number_key;
+
+CREATE UNIQUE INDEX idx_doctors_user_id ON doctors(user_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_doctors_email ON doctors(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_doctors_license_number ON doctors(license_number) WHERE deleted_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_patients_phone_suffix_index;
+ALTER TABLE patients DROP COLUMN IF EXISTS phone_suffix_index;
@@ -0,0 +1,6 @@
+-- The blind index on phone numbers only finds whole numbers. A second blind
+-- index, hashed under its own name, holds the last four digits so a patient
+-- can be found from the end of their number or from a number written with a
+-- different prefix. Existing rows get it from `keys rotate`.
+ALTER TABLE patients ADD COLUMN phone_suffix_index VARCHAR(64);
+CREATE INDEX idx_patients_phone_suffix_index ON patients(phone_suffix_index);
@@ -0,0 +1,50 @@
+-- Run `keys decrypt` first: encrypted alerts are not JSON and encrypted drug
+-- codes do not fit back into VARCHAR(50).
+DROP TRIGGER encounter_note_revisions_no_update_delete ON encounter_note_revisions;
+DROP TRIGGER encounter_note_revisions_no_truncate ON encounter_note_revisions;
+DROP TRIGGER encounter_note_amendments_no_update_delete ON encounter_note_amendments;
+DROP TRIGGER encounter_note_amendments_no_truncate ON encounter_note_amendments;
+DROP FUNCTION encounter_note_history_protect();
+
+CREATE TRIGGER encounter_note_revisions_no_update_delete
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON encounter_note_revisions
+    FOR EACH STATEMENT EXECUTE FUNCTION encounter_note_history_append_only();
+
+CREATE TRIGGER encounter_note_amendments_no_update_delete
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON encounter_note_amendments
+    FOR EACH STATEMENT EXECUTE FUNCTION encounter_note_history_append_only();
+
+CREATE OR REPLACE FUNCTION encounter_notes_protect_signed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND
+       ROW(NEW.patient_id, NEW.author_id, NEW.subjective, NEW.objective, NEW.assessment, NEW.plan,
+           NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.patient_id, OLD.author_id, OLD.subjective, OLD.objective, OLD.assessment, OLD.plan,
+           OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE' AND current_setting('hms.patient_merge', true) = 'on' AND
+       ROW(NEW.author_id, NEW.subjective, NEW.objective, NEW.assessment, NEW.plan,
+           NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.author_id, OLD.subjective, OLD.objective, OLD.assessment, OLD.plan,
+           OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF OLD.status = 'signed' THEN
+        RAISE EXCEPTION 'encounter note % is signed and cannot be changed', OLD.id;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE prescriptions ALTER COLUMN alerts DROP DEFAULT;
+ALTER TABLE prescriptions ALTER COLUMN alerts TYPE JSONB USING alerts::jsonb;
+ALTER TABLE prescriptions ALTER COLUMN alerts SET DEFAULT '[]';
+ALTER TABLE prescriptions DROP CONSTRAINT IF EXISTS prescriptions_override_reason_check;
+ALTER TABLE prescriptions DROP COLUMN IF EXISTS blocked;
+ALTER TABLE prescriptions
+    ADD CONSTRAINT prescriptions_check CHECK (override_reason IS NOT NULL OR NOT jsonb_path_exists(alerts, '$[*] ? (@.severity == "block")'));
+ALTER TABLE prescriptions ALTER COLUMN drug_code TYPE VARCHAR(50);
@@ -0,0 +1,91 @@
+-- Encounter notes and their history, allergies, medications, problems,
+-- prescriptions and observation notes are encrypted by the application.
+-- Rows written before this migration stay in plain text, and are still
+-- read, until `keys rotate` encrypts them.
+
+-- An encrypted drug code no longer fits in VARCHAR(50), and encrypted alerts
+-- are no longer JSON. The rule that a blocking alert needs an override
+-- reason moves to a flag the application sets from the alerts.
+ALTER TABLE prescriptions ALTER COLUMN drug_code TYPE TEXT;
+ALTER TABLE prescriptions ADD COLUMN blocked BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE prescriptions SET blocked = jsonb_path_exists(alerts, '$[*] ? (@.severity == "block")');
+
+DO $$
+DECLARE
+    constraint_name TEXT;
+BEGIN
+    SELECT conname INTO constraint_name
+    FROM pg_constraint
+    WHERE conrelid = 'prescriptions'::regclass AND contype = 'c'
+      AND pg_get_constraintdef(oid) LIKE '%jsonb_path_exists%';
+    IF constraint_name IS NOT NULL THEN
+        EXECUTE format('ALTER TABLE prescriptions DROP CONSTRAINT %I', constraint_name);
+    END IF;
+END $$;
+
+ALTER TABLE prescriptions ADD CONSTRAINT prescriptions_override_reason_check
+    CHECK (override_reason IS NOT NULL OR NOT blocked);
+ALTER TABLE prescriptions ALTER COLUMN alerts DROP DEFAULT;
+ALTER TABLE prescriptions ALTER COLUMN alerts TYPE TEXT USING alerts::text;
+ALTER TABLE prescriptions ALTER COLUMN alerts SET DEFAULT '[]';
+
+-- Signed notes, revisions and amendments stay immutable, except that key
+-- rotation may rewrite their encrypted text. The rotation transaction sets
+-- hms.key_rotation locally; every other column must stay as it was.
+CREATE OR REPLACE FUNCTION encounter_notes_protect_signed() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND
+       ROW(NEW.patient_id, NEW.author_id, NEW.subjective, NEW.objective, NEW.assessment, NEW.plan,
+           NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.patient_id, OLD.author_id, OLD.subjective, OLD.objective, OLD.assessment, OLD.plan,
+           OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE' AND current_setting('hms.patient_merge', true) = 'on' AND
+       ROW(NEW.author_id, NEW.subjective, NEW.objective, NEW.assessment, NEW.plan,
+           NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.author_id, OLD.subjective, OLD.objective, OLD.assessment, OLD.plan,
+           OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF TG_OP = 'UPDATE' AND current_setting('hms.key_rotation', true) = 'on' AND
+       ROW(NEW.patient_id, NEW.appointment_id, NEW.author_id, NEW.status, NEW.version, NEW.signed_at, NEW.signed_by) IS NOT DISTINCT FROM
+       ROW(OLD.patient_id, OLD.appointment_id, OLD.author_id, OLD.status, OLD.version, OLD.signed_at, OLD.signed_by) THEN
+        RETURN NEW;
+    END IF;
+    IF OLD.status = 'signed' THEN
+        RAISE EXCEPTION 'encounter note % is signed and cannot be changed', OLD.id;
+    END IF;
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER encounter_note_revisions_no_update_delete ON encounter_note_revisions;
+DROP TRIGGER encounter_note_amendments_no_update_delete ON encounter_note_amendments;
+
+CREATE FUNCTION encounter_note_history_protect() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND current_setting('hms.key_rotation', true) = 'on' AND
+       (to_jsonb(NEW) - TG_ARGV) = (to_jsonb(OLD) - TG_ARGV) THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION '% is append-only', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER encounter_note_revisions_no_update_delete
+    BEFORE UPDATE OR DELETE ON encounter_note_revisions
+    FOR EACH ROW EXECUTE FUNCTION encounter_note_history_protect('subjective', 'objective', 'assessment', 'plan');
+CREATE TRIGGER encounter_note_revisions_no_truncate
+    BEFORE TRUNCATE ON encounter_note_revisions
+    FOR EACH STATEMENT EXECUTE FUNCTION encounter_note_history_append_only();
+
+CREATE TRIGGER encounter_note_amendments_no_update_delete
+    BEFORE UPDATE OR DELETE ON encounter_note_amendments
+    FOR EACH ROW EXECUTE FUNCTION encounter_note_history_protect('content', 'reason');
+CREATE TRIGGER encounter_note_amendments_no_truncate
+    BEFORE TRUNCATE ON encounter_note_amendments
+    FOR EACH STATEMENT EXECUTE FUNCTION encounter_note_history_append_only();
@@ -0,0 +1,459 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// migrationLockKey identifies the advisory lock that serializes migration
+// runs across replicas.
+const migrationLockKey = 72_011_001
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// LegacyBaseline is the last migration databases created before the
+// migration runner already have: docker's initdb ran the same SQL as
+// migrations 001-003, and GORM's AutoMigrate created the same tables.
+const LegacyBaseline = 3
+
+// legacyColumns are the columns migrations 001-003 create that the later
+// migrations and the application rely on.
+var legacyColumns = map[string][]string{
+	"users": {"id", "username", "email", "password", "role", "first_name", "last_name", "created_at", "updated_at"},
+	"patients": {"id", "first_name", "last_name", "email", "phone", "date_of_birth", "gender", "address",
+		"medical_history", "allergies", "medications", "created_by", "updated_by", "created_at", "updated_at"},
+	"appointments": {"id", "patient_id", "doctor_id", "date_time", "duration", "status", "notes",
+		"diagnosis", "treatment", "created_by", "created_at", "updated_at"},
+}
+
+// Migration is one versioned schema change with its rollback.
+type Migration struct {
+	Version  int
+	Name     string
+	UpSQL    string
+	DownSQL  string
+	Checksum string // SHA-256 of UpSQL
+}
+
+// MigrationStatus describes a migration as seen by the database.
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+	// State is "applied", "pending", "modified" (applied, but the file has
+	// changed since) or "missing" (applied, but no longer shipped).
+	State string `json:"state"`
+}
+
+// Migrator applies the embedded SQL migrations and records them in the
+// schema_migrations table.
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a Migrator over the migrations embedded in the binary.
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	sub, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	migrations, err := LoadMigrations(sub)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations reads NNN_name.up.sql / NNN_name.down.sql pairs from fsys,
+// ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.UpSQL = string(content)
+			sum := sha256.Sum256(content)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.UpSQL == "" {
+			return nil, fmt.Errorf("migration %03d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies up to steps pending migrations (all of them when steps <= 0)
+// and returns the ones applied. It refuses to run if an applied migration's
+// file has been modified. A database that predates the migration runner is
+// adopted first: migrations up to LegacyBaseline are recorded as applied
+// without running them.
+func (m *Migrator) Up(ctx context.Context, steps int) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		records, err := m.appliedRecords(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			if err := m.adoptLegacySchema(ctx, conn); err != nil {
+				return err
+			}
+			if records, err = m.appliedRecords(ctx, conn); err != nil {
+				return err
+			}
+		}
+		if err := m.verify(records); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, done := records[migration.Version]; done {
+				continue
+			}
+			if steps > 0 && len(applied) == steps {
+				break
+			}
+			if err := m.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Baseline records every migration up to version as applied without running
+// it, for a database whose schema already has those changes. It returns the
+// migrations recorded.
+func (m *Migrator) Baseline(ctx context.Context, version int) ([]Migration, error) {
+	var recorded []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		records, err := m.appliedRecords(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.verify(records); err != nil {
+			return err
+		}
+		recorded, err = m.record(ctx, conn, records, version)
+		return err
+	})
+	return recorded, err
+}
+
+// Down rolls back the most recently applied migrations, one by default.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+
+	var rolledBack []Migration
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		records, err := m.appliedRecords(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+			migration := m.migrations[i]
+			if _, done := records[migration.Version]; !done {
+				continue
+			}
+			if migration.DownSQL == "" {
+				return fmt.Errorf("migration %03d_%s has no down file", migration.Version, migration.Name)
+			}
+			if err := m.apply(ctx, conn, migration, false); err != nil {
+				return err
+			}
+			rolledBack = append(rolledBack, migration)
+		}
+		return nil
+	})
+	return rolledBack, err
+}
+
+// Status reports every known migration, plus any applied version that is no
+// longer shipped.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.withLock(ctx, func(conn *sql.Conn) error {
+		records, err := m.appliedRecords(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[int]bool, len(m.migrations))
+		for _, migration := range m.migrations {
+			known[migration.Version] = true
+			status := MigrationStatus{Version: migration.Version, Name: migration.Name, State: "pending"}
+			if record, ok := records[migration.Version]; ok {
+				appliedAt := record.appliedAt
+				status.AppliedAt = &appliedAt
+				status.State = "applied"
+				if record.checksum != migration.Checksum {
+					status.State = "modified"
+				}
+			}
+			statuses = append(statuses, status)
+		}
+
+		for version, record := range records {
+			if known[version] {
+				continue
+			}
+			appliedAt := record.appliedAt
+			statuses = append(statuses, MigrationStatus{Version: version, Name: record.name, AppliedAt: &appliedAt, State: "missing"})
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+		return nil
+	})
+	return statuses, err
+}
+
+// CreateMigration writes an empty up/down pair named after the next free
+// version into dir and returns their paths.
+func CreateMigration(dir, name string) (string, string, error) {
+	name = strings.Trim(strings.ToLower(regexp.MustCompile(`[^a-zA-Z0-9]+`).ReplaceAllString(name, "_")), "_")
+	if name == "" {
+		return "", "", fmt.Errorf("migration name is required")
+	}
+
+	existing, err := LoadMigrations(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+	version := 1
+	if len(existing) > 0 {
+		version = existing[len(existing)-1].Version + 1
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%03d_%s", version, name))
+	upPath, downPath := base+".up.sql", base+".down.sql"
+	if err := os.WriteFile(upPath, []byte("-- Write the schema change here.\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create migration: %w", err)
+	}
+	if err := os.WriteFile(downPath, []byte("-- Write the statements that undo the up migration here.\n"), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to create migration: %w", err)
+	}
+	return upPath, downPath, nil
+}
+
+type migrationRecord struct {
+	name      string
+	checksum  string
+	appliedAt time.Time
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock, so concurrently starting replicas apply migrations one at a time.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum VARCHAR(64) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) appliedRecords(ctx context.Context, conn *sql.Conn) (map[int]migrationRecord, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	records := make(map[int]migrationRecord)
+	for rows.Next() {
+		var version int
+		var record migrationRecord
+		if err := rows.Scan(&version, &record.name, &record.checksum, &record.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+		}
+		records[version] = record
+	}
+	return records, rows.Err()
+}
+
+// verify fails if any applied migration's up file no longer matches the
+// checksum recorded when it ran.
+func (m *Migrator) verify(records map[int]migrationRecord) error {
+	for _, migration := range m.migrations {
+		record, ok := records[migration.Version]
+		if ok && record.checksum != migration.Checksum {
+			return fmt.Errorf("migration %03d_%s was modified after it was applied (checksum mismatch)", migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// adoptLegacySchema baselines a database whose tables were created before
+// the migration runner, so migration 001 does not fail on the existing
+// users table. A fresh database is left alone, and one whose tables do not
+// match migrations 001-003 is refused rather than half migrated.
+func (m *Migrator) adoptLegacySchema(ctx context.Context, conn *sql.Conn) error {
+	var exists bool
+	if err := conn.QueryRowContext(ctx, "SELECT to_regclass('public.users') IS NOT NULL").Scan(&exists); err != nil {
+		return fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	var missing []string
+	for table, columns := range legacyColumns {
+		rows, err := conn.QueryContext(ctx,
+			"SELECT column_name FROM information_schema.columns WHERE table_schema = 'public' AND table_name = $1", table)
+		if err != nil {
+			return fmt.Errorf("failed to inspect schema: %w", err)
+		}
+		present := make(map[string]bool)
+		for rows.Next() {
+			var column string
+			if err := rows.Scan(&column); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to inspect schema: %w", err)
+			}
+			present[column] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to inspect schema: %w", err)
+		}
+		for _, column := range columns {
+			if !present[column] {
+				missing = append(missing, table+"."+column)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("the database has tables from before versioned migrations, but is missing %s; "+
+			"bring the schema in line with migrations 001-%03d and run `migrate baseline %d`",
+			strings.Join(missing, ", "), LegacyBaseline, LegacyBaseline)
+	}
+
+	_, err := m.record(ctx, conn, nil, LegacyBaseline)
+	return err
+}
+
+// record inserts bookkeeping rows for every migration up to version that is
+// not in records, without running them.
+func (m *Migrator) record(ctx context.Context, conn *sql.Conn, records map[int]migrationRecord, version int) ([]Migration, error) {
+	var recorded []Migration
+	for _, migration := range m.migrations {
+		if migration.Version > version {
+			break
+		}
+		if _, done := records[migration.Version]; done {
+			continue
+		}
+		if _, err := conn.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum); err != nil {
+			return recorded, fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+		}
+		recorded = append(recorded, migration)
+	}
+	return recorded, nil
+}
+
+// apply runs one migration in either direction inside a transaction together
+// with its bookkeeping row.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	script, direction := migration.UpSQL, "up"
+	if !up {
+		script, direction = migration.DownSQL, "down"
+	}
+
+	if check, ok := preconditions[migration.Version]; ok && up {
+		if err := check(ctx, tx); err != nil {
+			return fmt.Errorf("migration %03d_%s cannot be applied: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %03d_%s %s failed: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+			migration.Version, migration.Name, migration.Checksum)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %03d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Commit()
+}
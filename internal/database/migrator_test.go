@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newMockMigrator(t *testing.T) (*Migrator, sqlmock.Sqlmock, func()) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator, mock, func() { db.Close() }
+}
+
+func expectColumns(mock sqlmock.Sqlmock, table string, columns []string) {
+	rows := sqlmock.NewRows([]string{"column_name"})
+	for _, column := range columns {
+		rows.AddRow(column)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta("FROM information_schema.columns")).WithArgs(table).WillReturnRows(rows)
+}
+
+func TestEmbeddedMigrationsAreComplete(t *testing.T) {
+	migrator, _, done := newMockMigrator(t)
+	defer done()
+
+	for i, m := range migrator.migrations {
+		if m.Version != i+1 {
+			t.Fatalf("migration %03d_%s is out of sequence, want version %d", m.Version, m.Name, i+1)
+		}
+		if m.DownSQL == "" {
+			t.Errorf("migration %03d_%s has no down file", m.Version, m.Name)
+		}
+	}
+}
+
+func TestAdoptLegacySchemaLeavesFreshDatabase(t *testing.T) {
+	migrator, mock, done := newMockMigrator(t)
+	defer done()
+
+	mock.ExpectQuery(regexp.QuoteMeta("to_regclass")).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	conn, err := migrator.db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.adoptLegacySchema(context.Background(), conn); err != nil {
+		t.Fatalf("adopting a fresh database: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdoptLegacySchemaRecordsBaseline(t *testing.T) {
+	migrator, mock, done := newMockMigrator(t)
+	defer done()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery(regexp.QuoteMeta("to_regclass")).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	for table, columns := range legacyColumns {
+		expectColumns(mock, table, columns)
+	}
+	for _, m := range migrator.migrations[:LegacyBaseline] {
+		mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations")).
+			WithArgs(m.Version, m.Name, m.Checksum).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+
+	conn, err := migrator.db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.adoptLegacySchema(context.Background(), conn); err != nil {
+		t.Fatalf("adopting a legacy database: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAdoptLegacySchemaRefusesMismatch(t *testing.T) {
+	migrator, mock, done := newMockMigrator(t)
+	defer done()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery(regexp.QuoteMeta("to_regclass")).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	for table, columns := range legacyColumns {
+		if table == "users" {
+			// AutoMigrate named the column after the Go field
+			columns = append([]string{"id", "name"}, columns[2:]...)
+		}
+		expectColumns(mock, table, columns)
+	}
+
+	conn, err := migrator.db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = migrator.adoptLegacySchema(context.Background(), conn)
+	if err == nil || !strings.Contains(err.Error(), "users.username") {
+		t.Fatalf("got %v, want the missing users.username column reported", err)
+	}
+}
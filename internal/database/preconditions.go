@@ -0,0 +1,75 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// maxReportedConflicts caps how many conflicting rows a failed precondition
+// lists; the rest are only counted.
+const maxReportedConflicts = 20
+
+// preconditions check, before a migration's up script runs and inside its
+// transaction, for existing data the script would fail on. They fail with
+// the offending rows listed, so they can be fixed by hand, instead of
+// leaving the operator with a bare constraint violation.
+var preconditions = map[int]func(ctx context.Context, tx *sql.Tx) error{
+	6:  noOverlappingAppointments("scheduled"),
+	10: noOverlappingAppointments("scheduled", "confirmed", "checked_in", "in_progress"),
+}
+
+// noOverlappingAppointments fails if any doctor has two appointments in one
+// of statuses whose times overlap, which the appointments_no_double_booking
+// exclusion constraint would reject.
+func noOverlappingAppointments(statuses ...string) func(ctx context.Context, tx *sql.Tx) error {
+	quoted := make([]string, len(statuses))
+	for i, status := range statuses {
+		quoted[i] = "'" + status + "'"
+	}
+	active := strings.Join(quoted, ", ")
+	query := fmt.Sprintf(`
+		SELECT a.doctor_id, a.id, b.id
+		FROM appointments a
+		JOIN appointments b ON b.doctor_id = a.doctor_id AND b.id > a.id
+			AND tsrange(a.date_time, a.date_time + a.duration * interval '1 minute')
+				&& tsrange(b.date_time, b.date_time + b.duration * interval '1 minute')
+		WHERE a.status IN (%[1]s) AND b.status IN (%[1]s)
+		ORDER BY a.doctor_id, a.id, b.id`, active)
+
+	return func(ctx context.Context, tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("failed to look for overlapping appointments: %w", err)
+		}
+		defer rows.Close()
+
+		var pairs []string
+		total := 0
+		for rows.Next() {
+			var doctorID, first, second int64
+			if err := rows.Scan(&doctorID, &first, &second); err != nil {
+				return fmt.Errorf("failed to look for overlapping appointments: %w", err)
+			}
+			total++
+			if len(pairs) < maxReportedConflicts {
+				pairs = append(pairs, fmt.Sprintf("%d and %d (doctor %d)", first, second, doctorID))
+			}
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("failed to look for overlapping appointments: %w", err)
+		}
+		if total == 0 {
+			return nil
+		}
+
+		listed := strings.Join(pairs, ", ")
+		if total > len(pairs) {
+			listed += fmt.Sprintf(" and %d more", total-len(pairs))
+		}
+		return fmt.Errorf("%d pairs of overlapping %s appointments are double-booked: %s; "+
+			"cancel or reschedule one appointment of each pair and run the migrations again",
+			total, strings.Join(statuses, "/"), listed)
+	}
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func migrationByVersion(t *testing.T, migrator *Migrator, version int) Migration {
+	t.Helper()
+	for _, m := range migrator.migrations {
+		if m.Version == version {
+			return m
+		}
+	}
+	t.Fatalf("no migration %d", version)
+	return Migration{}
+}
+
+func TestApplyRefusesOverlappingAppointments(t *testing.T) {
+	for _, version := range []int{6, 10} {
+		migrator, mock, done := newMockMigrator(t)
+		migration := migrationByVersion(t, migrator, version)
+
+		// Doctor 3 has appointment 12 overlapping both 15 and 16; doctor 4's
+		// pair overlaps too.
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta("FROM appointments a")).
+			WillReturnRows(sqlmock.NewRows([]string{"doctor_id", "id", "id"}).
+				AddRow(3, 12, 15).
+				AddRow(3, 12, 16).
+				AddRow(4, 20, 21))
+		mock.ExpectRollback()
+
+		conn, err := migrator.db.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = migrator.apply(context.Background(), conn, migration, true)
+		if err == nil {
+			t.Fatalf("migration %03d applied over overlapping appointments", version)
+		}
+		for _, want := range []string{"3 pairs", "12 and 15 (doctor 3)", "12 and 16 (doctor 3)", "20 and 21 (doctor 4)"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("migration %03d: error %q does not mention %q", version, err, want)
+			}
+		}
+		// The script itself must not have run.
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		done()
+	}
+}
+
+func TestApplyRunsMigrationWithoutOverlaps(t *testing.T) {
+	migrator, mock, done := newMockMigrator(t)
+	defer done()
+	migration := migrationByVersion(t, migrator, 6)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM appointments a")).
+		WillReturnRows(sqlmock.NewRows([]string{"doctor_id", "id", "id"}))
+	mock.ExpectExec(regexp.QuoteMeta("ADD CONSTRAINT appointments_no_double_booking")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO schema_migrations")).
+		WithArgs(migration.Version, migration.Name, migration.Checksum).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	conn, err := migrator.db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.apply(context.Background(), conn, migration, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOverlapCheckCoversActiveStatuses(t *testing.T) {
+	migrator, mock, done := newMockMigrator(t)
+	defer done()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE a.status IN ('scheduled', 'confirmed', 'checked_in', 'in_progress') AND b.status IN ('scheduled', 'confirmed', 'checked_in', 'in_progress')")).
+		WillReturnRows(sqlmock.NewRows([]string{"doctor_id", "id", "id"}))
+	mock.ExpectRollback()
+
+	tx, err := migrator.db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := preconditions[10](context.Background(), tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
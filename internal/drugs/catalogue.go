@@ -0,0 +1,217 @@
+// Package drugs provides the local drug catalogue prescriptions are written
+// against.
+package drugs
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Drug is a catalogue entry. Ingredients and allergy groups drive the
+// allergy checks; Class drives the duplicate-therapy checks.
+type Drug struct {
+	Code          string   `json:"code"`
+	Name          string   `json:"name"`
+	BrandNames    []string `json:"brand_names,omitempty"`
+	Class         string   `json:"class,omitempty"`
+	Ingredients   []string `json:"ingredients,omitempty"`
+	AllergyGroups []string `json:"allergy_groups,omitempty"`
+	Forms         []string `json:"forms,omitempty"`
+}
+
+// Terms returns the normalized names an allergy or another drug may be
+// recorded under: the drug name, its brand names, its ingredients and its
+// allergy groups.
+func (d *Drug) Terms() []string {
+	terms := []string{Normalize(d.Name)}
+	for _, list := range [][]string{d.BrandNames, d.Ingredients, d.AllergyGroups} {
+		for _, term := range list {
+			terms = append(terms, Normalize(term))
+		}
+	}
+	return terms
+}
+
+// ActiveIngredients returns the normalized ingredients, or the drug name when
+// no ingredients are listed.
+func (d *Drug) ActiveIngredients() []string {
+	if len(d.Ingredients) == 0 {
+		return []string{Normalize(d.Name)}
+	}
+	ingredients := make([]string, len(d.Ingredients))
+	for i, ingredient := range d.Ingredients {
+		ingredients[i] = Normalize(ingredient)
+	}
+	return ingredients
+}
+
+// Catalogue looks up drugs by code, name or brand name.
+type Catalogue interface {
+	// Lookup finds a drug by code, name or brand name, ignoring case.
+	Lookup(name string) (*Drug, bool)
+	// Search returns up to limit drugs whose name or a brand name starts
+	// with or contains query, prefix matches first.
+	Search(query string, limit int) []*Drug
+}
+
+// Normalize folds a drug or substance name for comparison.
+func Normalize(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+type memoryCatalogue struct {
+	drugs []*Drug
+	index map[string]*Drug
+}
+
+// NewCatalogue builds an in-memory catalogue. Codes and names must be unique.
+func NewCatalogue(drugs []*Drug) (Catalogue, error) {
+	c := &memoryCatalogue{index: make(map[string]*Drug)}
+	for _, drug := range drugs {
+		if strings.TrimSpace(drug.Name) == "" {
+			return nil, fmt.Errorf("drug %q has no name", drug.Code)
+		}
+		keys := append([]string{drug.Code, drug.Name}, drug.BrandNames...)
+		for _, key := range keys {
+			key = Normalize(key)
+			if key == "" {
+				continue
+			}
+			if existing, ok := c.index[key]; ok && existing != drug {
+				return nil, fmt.Errorf("drug catalogue lists %q more than once", key)
+			}
+			c.index[key] = drug
+		}
+		c.drugs = append(c.drugs, drug)
+	}
+	sort.Slice(c.drugs, func(i, j int) bool { return c.drugs[i].Name < c.drugs[j].Name })
+	return c, nil
+}
+
+func (c *memoryCatalogue) Lookup(name string) (*Drug, bool) {
+	drug, ok := c.index[Normalize(name)]
+	return drug, ok
+}
+
+func (c *memoryCatalogue) Search(query string, limit int) []*Drug {
+	query = Normalize(query)
+	var prefix, contains []*Drug
+	for _, drug := range c.drugs {
+		best := 0
+		for _, name := range append([]string{drug.Name}, drug.BrandNames...) {
+			name = Normalize(name)
+			if strings.HasPrefix(name, query) {
+				best = 2
+				break
+			}
+			if strings.Contains(name, query) {
+				best = 1
+			}
+		}
+		switch best {
+		case 2:
+			prefix = append(prefix, drug)
+		case 1:
+			contains = append(contains, drug)
+		}
+	}
+	results := append(prefix, contains...)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// LoadCatalogue reads a catalogue from a .json file holding an array of
+// drugs, or from a .csv file with a header row naming the columns code, name,
+// brand_names, class, ingredients, allergy_groups and forms. List columns are
+// separated by semicolons. An empty path yields an empty catalogue.
+func LoadCatalogue(path string) (Catalogue, error) {
+	if path == "" {
+		return NewCatalogue(nil)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open drug catalogue: %w", err)
+	}
+	defer f.Close()
+
+	var drugs []*Drug
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.NewDecoder(f).Decode(&drugs); err != nil {
+			return nil, fmt.Errorf("failed to parse drug catalogue: %w", err)
+		}
+	case ".csv":
+		drugs, err = readCSV(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse drug catalogue: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("drug catalogue must be a .json or .csv file")
+	}
+
+	return NewCatalogue(drugs)
+}
+
+func readCSV(r io.Reader) ([]*Drug, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("missing header row: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[Normalize(name)] = i
+	}
+	if _, ok := columns["name"]; !ok {
+		return nil, errors.New("header has no name column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	list := func(record []string, name string) []string {
+		var values []string
+		for _, value := range strings.Split(field(record, name), ";") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+		return values
+	}
+
+	var drugs []*Drug
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		drugs = append(drugs, &Drug{
+			Code:          field(record, "code"),
+			Name:          field(record, "name"),
+			BrandNames:    list(record, "brand_names"),
+			Class:         field(record, "class"),
+			Ingredients:   list(record, "ingredients"),
+			AllergyGroups: list(record, "allergy_groups"),
+			Forms:         list(record, "forms"),
+		})
+	}
+	return drugs, nil
+}
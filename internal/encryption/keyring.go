@@ -0,0 +1,241 @@
+// Package encryption encrypts sensitive fields at rest.
+//
+// Each value is sealed with AES-256-GCM under a fresh data key, and the data
+// key is itself sealed under a master key (envelope encryption). A stored
+// value reads
+//
+//	enc:v1:<master key ID>:<base64 wrapped data key>:<base64 nonce and ciphertext>
+//
+// so rotating the master key only needs the rows still naming an old key
+// ID to be re-encrypted. The column name is bound to the ciphertext as
+// additional data, so a value copied into another column fails to decrypt.
+// Values without the prefix are plaintext written before encryption was
+// enabled; they are read as they are until re-encrypted.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// Prefix starts every encrypted value.
+	Prefix = "enc:v1:"
+	// KeySize is the size in bytes of master keys and data keys.
+	KeySize = 32
+)
+
+// ErrNoKeys is returned when neither a keystore file nor master keys are
+// configured.
+var ErrNoKeys = errors.New("no encryption keys configured: set ENCRYPTION_KEYS or ENCRYPTION_KEYSTORE_FILE")
+
+// Keyring holds the master keys values can be decrypted with, the one new
+// values are encrypted with and the key blind indexes are computed with.
+type Keyring struct {
+	masters  map[string]cipher.AEAD
+	activeID string
+	indexKey []byte
+}
+
+// NewKeyring builds a keyring from base64-encoded keys. activeID may be
+// left empty when there is only one master key.
+func NewKeyring(keys map[string]string, activeID, indexKey string) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	if activeID == "" && len(keys) == 1 {
+		for id := range keys {
+			activeID = id
+		}
+	}
+	if _, ok := keys[activeID]; !ok {
+		return nil, fmt.Errorf("active encryption key %q is not configured", activeID)
+	}
+
+	k := &Keyring{masters: make(map[string]cipher.AEAD, len(keys)), activeID: activeID}
+	for id, encoded := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("encryption key ID %q must be non-empty and must not contain ':'", id)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q: %w", id, err)
+		}
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q: %w", id, err)
+		}
+		k.masters[id] = aead
+	}
+
+	if indexKey == "" {
+		return nil, errors.New("no blind index key configured: set BLIND_INDEX_KEY or blind_index_key in the keystore")
+	}
+	key, err := decodeKey(indexKey)
+	if err != nil {
+		return nil, fmt.Errorf("blind index key: %w", err)
+	}
+	k.indexKey = key
+	return k, nil
+}
+
+// ActiveKeyID returns the ID of the master key new values are encrypted
+// with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.activeID
+}
+
+// KeyIDs returns the IDs of all master keys, sorted.
+func (k *Keyring) KeyIDs() []string {
+	ids := make([]string, 0, len(k.masters))
+	for id := range k.masters {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Encrypt seals plaintext for the named column under a new data key
+// wrapped by the active master key.
+func (k *Keyring) Encrypt(plaintext, column string) (string, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrapped, err := seal(k.masters[k.activeID], dataKey, []byte(k.activeID))
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(aead, []byte(plaintext), []byte(column))
+	if err != nil {
+		return "", err
+	}
+	return Prefix + k.activeID + ":" +
+		base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value stored in the named column. Plaintext written
+// before encryption was enabled is returned unchanged.
+func (k *Keyring) Decrypt(value, column string) (string, error) {
+	if !strings.HasPrefix(value, Prefix) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed encrypted value in column %s", column)
+	}
+	master, ok := k.masters[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("column %s is encrypted with unknown key %q", column, parts[0])
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value in column %s: %w", column, err)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value in column %s: %w", column, err)
+	}
+
+	dataKey, err := open(master, wrapped, []byte(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("failed to unwrap data key for column %s: %w", column, err)
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, sealed, []byte(column))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt column %s: %w", column, err)
+	}
+	return string(plaintext), nil
+}
+
+// NeedsRotation reports whether a stored value is plaintext or encrypted
+// with a master key other than the active one.
+func (k *Keyring) NeedsRotation(value string) bool {
+	id, ok := KeyID(value)
+	return !ok || id != k.activeID
+}
+
+// BlindIndex returns a keyed hash of value for exact-match lookups on an
+// encrypted column. name separates the indexes of different fields, so
+// equal values in two fields do not share an index.
+func (k *Keyring) BlindIndex(name, value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// KeyID returns the ID of the master key a stored value is encrypted with.
+// It reports false for plaintext.
+func KeyID(value string) (string, bool) {
+	if !strings.HasPrefix(value, Prefix) {
+		return "", false
+	}
+	id, _, ok := strings.Cut(strings.TrimPrefix(value, Prefix), ":")
+	return id, ok
+}
+
+// GenerateKey returns a new random base64-encoded key.
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("not valid base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("must be %d bytes, got %d", KeySize, len(key))
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce, which is prepended to the
+// result.
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
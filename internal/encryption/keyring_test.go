@@ -0,0 +1,176 @@
+package encryption
+
+import (
+	"strings"
+	"testing"
+)
+
+func mustKey(t *testing.T) string {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// newTestKeyring returns a keyring with the given master key IDs, the last
+// one active, and a fresh blind index key.
+func newTestKeyring(t *testing.T, ids ...string) (*Keyring, map[string]string, string) {
+	t.Helper()
+	keys := make(map[string]string, len(ids))
+	for _, id := range ids {
+		keys[id] = mustKey(t)
+	}
+	indexKey := mustKey(t)
+	k, err := NewKeyring(keys, ids[len(ids)-1], indexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k, keys, indexKey
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	k, _, _ := newTestKeyring(t, "k1")
+
+	for _, plaintext := range []string{"+1 555 123 4567", "ünïcödé ✓", strings.Repeat("long note ", 1000)} {
+		stored, err := k.Encrypt(plaintext, "phone")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(stored, Prefix+"k1:") {
+			t.Fatalf("stored value %q does not name its key", stored[:20])
+		}
+		if strings.Contains(stored, plaintext) {
+			t.Fatal("stored value contains the plaintext")
+		}
+		got, err := k.Decrypt(stored, "phone")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != plaintext {
+			t.Fatalf("round trip = %q, want %q", got, plaintext)
+		}
+	}
+}
+
+func TestEncryptUsesFreshDataKeys(t *testing.T) {
+	k, _, _ := newTestKeyring(t, "k1")
+	a, _ := k.Encrypt("same", "address")
+	b, _ := k.Encrypt("same", "address")
+	if a == b {
+		t.Fatal("equal plaintexts encrypt to equal values")
+	}
+}
+
+func TestDecryptBindsColumn(t *testing.T) {
+	k, _, _ := newTestKeyring(t, "k1")
+	stored, err := k.Encrypt("penicillin", "substance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := k.Decrypt(stored, "reaction"); err == nil {
+		t.Fatal("a value copied into another column decrypted")
+	}
+}
+
+func TestDecryptPassesPlaintextThrough(t *testing.T) {
+	k, _, _ := newTestKeyring(t, "k1")
+	got, err := k.Decrypt("written before encryption", "content")
+	if err != nil || got != "written before encryption" {
+		t.Fatalf("Decrypt(plaintext) = %q, %v", got, err)
+	}
+	if !k.NeedsRotation("written before encryption") {
+		t.Fatal("plaintext does not need rotation")
+	}
+}
+
+func TestDecryptRejectsTamperingAndUnknownKeys(t *testing.T) {
+	k, _, _ := newTestKeyring(t, "k1")
+	stored, _ := k.Encrypt("secret", "plan")
+
+	tampered := stored[:len(stored)-2] + "AA"
+	if tampered == stored {
+		tampered = stored[:len(stored)-2] + "BB"
+	}
+	if _, err := k.Decrypt(tampered, "plan"); err == nil {
+		t.Error("tampered ciphertext decrypted")
+	}
+	if _, err := k.Decrypt(strings.Replace(stored, ":k1:", ":k9:", 1), "plan"); err == nil {
+		t.Error("value naming an unknown key decrypted")
+	}
+	if _, err := k.Decrypt(Prefix+"k1:only-two", "plan"); err == nil {
+		t.Error("malformed value decrypted")
+	}
+}
+
+func TestRotationKeepsOldKeysReadable(t *testing.T) {
+	old, keys, indexKey := newTestKeyring(t, "k1")
+	stored, _ := old.Encrypt("signed note", "subjective")
+
+	keys["k2"] = mustKey(t)
+	rotated, err := NewKeyring(keys, "k2", indexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rotated.NeedsRotation(stored) {
+		t.Fatal("value under the old key does not need rotation")
+	}
+	got, err := rotated.Decrypt(stored, "subjective")
+	if err != nil || got != "signed note" {
+		t.Fatalf("Decrypt under the old key = %q, %v", got, err)
+	}
+
+	restored, _ := rotated.Encrypt(got, "subjective")
+	if id, _ := KeyID(restored); id != "k2" || rotated.NeedsRotation(restored) {
+		t.Fatalf("re-encrypted value uses key %q", id)
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	k, keys, _ := newTestKeyring(t, "k1")
+
+	a := k.BlindIndex("phone", "5551234567")
+	if a != k.BlindIndex("phone", "5551234567") {
+		t.Fatal("blind index is not deterministic")
+	}
+	if len(a) != 64 || strings.Contains(a, "5551234567") {
+		t.Fatalf("blind index %q is not a hex SHA-256 MAC", a)
+	}
+	if a == k.BlindIndex("phone", "5551234568") {
+		t.Fatal("different values share a blind index")
+	}
+	if a == k.BlindIndex("phone_suffix", "5551234567") {
+		t.Fatal("indexes of different names are not separated")
+	}
+
+	other, err := NewKeyring(keys, "k1", mustKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == other.BlindIndex("phone", "5551234567") {
+		t.Fatal("blind index does not depend on the index key")
+	}
+}
+
+func TestNewKeyringValidatesKeys(t *testing.T) {
+	key, indexKey := mustKey(t), mustKey(t)
+	tests := []struct {
+		name     string
+		keys     map[string]string
+		activeID string
+		indexKey string
+	}{
+		{"no keys", nil, "", indexKey},
+		{"unknown active key", map[string]string{"k1": key}, "k2", indexKey},
+		{"ambiguous active key", map[string]string{"k1": key, "k2": key}, "", indexKey},
+		{"colon in key ID", map[string]string{"k:1": key}, "k:1", indexKey},
+		{"short key", map[string]string{"k1": "c2hvcnQ="}, "k1", indexKey},
+		{"no index key", map[string]string{"k1": key}, "k1", ""},
+	}
+	for _, tt := range tests {
+		if _, err := NewKeyring(tt.keys, tt.activeID, tt.indexKey); err == nil {
+			t.Errorf("%s: NewKeyring succeeded, want an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package encryption
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Keystore is the JSON file master keys can be kept in instead of the
+// environment. Keys are base64-encoded.
+type Keystore struct {
+	ActiveKeyID   string            `json:"active_key_id"`
+	Keys          map[string]string `json:"keys"`
+	BlindIndexKey string            `json:"blind_index_key"`
+}
+
+// Load builds the keyring from the keystore file when one is given and from
+// the keys passed in otherwise.
+func Load(keystoreFile string, keys map[string]string, activeID, indexKey string) (*Keyring, error) {
+	if keystoreFile == "" {
+		return NewKeyring(keys, activeID, indexKey)
+	}
+	store, err := ReadKeystore(keystoreFile)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeyring(store.Keys, store.ActiveKeyID, store.BlindIndexKey)
+}
+
+// ReadKeystore reads a keystore file.
+func ReadKeystore(path string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore: %w", err)
+	}
+	var store Keystore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse keystore %s: %w", path, err)
+	}
+	return &store, nil
+}
+
+// AddKey adds a new master key to the keystore file and makes it the
+// active one, creating the file with a new blind index key if it does not
+// exist. Existing keys are kept so that values encrypted with them can
+// still be read until they are rotated.
+func AddKey(path, id string) error {
+	store, err := ReadKeystore(path)
+	if errors.Is(err, os.ErrNotExist) {
+		indexKey, genErr := GenerateKey()
+		if genErr != nil {
+			return genErr
+		}
+		store, err = &Keystore{Keys: map[string]string{}, BlindIndexKey: indexKey}, nil
+	}
+	if err != nil {
+		return err
+	}
+	if store.Keys == nil {
+		store.Keys = map[string]string{}
+	}
+	if _, ok := store.Keys[id]; ok {
+		return fmt.Errorf("keystore already has a key %q", id)
+	}
+
+	key, err := GenerateKey()
+	if err != nil {
+		return err
+	}
+	store.Keys[id] = key
+	store.ActiveKeyID = id
+	// Refuse to write a keystore the server could not load.
+	if _, err := NewKeyring(store.Keys, store.ActiveKeyID, store.BlindIndexKey); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode keystore: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write keystore: %w", err)
+	}
+	return nil
+}
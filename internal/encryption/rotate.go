@@ -0,0 +1,241 @@
+package encryption
+
+import (
+	"fmt"
+
+	"hospital-management/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Table lists the encrypted columns of a table and the blind indexes kept
+// for them.
+type Table struct {
+	Name    string
+	Columns []string
+	Indexes []Index
+}
+
+// Index is a blind index column holding the keyed hash of another,
+// encrypted column after normalizing it.
+type Index struct {
+	Column    string
+	Source    string
+	Name      string
+	Normalize func(string) string
+}
+
+// Tables are the columns tagged `gorm:"serializer:encrypted"` or
+// `gorm:"serializer:encrypted_json"`. A column added there must be added
+// here so that Rotate encrypts its existing rows.
+var Tables = []Table{
+	{
+		Name:    "patients",
+		Columns: []string{"phone", "address"},
+		Indexes: []Index{
+			{Column: "phone_index", Source: "phone", Name: phoneIndexName, Normalize: models.PhoneDigits},
+			{Column: "phone_suffix_index", Source: "phone", Name: phoneSuffixIndexName, Normalize: models.PhoneSuffix},
+		},
+	},
+	{Name: "patient_legacy_notes", Columns: []string{"content"}},
+	{Name: "appointments", Columns: []string{"diagnosis", "treatment"}},
+	{Name: "encounter_notes", Columns: []string{"subjective", "objective", "assessment", "plan"}},
+	{Name: "encounter_note_revisions", Columns: []string{"subjective", "objective", "assessment", "plan"}},
+	{Name: "encounter_note_amendments", Columns: []string{"content", "reason"}},
+	{Name: "allergies", Columns: []string{"substance", "reaction"}},
+	{Name: "medications", Columns: []string{"name", "dose", "route", "frequency"}},
+	{Name: "problems", Columns: []string{"condition"}},
+	{
+		Name: "prescriptions",
+		Columns: []string{"drug_code", "drug_name", "dose", "route", "frequency", "quantity", "instructions",
+			"alerts", "override_reason", "cancel_reason"},
+	},
+	// Observation values, codes and flags stay in plain text for trends and
+	// reference range checks; only the free text is encrypted.
+	{Name: "observations", Columns: []string{"note", "error_reason"}},
+}
+
+// RewriteStats counts the rows Rotate or Decrypt looked at and rewrote in
+// one table.
+type RewriteStats struct {
+	Table     string
+	Scanned   int
+	Rewritten int
+}
+
+// Rotate re-encrypts, batch by batch, every value of tables that is still
+// plaintext or encrypted with a master key other than the active one, and
+// recomputes blind indexes that are missing or were computed with another
+// index key. Each batch is locked and rewritten in its own transaction, so
+// the server can keep running and an interrupted rotation can simply be
+// started again. progress, if not nil, is called after each batch.
+func Rotate(db *gorm.DB, k *Keyring, tables []Table, batchSize int, progress func(RewriteStats)) ([]RewriteStats, error) {
+	return rewrite(db, k, tables, batchSize, progress, true)
+}
+
+// Decrypt writes every encrypted value of tables back in plain text, batch
+// by batch like Rotate. It is only meant for rolling the schema back to a
+// version without field encryption, with the server stopped.
+func Decrypt(db *gorm.DB, k *Keyring, tables []Table, batchSize int, progress func(RewriteStats)) ([]RewriteStats, error) {
+	return rewrite(db, k, tables, batchSize, progress, false)
+}
+
+func rewrite(db *gorm.DB, k *Keyring, tables []Table, batchSize int, progress func(RewriteStats), encrypt bool) ([]RewriteStats, error) {
+	var all []RewriteStats
+	for _, table := range tables {
+		stats := RewriteStats{Table: table.Name}
+		var lastID uint
+		for {
+			n, rewritten, next, err := rewriteBatch(db, k, table, lastID, batchSize, encrypt)
+			if err != nil {
+				return all, fmt.Errorf("failed to rewrite %s after id %d: %w", table.Name, lastID, err)
+			}
+			if n == 0 {
+				break
+			}
+			stats.Scanned += n
+			stats.Rewritten += rewritten
+			lastID = next
+			if progress != nil {
+				progress(stats)
+			}
+		}
+		all = append(all, stats)
+	}
+	return all, nil
+}
+
+// rewriteBatch encrypts or decrypts the rows of one batch after afterID. It
+// returns how many rows it read, how many it changed and the last ID read.
+func rewriteBatch(db *gorm.DB, k *Keyring, table Table, afterID uint, batchSize int, encrypt bool) (int, int, uint, error) {
+	columns := append([]string{"id"}, table.Columns...)
+	indexes := table.Indexes
+	if !encrypt {
+		indexes = nil
+	}
+	for _, index := range indexes {
+		columns = append(columns, index.Column)
+	}
+
+	var scanned, rewritten int
+	var lastID uint
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Signed encounter notes and their history are otherwise immutable;
+		// the triggers guarding them let a rotation rewrite their text.
+		if err := tx.Exec("SET LOCAL hms.key_rotation = 'on'").Error; err != nil {
+			return err
+		}
+		var rows []map[string]interface{}
+		err := tx.Table(table.Name).
+			Select(columns).
+			Where("id > ?", afterID).
+			Order("id").
+			Limit(batchSize).
+			// Locked until rewritten, so concurrent edits are neither lost
+			// nor overwritten with stale values.
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		scanned = len(rows)
+
+		for _, row := range rows {
+			id, err := rowID(row["id"])
+			if err != nil {
+				return err
+			}
+			lastID = id
+
+			updates := map[string]interface{}{}
+			plaintexts := map[string]string{}
+			for _, column := range table.Columns {
+				stored, ok := row[column].(string)
+				if !ok || stored == "" {
+					continue
+				}
+				plaintext, err := k.Decrypt(stored, column)
+				if err != nil {
+					return fmt.Errorf("row %d: %w", id, err)
+				}
+				plaintexts[column] = plaintext
+				switch {
+				case encrypt && k.NeedsRotation(stored):
+					if updates[column], err = k.Encrypt(plaintext, column); err != nil {
+						return err
+					}
+				case !encrypt && plaintext != stored:
+					updates[column] = plaintext
+				}
+			}
+			for _, index := range indexes {
+				want := ""
+				if normalized := index.Normalize(plaintexts[index.Source]); normalized != "" {
+					want = k.BlindIndex(index.Name, normalized)
+				}
+				if current, _ := row[index.Column].(string); current != want {
+					updates[index.Column] = models.StringPtr(want)
+				}
+			}
+			if len(updates) == 0 {
+				continue
+			}
+
+			if err := tx.Table(table.Name).Where("id = ?", id).UpdateColumns(updates).Error; err != nil {
+				return fmt.Errorf("row %d: %w", id, err)
+			}
+			rewritten++
+		}
+		return nil
+	})
+	return scanned, rewritten, lastID, err
+}
+
+// ColumnStatus counts the values of an encrypted column by the master key
+// they are encrypted with; plaintext values are counted under "".
+type ColumnStatus struct {
+	Table  string
+	Column string
+	Keys   map[string]int64
+}
+
+// Status reports how the values of each encrypted column are stored.
+func Status(db *gorm.DB, tables []Table) ([]ColumnStatus, error) {
+	var statuses []ColumnStatus
+	for _, table := range tables {
+		for _, column := range table.Columns {
+			var rows []struct {
+				KeyID string
+				Count int64
+			}
+			keyExpr := fmt.Sprintf("CASE WHEN %[1]s LIKE '%[2]s%%' THEN split_part(%[1]s, ':', 3) ELSE '' END", column, Prefix)
+			err := db.Table(table.Name).
+				Select(keyExpr + " AS key_id, COUNT(*) AS count").
+				Where(fmt.Sprintf("%s IS NOT NULL AND %s <> ''", column, column)).
+				Group("key_id").
+				Scan(&rows).Error
+			if err != nil {
+				return nil, fmt.Errorf("failed to count %s.%s: %w", table.Name, column, err)
+			}
+			status := ColumnStatus{Table: table.Name, Column: column, Keys: map[string]int64{}}
+			for _, row := range rows {
+				status.Keys[row.KeyID] = row.Count
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses, nil
+}
+
+func rowID(value interface{}) (uint, error) {
+	switch v := value.(type) {
+	case int64:
+		return uint(v), nil
+	case int32:
+		return uint(v), nil
+	case int:
+		return uint(v), nil
+	}
+	return 0, fmt.Errorf("unexpected id type %T", value)
+}
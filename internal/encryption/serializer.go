@@ -0,0 +1,196 @@
+package encryption
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"hospital-management/internal/models"
+
+	"gorm.io/gorm/schema"
+)
+
+// SerializerName is the GORM serializer encrypted fields are tagged with:
+// `gorm:"serializer:encrypted"`. The field must be a string or *string.
+const SerializerName = "encrypted"
+
+// JSONSerializerName is the serializer for fields of any other type, which
+// are stored as encrypted JSON: `gorm:"serializer:encrypted_json"`. The
+// column must be a text column.
+const JSONSerializerName = "encrypted_json"
+
+var active atomic.Pointer[Keyring]
+
+// errNoKeyring is returned when an encrypted field is read or written
+// before Use is called.
+var errNoKeyring = errors.New("encryption keys are not loaded")
+
+func init() {
+	// Registered up front because GORM resolves serializers when it first
+	// parses a model, which may happen before the keys are loaded.
+	schema.RegisterSerializer(SerializerName, Serializer{})
+	schema.RegisterSerializer(JSONSerializerName, JSONSerializer{})
+}
+
+// Use makes k the keyring encrypted fields are read and written with.
+func Use(k *Keyring) {
+	active.Store(k)
+}
+
+// Active returns the keyring set by Use, or nil.
+func Active() *Keyring {
+	return active.Load()
+}
+
+// phoneIndexName and phoneSuffixIndexName separate the phone number blind
+// indexes from each other and from any other.
+const (
+	phoneIndexName       = "phone"
+	phoneSuffixIndexName = "phone_suffix"
+)
+
+// IndexPhone returns the blind index of a phone number's digits, computed
+// with the keyring set by Use, or nil if the number has no digits.
+func IndexPhone(phone string) (*string, error) {
+	return blindIndex(phoneIndexName, models.PhoneDigits(phone))
+}
+
+// IndexPhoneSuffix returns the blind index of the last digits of a phone
+// number, or nil if it has fewer than models.PhoneSuffixLength digits.
+func IndexPhoneSuffix(phone string) (*string, error) {
+	return blindIndex(phoneSuffixIndexName, models.PhoneSuffix(phone))
+}
+
+func blindIndex(name, normalized string) (*string, error) {
+	if normalized == "" {
+		return nil, nil
+	}
+	k := active.Load()
+	if k == nil {
+		return nil, errNoKeyring
+	}
+	index := k.BlindIndex(name, normalized)
+	return &index, nil
+}
+
+// Serializer encrypts a field on write and decrypts it on read, using the
+// column name as additional data. Empty strings and NULLs are stored as
+// they are.
+type Serializer struct{}
+
+// Scan implements schema.SerializerInterface.
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var stored string
+		switch v := dbValue.(type) {
+		case string:
+			stored = v
+		case []byte:
+			stored = string(v)
+		default:
+			return fmt.Errorf("unsupported value %T in encrypted column %s", dbValue, field.DBName)
+		}
+
+		plaintext := stored
+		if stored != "" {
+			k := active.Load()
+			if k == nil {
+				return errNoKeyring
+			}
+			var err error
+			if plaintext, err = k.Decrypt(stored, field.DBName); err != nil {
+				return err
+			}
+		}
+
+		switch field.FieldType.Kind() {
+		case reflect.String:
+			fieldValue.Elem().SetString(plaintext)
+		case reflect.Ptr:
+			fieldValue.Elem().Set(reflect.ValueOf(&plaintext))
+		default:
+			return fmt.Errorf("encrypted field %s must be a string or *string", field.Name)
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value implements schema.SerializerValuerInterface.
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	var plaintext string
+	switch v := fieldValue.(type) {
+	case string:
+		plaintext = v
+	case *string:
+		if v == nil {
+			return nil, nil
+		}
+		plaintext = *v
+	default:
+		return nil, fmt.Errorf("encrypted field %s must be a string or *string", field.Name)
+	}
+	if plaintext == "" {
+		return plaintext, nil
+	}
+
+	k := active.Load()
+	if k == nil {
+		return nil, errNoKeyring
+	}
+	return k.Encrypt(plaintext, field.DBName)
+}
+
+// JSONSerializer stores a field as JSON encrypted like Serializer does.
+// Plain JSON written before the column was encrypted is still read.
+type JSONSerializer struct{}
+
+// Scan implements schema.SerializerInterface.
+func (JSONSerializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var stored string
+		switch v := dbValue.(type) {
+		case string:
+			stored = v
+		case []byte:
+			stored = string(v)
+		default:
+			return fmt.Errorf("unsupported value %T in encrypted column %s", dbValue, field.DBName)
+		}
+
+		if stored != "" {
+			k := active.Load()
+			if k == nil {
+				return errNoKeyring
+			}
+			plaintext, err := k.Decrypt(stored, field.DBName)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal([]byte(plaintext), fieldValue.Interface()); err != nil {
+				return fmt.Errorf("failed to decode column %s: %w", field.DBName, err)
+			}
+		}
+	}
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+// Value implements schema.SerializerValuerInterface.
+func (JSONSerializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, err := json.Marshal(fieldValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode column %s: %w", field.DBName, err)
+	}
+
+	k := active.Load()
+	if k == nil {
+		return nil, errNoKeyring
+	}
+	return k.Encrypt(string(plaintext), field.DBName)
+}
@@ -0,0 +1,210 @@
+package encryption
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"hospital-management/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// useTestKeyring makes a new keyring the active one for the test.
+func useTestKeyring(t *testing.T) *Keyring {
+	t.Helper()
+	k, _, _ := newTestKeyring(t, "k1")
+	previous := Active()
+	Use(k)
+	t.Cleanup(func() { Use(previous) })
+	return k
+}
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+// insertedValues returns what Create would write for value, by column.
+func insertedValues(t *testing.T, db *gorm.DB, value interface{}) map[string]interface{} {
+	t.Helper()
+	stmt := db.Session(&gorm.Session{DryRun: true}).Create(value).Statement
+	sql := stmt.SQL.String()
+	columns := strings.Split(sql[strings.Index(sql, "(")+1:strings.Index(sql, ")")], ",")
+
+	values := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		v := stmt.Vars[i]
+		if valuer, ok := v.(driver.Valuer); ok {
+			var err error
+			if v, err = valuer.Value(); err != nil {
+				t.Fatalf("column %s: %v", column, err)
+			}
+		}
+		values[strings.Trim(column, `" `)] = v
+	}
+	return values
+}
+
+func TestSerializerEncryptsOnWrite(t *testing.T) {
+	k := useTestKeyring(t)
+	db, _ := newMockDB(t)
+
+	values := insertedValues(t, db, &models.Allergy{PatientID: 1, Substance: "Penicillin", Severity: models.SeveritySevere})
+
+	stored, ok := values["substance"].(string)
+	if !ok || !strings.HasPrefix(stored, Prefix) {
+		t.Fatalf("substance written as %v, want an encrypted value", values["substance"])
+	}
+	if got, err := k.Decrypt(stored, "substance"); err != nil || got != "Penicillin" {
+		t.Fatalf("stored substance decrypts to %q, %v", got, err)
+	}
+	if values["reaction"] != "" {
+		t.Errorf("empty reaction written as %v, want it left empty", values["reaction"])
+	}
+	if values["severity"] != models.SeveritySevere {
+		t.Errorf("severity written as %v, want it in plain text", values["severity"])
+	}
+}
+
+func TestSerializerDecryptsOnRead(t *testing.T) {
+	k := useTestKeyring(t)
+	db, mock := newMockDB(t)
+
+	substance, _ := k.Encrypt("Penicillin", "substance")
+	mock.ExpectQuery(`SELECT \* FROM "allergies"`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "patient_id", "substance", "reaction", "severity", "status"}).
+			// The reaction was written before the column was encrypted.
+			AddRow(4, 1, substance, "hives", models.SeveritySevere, models.ClinicalStatusActive))
+
+	var allergy models.Allergy
+	if err := db.First(&allergy, 4).Error; err != nil {
+		t.Fatal(err)
+	}
+	if allergy.Substance != "Penicillin" || allergy.Reaction != "hives" {
+		t.Fatalf("read substance %q and reaction %q", allergy.Substance, allergy.Reaction)
+	}
+}
+
+func TestSerializerHandlesNilPointers(t *testing.T) {
+	k := useTestKeyring(t)
+	db, mock := newMockDB(t)
+
+	values := insertedValues(t, db, &models.Patient{FirstName: "Ann", Phone: "555 0100", Address: nil})
+	if values["address"] != nil {
+		t.Fatalf("nil address written as %v, want NULL", values["address"])
+	}
+
+	phone, _ := k.Encrypt("555 0100", "phone")
+	mock.ExpectQuery(`SELECT \* FROM "patients"`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "first_name", "phone", "address"}).AddRow(1, "Ann", phone, nil))
+	var patient models.Patient
+	if err := db.First(&patient, 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if patient.Phone != "555 0100" || patient.Address != nil {
+		t.Fatalf("read phone %q and address %v", patient.Phone, patient.Address)
+	}
+}
+
+func TestJSONSerializerRoundTrip(t *testing.T) {
+	k := useTestKeyring(t)
+	db, mock := newMockDB(t)
+
+	alerts := []models.PrescriptionAlert{{
+		Type:     models.AlertTypeAllergy,
+		Severity: models.AlertSeverityBlock,
+		Message:  "patient has a severe allergy to penicillin",
+		Source:   "allergy:4",
+	}}
+	values := insertedValues(t, db, &models.Prescription{DrugName: "Amoxicillin", Alerts: alerts, Blocked: true})
+
+	stored, ok := values["alerts"].(string)
+	if !ok || !strings.HasPrefix(stored, Prefix) || strings.Contains(stored, "penicillin") {
+		t.Fatalf("alerts written as %v, want an encrypted value", values["alerts"])
+	}
+	plaintext, err := k.Decrypt(stored, "alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []models.PrescriptionAlert
+	if err := json.Unmarshal([]byte(plaintext), &decoded); err != nil || !reflect.DeepEqual(decoded, alerts) {
+		t.Fatalf("stored alerts decode to %+v, %v", decoded, err)
+	}
+	if values["blocked"] != true {
+		t.Errorf("blocked written as %v", values["blocked"])
+	}
+
+	legacy, _ := json.Marshal(alerts)
+	mock.ExpectQuery(`SELECT \* FROM "prescriptions"`).WillReturnRows(
+		sqlmock.NewRows([]string{"id", "drug_name", "alerts"}).
+			AddRow(1, "Amoxicillin", stored).
+			// Alerts written before the column was encrypted.
+			AddRow(2, "Amoxicillin", string(legacy)))
+	var prescriptions []models.Prescription
+	if err := db.Find(&prescriptions).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range prescriptions {
+		if !reflect.DeepEqual(p.Alerts, alerts) {
+			t.Errorf("prescription %d read alerts %+v", p.ID, p.Alerts)
+		}
+	}
+}
+
+func TestSerializerRequiresKeyring(t *testing.T) {
+	previous := Active()
+	Use(nil)
+	t.Cleanup(func() { Use(previous) })
+	db, _ := newMockDB(t)
+
+	// The values are only serialized when the statement runs.
+	stmt := db.Session(&gorm.Session{DryRun: true}).Create(&models.Problem{Condition: "Asthma"}).Statement
+	for _, v := range stmt.Vars {
+		if valuer, ok := v.(driver.Valuer); ok {
+			if _, err := valuer.Value(); errors.Is(err, errNoKeyring) {
+				return
+			}
+		}
+	}
+	t.Fatal("an encrypted field was written without keys")
+}
+
+func TestIndexPhone(t *testing.T) {
+	useTestKeyring(t)
+
+	a, err := IndexPhone("+1 (555) 123-4567")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := IndexPhone("15551234567")
+	if a == nil || b == nil || *a != *b {
+		t.Fatal("the same digits in another format index differently")
+	}
+	if none, _ := IndexPhone("n/a"); none != nil {
+		t.Fatal("a number without digits was indexed")
+	}
+
+	suffix, _ := IndexPhoneSuffix("555-123-4567")
+	other, _ := IndexPhoneSuffix("+44 20 7946 4567")
+	if suffix == nil || other == nil || *suffix != *other {
+		t.Fatal("numbers ending in the same four digits have different suffix indexes")
+	}
+	if short, _ := IndexPhoneSuffix("123"); short != nil {
+		t.Fatal("a number shorter than the suffix was indexed")
+	}
+}
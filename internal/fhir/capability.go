@@ -0,0 +1,117 @@
+package fhir
+
+import "time"
+
+type CapabilitySoftware struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type CapabilityImplementation struct {
+	Description string `json:"description"`
+	URL         string `json:"url,omitempty"`
+}
+
+type CapabilityInteraction struct {
+	Code string `json:"code"`
+}
+
+type CapabilitySearchParam struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+type CapabilityResource struct {
+	Type        string                  `json:"type"`
+	Interaction []CapabilityInteraction `json:"interaction"`
+	SearchParam []CapabilitySearchParam `json:"searchParam,omitempty"`
+}
+
+type CapabilitySecurity struct {
+	Description string `json:"description"`
+}
+
+type CapabilityRest struct {
+	Mode     string               `json:"mode"`
+	Security *CapabilitySecurity  `json:"security,omitempty"`
+	Resource []CapabilityResource `json:"resource"`
+}
+
+type CapabilityStatement struct {
+	ResourceType   string                    `json:"resourceType"`
+	Status         string                    `json:"status"`
+	Date           string                    `json:"date"`
+	Kind           string                    `json:"kind"`
+	Software       CapabilitySoftware        `json:"software"`
+	Implementation *CapabilityImplementation `json:"implementation,omitempty"`
+	FHIRVersion    string                    `json:"fhirVersion"`
+	Format         []string                  `json:"format"`
+	Rest           []CapabilityRest          `json:"rest"`
+}
+
+// startedAt dates the CapabilityStatement; the capabilities only change
+// with a new build.
+var startedAt = time.Now().UTC()
+
+// Capabilities describes what the facade at baseURL supports. It must be
+// kept in step with the routes registered for it.
+func Capabilities(baseURL string) *CapabilityStatement {
+	readCreateSearch := []CapabilityInteraction{{Code: "read"}, {Code: "search-type"}, {Code: "create"}}
+	return &CapabilityStatement{
+		ResourceType: "CapabilityStatement",
+		Status:       "active",
+		Date:         startedAt.Format(time.RFC3339),
+		Kind:         "instance",
+		Software:     CapabilitySoftware{Name: "hospital-management"},
+		Implementation: &CapabilityImplementation{
+			Description: "Hospital management FHIR R4 facade",
+			URL:         baseURL,
+		},
+		FHIRVersion: Version,
+		Format:      []string{ContentType, "json"},
+		Rest: []CapabilityRest{{
+			Mode: "server",
+			Security: &CapabilitySecurity{
+				Description: "Bearer token from /api/v1/login; access follows the role permissions of the REST API.",
+			},
+			Resource: []CapabilityResource{
+				{
+					Type:        "Patient",
+					Interaction: readCreateSearch,
+					SearchParam: []CapabilitySearchParam{
+						{Name: "_id", Type: "token"},
+						{Name: "identifier", Type: "token", Documentation: "system " + SystemPatientID},
+						{Name: "name", Type: "string", Documentation: "matches given or family name"},
+						{Name: "birthdate", Type: "date"},
+						{Name: "gender", Type: "token"},
+						{Name: "phone", Type: "token"},
+						{Name: "_count", Type: "number"},
+					},
+				},
+				{
+					Type:        "Practitioner",
+					Interaction: readCreateSearch,
+					SearchParam: []CapabilitySearchParam{
+						{Name: "_id", Type: "token"},
+						{Name: "identifier", Type: "token", Documentation: "systems " + SystemUserID + " and " + SystemLicense},
+						{Name: "name", Type: "string", Documentation: "matches name or email"},
+					},
+				},
+				{
+					Type:        "Appointment",
+					Interaction: readCreateSearch,
+					SearchParam: []CapabilitySearchParam{
+						{Name: "_id", Type: "token"},
+						{Name: "identifier", Type: "token", Documentation: "system " + SystemAppointmentID},
+						{Name: "date", Type: "date"},
+						{Name: "patient", Type: "reference"},
+						{Name: "practitioner", Type: "reference"},
+						{Name: "status", Type: "token"},
+						{Name: "_count", Type: "number"},
+					},
+				},
+			},
+		}},
+	}
+}
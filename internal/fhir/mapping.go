@@ -0,0 +1,393 @@
+package fhir
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// appointmentStatuses maps appointment statuses to FHIR Appointment.status.
+// A checked-in patient has "arrived" and is waiting; once the consultation
+// starts the appointment is "checked-in", FHIR's state for an encounter that
+// may begin.
+var appointmentStatuses = map[string]string{
+	models.AppointmentRequested:  "proposed",
+	models.AppointmentScheduled:  "booked",
+	models.AppointmentConfirmed:  "booked",
+	models.AppointmentCheckedIn:  "arrived",
+	models.AppointmentInProgress: "checked-in",
+	models.AppointmentCompleted:  "fulfilled",
+	models.AppointmentCancelled:  "cancelled",
+	models.AppointmentNoShow:     "noshow",
+}
+
+// AppointmentStatus returns the FHIR status of an appointment status.
+func AppointmentStatus(status string) string {
+	if fhirStatus, ok := appointmentStatuses[status]; ok {
+		return fhirStatus
+	}
+	return "pending"
+}
+
+// AppointmentStatusesFor returns the appointment statuses that map to a FHIR
+// status, for searching.
+func AppointmentStatusesFor(fhirStatus string) []string {
+	var statuses []string
+	for status, mapped := range appointmentStatuses {
+		if mapped == fhirStatus {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// FromPatient converts a patient to a FHIR Patient.
+func FromPatient(p *models.Patient) *Patient {
+	resource := &Patient{
+		ResourceType: "Patient",
+		ID:           formatID(p.ID),
+		Meta:         &Meta{LastUpdated: p.UpdatedAt.UTC().Format(time.RFC3339)},
+		Identifier:   []Identifier{{Use: "usual", System: SystemPatientID, Value: formatID(p.ID)}},
+		Name:         []HumanName{{Use: "official", Family: p.LastName, Given: []string{p.FirstName}}},
+		Telecom:      []ContactPoint{{System: "phone", Value: p.Phone}},
+		Gender:       p.Gender,
+		BirthDate:    p.DateOfBirth.Format(dateLayout),
+	}
+	if p.MRN != nil {
+		resource.Identifier = append(resource.Identifier, Identifier{Use: "official", System: SystemMRN, Value: *p.MRN})
+	}
+	if p.Email != nil && *p.Email != "" {
+		resource.Telecom = append(resource.Telecom, ContactPoint{System: "email", Value: *p.Email})
+	}
+	if p.Address != nil && *p.Address != "" {
+		resource.Address = []Address{{Text: *p.Address}}
+	}
+	if p.MergedIntoID != nil {
+		active := false
+		resource.Active = &active
+		resource.Link = []PatientLink{{Other: Reference{Reference: "Patient/" + formatID(*p.MergedIntoID)}, Type: "replaced-by"}}
+	}
+	return resource
+}
+
+// PatientRequest validates a FHIR Patient and converts it to a create
+// request. The patient needs an official or first name with a family and a
+// given name, a gender other than unknown, a birth date and a phone number.
+func PatientRequest(p *Patient) (*models.PatientRequest, []Issue) {
+	var issues []Issue
+	if p.ResourceType != "Patient" {
+		issues = append(issues, invalid(IssueInvalid, "resourceType", "resourceType must be Patient"))
+	}
+	if p.ID != "" {
+		issues = append(issues, invalid(IssueInvalid, "Patient.id", "id must not be set on create"))
+	}
+
+	req := &models.PatientRequest{}
+
+	name := preferredName(p.Name)
+	if name == nil {
+		issues = append(issues, invalid(IssueRequired, "Patient.name", "a name is required"))
+	} else {
+		req.LastName = strings.TrimSpace(name.Family)
+		req.FirstName = strings.TrimSpace(strings.Join(name.Given, " "))
+		if req.LastName == "" {
+			issues = append(issues, invalid(IssueRequired, "Patient.name.family", "family name is required"))
+		}
+		if req.FirstName == "" {
+			issues = append(issues, invalid(IssueRequired, "Patient.name.given", "given name is required"))
+		}
+	}
+
+	switch p.Gender {
+	case "male", "female", "other":
+		req.Gender = p.Gender
+	case "":
+		issues = append(issues, invalid(IssueRequired, "Patient.gender", "gender is required"))
+	default:
+		issues = append(issues, invalid(IssueValue, "Patient.gender", "gender must be male, female or other"))
+	}
+
+	if p.BirthDate == "" {
+		issues = append(issues, invalid(IssueRequired, "Patient.birthDate", "birthDate is required"))
+	} else if birth, err := time.Parse(dateLayout, p.BirthDate); err != nil {
+		issues = append(issues, invalid(IssueValue, "Patient.birthDate", "birthDate must be a full date (YYYY-MM-DD)"))
+	} else if birth.After(time.Now()) {
+		issues = append(issues, invalid(IssueValue, "Patient.birthDate", "birthDate must not be in the future"))
+	} else {
+		req.DateOfBirth = p.BirthDate
+	}
+
+	for i, contact := range p.Telecom {
+		switch contact.System {
+		case "phone":
+			if req.Phone == "" {
+				req.Phone = strings.TrimSpace(contact.Value)
+			}
+		case "email":
+			if req.Email == "" {
+				req.Email = strings.TrimSpace(contact.Value)
+				if !strings.Contains(req.Email, "@") {
+					issues = append(issues, invalid(IssueValue, fmt.Sprintf("Patient.telecom[%d].value", i), "email address is not valid"))
+				}
+			}
+		}
+	}
+	if req.Phone == "" {
+		issues = append(issues, invalid(IssueRequired, "Patient.telecom", "a phone number is required"))
+	}
+
+	if len(p.Address) > 0 {
+		req.Address = p.Address[0].Text
+	}
+
+	return req, issues
+}
+
+// FromDoctor converts a doctor's directory profile to a FHIR Practitioner.
+// The practitioner's ID is the doctor's user ID.
+func FromDoctor(d *models.Doctor) *Practitioner {
+	active := true
+	resource := &Practitioner{
+		ResourceType: "Practitioner",
+		ID:           formatID(d.UserID),
+		Meta:         &Meta{LastUpdated: d.UpdatedAt.UTC().Format(time.RFC3339)},
+		Identifier: []Identifier{
+			{Use: "usual", System: SystemUserID, Value: formatID(d.UserID)},
+			{Use: "official", System: SystemLicense, Value: d.LicenseNumber},
+		},
+		Active:        &active,
+		Name:          []HumanName{{Use: "official", Text: d.Name}},
+		Telecom:       []ContactPoint{{System: "email", Value: d.Email, Use: "work"}},
+		Qualification: []Qualification{{Code: CodeableConcept{Text: d.Specialization}}},
+	}
+	if d.User != nil {
+		resource.Name[0].Family = d.User.LastName
+		resource.Name[0].Given = []string{d.User.FirstName}
+	}
+	if d.Phone != "" {
+		resource.Telecom = append(resource.Telecom, ContactPoint{System: "phone", Value: d.Phone, Use: "work"})
+	}
+	return resource
+}
+
+// DoctorRequest validates a FHIR Practitioner and converts it to a directory
+// profile request. A practitioner can only be created for an existing doctor
+// account, named by a user-id identifier; it also needs a license-number
+// identifier and a qualification naming the specialization.
+func DoctorRequest(p *Practitioner) (*models.DoctorRequest, []Issue) {
+	var issues []Issue
+	if p.ResourceType != "Practitioner" {
+		issues = append(issues, invalid(IssueInvalid, "resourceType", "resourceType must be Practitioner"))
+	}
+	if p.ID != "" {
+		issues = append(issues, invalid(IssueInvalid, "Practitioner.id", "id must not be set on create"))
+	}
+
+	req := &models.DoctorRequest{}
+	for i, identifier := range p.Identifier {
+		switch identifier.System {
+		case SystemUserID:
+			id, err := strconv.ParseUint(identifier.Value, 10, 32)
+			if err != nil {
+				issues = append(issues, invalid(IssueValue, fmt.Sprintf("Practitioner.identifier[%d].value", i), "user ID must be a number"))
+				continue
+			}
+			req.UserID = uint(id)
+		case SystemLicense:
+			req.LicenseNumber = strings.TrimSpace(identifier.Value)
+		}
+	}
+	if req.UserID == 0 {
+		issues = append(issues, invalid(IssueRequired, "Practitioner.identifier", "an identifier with system "+SystemUserID+" naming the doctor's user account is required"))
+	}
+	if req.LicenseNumber == "" {
+		issues = append(issues, invalid(IssueRequired, "Practitioner.identifier", "an identifier with system "+SystemLicense+" is required"))
+	}
+
+	if len(p.Qualification) == 0 || strings.TrimSpace(p.Qualification[0].Code.Text) == "" {
+		issues = append(issues, invalid(IssueRequired, "Practitioner.qualification", "a qualification naming the specialization is required"))
+	} else {
+		req.Specialization = strings.TrimSpace(p.Qualification[0].Code.Text)
+	}
+
+	for _, contact := range p.Telecom {
+		if contact.System == "phone" && req.Phone == "" {
+			req.Phone = contact.Value
+		}
+	}
+
+	return req, issues
+}
+
+// FromAppointment converts an appointment to a FHIR Appointment.
+func FromAppointment(a *models.Appointment) *Appointment {
+	resource := &Appointment{
+		ResourceType:    "Appointment",
+		ID:              formatID(a.ID),
+		Meta:            &Meta{LastUpdated: a.UpdatedAt.UTC().Format(time.RFC3339)},
+		Identifier:      []Identifier{{Use: "usual", System: SystemAppointmentID, Value: formatID(a.ID)}},
+		Status:          AppointmentStatus(a.Status),
+		Start:           a.DateTime.UTC().Format(time.RFC3339),
+		End:             a.DateTime.Add(time.Duration(a.Duration) * time.Minute).UTC().Format(time.RFC3339),
+		MinutesDuration: a.Duration,
+		Created:         a.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if a.Notes != nil {
+		resource.Comment = *a.Notes
+	}
+	if a.StatusReason != nil && (a.Status == models.AppointmentCancelled || a.Status == models.AppointmentNoShow) {
+		resource.CancelationReason = &CodeableConcept{Text: *a.StatusReason}
+	}
+
+	patient := &Reference{Reference: "Patient/" + formatID(a.PatientID)}
+	if a.Patient != nil {
+		patient.Display = a.Patient.GetFullName()
+	}
+	practitioner := &Reference{Reference: "Practitioner/" + formatID(a.DoctorID)}
+	if a.Doctor != nil {
+		practitioner.Display = a.Doctor.GetFullName()
+	}
+	resource.Participant = []AppointmentParticipant{
+		{Actor: patient, Status: "accepted"},
+		{Actor: practitioner, Status: "accepted"},
+	}
+	return resource
+}
+
+// AppointmentRequest validates a FHIR Appointment and converts it to a
+// booking request. It needs a proposed or booked status, a start, an end or
+// minutesDuration, and one Patient and one Practitioner participant.
+func AppointmentRequest(a *Appointment) (*models.AppointmentRequest, []Issue) {
+	var issues []Issue
+	if a.ResourceType != "Appointment" {
+		issues = append(issues, invalid(IssueInvalid, "resourceType", "resourceType must be Appointment"))
+	}
+	if a.ID != "" {
+		issues = append(issues, invalid(IssueInvalid, "Appointment.id", "id must not be set on create"))
+	}
+
+	req := &models.AppointmentRequest{Notes: a.Comment}
+
+	switch a.Status {
+	case "proposed", "pending":
+		req.Status = models.AppointmentRequested
+	case "booked":
+		req.Status = models.AppointmentScheduled
+	case "":
+		issues = append(issues, invalid(IssueRequired, "Appointment.status", "status is required"))
+	default:
+		issues = append(issues, invalid(IssueValue, "Appointment.status", "new appointments must be proposed, pending or booked"))
+	}
+
+	start, err := time.Parse(time.RFC3339, a.Start)
+	if a.Start == "" {
+		issues = append(issues, invalid(IssueRequired, "Appointment.start", "start is required"))
+	} else if err != nil {
+		issues = append(issues, invalid(IssueValue, "Appointment.start", "start must be an instant with a time zone"))
+	} else {
+		req.DateTime = start.Format(time.RFC3339)
+	}
+
+	req.Duration = a.MinutesDuration
+	if a.End != "" {
+		end, err := time.Parse(time.RFC3339, a.End)
+		switch {
+		case err != nil:
+			issues = append(issues, invalid(IssueValue, "Appointment.end", "end must be an instant with a time zone"))
+		case a.Start != "" && !start.IsZero():
+			minutes := int(end.Sub(start).Minutes())
+			if a.MinutesDuration != 0 && a.MinutesDuration != minutes {
+				issues = append(issues, invalid(IssueInvalid, "Appointment.minutesDuration", "minutesDuration does not match start and end"))
+			}
+			req.Duration = minutes
+		}
+	}
+	if req.Duration == 0 {
+		issues = append(issues, invalid(IssueRequired, "Appointment.end", "end or minutesDuration is required"))
+	} else if req.Duration < 15 || req.Duration > 240 {
+		issues = append(issues, invalid(IssueValue, "Appointment.minutesDuration", "appointments must last between 15 and 240 minutes"))
+	}
+
+	for i, participant := range a.Participant {
+		if participant.Actor == nil {
+			continue
+		}
+		path := fmt.Sprintf("Appointment.participant[%d].actor", i)
+		resourceType, id, ok := ParseReference(participant.Actor.Reference)
+		if !ok {
+			issues = append(issues, invalid(IssueValue, path, "reference must be of the form Type/id"))
+			continue
+		}
+		switch resourceType {
+		case "Patient":
+			if req.PatientID != 0 {
+				issues = append(issues, invalid(IssueInvalid, path, "only one patient may take part"))
+			}
+			req.PatientID = id
+		case "Practitioner":
+			if req.DoctorID != 0 {
+				issues = append(issues, invalid(IssueInvalid, path, "only one practitioner may take part"))
+			}
+			req.DoctorID = id
+		default:
+			issues = append(issues, invalid(IssueNotSupported, path, resourceType+" participants are not supported"))
+		}
+	}
+	if req.PatientID == 0 {
+		issues = append(issues, invalid(IssueRequired, "Appointment.participant", "a Patient participant is required"))
+	}
+	if req.DoctorID == 0 {
+		issues = append(issues, invalid(IssueRequired, "Appointment.participant", "a Practitioner participant is required"))
+	}
+
+	return req, issues
+}
+
+// ParseReference splits a relative reference such as "Patient/12". A full
+// URL ending in Type/id is also accepted.
+func ParseReference(reference string) (string, uint, bool) {
+	parts := strings.Split(strings.TrimRight(reference, "/"), "/")
+	if len(parts) < 2 {
+		return "", 0, false
+	}
+	id, err := strconv.ParseUint(parts[len(parts)-1], 10, 32)
+	if err != nil || id == 0 {
+		return "", 0, false
+	}
+	return parts[len(parts)-2], uint(id), true
+}
+
+// ParseID parses a logical resource ID.
+func ParseID(id string) (uint, bool) {
+	parsed, err := strconv.ParseUint(id, 10, 32)
+	if err != nil || parsed == 0 {
+		return 0, false
+	}
+	return uint(parsed), true
+}
+
+func formatID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// preferredName returns the official name, or the first name when none is
+// marked official.
+func preferredName(names []HumanName) *HumanName {
+	for i := range names {
+		if names[i].Use == "official" {
+			return &names[i]
+		}
+	}
+	if len(names) > 0 {
+		return &names[0]
+	}
+	return nil
+}
+
+func invalid(code, expression, diagnostics string) Issue {
+	return Issue{Severity: SeverityError, Code: code, Diagnostics: diagnostics, Expression: []string{expression}}
+}
@@ -0,0 +1,372 @@
+package fhir
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+)
+
+// roundTrip encodes a resource as JSON and decodes it again, as a client
+// reading and re-posting it would.
+func roundTrip[T any](t *testing.T, resource *T) *T {
+	t.Helper()
+	data, err := json.Marshal(resource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded T
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return &decoded
+}
+
+func expressions(issues []Issue) []string {
+	var paths []string
+	for _, issue := range issues {
+		if issue.Severity != SeverityError {
+			continue
+		}
+		paths = append(paths, issue.Expression...)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestPatientRoundTrip(t *testing.T) {
+	email, address, mrn := "jane@example.org", "1 Main Street, Springfield", "MRN0100004217"
+	patient := &models.Patient{
+		ID:          42,
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		Email:       &email,
+		Phone:       "555-0100",
+		DateOfBirth: time.Date(1980, 2, 3, 0, 0, 0, 0, time.UTC),
+		Gender:      "female",
+		Address:     &address,
+		MRN:         &mrn,
+		UpdatedAt:   time.Date(2026, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600)),
+	}
+
+	resource := roundTrip(t, FromPatient(patient))
+
+	if resource.ResourceType != "Patient" || resource.ID != "42" || resource.Meta.LastUpdated != "2026-03-01T08:30:00Z" {
+		t.Errorf("resource = %+v", resource)
+	}
+	want := []Identifier{
+		{Use: "usual", System: SystemPatientID, Value: "42"},
+		{Use: "official", System: SystemMRN, Value: mrn},
+	}
+	if !reflect.DeepEqual(resource.Identifier, want) {
+		t.Errorf("identifiers = %+v, want %+v", resource.Identifier, want)
+	}
+	if resource.Active != nil || resource.Link != nil {
+		t.Errorf("an unmerged patient is marked active=%v with links %+v", resource.Active, resource.Link)
+	}
+
+	// Posting the resource back, without its ID, describes the same patient.
+	resource.ID = ""
+	req, issues := PatientRequest(resource)
+	if len(issues) > 0 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	wantReq := &models.PatientRequest{
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		Email:       email,
+		Phone:       "555-0100",
+		DateOfBirth: "1980-02-03",
+		Gender:      "female",
+		Address:     address,
+	}
+	if !reflect.DeepEqual(req, wantReq) {
+		t.Errorf("request = %+v, want %+v", req, wantReq)
+	}
+}
+
+func TestFromPatientMerged(t *testing.T) {
+	survivor := uint(7)
+	resource := FromPatient(&models.Patient{ID: 42, FirstName: "Jane", LastName: "Doe", MergedIntoID: &survivor})
+
+	if resource.Active == nil || *resource.Active {
+		t.Error("a merged patient is not marked inactive")
+	}
+	if len(resource.Link) != 1 || resource.Link[0].Type != "replaced-by" || resource.Link[0].Other.Reference != "Patient/7" {
+		t.Errorf("links = %+v, want replaced-by Patient/7", resource.Link)
+	}
+	if len(resource.Telecom) != 1 || resource.Address != nil {
+		t.Errorf("telecom = %+v, address = %+v, want only the phone", resource.Telecom, resource.Address)
+	}
+}
+
+func validPatient() *Patient {
+	return &Patient{
+		ResourceType: "Patient",
+		Name:         []HumanName{{Use: "official", Family: "Doe", Given: []string{"Jane", "Ann"}}},
+		Telecom:      []ContactPoint{{System: "phone", Value: "555-0100"}},
+		Gender:       "female",
+		BirthDate:    "1980-02-03",
+	}
+}
+
+func TestPatientRequestPrefersOfficialName(t *testing.T) {
+	p := validPatient()
+	p.Name = append([]HumanName{{Use: "nickname", Family: "D", Given: []string{"JJ"}}}, p.Name...)
+
+	req, issues := PatientRequest(p)
+	if len(issues) > 0 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	if req.FirstName != "Jane Ann" || req.LastName != "Doe" {
+		t.Errorf("name = %q %q, want the official name with both given names", req.FirstName, req.LastName)
+	}
+}
+
+func TestPatientRequestIssues(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(p *Patient)
+		want   []string
+	}{
+		{"wrong resource type", func(p *Patient) { p.ResourceType = "Practitioner" }, []string{"resourceType"}},
+		{"id on create", func(p *Patient) { p.ID = "42" }, []string{"Patient.id"}},
+		{"no name", func(p *Patient) { p.Name = nil }, []string{"Patient.name"}},
+		{"no family name", func(p *Patient) { p.Name[0].Family = " " }, []string{"Patient.name.family"}},
+		{"no given name", func(p *Patient) { p.Name[0].Given = nil }, []string{"Patient.name.given"}},
+		{"no gender", func(p *Patient) { p.Gender = "" }, []string{"Patient.gender"}},
+		{"unknown gender", func(p *Patient) { p.Gender = "unknown" }, []string{"Patient.gender"}},
+		{"no birth date", func(p *Patient) { p.BirthDate = "" }, []string{"Patient.birthDate"}},
+		{"partial birth date", func(p *Patient) { p.BirthDate = "1980-02" }, []string{"Patient.birthDate"}},
+		{"future birth date", func(p *Patient) { p.BirthDate = time.Now().AddDate(1, 0, 0).Format(dateLayout) }, []string{"Patient.birthDate"}},
+		{"no phone", func(p *Patient) { p.Telecom = []ContactPoint{{System: "email", Value: "jane@example.org"}} }, []string{"Patient.telecom"}},
+		{"invalid email", func(p *Patient) { p.Telecom = append(p.Telecom, ContactPoint{System: "email", Value: "jane"}) }, []string{"Patient.telecom[1].value"}},
+		{"everything missing", func(p *Patient) { *p = Patient{ResourceType: "Patient"} },
+			[]string{"Patient.birthDate", "Patient.gender", "Patient.name", "Patient.telecom"}},
+	}
+	for _, tt := range tests {
+		p := validPatient()
+		tt.modify(p)
+		_, issues := PatientRequest(p)
+		if got := expressions(issues); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: issues at %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPractitionerRoundTrip(t *testing.T) {
+	doctor := &models.Doctor{
+		UserID:         7,
+		Name:           "Gregory House",
+		Email:          "house@example.org",
+		Phone:          "555-0107",
+		Specialization: "Diagnostics",
+		LicenseNumber:  "LIC-1234",
+		User:           &models.User{FirstName: "Gregory", LastName: "House"},
+	}
+
+	resource := roundTrip(t, FromDoctor(doctor))
+	if resource.ID != "7" || resource.Name[0].Family != "House" || len(resource.Telecom) != 2 {
+		t.Errorf("resource = %+v", resource)
+	}
+
+	resource.ID = ""
+	req, issues := DoctorRequest(resource)
+	if len(issues) > 0 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	want := &models.DoctorRequest{UserID: 7, Phone: "555-0107", Specialization: "Diagnostics", LicenseNumber: "LIC-1234"}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("request = %+v, want %+v", req, want)
+	}
+
+	_, issues = DoctorRequest(&Practitioner{
+		ResourceType: "Practitioner",
+		Identifier:   []Identifier{{System: SystemUserID, Value: "seven"}},
+	})
+	if got := expressions(issues); !reflect.DeepEqual(got, []string{"Practitioner.identifier", "Practitioner.identifier", "Practitioner.identifier[0].value", "Practitioner.qualification"}) {
+		t.Errorf("issues at %v", got)
+	}
+}
+
+func TestAppointmentStatusMapping(t *testing.T) {
+	statuses := []string{
+		models.AppointmentRequested, models.AppointmentScheduled, models.AppointmentConfirmed,
+		models.AppointmentCheckedIn, models.AppointmentInProgress, models.AppointmentCompleted,
+		models.AppointmentCancelled, models.AppointmentNoShow,
+	}
+	for _, status := range statuses {
+		fhirStatus := AppointmentStatus(status)
+		if fhirStatus == "pending" {
+			t.Errorf("%s has no FHIR status", status)
+			continue
+		}
+		found := false
+		for _, s := range AppointmentStatusesFor(fhirStatus) {
+			found = found || s == status
+		}
+		if !found {
+			t.Errorf("searching for %s does not find %s appointments", fhirStatus, status)
+		}
+	}
+
+	if got := AppointmentStatus("archived"); got != "pending" {
+		t.Errorf("unknown status maps to %q, want pending", got)
+	}
+	booked := AppointmentStatusesFor("booked")
+	sort.Strings(booked)
+	if !reflect.DeepEqual(booked, []string{models.AppointmentConfirmed, models.AppointmentScheduled}) {
+		t.Errorf("booked = %v, want confirmed and scheduled", booked)
+	}
+	if got := AppointmentStatusesFor("entered-in-error"); got != nil {
+		t.Errorf("entered-in-error = %v, want no statuses", got)
+	}
+}
+
+func TestAppointmentRoundTrip(t *testing.T) {
+	notes, reason := "Follow-up", "Patient unwell"
+	appointment := &models.Appointment{
+		ID:           9,
+		PatientID:    42,
+		DoctorID:     7,
+		DateTime:     time.Date(2030, 3, 4, 10, 0, 0, 0, time.FixedZone("CET", 3600)),
+		Duration:     45,
+		Status:       models.AppointmentScheduled,
+		Notes:        &notes,
+		StatusReason: &reason,
+		Patient:      &models.Patient{FirstName: "Jane", LastName: "Doe"},
+		Doctor:       &models.User{FirstName: "Gregory", LastName: "House"},
+	}
+
+	resource := roundTrip(t, FromAppointment(appointment))
+
+	if resource.Status != "booked" || resource.Start != "2030-03-04T09:00:00Z" || resource.End != "2030-03-04T09:45:00Z" || resource.MinutesDuration != 45 {
+		t.Errorf("resource = %+v", resource)
+	}
+	if resource.CancelationReason != nil {
+		t.Error("a booked appointment carries a cancellation reason")
+	}
+	if p := resource.Participant; len(p) != 2 || p[0].Actor.Reference != "Patient/42" || p[0].Actor.Display != "Jane Doe" ||
+		p[1].Actor.Reference != "Practitioner/7" || p[1].Actor.Display != "Gregory House" {
+		t.Errorf("participants = %+v", resource.Participant)
+	}
+
+	resource.ID = ""
+	req, issues := AppointmentRequest(resource)
+	if len(issues) > 0 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	want := &models.AppointmentRequest{
+		PatientID: 42,
+		DoctorID:  7,
+		DateTime:  "2030-03-04T09:00:00Z",
+		Duration:  45,
+		Notes:     notes,
+		Status:    models.AppointmentScheduled,
+	}
+	if !reflect.DeepEqual(req, want) {
+		t.Errorf("request = %+v, want %+v", req, want)
+	}
+
+	appointment.Status = models.AppointmentCancelled
+	if resource := FromAppointment(appointment); resource.Status != "cancelled" || resource.CancelationReason == nil || resource.CancelationReason.Text != reason {
+		t.Errorf("cancelled appointment = %+v, want the reason given", resource)
+	}
+}
+
+func validAppointment() *Appointment {
+	return &Appointment{
+		ResourceType:    "Appointment",
+		Status:          "proposed",
+		Start:           "2030-03-04T09:00:00+01:00",
+		MinutesDuration: 30,
+		Participant: []AppointmentParticipant{
+			{Actor: &Reference{Reference: "Patient/42"}},
+			{Actor: &Reference{Reference: "https://fhir.example.org/fhir/Practitioner/7"}},
+		},
+	}
+}
+
+func TestAppointmentRequest(t *testing.T) {
+	req, issues := AppointmentRequest(validAppointment())
+	if len(issues) > 0 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	if req.Status != models.AppointmentRequested || req.PatientID != 42 || req.DoctorID != 7 || req.Duration != 30 || req.DateTime != "2030-03-04T09:00:00+01:00" {
+		t.Errorf("request = %+v", req)
+	}
+
+	a := validAppointment()
+	a.MinutesDuration = 0
+	a.End = "2030-03-04T09:20:00+01:00"
+	if req, issues := AppointmentRequest(a); len(issues) > 0 || req.Duration != 20 {
+		t.Errorf("duration from end = %d, issues %+v, want 20", req.Duration, issues)
+	}
+}
+
+func TestAppointmentRequestIssues(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(a *Appointment)
+		want   []string
+	}{
+		{"wrong resource type", func(a *Appointment) { a.ResourceType = "Patient" }, []string{"resourceType"}},
+		{"id on create", func(a *Appointment) { a.ID = "9" }, []string{"Appointment.id"}},
+		{"no status", func(a *Appointment) { a.Status = "" }, []string{"Appointment.status"}},
+		{"already fulfilled", func(a *Appointment) { a.Status = "fulfilled" }, []string{"Appointment.status"}},
+		{"no start", func(a *Appointment) { a.Start = "" }, []string{"Appointment.start"}},
+		{"start without zone", func(a *Appointment) { a.Start = "2030-03-04T09:00:00" }, []string{"Appointment.start"}},
+		{"end without zone", func(a *Appointment) { a.End = "2030-03-04T09:30:00" }, []string{"Appointment.end"}},
+		{"no duration", func(a *Appointment) { a.MinutesDuration = 0 }, []string{"Appointment.end"}},
+		{"duration disagrees with end", func(a *Appointment) { a.End = "2030-03-04T08:45:00Z" }, []string{"Appointment.minutesDuration"}},
+		{"too short", func(a *Appointment) { a.MinutesDuration = 10 }, []string{"Appointment.minutesDuration"}},
+		{"too long", func(a *Appointment) { a.MinutesDuration = 300 }, []string{"Appointment.minutesDuration"}},
+		{"bad reference", func(a *Appointment) { a.Participant[0].Actor.Reference = "Patient/abc" },
+			[]string{"Appointment.participant", "Appointment.participant[0].actor"}},
+		{"two patients", func(a *Appointment) {
+			a.Participant = append(a.Participant, AppointmentParticipant{Actor: &Reference{Reference: "Patient/43"}})
+		}, []string{"Appointment.participant[2].actor"}},
+		{"unsupported participant", func(a *Appointment) {
+			a.Participant = append(a.Participant, AppointmentParticipant{Actor: &Reference{Reference: "Location/1"}})
+		}, []string{"Appointment.participant[2].actor"}},
+		{"no practitioner", func(a *Appointment) { a.Participant = a.Participant[:1] }, []string{"Appointment.participant"}},
+		{"no participants", func(a *Appointment) { a.Participant = nil }, []string{"Appointment.participant", "Appointment.participant"}},
+	}
+	for _, tt := range tests {
+		a := validAppointment()
+		tt.modify(a)
+		_, issues := AppointmentRequest(a)
+		if got := expressions(issues); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: issues at %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		reference    string
+		resourceType string
+		id           uint
+		ok           bool
+	}{
+		{"Patient/12", "Patient", 12, true},
+		{"Practitioner/7/", "Practitioner", 7, true},
+		{"https://fhir.example.org/fhir/Patient/12", "Patient", 12, true},
+		{"Patient", "", 0, false},
+		{"Patient/", "", 0, false},
+		{"Patient/0", "", 0, false},
+		{"Patient/-1", "", 0, false},
+		{"Patient/abc", "", 0, false},
+		{"Patient/99999999999", "", 0, false},
+		{"", "", 0, false},
+	}
+	for _, tt := range tests {
+		resourceType, id, ok := ParseReference(tt.reference)
+		if resourceType != tt.resourceType || id != tt.id || ok != tt.ok {
+			t.Errorf("ParseReference(%q) = %q, %d, %v, want %q, %d, %v", tt.reference, resourceType, id, ok, tt.resourceType, tt.id, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+// Package fhir maps the hospital's patients, doctors and appointments to HL7
+// FHIR R4 resources. Only the elements the hospital stores are modelled;
+// anything else in an incoming resource is ignored.
+package fhir
+
+// ContentType is the media type of FHIR JSON.
+const ContentType = "application/fhir+json"
+
+// Version is the FHIR version served.
+const Version = "4.0.1"
+
+// Identifier systems for the hospital's own IDs.
+const (
+	SystemPatientID     = "urn:hospital-management:patient-id"
+	SystemUserID        = "urn:hospital-management:user-id"
+	SystemLicense       = "urn:hospital-management:license-number"
+	SystemAppointmentID = "urn:hospital-management:appointment-id"
+	SystemMRN           = "urn:hospital-management:mrn"
+)
+
+type Meta struct {
+	LastUpdated string `json:"lastUpdated,omitempty"`
+}
+
+type Identifier struct {
+	Use    string `json:"use,omitempty"`
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+}
+
+type HumanName struct {
+	Use    string   `json:"use,omitempty"`
+	Text   string   `json:"text,omitempty"`
+	Family string   `json:"family,omitempty"`
+	Given  []string `json:"given,omitempty"`
+}
+
+type ContactPoint struct {
+	System string `json:"system,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Use    string `json:"use,omitempty"`
+}
+
+type Address struct {
+	Text string `json:"text,omitempty"`
+}
+
+type CodeableConcept struct {
+	Text string `json:"text,omitempty"`
+}
+
+type Reference struct {
+	Reference string `json:"reference,omitempty"`
+	Display   string `json:"display,omitempty"`
+}
+
+type Patient struct {
+	ResourceType string         `json:"resourceType"`
+	ID           string         `json:"id,omitempty"`
+	Meta         *Meta          `json:"meta,omitempty"`
+	Identifier   []Identifier   `json:"identifier,omitempty"`
+	Active       *bool          `json:"active,omitempty"`
+	Name         []HumanName    `json:"name,omitempty"`
+	Telecom      []ContactPoint `json:"telecom,omitempty"`
+	Gender       string         `json:"gender,omitempty"`
+	BirthDate    string         `json:"birthDate,omitempty"`
+	Address      []Address      `json:"address,omitempty"`
+	Link         []PatientLink  `json:"link,omitempty"`
+}
+
+// PatientLink points a merged patient at the one that replaced it.
+type PatientLink struct {
+	Other Reference `json:"other"`
+	Type  string    `json:"type"`
+}
+
+type Qualification struct {
+	Code CodeableConcept `json:"code"`
+}
+
+type Practitioner struct {
+	ResourceType  string          `json:"resourceType"`
+	ID            string          `json:"id,omitempty"`
+	Meta          *Meta           `json:"meta,omitempty"`
+	Identifier    []Identifier    `json:"identifier,omitempty"`
+	Active        *bool           `json:"active,omitempty"`
+	Name          []HumanName     `json:"name,omitempty"`
+	Telecom       []ContactPoint  `json:"telecom,omitempty"`
+	Qualification []Qualification `json:"qualification,omitempty"`
+}
+
+type AppointmentParticipant struct {
+	Actor  *Reference `json:"actor,omitempty"`
+	Status string     `json:"status"`
+}
+
+type Appointment struct {
+	ResourceType      string                   `json:"resourceType"`
+	ID                string                   `json:"id,omitempty"`
+	Meta              *Meta                    `json:"meta,omitempty"`
+	Identifier        []Identifier             `json:"identifier,omitempty"`
+	Status            string                   `json:"status"`
+	CancelationReason *CodeableConcept         `json:"cancelationReason,omitempty"`
+	Start             string                   `json:"start,omitempty"`
+	End               string                   `json:"end,omitempty"`
+	MinutesDuration   int                      `json:"minutesDuration,omitempty"`
+	Created           string                   `json:"created,omitempty"`
+	Comment           string                   `json:"comment,omitempty"`
+	Participant       []AppointmentParticipant `json:"participant"`
+}
+
+type BundleLink struct {
+	Relation string `json:"relation"`
+	URL      string `json:"url"`
+}
+
+type BundleSearch struct {
+	Mode string `json:"mode"`
+}
+
+type BundleEntry struct {
+	FullURL  string        `json:"fullUrl"`
+	Resource interface{}   `json:"resource"`
+	Search   *BundleSearch `json:"search,omitempty"`
+}
+
+type Bundle struct {
+	ResourceType string        `json:"resourceType"`
+	Type         string        `json:"type"`
+	Total        *int64        `json:"total,omitempty"`
+	Link         []BundleLink  `json:"link,omitempty"`
+	Entry        []BundleEntry `json:"entry"`
+}
+
+// Issue severities and codes used in OperationOutcome.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+
+	IssueInvalid      = "invalid"
+	IssueRequired     = "required"
+	IssueValue        = "value"
+	IssueStructure    = "structure"
+	IssueNotFound     = "not-found"
+	IssueConflict     = "conflict"
+	IssueForbidden    = "forbidden"
+	IssueException    = "exception"
+	IssueNotSupported = "not-supported"
+	IssueBusiness     = "business-rule"
+	IssueDuplicate    = "duplicate"
+)
+
+type Issue struct {
+	Severity    string   `json:"severity"`
+	Code        string   `json:"code"`
+	Diagnostics string   `json:"diagnostics,omitempty"`
+	Expression  []string `json:"expression,omitempty"`
+}
+
+type OperationOutcome struct {
+	ResourceType string  `json:"resourceType"`
+	Issue        []Issue `json:"issue"`
+}
+
+// NewOperationOutcome wraps issues in an OperationOutcome.
+func NewOperationOutcome(issues ...Issue) *OperationOutcome {
+	return &OperationOutcome{ResourceType: "OperationOutcome", Issue: issues}
+}
+
+// ErrorOutcome is an OperationOutcome holding a single error.
+func ErrorOutcome(code, diagnostics string) *OperationOutcome {
+	return NewOperationOutcome(Issue{Severity: SeverityError, Code: code, Diagnostics: diagnostics})
+}
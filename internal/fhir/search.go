@@ -0,0 +1,89 @@
+package fhir
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DateRange is the half-open interval [From, To) a date search parameter
+// selects. Zero bounds are open.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// ParseDateParams intersects the ranges selected by the values of a FHIR
+// date search parameter, e.g. ["ge2024-01-01", "lt2024-02-01"]. Each value
+// has an optional eq, ge, gt, le or lt prefix and is a year, year-month,
+// date or RFC3339 instant; a partial date covers its whole period.
+// Dates without a time zone are read in loc.
+func ParseDateParams(values []string, loc *time.Location) (DateRange, error) {
+	var r DateRange
+	for _, value := range values {
+		prefix := "eq"
+		if len(value) > 2 && value[0] >= 'a' && value[0] <= 'z' {
+			prefix, value = value[:2], value[2:]
+		}
+
+		lo, hi, err := parseDatePeriod(value, loc)
+		if err != nil {
+			return r, err
+		}
+
+		var from, to time.Time
+		switch prefix {
+		case "eq":
+			from, to = lo, hi
+		case "ge":
+			from = lo
+		case "gt":
+			from = hi
+		case "le":
+			to = hi
+		case "lt":
+			to = lo
+		default:
+			return r, fmt.Errorf("unsupported date prefix %q", prefix)
+		}
+		if !from.IsZero() && (r.From.IsZero() || from.After(r.From)) {
+			r.From = from
+		}
+		if !to.IsZero() && (r.To.IsZero() || to.Before(r.To)) {
+			r.To = to
+		}
+	}
+	return r, nil
+}
+
+// parseDatePeriod returns the period a FHIR date value covers.
+func parseDatePeriod(value string, loc *time.Location) (time.Time, time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, t.Add(time.Second), nil
+	}
+	layouts := []struct {
+		layout string
+		next   func(time.Time) time.Time
+	}{
+		{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+		{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+		{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+	}
+	for _, l := range layouts {
+		if len(value) != len(l.layout) {
+			continue
+		}
+		if t, err := time.ParseInLocation(l.layout, value, loc); err == nil {
+			return t, l.next(t), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+// ParseToken splits a token search value of the form [system|]code.
+func ParseToken(value string) (system, code string) {
+	if i := strings.Index(value, "|"); i >= 0 {
+		return value[:i], value[i+1:]
+	}
+	return "", value
+}
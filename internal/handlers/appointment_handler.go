@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"hospital-management/internal/models"
@@ -28,58 +30,34 @@ func (h *AppointmentHandler) CreateAppointment(c *gin.Context) {
 		return
 	}
 
-	createdAppointment, err := h.appointmentService.CreateAppointment(&appointmentReq)
+	createdAppointment, err := h.appointmentService.CreateAppointment(actorFromContext(c), &appointmentReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAppointmentError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, createdAppointment)
 }
 
+// GetAppointments handles searching appointments. Any combination of
+// doctor_id, patient_id, status (comma-separated), from and to (RFC3339 or
+// YYYY-MM-DD, to-dates include the whole day), date (a single day),
+// department and created_by is evaluated in one query, together with the
+// shared sort, limit, cursor and page parameters.
 func (h *AppointmentHandler) GetAppointments(c *gin.Context) {
-	doctorIDStr := c.Query("doctor_id")
-	patientIDStr := c.Query("patient_id")
-	dateStr := c.Query("date")
-
-	var appointments []*models.Appointment
-	var err error
-
-	if doctorIDStr != "" {
-		if doctorID, parseErr := strconv.ParseUint(doctorIDStr, 10, 32); parseErr == nil {
-			appointments, err = h.appointmentService.GetAppointmentsByDoctor(uint(doctorID))
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
-			return
-		}
-	} else if patientIDStr != "" {
-		if patientID, parseErr := strconv.ParseUint(patientIDStr, 10, 32); parseErr == nil {
-			appointments, err = h.appointmentService.GetAppointmentsByPatient(uint(patientID))
-		} else {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
-			return
-		}
-	} else if dateStr != "" {
-		allAppointments, err := h.appointmentService.GetAllAppointments()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		for _, appt := range allAppointments {
-			if appt.DateTime.Format("2006-01-02") == dateStr {
-				appointments = append(appointments, appt)
-			}
-		}
-	} else {
-		appointments, err = h.appointmentService.GetAllAppointments()
+	filter, err := appointmentFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
+	result, err := h.appointmentService.ListAppointments(actorFromContext(c), filter, listOptionsFromQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeListError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, appointments)
+	c.JSON(http.StatusOK, result)
 }
 
 func (h *AppointmentHandler) GetAppointmentByID(c *gin.Context) {
@@ -89,7 +67,7 @@ func (h *AppointmentHandler) GetAppointmentByID(c *gin.Context) {
 		return
 	}
 
-	appointment, err := h.appointmentService.GetAppointmentByID(uint(id))
+	appointment, err := h.appointmentService.GetAppointmentByID(actorFromContext(c), uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -111,9 +89,16 @@ func (h *AppointmentHandler) UpdateAppointment(c *gin.Context) {
 		return
 	}
 
-	updatedAppointment, err := h.appointmentService.UpdateAppointment(uint(id), &appointmentUpdateReq)
+	// Clinical findings are recorded as encounter notes, which keep every
+	// version; they are no longer written over on the appointment
+	if appointmentUpdateReq.Diagnosis != "" || appointmentUpdateReq.Treatment != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "diagnosis and treatment are recorded as encounter notes: POST /api/v1/appointments/:id/notes"})
+		return
+	}
+
+	updatedAppointment, err := h.appointmentService.UpdateAppointment(actorFromContext(c), uint(id), &appointmentUpdateReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAppointmentError(c, err)
 		return
 	}
 
@@ -127,7 +112,7 @@ func (h *AppointmentHandler) DeleteAppointment(c *gin.Context) {
 		return
 	}
 
-	if err := h.appointmentService.DeleteAppointment(uint(id)); err != nil {
+	if err := h.appointmentService.DeleteAppointment(actorFromContext(c), uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -147,7 +132,7 @@ func (h *AppointmentHandler) GetDoctorSchedule(c *gin.Context) {
 		dateStr = time.Now().Format("2006-01-02")
 	}
 
-	appointments, err := h.appointmentService.GetAppointmentsByDoctor(uint(doctorID))
+	appointments, err := h.appointmentService.GetAppointmentsByDoctor(actorFromContext(c), uint(doctorID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -169,6 +154,8 @@ func (h *AppointmentHandler) GetDoctorSchedule(c *gin.Context) {
 	c.JSON(http.StatusOK, dayAppointments)
 }
 
+// UpdateAppointmentStatus moves an appointment through its lifecycle. A
+// reason is required when cancelling or marking a no-show.
 func (h *AppointmentHandler) UpdateAppointmentStatus(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -176,27 +163,15 @@ func (h *AppointmentHandler) UpdateAppointmentStatus(c *gin.Context) {
 		return
 	}
 
-	var statusUpdate struct {
-		Status string `json:"status"`
-		Notes  string `json:"notes,omitempty"`
-	}
-	if err := c.ShouldBindJSON(&statusUpdate); err != nil {
+	var statusReq models.AppointmentStatusRequest
+	if err := c.ShouldBindJSON(&statusReq); err != nil || statusReq.Status == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
 
-	if statusUpdate.Status != "confirmed" && statusUpdate.Status != "cancelled" && statusUpdate.Status != "completed" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Use 'confirmed', 'cancelled', or 'completed'"})
-		return
-	}
-
-	updateReq := models.AppointmentUpdateRequest{
-		Status: statusUpdate.Status,
-		Notes:  statusUpdate.Notes,
-	}
-	updatedAppointment, err := h.appointmentService.UpdateAppointment(uint(id), &updateReq)
+	updatedAppointment, err := h.appointmentService.ChangeAppointmentStatus(actorFromContext(c), uint(id), &statusReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAppointmentError(c, err)
 		return
 	}
 
@@ -204,7 +179,7 @@ func (h *AppointmentHandler) UpdateAppointmentStatus(c *gin.Context) {
 }
 
 func (h *AppointmentHandler) GetUpcomingAppointments(c *gin.Context) {
-	allAppointments, err := h.appointmentService.GetAllAppointments()
+	allAppointments, err := h.appointmentService.GetAllAppointments(actorFromContext(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -239,11 +214,182 @@ func (h *AppointmentHandler) RescheduleAppointment(c *gin.Context) {
 	updateReq := models.AppointmentUpdateRequest{
 		DateTime: rescheduleReq.DateTime,
 	}
-	updatedAppointment, err := h.appointmentService.UpdateAppointment(uint(id), &updateReq)
+	updatedAppointment, err := h.appointmentService.UpdateAppointment(actorFromContext(c), uint(id), &updateReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeAppointmentError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, updatedAppointment)
 }
+
+// CreateAppointmentSeries books every occurrence of a recurring series, or
+// none of them if any occurrence cannot be booked.
+func (h *AppointmentHandler) CreateAppointmentSeries(c *gin.Context) {
+	var seriesReq models.AppointmentSeriesRequest
+	if err := c.ShouldBindJSON(&seriesReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	series, err := h.appointmentService.CreateAppointmentSeries(actorFromContext(c), &seriesReq)
+	if err != nil {
+		writeAppointmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, series)
+}
+
+func (h *AppointmentHandler) GetAppointmentSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("seriesId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid series ID"})
+		return
+	}
+
+	series, err := h.appointmentService.GetAppointmentSeries(actorFromContext(c), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// UpdateAppointmentSeries changes this occurrence, this and the following
+// ones, or the whole series the appointment belongs to.
+func (h *AppointmentHandler) UpdateAppointmentSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	var updateReq models.AppointmentSeriesUpdateRequest
+	if err := c.ShouldBindJSON(&updateReq); err != nil || !validSeriesScope(updateReq.Scope) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body. scope must be one of: this following all"})
+		return
+	}
+
+	appointments, err := h.appointmentService.UpdateAppointmentSeries(actorFromContext(c), uint(id), &updateReq)
+	if err != nil {
+		writeAppointmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appointments)
+}
+
+// CancelAppointmentSeries cancels this occurrence, this and the following
+// ones, or the whole series the appointment belongs to.
+func (h *AppointmentHandler) CancelAppointmentSeries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	var cancelReq models.AppointmentSeriesCancelRequest
+	if err := c.ShouldBindJSON(&cancelReq); err != nil || !validSeriesScope(cancelReq.Scope) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body. scope must be one of: this following all"})
+		return
+	}
+
+	appointments, err := h.appointmentService.CancelAppointmentSeries(actorFromContext(c), uint(id), &cancelReq)
+	if err != nil {
+		writeAppointmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appointments)
+}
+
+func validSeriesScope(scope string) bool {
+	return scope == models.SeriesScopeThis || scope == models.SeriesScopeFollowing || scope == models.SeriesScopeAll
+}
+
+// writeAppointmentError responds with 409 and the conflicting appointments
+// (or occurrences, for a series) when a booking overlaps the doctor's
+// schedule, 422 when it falls outside the
+// doctor's working hours or breaks the status lifecycle, 403 when the role may
+// not make a status change, 400 when a required reason is missing, and 500
+// otherwise.
+func writeAppointmentError(c *gin.Context, err error) {
+	var conflictErr *service.ConflictError
+	if errors.As(err, &conflictErr) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     conflictErr.Error(),
+			"conflicts": conflictErr.Conflicts,
+		})
+		return
+	}
+	var seriesErr *service.SeriesConflictError
+	if errors.As(err, &seriesErr) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":       seriesErr.Error(),
+			"occurrences": seriesErr.Occurrences,
+		})
+		return
+	}
+	var transitionErr *service.TransitionError
+	if errors.Is(err, service.ErrOutsideAvailability) || errors.Is(err, service.ErrNotReschedulable) ||
+		errors.Is(err, service.ErrNotInSeries) || errors.As(err, &transitionErr) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrTransitionForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrReasonRequired) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// appointmentFilterFromQuery reads the appointment list filters: doctor_id,
+// patient_id, created_by, from, to, date, status (comma-separated) and
+// department.
+func appointmentFilterFromQuery(c *gin.Context) (*models.AppointmentFilter, error) {
+	var filter models.AppointmentFilter
+	var err error
+
+	if filter.DoctorID, err = parseUintQuery(c, "doctor_id"); err != nil {
+		return nil, errors.New("Invalid doctor ID")
+	}
+	if filter.PatientID, err = parseUintQuery(c, "patient_id"); err != nil {
+		return nil, errors.New("Invalid patient ID")
+	}
+	if filter.CreatedBy, err = parseUintQuery(c, "created_by"); err != nil {
+		return nil, errors.New("Invalid created_by user ID")
+	}
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		return nil, errors.New("Invalid from date")
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		return nil, errors.New("Invalid to date")
+	}
+	if len(c.Query("to")) == len("2006-01-02") {
+		filter.To = filter.To.Add(24 * time.Hour)
+	}
+	if dateStr := c.Query("date"); dateStr != "" {
+		date, err := time.Parse("2006-01-02", dateStr)
+		if err != nil {
+			return nil, errors.New("Invalid date format")
+		}
+		filter.From = date
+		filter.To = date.Add(24 * time.Hour)
+	}
+	if status := c.Query("status"); status != "" {
+		for _, s := range strings.Split(status, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				filter.Statuses = append(filter.Statuses, s)
+			}
+		}
+	}
+	filter.Department = c.Query("department")
+
+	return &filter, nil
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestWriteAppointmentErrorConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	writeAppointmentError(c, &service.ConflictError{Conflicts: []*models.Appointment{{ID: 41}}})
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusConflict)
+	}
+	var body struct {
+		Error     string               `json:"error"`
+		Conflicts []models.Appointment `json:"conflicts"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error == "" || len(body.Conflicts) != 1 || body.Conflicts[0].ID != 41 {
+		t.Fatalf("body = %s, want the conflicting appointment", w.Body.String())
+	}
+}
+
+func TestWriteAppointmentErrorStatuses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{&service.SeriesConflictError{}, http.StatusConflict},
+		{service.ErrOutsideAvailability, http.StatusUnprocessableEntity},
+		{&service.TransitionError{From: models.AppointmentCompleted, To: models.AppointmentScheduled}, http.StatusUnprocessableEntity},
+		{service.ErrTransitionForbidden, http.StatusForbidden},
+		{service.ErrReasonRequired, http.StatusBadRequest},
+		{errors.New("database is down"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		writeAppointmentError(c, tt.err)
+		if w.Code != tt.want {
+			t.Errorf("%v: status = %d, want %d", tt.err, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetAuditLog handles querying the audit trail. Supported filters are
+// patient_id, user_id, entity, action, from and to (RFC3339 or YYYY-MM-DD),
+// plus limit and offset.
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	var query models.AuditQuery
+	var err error
+
+	if query.PatientID, err = parseUintQuery(c, "patient_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+	if query.UserID, err = parseUintQuery(c, "user_id"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if query.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+		return
+	}
+	if query.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+		return
+	}
+	// A bare date for "to" includes the whole day
+	if len(c.Query("to")) == len("2006-01-02") {
+		query.To = query.To.Add(24 * time.Hour)
+	}
+	query.Entity = c.Query("entity")
+	query.Action = c.Query("action")
+	query.Limit, _ = strconv.Atoi(c.Query("limit"))
+	query.Offset, _ = strconv.Atoi(c.Query("offset"))
+
+	entries, err := h.auditService.Query(&query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+	})
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvailabilityHandler struct {
+	availabilityService service.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilityService service.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		availabilityService: availabilityService,
+	}
+}
+
+// GetAvailability handles getting a doctor's weekly template and exceptions
+func (h *AvailabilityHandler) GetAvailability(c *gin.Context) {
+	doctorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	availability, err := h.availabilityService.GetAvailability(uint(doctorID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, availability)
+}
+
+// SetWeeklyTemplate handles replacing a doctor's weekly shifts and breaks
+func (h *AvailabilityHandler) SetWeeklyTemplate(c *gin.Context) {
+	doctorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	var templateReq models.AvailabilityTemplateRequest
+	if err := c.ShouldBindJSON(&templateReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	rules, err := h.availabilityService.SetWeeklyTemplate(uint(doctorID), &templateReq)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"doctor_id": doctorID, "rules": rules})
+}
+
+// AddException handles adding leave, a holiday or an extra session
+func (h *AvailabilityHandler) AddException(c *gin.Context) {
+	doctorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	var exceptionReq models.AvailabilityExceptionRequest
+	if err := c.ShouldBindJSON(&exceptionReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	exception, err := h.availabilityService.AddException(uint(doctorID), &exceptionReq)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, exception)
+}
+
+// RemoveException handles deleting an availability exception
+func (h *AvailabilityHandler) RemoveException(c *gin.Context) {
+	doctorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+	exceptionID, err := strconv.ParseUint(c.Param("exceptionId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid exception ID"})
+		return
+	}
+
+	if err := h.availabilityService.RemoveException(uint(doctorID), uint(exceptionID)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSlots handles searching a doctor's free slots. from and to accept RFC3339
+// or YYYY-MM-DD and default to the next seven days; duration defaults to 30
+// minutes.
+func (h *AvailabilityHandler) GetSlots(c *gin.Context) {
+	doctorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+		return
+	}
+	if from.IsZero() {
+		from = time.Now()
+	}
+
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+		return
+	}
+	if to.IsZero() {
+		to = from.Add(7 * 24 * time.Hour)
+	}
+
+	duration, err := strconv.Atoi(c.DefaultQuery("duration", "30"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration"})
+		return
+	}
+
+	slots, err := h.availabilityService.FindSlots(uint(doctorID), from, to, duration)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"doctor_id": doctorID,
+		"duration":  duration,
+		"slots":     slots,
+	})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Media types of the bulk formats.
+var formatContentTypes = map[string]string{
+	models.FormatCSV:    "text/csv; charset=utf-8",
+	models.FormatNDJSON: "application/x-ndjson",
+}
+
+type BulkHandler struct {
+	importService service.ImportService
+	exportService service.ExportService
+}
+
+func NewBulkHandler(importService service.ImportService, exportService service.ExportService) *BulkHandler {
+	return &BulkHandler{
+		importService: importService,
+		exportService: exportService,
+	}
+}
+
+// ImportPatients starts a bulk patient import. The file is the request body
+// or the "file" part of a multipart form; its format is taken from ?format=,
+// the file extension or the content type. Responds 202 with the pending job.
+func (h *BulkHandler) ImportPatients(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxImportSize+1<<20)
+
+	body, fileName, err := importFile(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := c.Query("format")
+	if format == "" {
+		format = formatFromFile(fileName, c.ContentType())
+	}
+
+	job, err := h.importService.StartPatientImport(actorFromContext(c), strings.ToLower(format), fileName, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, service.ErrUnsupportedFormat):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrImportTooLarge), errors.As(err, &maxBytesErr):
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": service.ErrImportTooLarge.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/v1/imports/%d", job.ID))
+	c.JSON(http.StatusAccepted, job)
+}
+
+// importFile returns the uploaded file and its name, if known.
+func importFile(c *gin.Context) (io.Reader, string, error) {
+	if c.ContentType() != "multipart/form-data" {
+		return c.Request.Body, "", nil
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid multipart body: %w", err)
+	}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return nil, "", errors.New("multipart body has no file part")
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid multipart body: %w", err)
+		}
+		if part.FormName() == "file" {
+			return part, part.FileName(), nil
+		}
+	}
+}
+
+// formatFromFile guesses an import format from a file name or media type.
+func formatFromFile(fileName, contentType string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		return models.FormatCSV
+	case ".ndjson", ".jsonl":
+		return models.FormatNDJSON
+	}
+	switch contentType {
+	case "text/csv":
+		return models.FormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return models.FormatNDJSON
+	}
+	return ""
+}
+
+func (h *BulkHandler) GetImportJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import job ID"})
+		return
+	}
+
+	job, err := h.importService.GetImportJob(actorFromContext(c), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ExportPatients streams the patients matching the list filters as CSV or
+// NDJSON (?format=, default csv).
+func (h *BulkHandler) ExportPatients(c *gin.Context) {
+	format, ok := exportFormat(c, "patients")
+	if !ok {
+		return
+	}
+
+	err := h.exportService.ExportPatients(actorFromContext(c), format, patientFilterFromQuery(c), c.Writer)
+	writeExportError(c, err)
+}
+
+// ExportAppointments streams the appointments matching the list filters as
+// CSV or NDJSON (?format=, default csv).
+func (h *BulkHandler) ExportAppointments(c *gin.Context) {
+	format, ok := exportFormat(c, "appointments")
+	if !ok {
+		return
+	}
+	filter, err := appointmentFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.exportService.ExportAppointments(actorFromContext(c), format, filter, c.Writer)
+	writeExportError(c, err)
+}
+
+// exportFormat reads ?format= and sets the download headers for it.
+func exportFormat(c *gin.Context, name string) (string, bool) {
+	format := strings.ToLower(c.DefaultQuery("format", models.FormatCSV))
+	contentType, ok := formatContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": service.ErrUnsupportedFormat.Error()})
+		return "", false
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s-%s.%s", name, time.Now().Format("20060102"), format))
+	return format, true
+}
+
+// writeExportError reports an export that failed before anything was
+// written. Once rows have been sent the status cannot change, so the error
+// is only logged and the response is cut short.
+func writeExportError(c *gin.Context, err error) {
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		log.Printf("Export aborted: %v", err)
+		c.Abort()
+		return
+	}
+	c.Header("Content-Disposition", "")
+	c.Header("Content-Type", "")
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsentHandler serves patients' consent decisions.
+type ConsentHandler struct {
+	consentService service.ConsentService
+}
+
+func NewConsentHandler(consentService service.ConsentService) *ConsentHandler {
+	return &ConsentHandler{
+		consentService: consentService,
+	}
+}
+
+func (h *ConsentHandler) GetConsents(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	consents, err := h.consentService.GetConsents(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, consents)
+}
+
+// GetConsentStatus returns whether the patient currently consents to each
+// consent type.
+func (h *ConsentHandler) GetConsentStatus(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	statuses, err := h.consentService.GetConsentStatus(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, statuses)
+}
+
+func (h *ConsentHandler) RecordConsent(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.ConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	consent, err := h.consentService.RecordConsent(actorFromContext(c), patientID, &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrPatientMerged) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, consent)
+}
+
+func (h *ConsentHandler) RevokeConsent(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+	var req models.ConsentRevokeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+	consent, err := h.consentService.RevokeConsent(actorFromContext(c), patientID, id, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrConsentRevoked) || errors.Is(err, service.ErrConsentNotGranted) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, consent)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// actorFromContext builds the acting user from the claims the authentication
+// middleware stored in the request context.
+func actorFromContext(c *gin.Context) *models.Actor {
+	actor := &models.Actor{
+		Role:     c.GetString("role"),
+		ClientIP: c.ClientIP(),
+	}
+	if val, exists := c.Get("user_id"); exists {
+		if userID, ok := val.(uint); ok {
+			actor.UserID = userID
+		}
+	}
+	return actor
+}
+
+// parseUintQuery parses an optional unsigned integer query parameter.
+func parseUintQuery(c *gin.Context, key string) (uint, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, nil
+	}
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(parsed), nil
+}
+
+// parseTimeQuery parses an optional RFC3339 timestamp or YYYY-MM-DD date
+// query parameter.
+func parseTimeQuery(c *gin.Context, key string) (time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// listOptionsFromQuery reads the shared sort, limit, cursor and page query
+// parameters of list endpoints.
+func listOptionsFromQuery(c *gin.Context) *models.ListOptions {
+	opts := &models.ListOptions{
+		Sort:   c.Query("sort"),
+		Cursor: c.Query("cursor"),
+	}
+	opts.Limit, _ = strconv.Atoi(c.Query("limit"))
+	opts.Page, _ = strconv.Atoi(c.Query("page"))
+	return opts
+}
+
+// writeListError responds with 400 for bad sort or cursor parameters and 500
+// otherwise.
+func writeListError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrInvalidListQuery) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DoctorHandler struct {
+	doctorService service.DoctorService
+}
+
+func NewDoctorHandler(doctorService service.DoctorService) *DoctorHandler {
+	return &DoctorHandler{
+		doctorService: doctorService,
+	}
+}
+
+// GetDoctors handles searching the doctor directory by specialization,
+// department and free-text q
+func (h *DoctorHandler) GetDoctors(c *gin.Context) {
+	filter := models.DoctorFilter{
+		Specialization: c.Query("specialization"),
+		Department:     c.Query("department"),
+		Query:          c.Query("q"),
+	}
+
+	doctors, err := h.doctorService.SearchDoctors(&filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, doctors)
+}
+
+// CreateDoctor handles creating a profile for a doctor user account
+func (h *DoctorHandler) CreateDoctor(c *gin.Context) {
+	var doctorReq models.DoctorRequest
+	if err := c.ShouldBindJSON(&doctorReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	createdDoctor, err := h.doctorService.CreateDoctor(&doctorReq)
+	if err != nil {
+		if errors.Is(err, repository.ErrDoctorProfileTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createdDoctor)
+}
+
+// GetDoctorByID handles getting a doctor profile by the doctor's user ID
+func (h *DoctorHandler) GetDoctorByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	doctor, err := h.doctorService.GetDoctor(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, doctor)
+}
+
+// UpdateDoctor handles doctor profile updates
+func (h *DoctorHandler) UpdateDoctor(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	var doctorReq models.DoctorUpdateRequest
+	if err := c.ShouldBindJSON(&doctorReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	updatedDoctor, err := h.doctorService.UpdateDoctor(uint(id), &doctorReq)
+	if err != nil {
+		if errors.Is(err, repository.ErrDoctorProfileTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, updatedDoctor)
+}
+
+// DeleteDoctor handles removing a doctor profile
+func (h *DoctorHandler) DeleteDoctor(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	if err := h.doctorService.DeleteDoctor(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Doctor deleted successfully"})
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EncounterNoteHandler struct {
+	noteService service.EncounterNoteService
+}
+
+func NewEncounterNoteHandler(noteService service.EncounterNoteService) *EncounterNoteHandler {
+	return &EncounterNoteHandler{
+		noteService: noteService,
+	}
+}
+
+// CreateNote starts a draft note for an appointment, authored by the caller.
+func (h *EncounterNoteHandler) CreateNote(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	var noteReq models.EncounterNoteRequest
+	if err := c.ShouldBindJSON(&noteReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	note, err := h.noteService.CreateNote(actorFromContext(c), uint(appointmentID), &noteReq)
+	if err != nil {
+		writeNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, note)
+}
+
+func (h *EncounterNoteHandler) GetAppointmentNotes(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	notes, err := h.noteService.GetAppointmentNotes(actorFromContext(c), uint(appointmentID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+func (h *EncounterNoteHandler) GetPatientNotes(c *gin.Context) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return
+	}
+
+	notes, err := h.noteService.GetPatientNotes(actorFromContext(c), uint(patientID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// GetNote returns a note with its draft revisions and amendments.
+func (h *EncounterNoteHandler) GetNote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid note ID"})
+		return
+	}
+
+	note, err := h.noteService.GetNote(actorFromContext(c), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// UpdateNote replaces the content of a draft note.
+func (h *EncounterNoteHandler) UpdateNote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid note ID"})
+		return
+	}
+
+	var noteReq models.EncounterNoteRequest
+	if err := c.ShouldBindJSON(&noteReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	note, err := h.noteService.UpdateNote(actorFromContext(c), uint(id), &noteReq)
+	if err != nil {
+		writeNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+func (h *EncounterNoteHandler) SignNote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid note ID"})
+		return
+	}
+
+	note, err := h.noteService.SignNote(actorFromContext(c), uint(id))
+	if err != nil {
+		writeNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+func (h *EncounterNoteHandler) AmendNote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid note ID"})
+		return
+	}
+
+	var amendmentReq models.NoteAmendmentRequest
+	if err := c.ShouldBindJSON(&amendmentReq); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	amendment, err := h.noteService.AmendNote(actorFromContext(c), uint(id), &amendmentReq)
+	if err != nil {
+		writeNoteError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, amendment)
+}
+
+// writeNoteError responds with 409 when the note's state does not allow the
+// change, 403 when the caller is not the draft's author, and 400 otherwise.
+func writeNoteError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrNoteSigned), errors.Is(err, service.ErrNoteNotSigned), errors.Is(err, repository.ErrNoteChanged):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotNoteAuthor):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,447 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"hospital-management/internal/fhir"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FHIRHandler serves the FHIR R4 facade under /fhir/R4. Every interaction
+// goes through the same services as the REST API, so validation, conflict
+// checks and auditing apply unchanged.
+type FHIRHandler struct {
+	patientService     service.PatientService
+	doctorService      service.DoctorService
+	appointmentService service.AppointmentService
+	location           *time.Location
+}
+
+func NewFHIRHandler(patientService service.PatientService, doctorService service.DoctorService, appointmentService service.AppointmentService, location *time.Location) *FHIRHandler {
+	return &FHIRHandler{
+		patientService:     patientService,
+		doctorService:      doctorService,
+		appointmentService: appointmentService,
+		location:           location,
+	}
+}
+
+// Metadata returns the CapabilityStatement.
+func (h *FHIRHandler) Metadata(c *gin.Context) {
+	writeFHIR(c, http.StatusOK, fhir.Capabilities(fhirBaseURL(c)))
+}
+
+func (h *FHIRHandler) ReadPatient(c *gin.Context) {
+	id, ok := fhirIDParam(c)
+	if !ok {
+		return
+	}
+	patient, err := h.patientService.GetPatientByID(actorFromContext(c), id)
+	if err != nil {
+		writeFHIR(c, http.StatusNotFound, fhir.ErrorOutcome(fhir.IssueNotFound, fmt.Sprintf("Patient/%d not found", id)))
+		return
+	}
+	writeFHIR(c, http.StatusOK, fhir.FromPatient(patient))
+}
+
+// SearchPatients supports _id, identifier, name, birthdate, gender, phone and
+// _count. _id and identifier look up a single patient and cannot be combined
+// with the other parameters.
+func (h *FHIRHandler) SearchPatients(c *gin.Context) {
+	if id, ok, done := h.idSearch(c, fhir.SystemPatientID); done {
+		return
+	} else if ok {
+		var entries []interface{}
+		if patient, err := h.patientService.GetPatientByID(actorFromContext(c), id); err == nil {
+			entries = append(entries, fhir.FromPatient(patient))
+		}
+		writeFHIR(c, http.StatusOK, searchBundle(c, "Patient", entries, nil, ""))
+		return
+	}
+
+	filter := &models.PatientFilter{
+		Name:   c.Query("name"),
+		Gender: c.Query("gender"),
+		Phone:  c.Query("phone"),
+	}
+	if values := c.QueryArray("birthdate"); len(values) > 0 {
+		born, err := fhir.ParseDateParams(values, time.UTC)
+		if err != nil {
+			writeFHIR(c, http.StatusBadRequest, fhir.ErrorOutcome(fhir.IssueValue, "birthdate: "+err.Error()))
+			return
+		}
+		filter.BornFrom, filter.BornTo = born.From, born.To
+	}
+
+	result, err := h.patientService.ListPatients(actorFromContext(c), filter, fhirListOptions(c))
+	if err != nil {
+		writeFHIRSearchError(c, err)
+		return
+	}
+
+	entries := make([]interface{}, 0, len(result.Data))
+	for _, patient := range result.Data {
+		entries = append(entries, fhir.FromPatient(patient))
+	}
+	writeFHIR(c, http.StatusOK, searchBundle(c, "Patient", entries, &result.Total, result.NextCursor))
+}
+
+func (h *FHIRHandler) CreatePatient(c *gin.Context) {
+	var resource fhir.Patient
+	if !bindFHIR(c, &resource) {
+		return
+	}
+	req, issues := fhir.PatientRequest(&resource)
+	if len(issues) > 0 {
+		writeFHIR(c, http.StatusBadRequest, fhir.NewOperationOutcome(issues...))
+		return
+	}
+
+	patient, err := h.patientService.CreatePatient(actorFromContext(c), req)
+	if err != nil {
+		writeFHIRServiceError(c, err)
+		return
+	}
+	writeFHIRCreated(c, "Patient", patient.ID, fhir.FromPatient(patient))
+}
+
+func (h *FHIRHandler) ReadPractitioner(c *gin.Context) {
+	id, ok := fhirIDParam(c)
+	if !ok {
+		return
+	}
+	doctor, err := h.doctorService.GetDoctor(id)
+	if err != nil {
+		writeFHIR(c, http.StatusNotFound, fhir.ErrorOutcome(fhir.IssueNotFound, fmt.Sprintf("Practitioner/%d not found", id)))
+		return
+	}
+	writeFHIR(c, http.StatusOK, fhir.FromDoctor(doctor))
+}
+
+// SearchPractitioners supports _id, identifier (user ID or license number)
+// and name. The directory is small, so results are not paged.
+func (h *FHIRHandler) SearchPractitioners(c *gin.Context) {
+	filter := &models.DoctorFilter{Query: c.Query("name")}
+
+	if value := c.Query("identifier"); value != "" {
+		system, code := fhir.ParseToken(value)
+		if system == fhir.SystemLicense {
+			filter.LicenseNumber = code
+		} else if system != "" && system != fhir.SystemUserID {
+			writeFHIR(c, http.StatusOK, searchBundle(c, "Practitioner", nil, nil, ""))
+			return
+		}
+	}
+	if filter.LicenseNumber == "" {
+		if id, ok, done := h.idSearch(c, fhir.SystemUserID); done {
+			return
+		} else if ok {
+			var entries []interface{}
+			if doctor, err := h.doctorService.GetDoctor(id); err == nil {
+				entries = append(entries, fhir.FromDoctor(doctor))
+			}
+			writeFHIR(c, http.StatusOK, searchBundle(c, "Practitioner", entries, nil, ""))
+			return
+		}
+	}
+
+	doctors, err := h.doctorService.SearchDoctors(filter)
+	if err != nil {
+		writeFHIRSearchError(c, err)
+		return
+	}
+	entries := make([]interface{}, 0, len(doctors))
+	for _, doctor := range doctors {
+		entries = append(entries, fhir.FromDoctor(doctor))
+	}
+	total := int64(len(entries))
+	writeFHIR(c, http.StatusOK, searchBundle(c, "Practitioner", entries, &total, ""))
+}
+
+// CreatePractitioner adds the directory profile of an existing doctor
+// account.
+func (h *FHIRHandler) CreatePractitioner(c *gin.Context) {
+	var resource fhir.Practitioner
+	if !bindFHIR(c, &resource) {
+		return
+	}
+	req, issues := fhir.DoctorRequest(&resource)
+	if len(issues) > 0 {
+		writeFHIR(c, http.StatusBadRequest, fhir.NewOperationOutcome(issues...))
+		return
+	}
+
+	doctor, err := h.doctorService.CreateDoctor(req)
+	if err != nil {
+		writeFHIRServiceError(c, err)
+		return
+	}
+	writeFHIRCreated(c, "Practitioner", doctor.UserID, fhir.FromDoctor(doctor))
+}
+
+func (h *FHIRHandler) ReadAppointment(c *gin.Context) {
+	id, ok := fhirIDParam(c)
+	if !ok {
+		return
+	}
+	appointment, err := h.appointmentService.GetAppointmentByID(actorFromContext(c), id)
+	if err != nil {
+		writeFHIR(c, http.StatusNotFound, fhir.ErrorOutcome(fhir.IssueNotFound, fmt.Sprintf("Appointment/%d not found", id)))
+		return
+	}
+	writeFHIR(c, http.StatusOK, fhir.FromAppointment(appointment))
+}
+
+// SearchAppointments supports _id, identifier, date, patient, practitioner,
+// status (comma-separated) and _count. Dates without a time zone are read in
+// the clinic's time zone.
+func (h *FHIRHandler) SearchAppointments(c *gin.Context) {
+	if id, ok, done := h.idSearch(c, fhir.SystemAppointmentID); done {
+		return
+	} else if ok {
+		var entries []interface{}
+		if appointment, err := h.appointmentService.GetAppointmentByID(actorFromContext(c), id); err == nil {
+			entries = append(entries, fhir.FromAppointment(appointment))
+		}
+		writeFHIR(c, http.StatusOK, searchBundle(c, "Appointment", entries, nil, ""))
+		return
+	}
+
+	filter := &models.AppointmentFilter{}
+	if values := c.QueryArray("date"); len(values) > 0 {
+		dates, err := fhir.ParseDateParams(values, h.location)
+		if err != nil {
+			writeFHIR(c, http.StatusBadRequest, fhir.ErrorOutcome(fhir.IssueValue, "date: "+err.Error()))
+			return
+		}
+		filter.From, filter.To = dates.From, dates.To
+	}
+
+	var ok bool
+	if filter.PatientID, ok = referenceQuery(c, "patient", "Patient"); !ok {
+		return
+	}
+	if filter.DoctorID, ok = referenceQuery(c, "practitioner", "Practitioner"); !ok {
+		return
+	}
+
+	if value := c.Query("status"); value != "" {
+		for _, status := range strings.Split(value, ",") {
+			filter.Statuses = append(filter.Statuses, fhir.AppointmentStatusesFor(status)...)
+		}
+		if len(filter.Statuses) == 0 {
+			writeFHIR(c, http.StatusOK, searchBundle(c, "Appointment", nil, nil, ""))
+			return
+		}
+	}
+
+	result, err := h.appointmentService.ListAppointments(actorFromContext(c), filter, fhirListOptions(c))
+	if err != nil {
+		writeFHIRSearchError(c, err)
+		return
+	}
+
+	entries := make([]interface{}, 0, len(result.Data))
+	for _, appointment := range result.Data {
+		entries = append(entries, fhir.FromAppointment(appointment))
+	}
+	writeFHIR(c, http.StatusOK, searchBundle(c, "Appointment", entries, &result.Total, result.NextCursor))
+}
+
+// CreateAppointment books a proposed (requested) or booked (scheduled)
+// appointment, with the same availability and double-booking checks as the
+// REST API.
+func (h *FHIRHandler) CreateAppointment(c *gin.Context) {
+	var resource fhir.Appointment
+	if !bindFHIR(c, &resource) {
+		return
+	}
+	req, issues := fhir.AppointmentRequest(&resource)
+	if len(issues) > 0 {
+		writeFHIR(c, http.StatusBadRequest, fhir.NewOperationOutcome(issues...))
+		return
+	}
+
+	appointment, err := h.appointmentService.CreateAppointment(actorFromContext(c), req)
+	if err != nil {
+		writeFHIRServiceError(c, err)
+		return
+	}
+	writeFHIRCreated(c, "Appointment", appointment.ID, fhir.FromAppointment(appointment))
+}
+
+// idSearch handles the _id and identifier parameters, which name a single
+// resource by its ID. ok reports whether one was given; done reports that an
+// error response has already been written.
+func (h *FHIRHandler) idSearch(c *gin.Context, system string) (id uint, ok bool, done bool) {
+	value := c.Query("_id")
+	if value == "" {
+		if identifier := c.Query("identifier"); identifier != "" {
+			identifierSystem, code := fhir.ParseToken(identifier)
+			if identifierSystem != "" && identifierSystem != system {
+				writeFHIR(c, http.StatusBadRequest, fhir.ErrorOutcome(fhir.IssueNotSupported, "identifier system must be "+system))
+				return 0, false, true
+			}
+			value = code
+		}
+	}
+	if value == "" {
+		return 0, false, false
+	}
+
+	for key := range c.Request.URL.Query() {
+		if key != "_id" && key != "identifier" && !strings.HasPrefix(key, "_") {
+			writeFHIR(c, http.StatusBadRequest, fhir.ErrorOutcome(fhir.IssueNotSupported, "_id and identifier cannot be combined with "+key))
+			return 0, false, true
+		}
+	}
+
+	id, valid := fhir.ParseID(value)
+	if !valid {
+		// No resource can have a malformed ID.
+		return 0, true, false
+	}
+	return id, true, false
+}
+
+// referenceQuery parses a reference search parameter such as
+// patient=Patient/12 or patient=12.
+func referenceQuery(c *gin.Context, key, resourceType string) (uint, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, true
+	}
+	if id, valid := fhir.ParseID(value); valid {
+		return id, true
+	}
+	refType, id, valid := fhir.ParseReference(value)
+	if !valid || refType != resourceType {
+		writeFHIR(c, http.StatusBadRequest, fhir.ErrorOutcome(fhir.IssueValue, fmt.Sprintf("%s must reference a %s", key, resourceType)))
+		return 0, false
+	}
+	return id, true
+}
+
+func fhirIDParam(c *gin.Context) (uint, bool) {
+	id, ok := fhir.ParseID(c.Param("id"))
+	if !ok {
+		writeFHIR(c, http.StatusNotFound, fhir.ErrorOutcome(fhir.IssueNotFound, "no resource with id "+c.Param("id")))
+		return 0, false
+	}
+	return id, true
+}
+
+// fhirListOptions maps _count and the _cursor paging token to list options.
+func fhirListOptions(c *gin.Context) *models.ListOptions {
+	opts := &models.ListOptions{Cursor: c.Query("_cursor")}
+	opts.Limit, _ = strconv.Atoi(c.Query("_count"))
+	return opts
+}
+
+// bindFHIR decodes a FHIR JSON request body, responding with an
+// OperationOutcome when it is not valid JSON of the expected shape.
+func bindFHIR(c *gin.Context, resource interface{}) bool {
+	if err := json.NewDecoder(c.Request.Body).Decode(resource); err != nil {
+		writeFHIR(c, http.StatusBadRequest, fhir.ErrorOutcome(fhir.IssueStructure, "invalid resource: "+err.Error()))
+		return false
+	}
+	return true
+}
+
+func searchBundle(c *gin.Context, resourceType string, resources []interface{}, total *int64, nextCursor string) *fhir.Bundle {
+	base := fhirBaseURL(c)
+	bundle := &fhir.Bundle{
+		ResourceType: "Bundle",
+		Type:         "searchset",
+		Total:        total,
+		Link:         []fhir.BundleLink{{Relation: "self", URL: base + "/" + resourceType + "?" + c.Request.URL.RawQuery}},
+		Entry:        []fhir.BundleEntry{},
+	}
+	if total == nil {
+		count := int64(len(resources))
+		bundle.Total = &count
+	}
+	if nextCursor != "" {
+		query := c.Request.URL.Query()
+		query.Set("_cursor", nextCursor)
+		bundle.Link = append(bundle.Link, fhir.BundleLink{Relation: "next", URL: base + "/" + resourceType + "?" + query.Encode()})
+	}
+
+	for _, resource := range resources {
+		var id string
+		switch r := resource.(type) {
+		case *fhir.Patient:
+			id = r.ID
+		case *fhir.Practitioner:
+			id = r.ID
+		case *fhir.Appointment:
+			id = r.ID
+		}
+		bundle.Entry = append(bundle.Entry, fhir.BundleEntry{
+			FullURL:  base + "/" + resourceType + "/" + id,
+			Resource: resource,
+			Search:   &fhir.BundleSearch{Mode: "match"},
+		})
+	}
+	return bundle
+}
+
+// fhirBaseURL is the absolute URL of the facade, as the client reached it.
+func fhirBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if forwarded := c.GetHeader("X-Forwarded-Proto"); forwarded != "" {
+		scheme = forwarded
+	}
+	base := url.URL{Scheme: scheme, Host: c.Request.Host, Path: "/fhir/R4"}
+	return base.String()
+}
+
+func writeFHIRCreated(c *gin.Context, resourceType string, id uint, resource interface{}) {
+	location := fmt.Sprintf("%s/%s/%d", fhirBaseURL(c), resourceType, id)
+	c.Header("Location", location)
+	writeFHIR(c, http.StatusCreated, resource)
+}
+
+// writeFHIRServiceError maps errors from a create to OperationOutcomes: 409
+// for booking conflicts, 422 for every other rule the services enforce.
+func writeFHIRServiceError(c *gin.Context, err error) {
+	var conflictErr *service.ConflictError
+	if errors.As(err, &conflictErr) {
+		writeFHIR(c, http.StatusConflict, fhir.ErrorOutcome(fhir.IssueConflict, err.Error()))
+		return
+	}
+	writeFHIR(c, http.StatusUnprocessableEntity, fhir.ErrorOutcome(fhir.IssueBusiness, err.Error()))
+}
+
+// writeFHIRSearchError responds with 400 for a bad _count or _cursor and 500
+// otherwise.
+func writeFHIRSearchError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrInvalidListQuery) {
+		writeFHIR(c, http.StatusBadRequest, fhir.ErrorOutcome(fhir.IssueValue, err.Error()))
+		return
+	}
+	writeFHIR(c, http.StatusInternalServerError, fhir.ErrorOutcome(fhir.IssueException, err.Error()))
+}
+
+func writeFHIR(c *gin.Context, status int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Data(status, fhir.ContentType+"; charset=utf-8", data)
+}
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ObservationHandler serves a patient's vital signs and other observations
+// under /patients/:id/observations.
+type ObservationHandler struct {
+	observationService service.ObservationService
+}
+
+func NewObservationHandler(observationService service.ObservationService) *ObservationHandler {
+	return &ObservationHandler{
+		observationService: observationService,
+	}
+}
+
+// RecordObservations stores a batch of values taken at the same time.
+func (h *ObservationHandler) RecordObservations(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.ObservationBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	observations, err := h.observationService.RecordObservations(actorFromContext(c), patientID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, observations)
+}
+
+// GetObservations lists a patient's observations, oldest first. Filters:
+// code (comma-separated), from and to (RFC3339 or YYYY-MM-DD, to-dates
+// include the whole day).
+func (h *ObservationHandler) GetObservations(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	filter, ok := observationFilterFromQuery(c)
+	if !ok {
+		return
+	}
+
+	observations, err := h.observationService.GetObservations(actorFromContext(c), patientID, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, observations)
+}
+
+// GetLatest returns the most recent value of each observation and the BMI.
+func (h *ObservationHandler) GetLatest(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+
+	observations, err := h.observationService.GetLatest(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, observations)
+}
+
+// GetTrends returns a trend per observation code over a date range, taking
+// the same filters as GetObservations. code may include bmi; without code
+// every observation is returned.
+func (h *ObservationHandler) GetTrends(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	filter, ok := observationFilterFromQuery(c)
+	if !ok {
+		return
+	}
+
+	trends, err := h.observationService.GetTrends(actorFromContext(c), patientID, filter)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, trends)
+}
+
+// MarkEnteredInError retracts an observation. It stays on record but is left
+// out of lists and trends.
+func (h *ObservationHandler) MarkEnteredInError(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ObservationErrorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	observation, err := h.observationService.MarkEnteredInError(actorFromContext(c), patientID, id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, observation)
+}
+
+// GetObservationTypes lists the observation codes with their units and
+// reference ranges.
+func (h *ObservationHandler) GetObservationTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, models.ObservationTypes)
+}
+
+func observationFilterFromQuery(c *gin.Context) (*models.ObservationFilter, bool) {
+	filter := &models.ObservationFilter{}
+	if codes := c.Query("code"); codes != "" {
+		filter.Codes = strings.Split(codes, ",")
+	}
+
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date"})
+		return nil, false
+	}
+	if !from.IsZero() {
+		filter.From = &from
+	}
+
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date"})
+		return nil, false
+	}
+	if !to.IsZero() {
+		if len(c.Query("to")) == len("2006-01-02") {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	return filter, true
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
 	"hospital-management/internal/service"
 
 	"github.com/gin-gonic/gin"
@@ -33,7 +35,7 @@ func (h *PatientHandler) CreatePatient(c *gin.Context) {
 		return
 	}
 
-	createdPatient, err := h.patientService.CreatePatient(&patientReq)
+	createdPatient, err := h.patientService.CreatePatient(actorFromContext(c), &patientReq)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -42,7 +44,10 @@ func (h *PatientHandler) CreatePatient(c *gin.Context) {
 	c.JSON(http.StatusCreated, createdPatient)
 }
 
-// SearchPatients handles patient search
+// SearchPatients handles patient search. q may mix names (typos and
+// partial names are tolerated), email addresses, phone numbers in any format
+// or just their last four digits, and dates of birth; every term must match. Results are ranked by
+// relevance and paged with limit and page.
 func (h *PatientHandler) SearchPatients(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
@@ -50,52 +55,29 @@ func (h *PatientHandler) SearchPatients(c *gin.Context) {
 		return
 	}
 
-	patients, err := h.patientService.SearchPatients(query)
+	result, err := h.patientService.SearchPatients(actorFromContext(c), query, listOptionsFromQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if errors.Is(err, service.ErrEmptySearch) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		writeListError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, patients)
+	c.JSON(http.StatusOK, result)
 }
 
-// GetPatients handles getting all patients with pagination
+// GetPatients handles listing patients. It accepts the name, phone and
+// gender filters plus the shared sort, limit, cursor and page parameters.
 func (h *PatientHandler) GetPatients(c *gin.Context) {
-	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
-	if err != nil || page < 1 {
-		page = 1
-	}
-
-	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	if err != nil || limit < 1 {
-		limit = 10
-	}
-
-	allPatients, err := h.patientService.GetAllPatients()
+	result, err := h.patientService.ListPatients(actorFromContext(c), patientFilterFromQuery(c), listOptionsFromQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeListError(c, err)
 		return
 	}
 
-	total := len(allPatients)
-	start := (page - 1) * limit
-	if start > total {
-		start = total
-	}
-	end := start + limit
-	if end > total {
-		end = total
-	}
-	patients := allPatients[start:end]
-
-	response := gin.H{
-		"patients": patients,
-		"total":    total,
-		"page":     page,
-		"limit":    limit,
-	}
-
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusOK, result)
 }
 
 // GetPatientByID handles getting a specific patient
@@ -107,7 +89,7 @@ func (h *PatientHandler) GetPatientByID(c *gin.Context) {
 		return
 	}
 
-	patient, err := h.patientService.GetPatientByID(uint(id))
+	patient, err := h.patientService.GetPatientByID(actorFromContext(c), uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -138,21 +120,18 @@ func (h *PatientHandler) UpdatePatient(c *gin.Context) {
 	}
 
 	patientReq := models.PatientRequest{
-		FirstName:      patient.FirstName,
-		LastName:       patient.LastName,
-		Email:          models.StringValue(patient.Email),
-		Phone:          patient.Phone,
-		DateOfBirth:    dob,
-		Gender:         patient.Gender,
-		Address:        models.StringValue(patient.Address),
-		MedicalHistory: models.StringValue(patient.MedicalHistory),
-		Allergies:      models.StringValue(patient.Allergies),
-		Medications:    models.StringValue(patient.Medications),
-	}
-
-	updatedPatient, err := h.patientService.UpdatePatient(uint(id), &patientReq)
+		FirstName:   patient.FirstName,
+		LastName:    patient.LastName,
+		Email:       models.StringValue(patient.Email),
+		Phone:       patient.Phone,
+		DateOfBirth: dob,
+		Gender:      patient.Gender,
+		Address:     models.StringValue(patient.Address),
+	}
+
+	updatedPatient, err := h.patientService.UpdatePatient(actorFromContext(c), uint(id), &patientReq)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writePatientError(c, err)
 		return
 	}
 
@@ -168,10 +147,30 @@ func (h *PatientHandler) DeletePatient(c *gin.Context) {
 		return
 	}
 
-	if err := h.patientService.DeletePatient(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.patientService.DeletePatient(actorFromContext(c), uint(id)); err != nil {
+		writePatientError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Patient deleted successfully"})
 }
+
+// writePatientError responds with 409 when the patient has been merged into
+// another chart and 500 otherwise.
+func writePatientError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrPatientMerged) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// patientFilterFromQuery reads the patient list filters: name, phone and
+// gender.
+func patientFilterFromQuery(c *gin.Context) *models.PatientFilter {
+	return &models.PatientFilter{
+		Name:   c.Query("name"),
+		Phone:  c.Query("phone"),
+		Gender: c.Query("gender"),
+	}
+}
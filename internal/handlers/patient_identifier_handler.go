@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PatientIdentifierHandler serves the identifiers other systems have issued
+// for a patient and lookups by identifier or MRN.
+type PatientIdentifierHandler struct {
+	identifierService service.PatientIdentifierService
+}
+
+func NewPatientIdentifierHandler(identifierService service.PatientIdentifierService) *PatientIdentifierHandler {
+	return &PatientIdentifierHandler{
+		identifierService: identifierService,
+	}
+}
+
+// GetPatientByIdentifier finds a patient by ?system=&value=. Use
+// system=mrn to look up a medical record number.
+func (h *PatientIdentifierHandler) GetPatientByIdentifier(c *gin.Context) {
+	patient, err := h.identifierService.FindPatient(actorFromContext(c), c.Query("system"), c.Query("value"))
+	if err != nil {
+		if errors.Is(err, service.ErrIdentifierRequired) || errors.Is(err, service.ErrInvalidMRN) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, patient)
+}
+
+func (h *PatientIdentifierHandler) GetIdentifiers(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	identifiers, err := h.identifierService.GetIdentifiers(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, identifiers)
+}
+
+func (h *PatientIdentifierHandler) AddIdentifier(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.PatientIdentifierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	identifier, err := h.identifierService.AddIdentifier(actorFromContext(c), patientID, &req)
+	if err != nil {
+		if errors.Is(err, repository.ErrIdentifierTaken) || errors.Is(err, repository.ErrPatientMerged) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, identifier)
+}
+
+func (h *PatientIdentifierHandler) DeleteIdentifier(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+	if err := h.identifierService.DeleteIdentifier(actorFromContext(c), patientID, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PatientMergeHandler serves the duplicate review queue and patient merges.
+type PatientMergeHandler struct {
+	mergeService service.PatientMergeService
+}
+
+func NewPatientMergeHandler(mergeService service.PatientMergeService) *PatientMergeHandler {
+	return &PatientMergeHandler{
+		mergeService: mergeService,
+	}
+}
+
+// GetDuplicateCandidates lists the pairs queued for review, highest score
+// first. ?status= is open (the default), dismissed or merged.
+func (h *PatientMergeHandler) GetDuplicateCandidates(c *gin.Context) {
+	status := c.DefaultQuery("status", models.DuplicateOpen)
+	switch status {
+	case models.DuplicateOpen, models.DuplicateDismissed, models.DuplicateMerged:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be open, dismissed or merged"})
+		return
+	}
+
+	result, err := h.mergeService.ListDuplicateCandidates(actorFromContext(c), status, listOptionsFromQuery(c))
+	if err != nil {
+		writeListError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// DismissCandidate marks a queued pair as different people.
+func (h *PatientMergeHandler) DismissCandidate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("candidateId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duplicate candidate ID"})
+		return
+	}
+
+	candidate, err := h.mergeService.DismissCandidate(actorFromContext(c), uint(id))
+	if err != nil {
+		writeMergeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, candidate)
+}
+
+// GetDuplicates scores a patient against the rest now and returns the
+// likely duplicates.
+func (h *PatientMergeHandler) GetDuplicates(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+
+	matches, err := h.mergeService.FindDuplicates(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, matches)
+}
+
+// MergePatient merges the patient named in the body into the one in the
+// URL, which survives.
+func (h *PatientMergeHandler) MergePatient(c *gin.Context) {
+	survivorID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.MergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if req.MergedID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "merged_id is required"})
+		return
+	}
+
+	merge, err := h.mergeService.MergePatients(actorFromContext(c), survivorID, req.MergedID)
+	if err != nil {
+		writeMergeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, merge)
+}
+
+// GetPatientMerges lists the merges a patient took part in, newest first.
+func (h *PatientMergeHandler) GetPatientMerges(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+
+	merges, err := h.mergeService.GetPatientMerges(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, merges)
+}
+
+// UnmergePatient undoes a merge.
+func (h *PatientMergeHandler) UnmergePatient(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid merge ID"})
+		return
+	}
+
+	merge, err := h.mergeService.UnmergePatients(actorFromContext(c), uint(id))
+	if err != nil {
+		writeMergeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, merge)
+}
+
+// writeMergeError responds with 409 when a patient, merge or candidate is
+// no longer in a state that allows the change and 400 otherwise.
+func writeMergeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrPatientMerged), errors.Is(err, repository.ErrMergeUndone), errors.Is(err, service.ErrCandidateReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
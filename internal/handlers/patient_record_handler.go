@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PatientRecordHandler serves a patient's allergies, medications, problem
+// list and legacy notes under /patients/:id.
+type PatientRecordHandler struct {
+	recordService service.PatientRecordService
+}
+
+func NewPatientRecordHandler(recordService service.PatientRecordService) *PatientRecordHandler {
+	return &PatientRecordHandler{
+		recordService: recordService,
+	}
+}
+
+func (h *PatientRecordHandler) GetAllergies(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	allergies, err := h.recordService.GetAllergies(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, allergies)
+}
+
+func (h *PatientRecordHandler) CreateAllergy(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.AllergyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	allergy, err := h.recordService.CreateAllergy(actorFromContext(c), patientID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, allergy)
+}
+
+func (h *PatientRecordHandler) UpdateAllergy(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+	var req models.AllergyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	allergy, err := h.recordService.UpdateAllergy(actorFromContext(c), patientID, id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, allergy)
+}
+
+func (h *PatientRecordHandler) DeleteAllergy(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+	if err := h.recordService.DeleteAllergy(actorFromContext(c), patientID, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *PatientRecordHandler) GetMedications(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	medications, err := h.recordService.GetMedications(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, medications)
+}
+
+func (h *PatientRecordHandler) CreateMedication(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.MedicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	medication, err := h.recordService.CreateMedication(actorFromContext(c), patientID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, medication)
+}
+
+func (h *PatientRecordHandler) UpdateMedication(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+	var req models.MedicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	medication, err := h.recordService.UpdateMedication(actorFromContext(c), patientID, id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, medication)
+}
+
+func (h *PatientRecordHandler) DeleteMedication(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+	if err := h.recordService.DeleteMedication(actorFromContext(c), patientID, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *PatientRecordHandler) GetProblems(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	problems, err := h.recordService.GetProblems(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, problems)
+}
+
+func (h *PatientRecordHandler) CreateProblem(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	var req models.ProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	problem, err := h.recordService.CreateProblem(actorFromContext(c), patientID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, problem)
+}
+
+func (h *PatientRecordHandler) UpdateProblem(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+	var req models.ProblemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	problem, err := h.recordService.UpdateProblem(actorFromContext(c), patientID, id, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, problem)
+}
+
+func (h *PatientRecordHandler) DeleteProblem(c *gin.Context) {
+	patientID, id, ok := patientRecordParams(c)
+	if !ok {
+		return
+	}
+	if err := h.recordService.DeleteProblem(actorFromContext(c), patientID, id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetLegacyNotes returns the free text carried over from the retired
+// medical_history, allergies and medications fields.
+func (h *PatientRecordHandler) GetLegacyNotes(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+	notes, err := h.recordService.GetLegacyNotes(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, notes)
+}
+
+// patientIDParam parses the :id path parameter, responding with 400 if it is
+// invalid.
+func patientIDParam(c *gin.Context) (uint, bool) {
+	patientID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid patient ID"})
+		return 0, false
+	}
+	return uint(patientID), true
+}
+
+// patientRecordParams parses the :id and :recordId path parameters,
+// responding with 400 if either is invalid.
+func patientRecordParams(c *gin.Context) (uint, uint, bool) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return 0, 0, false
+	}
+	id, err := strconv.ParseUint(c.Param("recordId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid record ID"})
+		return 0, 0, false
+	}
+	return patientID, uint(id), true
+}
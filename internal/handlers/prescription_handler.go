@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDrugSearchLimit = 20
+	maxDrugSearchLimit     = 100
+)
+
+type PrescriptionHandler struct {
+	prescriptionService service.PrescriptionService
+}
+
+func NewPrescriptionHandler(prescriptionService service.PrescriptionService) *PrescriptionHandler {
+	return &PrescriptionHandler{
+		prescriptionService: prescriptionService,
+	}
+}
+
+// CreatePrescription prescribes a drug during an appointment. A drug that
+// matches an active allergy is rejected with 422 and the alerts unless the
+// request carries an override_reason.
+func (h *PrescriptionHandler) CreatePrescription(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	var req models.PrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	prescription, err := h.prescriptionService.CreatePrescription(actorFromContext(c), uint(appointmentID), &req)
+	if err != nil {
+		writePrescriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, prescription)
+}
+
+// CheckPrescription reports the alerts a drug would raise for a patient
+// without prescribing it.
+func (h *PrescriptionHandler) CheckPrescription(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req models.PrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	result, err := h.prescriptionService.CheckPrescription(actorFromContext(c), patientID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *PrescriptionHandler) GetPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid prescription ID"})
+		return
+	}
+
+	prescription, err := h.prescriptionService.GetPrescription(actorFromContext(c), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescription)
+}
+
+func (h *PrescriptionHandler) GetPatientPrescriptions(c *gin.Context) {
+	patientID, ok := patientIDParam(c)
+	if !ok {
+		return
+	}
+
+	prescriptions, err := h.prescriptionService.GetPatientPrescriptions(actorFromContext(c), patientID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescriptions)
+}
+
+func (h *PrescriptionHandler) GetAppointmentPrescriptions(c *gin.Context) {
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	prescriptions, err := h.prescriptionService.GetAppointmentPrescriptions(actorFromContext(c), uint(appointmentID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescriptions)
+}
+
+func (h *PrescriptionHandler) CancelPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid prescription ID"})
+		return
+	}
+
+	var req models.PrescriptionCancelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	prescription, err := h.prescriptionService.CancelPrescription(actorFromContext(c), uint(id), &req)
+	if err != nil {
+		writePrescriptionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prescription)
+}
+
+// PrintPrescription returns the prescription as a PDF.
+func (h *PrescriptionHandler) PrintPrescription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid prescription ID"})
+		return
+	}
+
+	pdf, err := h.prescriptionService.PrintPrescription(actorFromContext(c), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=prescription-%d.pdf", id))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// SearchDrugs searches the drug catalogue by name (?q=, optional ?limit=).
+func (h *PrescriptionHandler) SearchDrugs(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+
+	limit := defaultDrugSearchLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxDrugSearchLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", maxDrugSearchLimit)})
+			return
+		}
+		limit = parsed
+	}
+
+	c.JSON(http.StatusOK, h.prescriptionService.SearchDrugs(query, limit))
+}
+
+// writePrescriptionError responds with 422 and the alerts when a prescription
+// is blocked, 409 when the prescription or appointment state does not allow
+// the change, and 400 otherwise.
+func writePrescriptionError(c *gin.Context, err error) {
+	var blockedErr *service.PrescriptionBlockedError
+	switch {
+	case errors.As(err, &blockedErr):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "alerts": blockedErr.Alerts})
+	case errors.Is(err, service.ErrNotPrescribable), errors.Is(err, service.ErrPrescriptionCancelled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hospital-management/internal/auth"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// queueRefreshInterval is how often a queue stream is resent without any
+// change, so estimated waits keep counting down.
+const queueRefreshInterval = 30 * time.Second
+
+type QueueHandler struct {
+	queueService service.QueueService
+}
+
+func NewQueueHandler(queueService service.QueueService) *QueueHandler {
+	return &QueueHandler{
+		queueService: queueService,
+	}
+}
+
+// CheckIn marks the patient as arrived for today's appointment.
+func (h *QueueHandler) CheckIn(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appointment ID"})
+		return
+	}
+
+	appointment, err := h.queueService.CheckIn(actorFromContext(c), uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrCheckInNotToday) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+		writeAppointmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+// GetQueue returns the doctor's waiting room for today.
+func (h *QueueHandler) GetQueue(c *gin.Context) {
+	doctorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	queue, err := h.queueService.GetQueue(uint(doctorID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, queue)
+}
+
+// StreamQueue sends the doctor's queue as a Server-Sent Events stream: a
+// "queue" event on connect, after every change, and periodically so
+// estimated waits stay current. A failed lookup sends a "queue_error" event
+// instead. Before every event the token the stream was opened with is
+// checked again, and once it has expired or its session was revoked a
+// "session_ended" event is sent and the stream is closed.
+func (h *QueueHandler) StreamQueue(c *gin.Context) {
+	doctorID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor ID"})
+		return
+	}
+
+	updates, stop := h.queueService.Watch(uint(doctorID))
+	defer stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	// send reports whether the stream should stay open.
+	send := func() bool {
+		if !auth.StillAuthenticated(c) {
+			c.SSEvent("session_ended", gin.H{"error": "Session ended"})
+			c.Writer.Flush()
+			return false
+		}
+		queue, err := h.queueService.GetQueue(uint(doctorID))
+		if err != nil {
+			c.SSEvent("queue_error", gin.H{"error": err.Error()})
+		} else {
+			c.SSEvent("queue", queue)
+		}
+		c.Writer.Flush()
+		return true
+	}
+
+	ticker := time.NewTicker(queueRefreshInterval)
+	defer ticker.Stop()
+
+	if !send() {
+		return
+	}
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-updates:
+			if !send() {
+				return
+			}
+		case <-ticker.C:
+			if !send() {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hospital-management/internal/auth"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeSessionChecker reports the session active for the first active calls.
+type fakeSessionChecker struct {
+	active int
+}
+
+func (f *fakeSessionChecker) IsSessionActive(sessionID string) (bool, error) {
+	f.active--
+	return f.active >= 0, nil
+}
+
+// streamQueue serves a queue stream authenticated with a token expiring at
+// expiresAt, runs during while it is open, then cancels the request and
+// returns the stream's body.
+func streamQueue(t *testing.T, queue service.QueueService, expiresAt time.Time, during func()) string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/doctors/:id/queue/stream", func(c *gin.Context) {
+		c.Set("session_id", "session-1")
+		c.Set("token_expires_at", expiresAt)
+	}, NewQueueHandler(queue).StreamQueue)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/doctors/4/queue/stream", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(w, req)
+		close(done)
+	}()
+	during()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("stream did not close")
+	}
+	return w.Body.String()
+}
+
+func TestStreamQueueSendsQueue(t *testing.T) {
+	queue := &fakeQueueService{updates: make(chan struct{})}
+	body := streamQueue(t, queue, time.Now().Add(time.Minute), func() {
+		queue.updates <- struct{}{}
+	})
+
+	if n := strings.Count(body, "event:queue\n"); n < 1 {
+		t.Fatalf("body = %q, want queue events", body)
+	}
+}
+
+func TestStreamQueueReportsLookupFailures(t *testing.T) {
+	queue := &fakeQueueService{updates: make(chan struct{}), err: errors.New("database is down")}
+	body := streamQueue(t, queue, time.Now().Add(time.Minute), func() {
+		queue.updates <- struct{}{}
+	})
+
+	if !strings.Contains(body, "event:queue_error\n") || strings.Contains(body, "event:error\n") {
+		t.Fatalf("body = %q, want queue_error events", body)
+	}
+}
+
+func TestStreamQueueClosesWhenTokenExpires(t *testing.T) {
+	queue := &fakeQueueService{updates: make(chan struct{})}
+	body := streamQueue(t, queue, time.Now().Add(-time.Second), func() {
+		// The stream closes on its own, without waiting for an update.
+		select {
+		case queue.updates <- struct{}{}:
+			t.Error("stream still open after its token expired")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	if strings.Contains(body, "event:queue\n") || !strings.Contains(body, "event:session_ended\n") {
+		t.Fatalf("body = %q, want only session_ended", body)
+	}
+}
+
+func TestStreamQueueClosesWhenSessionRevoked(t *testing.T) {
+	auth.InitializeSessionChecker(&fakeSessionChecker{active: 1})
+	defer auth.InitializeSessionChecker(nil)
+
+	queue := &fakeQueueService{updates: make(chan struct{})}
+	body := streamQueue(t, queue, time.Now().Add(time.Minute), func() {
+		queue.updates <- struct{}{}
+		select {
+		case queue.updates <- struct{}{}:
+			t.Error("stream still open after its session was revoked")
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	if strings.Count(body, "event:queue\n") != 1 || !strings.HasSuffix(strings.TrimSpace(body), `data:{"error":"Session ended"}`) {
+		t.Fatalf("body = %q, want one queue event then session_ended", body)
+	}
+}
@@ -1,15 +1,23 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
 	"github.com/gin-gonic/gin"
 )
 
-type WebHandler struct{}
+type WebHandler struct {
+	queueService service.QueueService
+}
 
-func NewWebHandler() *WebHandler {
-	return &WebHandler{}
+func NewWebHandler(queueService service.QueueService) *WebHandler {
+	return &WebHandler{
+		queueService: queueService,
+	}
 }
 
 // LoginPage renders the login page
@@ -28,12 +36,27 @@ func (h *WebHandler) ReceptionistDashboard(c *gin.Context) {
 	})
 }
 
-// DoctorDashboard renders the doctor dashboard
+// DoctorDashboard renders the doctor dashboard with the doctor's current
+// queue. The page keeps the queue live by subscribing to queue_stream_url.
 func (h *WebHandler) DoctorDashboard(c *gin.Context) {
 	username := getUsername(c)
+	doctorID := c.GetUint("user_id")
+
+	queue, err := h.queueService.GetQueue(doctorID)
+	if err != nil {
+		c.HTML(http.StatusInternalServerError, "doctor/dashboard.html", gin.H{
+			"title":    "Doctor Dashboard",
+			"username": username,
+			"error":    err.Error(),
+		})
+		return
+	}
+
 	c.HTML(http.StatusOK, "doctor/dashboard.html", gin.H{
-		"title":    "Doctor Dashboard",
-		"username": username,
+		"title":            "Doctor Dashboard",
+		"username":         username,
+		"queue":            queue,
+		"queue_stream_url": fmt.Sprintf("/api/v1/doctors/%d/queue/stream", doctorID),
 	})
 }
 
@@ -46,12 +69,16 @@ func (h *WebHandler) ReceptionistPatients(c *gin.Context) {
 	})
 }
 
-// DoctorPatients renders the patient records view for doctors
+// DoctorPatients renders the patient records view for doctors. The page
+// charts vital signs from the trend endpoint, substituting the patient's ID
+// into observation_trend_url.
 func (h *WebHandler) DoctorPatients(c *gin.Context) {
 	username := getUsername(c)
 	c.HTML(http.StatusOK, "doctor/patients.html", gin.H{
-		"title":    "Patient Records",
-		"username": username,
+		"title":                 "Patient Records",
+		"username":              username,
+		"observation_types":     models.ObservationTypes,
+		"observation_trend_url": "/api/v1/patients/{id}/observations/trend",
 	})
 }
 
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeQueueService struct {
+	service.QueueService
+	queue   *models.DoctorQueue
+	err     error
+	updates chan struct{}
+}
+
+func (f *fakeQueueService) GetQueue(doctorID uint) (*models.DoctorQueue, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.queue == nil {
+		f.queue = &models.DoctorQueue{}
+	}
+	f.queue.DoctorID = doctorID
+	return f.queue, nil
+}
+
+func (f *fakeQueueService) Watch(doctorID uint) (<-chan struct{}, func()) {
+	return f.updates, func() {}
+}
+
+// newWebRouter serves the web pages from the repository's templates, with
+// the doctor pages behind a stub that sets the claims RequireAuth would.
+func newWebRouter(t *testing.T, queues service.QueueService) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.LoadHTMLGlob("../../web/templates/*/*.html")
+
+	h := NewWebHandler(queues)
+	router.GET("/login", h.LoginPage)
+	doctor := router.Group("/doctor", func(c *gin.Context) {
+		c.Set("user_id", uint(7))
+		c.Set("username", "dr.house")
+	})
+	doctor.GET("/dashboard", h.DoctorDashboard)
+	doctor.GET("/patients", h.DoctorPatients)
+	return router
+}
+
+func TestLoginPageRenders(t *testing.T) {
+	router := newWebRouter(t, &fakeQueueService{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if !strings.Contains(w.Body.String(), `id="login-form"`) {
+		t.Fatalf("login form missing from page:\n%s", w.Body.String())
+	}
+}
+
+func TestDoctorDashboardRendersQueue(t *testing.T) {
+	queues := &fakeQueueService{queue: &models.DoctorQueue{
+		GeneratedAt: time.Now(),
+		Entries: []models.QueueEntry{{
+			Position:    1,
+			PatientName: "Jane </script> Doe",
+			Status:      models.AppointmentCheckedIn,
+		}},
+	}}
+	router := newWebRouter(t, queues)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/doctor/dashboard", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, `/api/v1/doctors/7/queue/stream`) {
+		t.Error("dashboard does not subscribe to the doctor's queue stream")
+	}
+	if !strings.Contains(body, "dr.house") {
+		t.Error("dashboard does not show the signed-in doctor")
+	}
+	if strings.Contains(body, "Jane </script> Doe") {
+		t.Error("patient name is not escaped inside the page script")
+	}
+}
+
+func TestDoctorPatientsRendersObservationTypes(t *testing.T) {
+	router := newWebRouter(t, &fakeQueueService{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/doctor/patients", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	body := w.Body.String()
+	for _, observationType := range models.ObservationTypes {
+		if !strings.Contains(body, `value="`+observationType.Code+`"`) {
+			t.Errorf("no checkbox for observation type %q", observationType.Code)
+		}
+	}
+	if !strings.Contains(body, `"/api/v1/patients/{id}/observations/trend"`) {
+		t.Error("page does not know the trend endpoint")
+	}
+}
@@ -0,0 +1,169 @@
+package hl7
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Acknowledgement codes (HL7 table 0008).
+const (
+	AckAccept = "AA"
+	AckError  = "AE"
+	AckReject = "AR"
+)
+
+// Error condition codes (HL7 table 0357).
+const (
+	CodeSegmentSequence        = "100"
+	CodeRequiredFieldMissing   = "101"
+	CodeDataType               = "102"
+	CodeTableValue             = "103"
+	CodeUnsupportedMessageType = "200"
+	CodeUnsupportedEvent       = "201"
+	CodeUnknownKey             = "204"
+	CodeDuplicateKey           = "205"
+	CodeApplicationError       = "207"
+)
+
+var codeText = map[string]string{
+	CodeSegmentSequence:        "Segment sequence error",
+	CodeRequiredFieldMissing:   "Required field missing",
+	CodeDataType:               "Data type error",
+	CodeTableValue:             "Table value not found",
+	CodeUnsupportedMessageType: "Unsupported message type",
+	CodeUnsupportedEvent:       "Unsupported event code",
+	CodeUnknownKey:             "Unknown key identifier",
+	CodeDuplicateKey:           "Duplicate key identifier",
+	CodeApplicationError:       "Application internal error",
+}
+
+// Error is a problem with an inbound message, reported in an ERR segment.
+type Error struct {
+	Code string
+	// Location points at the offending field as segment^sequence^field,
+	// e.g. "PID^1^7". It may be empty.
+	Location string
+	Message  string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Header names the applications exchanging messages (MSH-3 to MSH-6).
+type Header struct {
+	SendingApplication   string
+	SendingFacility      string
+	ReceivingApplication string
+	ReceivingFacility    string
+}
+
+var controlSeq atomic.Uint64
+
+func init() {
+	controlSeq.Store(uint64(time.Now().UnixMilli()) * 1000)
+}
+
+// NewControlID returns a message control ID unique to this process.
+func NewControlID() string {
+	return strconv.FormatUint(controlSeq.Add(1), 10)
+}
+
+// NewMSH builds a message header for an outgoing message of the given type,
+// e.g. ("SIU", "S12", "SIU_S12").
+func NewMSH(h Header, code, event, structure string, now time.Time) *Segment {
+	return NewSegment("MSH",
+		DefaultDelimiters.encodingCharacters(),
+		Escape(h.SendingApplication),
+		Escape(h.SendingFacility),
+		Escape(h.ReceivingApplication),
+		Escape(h.ReceivingFacility),
+		FormatTimestamp(now),
+		"",
+		Components(code, event, structure),
+		NewControlID(),
+		"P",
+		Version,
+	)
+}
+
+// Acknowledge builds the original-mode ACK for msg. The header is the
+// message's with sender and receiver swapped; errors become ERR segments
+// and the first one is also given as MSA-3.
+func Acknowledge(msg *Message, code string, errs ...*Error) *Message {
+	in := msg.MSH()
+	_, event := msg.Type()
+	header := Header{
+		SendingApplication:   in.Value(5),
+		SendingFacility:      in.Value(6),
+		ReceivingApplication: in.Value(3),
+		ReceivingFacility:    in.Value(4),
+	}
+	msh := NewMSH(header, "ACK", event, "ACK", time.Now())
+	if processingID := in.Field(11); processingID != "" {
+		msh.SetField(11, processingID)
+	}
+	if version := in.Field(12); version != "" {
+		msh.SetField(12, version)
+	}
+	return buildAck(msh, code, msg.ControlID(), errs)
+}
+
+// RejectUnparsed builds an AR acknowledgement for a frame that could not be
+// parsed, echoing its control ID if one can be found.
+func RejectUnparsed(payload []byte, err error) *Message {
+	controlID := ""
+	text := string(payload)
+	if strings.HasPrefix(text, "MSH") && len(text) > 3 {
+		line := strings.FieldsFunc(text, func(r rune) bool { return r == '\r' || r == '\n' })[0]
+		fields := strings.Split(line, text[3:4])
+		if len(fields) > 9 {
+			controlID = fields[9]
+		}
+	}
+	msh := NewMSH(Header{}, "ACK", "", "ACK", time.Now())
+	return buildAck(msh, AckReject, controlID, []*Error{{Code: CodeSegmentSequence, Message: err.Error()}})
+}
+
+func buildAck(msh *Segment, code, controlID string, errs []*Error) *Message {
+	ack := NewMessage(msh)
+	msa := NewSegment("MSA", code, Escape(controlID))
+	if len(errs) > 0 {
+		msa.SetField(3, Escape(errs[0].Message))
+	}
+	ack.Add(msa)
+	for _, e := range errs {
+		ack.Add(NewSegment("ERR",
+			"",
+			e.Location,
+			Components(e.Code, codeText[e.Code], "HL70357"),
+			"E",
+			"", "", "",
+			Escape(e.Message),
+		))
+	}
+	return ack
+}
+
+// AckCode returns the acknowledgement code of an ACK and the reason given
+// for a negative one.
+func AckCode(ack *Message) (code, text string) {
+	msa := ack.Segment("MSA")
+	if msa == nil {
+		return "", ""
+	}
+	code, text = msa.Value(1), msa.Value(3)
+	if text == "" {
+		if err := ack.Segment("ERR"); err != nil {
+			text = err.Value(8)
+		}
+	}
+	// Enhanced-mode commit codes are treated like their original-mode
+	// counterparts.
+	if len(code) == 2 && code[0] == 'C' {
+		code = "A" + code[1:]
+	}
+	return code, text
+}
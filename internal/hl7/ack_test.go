@@ -0,0 +1,56 @@
+package hl7
+
+import (
+	"testing"
+	"time"
+)
+
+var testTime = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestAcknowledge(t *testing.T) {
+	msg, err := Parse([]byte(admitMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ack := Acknowledge(msg, AckError, &Error{Code: CodeRequiredFieldMissing, Location: "PID^1^5", Message: "firstname is required"})
+
+	msh := ack.MSH()
+	if msh.Value(3) != "HOSPITAL-MANAGEMENT" || msh.Value(4) != "HMS" || msh.Value(5) != "REG" || msh.Value(6) != "WARD" {
+		t.Errorf("ACK header = %s, want sender and receiver swapped", msh.Fields)
+	}
+	if code, event := ack.Type(); code != "ACK" || event != "A04" {
+		t.Errorf("ACK type = %s^%s, want ACK^A04", code, event)
+	}
+	msa := ack.Segment("MSA")
+	if msa.Value(1) != AckError || msa.Value(2) != "MSG0001" {
+		t.Errorf("MSA = %v, want AE for MSG0001", msa.Fields)
+	}
+	errSeg := ack.Segment("ERR")
+	if errSeg == nil || errSeg.Value(2) != "PID" || errSeg.Component(3, 1) != CodeRequiredFieldMissing {
+		t.Fatalf("ERR = %v, want code 101 at PID^1^5", errSeg)
+	}
+	if code, text := AckCode(ack); code != AckError || text != "firstname is required" {
+		t.Errorf("AckCode = %s, %q", code, text)
+	}
+}
+
+func TestRejectUnparsedEchoesControlID(t *testing.T) {
+	payload := []byte("MSH|^~\\&|A|B|C|D|20260301|||CTRL7|P|2.5.1\r")
+	_, parseErr := Parse(payload)
+
+	ack := RejectUnparsed(payload, parseErr)
+
+	msa := ack.Segment("MSA")
+	if msa.Value(1) != AckReject || msa.Value(2) != "CTRL7" {
+		t.Errorf("MSA = %v, want AR for CTRL7", msa.Fields)
+	}
+}
+
+func TestAckCodeEnhancedMode(t *testing.T) {
+	ack := NewMessage(NewMSH(Header{}, "ACK", "A04", "ACK", testTime))
+	ack.Add(NewSegment("MSA", "CR", "MSG0001"))
+	if code, _ := AckCode(ack); code != AckReject {
+		t.Errorf("AckCode(CR) = %s, want %s", code, AckReject)
+	}
+}
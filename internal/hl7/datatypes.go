@@ -0,0 +1,51 @@
+package hl7
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timestampLayout is the DTM layout used for outgoing timestamps.
+const timestampLayout = "20060102150405-0700"
+
+// FormatTimestamp renders t as an HL7 DTM with seconds and offset.
+func FormatTimestamp(t time.Time) string {
+	return t.Format(timestampLayout)
+}
+
+// FormatDate renders t as an HL7 DT (YYYYMMDD).
+func FormatDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+// ParseTimestamp reads an HL7 DTM of any precision from YYYY to
+// YYYYMMDDHHMMSS.SSSS with an optional +/-ZZZZ offset. Values without an
+// offset are read in loc.
+func ParseTimestamp(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	offset := ""
+	if i := strings.IndexAny(value, "+-"); i >= 0 {
+		value, offset = value[:i], value[i:]
+	}
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		value = value[:i]
+	}
+
+	layouts := map[int]string{
+		4:  "2006",
+		6:  "200601",
+		8:  "20060102",
+		10: "2006010215",
+		12: "200601021504",
+		14: "20060102150405",
+	}
+	layout, ok := layouts[len(value)]
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid HL7 timestamp %q", value+offset)
+	}
+	if offset != "" {
+		return time.Parse(layout+"-0700", value+offset)
+	}
+	return time.ParseInLocation(layout, value, loc)
+}
@@ -0,0 +1,356 @@
+// Package hl7 reads and writes HL7 v2 messages in the ER7 (pipe-delimited)
+// encoding and carries them over MLLP. Only the segments the hospital
+// exchanges are mapped; everything else in a message is kept but ignored.
+package hl7
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is the HL7 version stamped on outgoing messages.
+const Version = "2.5.1"
+
+// ErrMalformed is returned for input that is not an HL7 v2 message.
+var ErrMalformed = errors.New("malformed HL7 message")
+
+// Delimiters are the separator characters declared in MSH-1 and MSH-2.
+type Delimiters struct {
+	Field        byte
+	Component    byte
+	Repetition   byte
+	Escape       byte
+	Subcomponent byte
+}
+
+// DefaultDelimiters are the delimiters used for outgoing messages.
+var DefaultDelimiters = Delimiters{Field: '|', Component: '^', Repetition: '~', Escape: '\\', Subcomponent: '&'}
+
+func (d Delimiters) encodingCharacters() string {
+	return string([]byte{d.Component, d.Repetition, d.Escape, d.Subcomponent})
+}
+
+// Segment is one line of a message. Fields are kept in their encoded form;
+// Fields[0] is the segment name, so Fields[n] is field n for every segment
+// except MSH, whose first field is the field separator itself.
+type Segment struct {
+	Fields []string
+	delims Delimiters
+}
+
+// NewSegment builds a segment from already encoded fields, starting at
+// field 1. Use Components and Escape to encode values.
+func NewSegment(name string, fields ...string) *Segment {
+	return &Segment{Fields: append([]string{name}, fields...), delims: DefaultDelimiters}
+}
+
+// Name returns the segment ID, e.g. "PID".
+func (s *Segment) Name() string {
+	return s.Fields[0]
+}
+
+// index maps an HL7 field number to its position in Fields.
+func (s *Segment) index(n int) int {
+	if s.Name() == "MSH" {
+		return n - 1
+	}
+	return n
+}
+
+// Field returns field n in its encoded form, or "" when absent.
+func (s *Segment) Field(n int) string {
+	if s.Name() == "MSH" && n == 1 {
+		return string(s.delims.Field)
+	}
+	i := s.index(n)
+	if i < 1 || i >= len(s.Fields) {
+		return ""
+	}
+	return s.Fields[i]
+}
+
+// SetField replaces field n with an encoded value, growing the segment as
+// needed.
+func (s *Segment) SetField(n int, value string) {
+	i := s.index(n)
+	if i < 1 {
+		return
+	}
+	for len(s.Fields) <= i {
+		s.Fields = append(s.Fields, "")
+	}
+	s.Fields[i] = value
+}
+
+// Repetitions returns the encoded repetitions of field n.
+func (s *Segment) Repetitions(n int) []string {
+	field := s.Field(n)
+	if field == "" {
+		return nil
+	}
+	if s.Name() == "MSH" && n == 2 {
+		return []string{field}
+	}
+	return strings.Split(field, string(s.delims.Repetition))
+}
+
+// Component returns component c (1-based) of the first repetition of field
+// n, decoded.
+func (s *Segment) Component(n, c int) string {
+	reps := s.Repetitions(n)
+	if len(reps) == 0 {
+		return ""
+	}
+	return s.RepetitionComponent(reps[0], c)
+}
+
+// Value returns the first component of field n, decoded.
+func (s *Segment) Value(n int) string {
+	return s.Component(n, 1)
+}
+
+// RepetitionComponent returns component c (1-based) of an encoded
+// repetition of one of the segment's fields, decoded. Subcomponents are
+// returned joined by spaces.
+func (s *Segment) RepetitionComponent(rep string, c int) string {
+	components := strings.Split(rep, string(s.delims.Component))
+	if c < 1 || c > len(components) {
+		return ""
+	}
+	subs := strings.Split(components[c-1], string(s.delims.Subcomponent))
+	for i, sub := range subs {
+		subs[i] = s.delims.unescape(sub)
+	}
+	return strings.TrimSpace(strings.Join(subs, " "))
+}
+
+// Subcomponent returns subcomponent sc of component c (both 1-based) of an
+// encoded repetition, decoded.
+func (s *Segment) Subcomponent(rep string, c, sc int) string {
+	components := strings.Split(rep, string(s.delims.Component))
+	if c < 1 || c > len(components) {
+		return ""
+	}
+	subs := strings.Split(components[c-1], string(s.delims.Subcomponent))
+	if sc < 1 || sc > len(subs) {
+		return ""
+	}
+	return strings.TrimSpace(s.delims.unescape(subs[sc-1]))
+}
+
+// Message is a parsed HL7 v2 message.
+type Message struct {
+	Segments []*Segment
+	delims   Delimiters
+}
+
+// NewMessage starts a message with the given MSH segment.
+func NewMessage(msh *Segment) *Message {
+	return &Message{Segments: []*Segment{msh}, delims: DefaultDelimiters}
+}
+
+// Add appends segments to the message.
+func (m *Message) Add(segments ...*Segment) {
+	for _, s := range segments {
+		s.delims = m.delims
+		m.Segments = append(m.Segments, s)
+	}
+}
+
+// Segment returns the first segment with the given name, or nil.
+func (m *Message) Segment(name string) *Segment {
+	for _, s := range m.Segments {
+		if s.Name() == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// MSH returns the message header.
+func (m *Message) MSH() *Segment {
+	return m.Segments[0]
+}
+
+// Type returns the message code and trigger event from MSH-9, e.g. "ADT",
+// "A04".
+func (m *Message) Type() (code, event string) {
+	msh := m.MSH()
+	return msh.Component(9, 1), msh.Component(9, 2)
+}
+
+// ControlID returns MSH-10, which the receiver echoes in its ACK.
+func (m *Message) ControlID() string {
+	return m.MSH().Value(10)
+}
+
+// Parse reads an ER7-encoded message. Segments may be separated by CR, LF
+// or CRLF. The delimiters are taken from the MSH segment.
+func Parse(data []byte) (*Message, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\r")
+	text = strings.ReplaceAll(text, "\n", "\r")
+	text = strings.Trim(text, "\r")
+
+	if len(text) < 8 || !strings.HasPrefix(text, "MSH") {
+		return nil, fmt.Errorf("%w: message must start with an MSH segment", ErrMalformed)
+	}
+	d := Delimiters{
+		Field:        text[3],
+		Component:    text[4],
+		Repetition:   text[5],
+		Escape:       text[6],
+		Subcomponent: text[7],
+	}
+	if err := d.validate(); err != nil {
+		return nil, err
+	}
+
+	msg := &Message{delims: d}
+	for _, line := range strings.Split(text, "\r") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, string(d.Field))
+		if len(fields[0]) != 3 {
+			return nil, fmt.Errorf("%w: invalid segment name %q", ErrMalformed, fields[0])
+		}
+		msg.Segments = append(msg.Segments, &Segment{Fields: fields, delims: d})
+	}
+
+	// The trigger event is left to the handler to check: acknowledgements
+	// often carry only the message code.
+	if code, _ := msg.Type(); code == "" {
+		return nil, fmt.Errorf("%w: MSH-9 message type is required", ErrMalformed)
+	}
+	if msg.ControlID() == "" {
+		return nil, fmt.Errorf("%w: MSH-10 message control ID is required", ErrMalformed)
+	}
+	return msg, nil
+}
+
+func (d Delimiters) validate() error {
+	chars := []byte{d.Field, d.Component, d.Repetition, d.Escape, d.Subcomponent}
+	for i, c := range chars {
+		if c == '\r' || c == '\n' || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			return fmt.Errorf("%w: invalid delimiter %q", ErrMalformed, c)
+		}
+		for _, other := range chars[:i] {
+			if c == other {
+				return fmt.Errorf("%w: delimiters must be distinct", ErrMalformed)
+			}
+		}
+	}
+	return nil
+}
+
+// Bytes encodes the message with CR segment terminators.
+func (m *Message) Bytes() []byte {
+	var b strings.Builder
+	for _, s := range m.Segments {
+		if s.Name() == "MSH" {
+			b.WriteString("MSH")
+			b.WriteByte(m.delims.Field)
+			b.WriteString(m.delims.encodingCharacters())
+			for _, f := range s.Fields[2:] {
+				b.WriteByte(m.delims.Field)
+				b.WriteString(f)
+			}
+		} else {
+			b.WriteString(strings.Join(s.Fields, string(m.delims.Field)))
+		}
+		b.WriteByte('\r')
+	}
+	return []byte(b.String())
+}
+
+// String renders the message with one segment per line, for logs.
+func (m *Message) String() string {
+	return strings.ReplaceAll(strings.TrimRight(string(m.Bytes()), "\r"), "\r", "\n")
+}
+
+// Escape encodes a value for use inside a field with the default
+// delimiters.
+func Escape(value string) string {
+	d := DefaultDelimiters
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case d.Field:
+			b.WriteString(`\F\`)
+		case d.Component:
+			b.WriteString(`\S\`)
+		case d.Repetition:
+			b.WriteString(`\R\`)
+		case d.Escape:
+			b.WriteString(`\E\`)
+		case d.Subcomponent:
+			b.WriteString(`\T\`)
+		case '\r':
+			b.WriteString(`\X0D\`)
+		case '\n':
+			b.WriteString(`\.br\`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Components escapes values and joins them into a single field, dropping
+// trailing empty components.
+func Components(values ...string) string {
+	for len(values) > 0 && values[len(values)-1] == "" {
+		values = values[:len(values)-1]
+	}
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = Escape(v)
+	}
+	return strings.Join(escaped, string(DefaultDelimiters.Component))
+}
+
+// unescape decodes the escape sequences of a component or subcomponent.
+// Formatting sequences other than line breaks are dropped.
+func (d Delimiters) unescape(value string) string {
+	if strings.IndexByte(value, d.Escape) < 0 {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != d.Escape {
+			b.WriteByte(value[i])
+			continue
+		}
+		end := strings.IndexByte(value[i+1:], d.Escape)
+		if end < 0 {
+			b.WriteString(value[i:])
+			break
+		}
+		seq := value[i+1 : i+1+end]
+		i += end + 1
+		switch {
+		case seq == "F":
+			b.WriteByte(d.Field)
+		case seq == "S":
+			b.WriteByte(d.Component)
+		case seq == "R":
+			b.WriteByte(d.Repetition)
+		case seq == "E":
+			b.WriteByte(d.Escape)
+		case seq == "T":
+			b.WriteByte(d.Subcomponent)
+		case seq == ".br":
+			b.WriteByte('\n')
+		case strings.HasPrefix(seq, "X"):
+			hex := seq[1:]
+			for j := 0; j+1 < len(hex); j += 2 {
+				if v, err := strconv.ParseUint(hex[j:j+2], 16, 8); err == nil {
+					b.WriteByte(byte(v))
+				}
+			}
+		}
+	}
+	return b.String()
+}
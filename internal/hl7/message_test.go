@@ -0,0 +1,171 @@
+package hl7
+
+import (
+	"errors"
+	"testing"
+)
+
+const admitMessage = "MSH|^~\\&|REG|WARD|HOSPITAL-MANAGEMENT|HMS|20260301120000||ADT^A04^ADT_A01|MSG0001|P|2.5.1\r" +
+	"EVN|A04|20260301120000\r" +
+	"PID|1||42^^^HMS^MR~9001^^^NHS^NH||Doe^Jane^^^^^L||19800102|F|||1 Main St^^Springfield^^12345||555-0100^PRN^PH~^NET^Internet^jane@example.org\r"
+
+func TestParseFieldOffsets(t *testing.T) {
+	msg, err := Parse([]byte(admitMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msh := msg.MSH()
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"MSH-1", msh.Field(1), "|"},
+		{"MSH-2", msh.Field(2), "^~\\&"},
+		{"MSH-3", msh.Value(3), "REG"},
+		{"MSH-4", msh.Value(4), "WARD"},
+		{"MSH-7", msh.Value(7), "20260301120000"},
+		{"MSH-9.3", msh.Component(9, 3), "ADT_A01"},
+		{"MSH-10", msg.ControlID(), "MSG0001"},
+		{"MSH-12", msh.Value(12), "2.5.1"},
+		{"EVN-1", msg.Segment("EVN").Value(1), "A04"},
+		{"PID-1", msg.Segment("PID").Value(1), "1"},
+		{"PID-5.2", msg.Segment("PID").Component(5, 2), "Jane"},
+		{"PID-7", msg.Segment("PID").Value(7), "19800102"},
+		{"PID-11.3", msg.Segment("PID").Component(11, 3), "Springfield"},
+		{"absent field", msg.Segment("PID").Value(30), ""},
+		{"absent component", msg.Segment("PID").Component(5, 9), ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if code, event := msg.Type(); code != "ADT" || event != "A04" {
+		t.Errorf("Type() = %s^%s, want ADT^A04", code, event)
+	}
+	if reps := msh.Repetitions(2); len(reps) != 1 {
+		t.Errorf("MSH-2 split into %d repetitions, want its encoding characters kept whole", len(reps))
+	}
+	if reps := msg.Segment("PID").Repetitions(3); len(reps) != 2 {
+		t.Errorf("PID-3 has %d repetitions, want 2", len(reps))
+	}
+	if id, ok := PatientID(msg.Segment("PID"), "HMS"); !ok || id != 42 {
+		t.Errorf("PatientID = %d, %v, want 42", id, ok)
+	}
+}
+
+func TestParseCustomDelimiters(t *testing.T) {
+	text := "MSH#$*@%#REG#WARD#HMS#HOSP#20260301120000##ADT$A08#MSG0002#P#2.5.1\n" +
+		"PID#1##42$$$HMS$MR*9001$$$NHS$NH##O@F@Brien$Mary@S@Ann##19800102#F\n"
+	msg, err := Parse([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if code, event := msg.Type(); code != "ADT" || event != "A08" {
+		t.Fatalf("Type() = %s^%s, want ADT^A08", code, event)
+	}
+	pid := msg.Segment("PID")
+	if id, ok := PatientID(pid, "HMS"); !ok || id != 42 {
+		t.Errorf("PatientID = %d, %v, want 42", id, ok)
+	}
+	if got := pid.Component(5, 1); got != "O#Brien" {
+		t.Errorf("family name = %q, want the escaped field separator decoded", got)
+	}
+	if got := pid.Component(5, 2); got != "Mary$Ann" {
+		t.Errorf("given name = %q, want the escaped component separator decoded", got)
+	}
+
+	// Re-encoding keeps the sender's delimiters.
+	want := "MSH#$*@%#REG#WARD#HMS#HOSP#20260301120000##ADT$A08#MSG0002#P#2.5.1\r" +
+		"PID#1##42$$$HMS$MR*9001$$$NHS$NH##O@F@Brien$Mary@S@Ann##19800102#F\r"
+	if got := string(msg.Bytes()); got != want {
+		t.Errorf("Bytes() = %q, want %q", got, want)
+	}
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		encoded string
+		want    string
+	}{
+		{`plain`, "plain"},
+		{`a\F\b`, "a|b"},
+		{`a\S\b`, "a^b"},
+		{`a\T\b`, "a&b"},
+		{`a\R\b`, "a~b"},
+		{`a\E\b`, `a\b`},
+		{`line\.br\break`, "line\nbreak"},
+		{`a\X0D0A\b`, "a\r\nb"},
+		{`\H\bold\N\`, "bold"},
+		{`dangling\F`, `dangling\F`},
+	}
+	for _, tt := range tests {
+		if got := DefaultDelimiters.unescape(tt.encoded); got != tt.want {
+			t.Errorf("unescape(%q) = %q, want %q", tt.encoded, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeRoundTrip(t *testing.T) {
+	value := "A|B^C~D\\E&F\r\nG"
+	if got := Escape(value); got != `A\F\B\S\C\R\D\E\E\T\F\X0D\\.br\G` {
+		t.Errorf("Escape(%q) = %q", value, got)
+	}
+
+	msh := NewMSH(Header{}, "ADT", "A08", "ADT_A01", testTime)
+	msg := NewMessage(msh)
+	msg.Add(NewSegment("NTE", "1", "", Escape(value)))
+	parsed, err := Parse(msg.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.Segment("NTE").Value(3); got != value {
+		t.Errorf("round trip = %q, want %q", got, value)
+	}
+}
+
+func TestParseAcceptsMessageCodeOnly(t *testing.T) {
+	msg, err := Parse([]byte("MSH|^~\\&|A|B|C|D|20260301||ACK|1|P|2.3\rMSA|AA|MSG0001\r"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, event := msg.Type(); code != "ACK" || event != "" {
+		t.Fatalf("Type() = %s^%s, want ACK with no event", code, event)
+	}
+}
+
+func TestParseLineEndings(t *testing.T) {
+	for _, sep := range []string{"\r", "\n", "\r\n"} {
+		text := "MSH|^~\\&|A|B|C|D|20260301||ADT^A08|1|P|2.5.1" + sep + "PID|1" + sep + sep
+		msg, err := Parse([]byte(text))
+		if err != nil {
+			t.Errorf("separator %q: %v", sep, err)
+			continue
+		}
+		if len(msg.Segments) != 2 {
+			t.Errorf("separator %q: %d segments, want 2", sep, len(msg.Segments))
+		}
+	}
+}
+
+func TestParseRejectsMalformed(t *testing.T) {
+	tests := map[string]string{
+		"empty":              "",
+		"no MSH":             "PID|1||42\r",
+		"short header":       "MSH|^~",
+		"repeated delimiter": "MSH|^^\\&|A|B|C|D|20260301||ADT^A08|1|P|2.5.1\r",
+		"letter delimiter":   "MSHX^~\\&XAXBXCXDX20260301XXADT^A08X1XPX2.5.1\r",
+		"no message type":    "MSH|^~\\&|A|B|C|D|20260301|||1|P|2.5.1\r",
+		"no control ID":      "MSH|^~\\&|A|B|C|D|20260301||ADT^A08||P|2.5.1\r",
+		"bad segment name":   "MSH|^~\\&|A|B|C|D|20260301||ADT^A08|1|P|2.5.1\rPATIENT|1\r",
+	}
+	for name, text := range tests {
+		if _, err := Parse([]byte(text)); !errors.Is(err, ErrMalformed) {
+			t.Errorf("%s: got %v, want ErrMalformed", name, err)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package hl7
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// MLLP block characters.
+const (
+	startBlock     = 0x0b
+	endBlock       = 0x1c
+	carriageReturn = 0x0d
+)
+
+// MaxFrameSize bounds the size of a single MLLP frame.
+const MaxFrameSize = 1 << 20
+
+// ErrFrameTooLarge is returned when a frame exceeds MaxFrameSize.
+var ErrFrameTooLarge = errors.New("MLLP frame too large")
+
+// ReadFrame reads the next MLLP frame and returns its payload. Bytes before
+// the start block are discarded.
+func ReadFrame(r *bufio.Reader) ([]byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == startBlock {
+			break
+		}
+	}
+
+	var payload []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		if b == endBlock {
+			next, err := r.ReadByte()
+			if err != nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+			if next == carriageReturn {
+				return payload, nil
+			}
+			// Not the end of the frame: keep the 0x1c as data and look at
+			// the next byte afresh, as it may itself start the end block.
+			r.UnreadByte()
+		}
+		if len(payload) >= MaxFrameSize {
+			return nil, ErrFrameTooLarge
+		}
+		payload = append(payload, b)
+	}
+}
+
+// WriteFrame writes payload as a single MLLP frame.
+func WriteFrame(w io.Writer, payload []byte) error {
+	frame := make([]byte, 0, len(payload)+3)
+	frame = append(frame, startBlock)
+	frame = append(frame, payload...)
+	frame = append(frame, endBlock, carriageReturn)
+	_, err := w.Write(frame)
+	return err
+}
+
+// Handler processes an inbound message and returns the acknowledgement to
+// send back. remoteAddr identifies the sending connection.
+type Handler interface {
+	ServeHL7(remoteAddr string, msg *Message) *Message
+}
+
+// HandlerFunc adapts a function to Handler.
+type HandlerFunc func(remoteAddr string, msg *Message) *Message
+
+func (f HandlerFunc) ServeHL7(remoteAddr string, msg *Message) *Message {
+	return f(remoteAddr, msg)
+}
+
+// Server accepts MLLP connections and answers every message with the ACK
+// its Handler returns. Frames that do not parse are rejected with an AR
+// acknowledgement built by the server.
+type Server struct {
+	Addr    string
+	Handler Handler
+	// IdleTimeout closes connections that send nothing for this long.
+	// Zero means no timeout.
+	IdleTimeout time.Duration
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+// ListenAndServe listens on s.Addr and serves connections until Close.
+func (s *Server) ListenAndServe() error {
+	ln, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.Addr, err)
+	}
+	return s.Serve(ln)
+}
+
+// Serve accepts connections on ln until Close. It returns nil after Close.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		ln.Close()
+		return nil
+	}
+	s.listener = ln
+	s.conns = make(map[net.Conn]struct{})
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(100 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections and closes the open ones.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	remoteAddr := conn.RemoteAddr().String()
+	reader := bufio.NewReader(conn)
+	for {
+		if s.IdleTimeout > 0 {
+			conn.SetReadDeadline(time.Now().Add(s.IdleTimeout))
+		}
+		payload, err := ReadFrame(reader)
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Printf("hl7: closing connection from %s: %v", remoteAddr, err)
+			}
+			return
+		}
+
+		var ack *Message
+		msg, err := Parse(payload)
+		if err != nil {
+			ack = RejectUnparsed(payload, err)
+		} else {
+			ack = s.Handler.ServeHL7(remoteAddr, msg)
+		}
+
+		if err := WriteFrame(conn, ack.Bytes()); err != nil {
+			log.Printf("hl7: failed to acknowledge message from %s: %v", remoteAddr, err)
+			return
+		}
+	}
+}
+
+// Client sends messages to an MLLP receiver over a single connection,
+// reconnecting after errors. It is safe for concurrent use; sends are
+// serialised.
+type Client struct {
+	Addr string
+	// Timeout bounds dialling and waiting for each acknowledgement.
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Send delivers msg and returns the receiver's acknowledgement. An error
+// means the message may not have been received; a NAK is returned as an
+// acknowledgement and must be checked with AckCode.
+func (c *Client) Send(msg *Message) (*Message, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ack, err := c.send(msg)
+	if err != nil {
+		c.closeConn()
+		return nil, err
+	}
+	return ack, nil
+}
+
+func (c *Client) send(msg *Message) (*Message, error) {
+	if c.conn == nil {
+		conn, err := net.DialTimeout("tcp", c.Addr, c.timeout())
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", c.Addr, err)
+		}
+		c.conn = conn
+		c.reader = bufio.NewReader(conn)
+	}
+
+	c.conn.SetDeadline(time.Now().Add(c.timeout()))
+	if err := WriteFrame(c.conn, msg.Bytes()); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+	payload, err := ReadFrame(c.reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read acknowledgement: %w", err)
+	}
+	ack, err := Parse(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse acknowledgement: %w", err)
+	}
+
+	msa := ack.Segment("MSA")
+	if msa == nil {
+		return nil, fmt.Errorf("acknowledgement has no MSA segment")
+	}
+	if id := msa.Value(2); id != msg.ControlID() {
+		return nil, fmt.Errorf("acknowledgement is for message %q, not %q", id, msg.ControlID())
+	}
+	return ack, nil
+}
+
+func (c *Client) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return 30 * time.Second
+}
+
+func (c *Client) closeConn() {
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+		c.reader = nil
+	}
+}
+
+// Close closes the client's connection, if any.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closeConn()
+	return nil
+}
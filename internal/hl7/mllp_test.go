@@ -0,0 +1,221 @@
+package hl7
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+		err   error
+	}{
+		{name: "single frame", input: "\x0bMSH|1\x1c\r", want: []string{"MSH|1"}, err: io.EOF},
+		{name: "consecutive frames", input: "\x0bA\x1c\r\x0bB\x1c\r", want: []string{"A", "B"}, err: io.EOF},
+		{name: "junk before start block", input: "noise\r\n\x00\x0bA\x1c\r", want: []string{"A"}, err: io.EOF},
+		{name: "junk between frames", input: "\x0bA\x1c\rjunk\x0bB\x1c\r", want: []string{"A", "B"}, err: io.EOF},
+		{name: "embedded end block", input: "\x0bA\x1cB\x1c\r", want: []string{"A\x1cB"}, err: io.EOF},
+		{name: "embedded end block before end", input: "\x0bA\x1c\x1c\r\x0bB\x1c\r", want: []string{"A\x1c", "B"}, err: io.EOF},
+		{name: "empty frame", input: "\x0b\x1c\r", want: []string{""}, err: io.EOF},
+		{name: "no frame", input: "", err: io.EOF},
+		{name: "truncated payload", input: "\x0bMSH|1", err: io.ErrUnexpectedEOF},
+		{name: "truncated end block", input: "\x0bMSH|1\x1c", err: io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		r := bufio.NewReader(strings.NewReader(tt.input))
+		var got []string
+		var err error
+		for {
+			var payload []byte
+			if payload, err = ReadFrame(r); err != nil {
+				break
+			}
+			got = append(got, string(payload))
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") || len(got) != len(tt.want) {
+			t.Errorf("%s: frames = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadFrameTooLarge(t *testing.T) {
+	input := append([]byte{startBlock}, bytes.Repeat([]byte("x"), MaxFrameSize+1)...)
+	input = append(input, endBlock, carriageReturn)
+
+	_, err := ReadFrame(bufio.NewReader(bytes.NewReader(input)))
+	if !errors.Is(err, ErrFrameTooLarge) {
+		t.Fatalf("got %v, want ErrFrameTooLarge", err)
+	}
+
+	// A frame of exactly MaxFrameSize is accepted.
+	input = append([]byte{startBlock}, bytes.Repeat([]byte("x"), MaxFrameSize)...)
+	input = append(input, endBlock, carriageReturn)
+	if payload, err := ReadFrame(bufio.NewReader(bytes.NewReader(input))); err != nil || len(payload) != MaxFrameSize {
+		t.Fatalf("got %d bytes, %v, want the whole frame", len(payload), err)
+	}
+}
+
+func TestWriteFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	payload := []byte("MSH|^~\\&|A\rPID|1\r")
+	if err := WriteFrame(&buf, payload); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Bytes(); got[0] != startBlock || !bytes.HasSuffix(got, []byte{endBlock, carriageReturn}) {
+		t.Fatalf("frame = %q, want it wrapped in MLLP block characters", got)
+	}
+	got, err := ReadFrame(bufio.NewReader(&buf))
+	if err != nil || !bytes.Equal(got, payload) {
+		t.Fatalf("ReadFrame = %q, %v, want %q", got, err, payload)
+	}
+}
+
+// serveOnce accepts one connection and answers each frame it receives with
+// the frame respond returns.
+func serveOnce(t *testing.T, respond func(payload []byte) []byte) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			payload, err := ReadFrame(r)
+			if err != nil {
+				return
+			}
+			if err := WriteFrame(conn, respond(payload)); err != nil {
+				return
+			}
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func outgoingMessage() *Message {
+	msg := NewMessage(NewMSH(Header{SendingApplication: "HMS"}, "SIU", "S12", "SIU_S12", testTime))
+	msg.Add(NewSegment("SCH", "1"))
+	return msg
+}
+
+func TestClientSend(t *testing.T) {
+	addr := serveOnce(t, func(payload []byte) []byte {
+		msg, err := Parse(payload)
+		if err != nil {
+			t.Errorf("receiver could not parse %q: %v", payload, err)
+			return nil
+		}
+		return Acknowledge(msg, AckAccept).Bytes()
+	})
+	client := &Client{Addr: addr, Timeout: time.Second}
+	defer client.Close()
+
+	for i := 0; i < 2; i++ {
+		ack, err := client.Send(outgoingMessage())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code, _ := AckCode(ack); code != AckAccept {
+			t.Fatalf("ack code = %s, want %s", code, AckAccept)
+		}
+	}
+}
+
+func TestClientSendRejectsMismatchedAck(t *testing.T) {
+	addr := serveOnce(t, func(payload []byte) []byte {
+		other := outgoingMessage()
+		return Acknowledge(other, AckAccept).Bytes()
+	})
+	client := &Client{Addr: addr, Timeout: time.Second}
+	defer client.Close()
+
+	msg := outgoingMessage()
+	_, err := client.Send(msg)
+	if err == nil || !strings.Contains(err.Error(), msg.ControlID()) {
+		t.Fatalf("got %v, want the control ID mismatch reported", err)
+	}
+	if client.conn != nil {
+		t.Fatal("connection kept open after a mismatched acknowledgement")
+	}
+}
+
+func TestClientSendRejectsAckWithoutMSA(t *testing.T) {
+	addr := serveOnce(t, func(payload []byte) []byte {
+		return NewMessage(NewMSH(Header{}, "ACK", "S12", "ACK", testTime)).Bytes()
+	})
+	client := &Client{Addr: addr, Timeout: time.Second}
+	defer client.Close()
+
+	if _, err := client.Send(outgoingMessage()); err == nil {
+		t.Fatal("accepted an acknowledgement without MSA")
+	}
+}
+
+func TestServerAcknowledgesMessages(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &Server{Handler: HandlerFunc(func(remoteAddr string, msg *Message) *Message {
+		return Acknowledge(msg, AckAccept)
+	})}
+	go server.Serve(ln)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+	r := bufio.NewReader(conn)
+
+	msg := outgoingMessage()
+	if err := WriteFrame(conn, msg.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	ack := readAck(t, r)
+	if code, _ := AckCode(ack); code != AckAccept || ack.Segment("MSA").Value(2) != msg.ControlID() {
+		t.Fatalf("ack = %s, want AA for %s", ack, msg.ControlID())
+	}
+
+	// A frame that is not HL7 is rejected on the same connection.
+	if err := WriteFrame(conn, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := AckCode(readAck(t, r)); code != AckReject {
+		t.Fatalf("ack code = %s, want %s", code, AckReject)
+	}
+}
+
+func readAck(t *testing.T, r *bufio.Reader) *Message {
+	t.Helper()
+	payload, err := ReadFrame(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ack, err := Parse(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ack
+}
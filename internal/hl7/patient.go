@@ -0,0 +1,162 @@
+package hl7
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hospital-management/internal/models"
+)
+
+// administrativeSex maps PID-8 (HL7 table 0001) to patient genders. The
+// hospital records only male, female and other, so every other code is
+// stored as other.
+var administrativeSex = map[string]string{
+	"M": "male",
+	"F": "female",
+	"O": "other",
+	"A": "other",
+	"N": "other",
+	"U": "other",
+}
+
+// PatientID returns the hospital patient ID carried in PID-3 under the
+// given assigning authority.
+func PatientID(pid *Segment, authority string) (uint, bool) {
+	for _, rep := range pid.Repetitions(3) {
+		if !strings.EqualFold(pid.Subcomponent(rep, 4, 1), authority) {
+			continue
+		}
+		id, err := strconv.ParseUint(pid.RepetitionComponent(rep, 1), 10, 64)
+		if err == nil && id > 0 {
+			return uint(id), true
+		}
+	}
+	return 0, false
+}
+
+// PatientRequest maps a PID segment to a patient request. Fields absent from
+// the segment are left empty; checking that required ones are present is up
+// to the caller, since an update may leave them out. A date of birth
+// without an offset is read in loc.
+func PatientRequest(pid *Segment, loc *time.Location) (*models.PatientRequest, *Error) {
+	req := &models.PatientRequest{}
+
+	if rep := preferredName(pid); rep != "" {
+		req.LastName = pid.Subcomponent(rep, 1, 1)
+		req.FirstName = pid.RepetitionComponent(rep, 2)
+	}
+
+	if dob := pid.Value(7); dob != "" {
+		t, err := ParseTimestamp(dob, loc)
+		if err != nil {
+			return nil, &Error{Code: CodeDataType, Location: "PID^1^7", Message: fmt.Sprintf("invalid date of birth %q", dob)}
+		}
+		req.DateOfBirth = t.Format("2006-01-02")
+	}
+
+	if sex := pid.Value(8); sex != "" {
+		gender, ok := administrativeSex[strings.ToUpper(sex)]
+		if !ok {
+			return nil, &Error{Code: CodeTableValue, Location: "PID^1^8", Message: fmt.Sprintf("unknown administrative sex %q", sex)}
+		}
+		req.Gender = gender
+	}
+
+	req.Address = address(pid)
+	req.Phone, req.Email = telecom(pid, 13)
+	if req.Phone == "" {
+		req.Phone, _ = telecom(pid, 14)
+	}
+	return req, nil
+}
+
+// preferredName returns the legal name from PID-5, or the first name given.
+func preferredName(pid *Segment) string {
+	reps := pid.Repetitions(5)
+	for _, rep := range reps {
+		if pid.RepetitionComponent(rep, 7) == "L" {
+			return rep
+		}
+	}
+	if len(reps) > 0 {
+		return reps[0]
+	}
+	return ""
+}
+
+// address flattens the first PID-11 address to a single line.
+func address(pid *Segment) string {
+	reps := pid.Repetitions(11)
+	if len(reps) == 0 {
+		return ""
+	}
+	var parts []string
+	for c := 1; c <= 6; c++ {
+		if v := pid.RepetitionComponent(reps[0], c); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// telecom returns the first phone number and e-mail address in an XTN
+// field. A phone number is read from the legacy first component or, failing
+// that, from the area code and local number.
+func telecom(pid *Segment, field int) (phone, email string) {
+	for _, rep := range pid.Repetitions(field) {
+		use := pid.RepetitionComponent(rep, 2)
+		equipment := pid.RepetitionComponent(rep, 3)
+		if use == "NET" || equipment == "Internet" || equipment == "X.400" {
+			if email == "" {
+				email = pid.RepetitionComponent(rep, 4)
+			}
+			continue
+		}
+		if phone != "" {
+			continue
+		}
+		phone = pid.RepetitionComponent(rep, 1)
+		if phone == "" {
+			phone = pid.RepetitionComponent(rep, 6) + pid.RepetitionComponent(rep, 7)
+		}
+	}
+	return phone, email
+}
+
+// PIDSegment renders a patient as a PID segment, identified by its ID under
+// the given assigning authority.
+func PIDSegment(p *models.Patient, authority string) *Segment {
+	sex := "O"
+	switch p.Gender {
+	case "male":
+		sex = "M"
+	case "female":
+		sex = "F"
+	}
+
+	phones := []string{Components(p.Phone, "PRN", "PH")}
+	if p.Email != nil && *p.Email != "" {
+		phones = append(phones, Components("", "NET", "Internet", *p.Email))
+	}
+	addr := ""
+	if p.Address != nil {
+		addr = Components(*p.Address)
+	}
+
+	return NewSegment("PID",
+		"1",
+		"",
+		Components(strconv.FormatUint(uint64(p.ID), 10), "", "", authority, "MR"),
+		"",
+		Components(p.LastName, p.FirstName, "", "", "", "", "L"),
+		"",
+		FormatDate(p.DateOfBirth),
+		sex,
+		"", "",
+		addr,
+		"",
+		strings.Join(phones, string(DefaultDelimiters.Repetition)),
+	)
+}
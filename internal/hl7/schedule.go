@@ -0,0 +1,72 @@
+package hl7
+
+import (
+	"strconv"
+	"time"
+
+	"hospital-management/internal/models"
+)
+
+// Scheduling trigger events sent for appointments.
+const (
+	EventNewBooking   = "S12"
+	EventCancellation = "S15"
+)
+
+// fillerStatuses maps appointment statuses to SCH-25 filler status codes
+// (HL7 table 0278).
+var fillerStatuses = map[string]string{
+	models.AppointmentRequested:  "Pending",
+	models.AppointmentScheduled:  "Booked",
+	models.AppointmentConfirmed:  "Booked",
+	models.AppointmentCheckedIn:  "Booked",
+	models.AppointmentInProgress: "Started",
+	models.AppointmentCompleted:  "Complete",
+	models.AppointmentCancelled:  "Cancelled",
+	models.AppointmentNoShow:     "Noshow",
+}
+
+// ScheduleMessage builds an SIU message for the given trigger event
+// announcing an appointment to other systems. Times are rendered in loc.
+func ScheduleMessage(h Header, event string, a *models.Appointment, patient *models.Patient, doctor *models.User, authority string, loc *time.Location, now time.Time) *Message {
+	msg := NewMessage(NewMSH(h, "SIU", event, "SIU_S12", now))
+
+	start := a.DateTime.In(loc)
+	end := start.Add(time.Duration(a.Duration) * time.Minute)
+	id := strconv.FormatUint(uint64(a.ID), 10)
+	duration := strconv.Itoa(a.Duration)
+	status := fillerStatuses[a.Status]
+
+	sch := NewSegment("SCH")
+	sch.SetField(2, Components(id, authority))
+	if a.StatusReason != nil {
+		sch.SetField(6, Components("", *a.StatusReason))
+	}
+	sch.SetField(9, duration)
+	sch.SetField(10, "min")
+	sch.SetField(11, Components("", "", "", FormatTimestamp(start), FormatTimestamp(end)))
+	sch.SetField(25, status)
+	msg.Add(sch)
+
+	if a.Notes != nil && *a.Notes != "" {
+		msg.Add(NewSegment("NTE", "1", "", Escape(*a.Notes)))
+	}
+
+	msg.Add(PIDSegment(patient, authority))
+
+	action := "A"
+	if event == EventCancellation {
+		action = ""
+	}
+	msg.Add(NewSegment("RGS", "1", action))
+
+	doctorID := strconv.FormatUint(uint64(doctor.ID), 10)
+	aip := NewSegment("AIP", "1", action, Components(doctorID, doctor.LastName, doctor.FirstName), Components("", "Doctor"))
+	aip.SetField(6, FormatTimestamp(start))
+	aip.SetField(9, duration)
+	aip.SetField(10, "min")
+	aip.SetField(12, status)
+	msg.Add(aip)
+
+	return msg
+}
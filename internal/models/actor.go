@@ -0,0 +1,9 @@
+package models
+
+// Actor identifies the authenticated user on whose behalf a service call is
+// made. Handlers build it from the JWT claims in the request context.
+type Actor struct {
+	UserID   uint
+	Role     string
+	ClientIP string
+}
@@ -2,19 +2,53 @@ package models
 
 import "time"
 
+// Appointment statuses. An appointment starts as requested or scheduled and
+// ends as completed, cancelled or no_show.
+const (
+	AppointmentRequested  = "requested"
+	AppointmentScheduled  = "scheduled"
+	AppointmentConfirmed  = "confirmed"
+	AppointmentCheckedIn  = "checked_in"
+	AppointmentInProgress = "in_progress"
+	AppointmentCompleted  = "completed"
+	AppointmentCancelled  = "cancelled"
+	AppointmentNoShow     = "no_show"
+)
+
+// ActiveAppointmentStatuses are the statuses that occupy a doctor's time and
+// therefore take part in double-booking checks. A request does not hold the
+// slot until it is scheduled.
+var ActiveAppointmentStatuses = []string{
+	AppointmentScheduled,
+	AppointmentConfirmed,
+	AppointmentCheckedIn,
+	AppointmentInProgress,
+}
+
 type Appointment struct {
 	ID        uint      `json:"id" db:"id"`
 	PatientID uint      `json:"patient_id" db:"patient_id" validate:"required"`
 	DoctorID  uint      `json:"doctor_id" db:"doctor_id" validate:"required"`
 	DateTime  time.Time `json:"date_time" db:"date_time" validate:"required"`
 	Duration  int       `json:"duration" db:"duration" validate:"required,min=15,max=240"` // in minutes
-	Status    string    `json:"status" db:"status" validate:"required,oneof=scheduled completed cancelled"`
+	Status    string    `json:"status" db:"status" validate:"required,oneof=requested scheduled confirmed checked_in in_progress completed cancelled no_show"`
 	Notes     *string   `json:"notes" db:"notes"`
-	Diagnosis *string   `json:"diagnosis" db:"diagnosis"`
-	Treatment *string   `json:"treatment" db:"treatment"`
+	Diagnosis *string   `json:"diagnosis" db:"diagnosis" gorm:"serializer:encrypted"` // legacy, superseded by encounter notes
+	Treatment *string   `json:"treatment" db:"treatment" gorm:"serializer:encrypted"` // legacy, superseded by encounter notes
 	CreatedBy uint      `json:"created_by" db:"created_by" validate:"required"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	SeriesID  *uint     `json:"series_id,omitempty" db:"series_id"` // set for occurrences of a recurring series
+
+	// When the appointment entered each lifecycle status
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty" db:"scheduled_at"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CheckedInAt  *time.Time `json:"checked_in_at,omitempty" db:"checked_in_at"`
+	StartedAt    *time.Time `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	NoShowAt     *time.Time `json:"no_show_at,omitempty" db:"no_show_at"`
+	StatusReason *string    `json:"status_reason,omitempty" db:"status_reason"` // why it was cancelled or marked no-show
 
 	// Populated by joins
 	Patient *Patient `gorm:"foreignKey:PatientID"`
@@ -28,17 +62,29 @@ type AppointmentRequest struct {
 	DateTime  string `json:"date_time" validate:"required"` // Will be parsed to time.Time
 	Duration  int    `json:"duration" validate:"required,min=15,max=240"`
 	Notes     string `json:"notes"`
+	// Status is "scheduled" (the default) or "requested" for a booking that
+	// still needs to be approved.
+	Status string `json:"status" validate:"omitempty,oneof=requested scheduled"`
 }
 
 type AppointmentUpdateRequest struct {
-	DateTime  string `json:"date_time"`
-	Duration  int    `json:"duration" validate:"omitempty,min=15,max=240"`
-	Status    string `json:"status" validate:"omitempty,oneof=scheduled completed cancelled"`
-	Notes     string `json:"notes"`
+	DateTime string `json:"date_time"`
+	Duration int    `json:"duration" validate:"omitempty,min=15,max=240"`
+	Status   string `json:"status" validate:"omitempty,oneof=requested scheduled confirmed checked_in in_progress completed cancelled no_show"`
+	Reason   string `json:"reason"` // required when cancelling or marking a no-show
+	Notes    string `json:"notes"`
+	// Diagnosis and Treatment are rejected; clinical findings are recorded
+	// as encounter notes.
 	Diagnosis string `json:"diagnosis"`
 	Treatment string `json:"treatment"`
 }
 
+// AppointmentStatusRequest moves an appointment to another lifecycle status.
+type AppointmentStatusRequest struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason"`
+}
+
 type AppointmentResponse struct {
 	ID        uint             `json:"id"`
 	PatientID uint             `json:"patient_id"`
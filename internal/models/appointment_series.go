@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Recurrence frequencies, named after their RRULE FREQ values.
+const (
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+// Scopes for changing an occurrence of a series.
+const (
+	SeriesScopeThis      = "this"
+	SeriesScopeFollowing = "following"
+	SeriesScopeAll       = "all"
+)
+
+// MaxSeriesOccurrences bounds how many appointments one series may expand to.
+const MaxSeriesOccurrences = 100
+
+// Recurrence is an RRULE-style rule: every Interval days, weeks or months,
+// ending after Count occurrences or on Until, whichever is given.
+type Recurrence struct {
+	Frequency string     `json:"freq" gorm:"column:frequency;size:10;not null"`
+	Interval  int        `json:"interval" gorm:"column:repeat_interval;not null;default:1"`
+	Count     int        `json:"count,omitempty" gorm:"not null;default:0"`
+	Until     *time.Time `json:"until,omitempty"`
+}
+
+// AppointmentSeries is a recurring booking. Each occurrence is stored as an
+// ordinary Appointment linked back through SeriesID. StartsAt, Duration and
+// Notes describe the series as booked; occurrences may have been changed
+// since.
+type AppointmentSeries struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	PatientID  uint       `json:"patient_id" gorm:"not null;index"`
+	DoctorID   uint       `json:"doctor_id" gorm:"not null;index"`
+	StartsAt   time.Time  `json:"starts_at" gorm:"not null"`
+	Duration   int        `json:"duration" gorm:"not null"`
+	Recurrence Recurrence `json:"recurrence" gorm:"embedded"`
+	Notes      *string    `json:"notes"`
+	CreatedBy  uint       `json:"created_by" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	Appointments []*Appointment `json:"appointments,omitempty" gorm:"foreignKey:SeriesID"`
+}
+
+func (AppointmentSeries) TableName() string {
+	return "appointment_series"
+}
+
+// Request types for appointment series
+type AppointmentSeriesRequest struct {
+	PatientID  uint       `json:"patient_id" validate:"required"`
+	DoctorID   uint       `json:"doctor_id" validate:"required"`
+	DateTime   string     `json:"date_time" validate:"required"` // first occurrence, RFC3339
+	Duration   int        `json:"duration" validate:"required,min=15,max=240"`
+	Notes      string     `json:"notes"`
+	Recurrence Recurrence `json:"recurrence"`
+}
+
+// AppointmentSeriesUpdateRequest changes one occurrence, it and the ones
+// after it, or every occurrence. A new date_time moves each affected
+// occurrence by the same amount as the selected one.
+type AppointmentSeriesUpdateRequest struct {
+	Scope    string `json:"scope" validate:"required,oneof=this following all"`
+	DateTime string `json:"date_time"`
+	Duration int    `json:"duration" validate:"omitempty,min=15,max=240"`
+	Notes    string `json:"notes"`
+}
+
+// AppointmentSeriesCancelRequest cancels occurrences of a series.
+type AppointmentSeriesCancelRequest struct {
+	Scope  string `json:"scope" validate:"required,oneof=this following all"`
+	Reason string `json:"reason"`
+}
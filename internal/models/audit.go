@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// Audit actions.
+const (
+	AuditActionView    = "view"
+	AuditActionList    = "list"
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"
+	AuditActionSign    = "sign"
+	AuditActionAmend   = "amend"
+	AuditActionPrint   = "print"
+	AuditActionExport  = "export"
+	AuditActionMerge   = "merge"
+	AuditActionUnmerge = "unmerge"
+	AuditActionRevoke  = "revoke"
+)
+
+// Audited entity names.
+const (
+	AuditEntityPatient     = "patient"
+	AuditEntityAppointment = "appointment"
+
+	AuditEntityAppointmentSeries = "appointment_series"
+	AuditEntityEncounterNote     = "encounter_note"
+	AuditEntityAllergy           = "allergy"
+	AuditEntityMedication        = "medication"
+	AuditEntityProblem           = "problem"
+	AuditEntityLegacyNote        = "legacy_note"
+	AuditEntityPrescription      = "prescription"
+	AuditEntityObservation       = "observation"
+	AuditEntityDuplicate         = "duplicate_candidate"
+	AuditEntityPatientMerge      = "patient_merge"
+	AuditEntityIdentifier        = "patient_identifier"
+	AuditEntityConsent           = "consent"
+)
+
+// AuditLog is an append-only record of a read or write of a medical record.
+type AuditLog struct {
+	ID        uint          `json:"id" gorm:"primaryKey"`
+	ActorID   uint          `json:"actor_id" gorm:"not null;index"`
+	ActorRole string        `json:"actor_role" gorm:"size:20"`
+	Action    string        `json:"action" gorm:"size:20;not null"`
+	Entity    string        `json:"entity" gorm:"size:50;not null"`
+	EntityID  *uint         `json:"entity_id"`
+	PatientID *uint         `json:"patient_id" gorm:"index"`
+	Changes   []FieldChange `json:"changes,omitempty" gorm:"type:jsonb;serializer:json"`
+	ClientIP  string        `json:"client_ip" gorm:"size:45"`
+	CreatedAt time.Time     `json:"created_at" gorm:"index"`
+}
+
+// FieldChange is the before/after value of a single field.
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditQuery filters audit log entries. Zero values are ignored.
+type AuditQuery struct {
+	PatientID uint
+	UserID    uint
+	Entity    string
+	Action    string
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
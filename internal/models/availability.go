@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+// Availability rule kinds. Breaks are carved out of the shifts on the same
+// weekday.
+const (
+	AvailabilityShift = "shift"
+	AvailabilityBreak = "break"
+)
+
+// Availability exception kinds. An unavailable exception removes time (leave,
+// holidays); an available exception adds an extra session on that date.
+const (
+	ExceptionUnavailable = "unavailable"
+	ExceptionAvailable   = "available"
+)
+
+// AvailabilityRule is a weekly recurring shift or break for a doctor. Times
+// are "HH:MM" in the clinic's time zone.
+type AvailabilityRule struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DoctorID  uint      `json:"doctor_id" gorm:"not null;index"`
+	Weekday   int       `json:"weekday" gorm:"not null"` // 0 = Sunday
+	Kind      string    `json:"kind" gorm:"size:20;not null"`
+	StartTime string    `json:"start_time" gorm:"size:5;not null"`
+	EndTime   string    `json:"end_time" gorm:"size:5;not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AvailabilityException overrides a doctor's weekly template on one date.
+// Empty start and end times cover the whole day.
+type AvailabilityException struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	DoctorID  uint      `json:"doctor_id" gorm:"not null;index"`
+	Date      time.Time `json:"date" gorm:"type:date;not null"`
+	Kind      string    `json:"kind" gorm:"size:20;not null"`
+	StartTime string    `json:"start_time" gorm:"size:5"`
+	EndTime   string    `json:"end_time" gorm:"size:5"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DoctorAvailability is a doctor's weekly template and upcoming exceptions.
+type DoctorAvailability struct {
+	DoctorID   uint                     `json:"doctor_id"`
+	Rules      []*AvailabilityRule      `json:"rules"`
+	Exceptions []*AvailabilityException `json:"exceptions"`
+}
+
+// Slot is a free interval in a doctor's schedule.
+type Slot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Request types for availability
+type AvailabilityRuleRequest struct {
+	Weekday   int    `json:"weekday" validate:"min=0,max=6"`
+	Kind      string `json:"kind" validate:"required,oneof=shift break"`
+	StartTime string `json:"start_time" validate:"required"`
+	EndTime   string `json:"end_time" validate:"required"`
+}
+
+type AvailabilityTemplateRequest struct {
+	Rules []AvailabilityRuleRequest `json:"rules"`
+}
+
+type AvailabilityExceptionRequest struct {
+	Date      string `json:"date" validate:"required"` // YYYY-MM-DD
+	Kind      string `json:"kind" validate:"required,oneof=unavailable available"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Reason    string `json:"reason"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+// Consent types.
+const (
+	ConsentTreatment   = "treatment"
+	ConsentDataSharing = "data_sharing"
+	ConsentSMS         = "sms"
+	ConsentEmail       = "email"
+	ConsentResearch    = "research"
+)
+
+// ConsentTypes lists every consent type, in the order statuses are shown.
+var ConsentTypes = []string{ConsentTreatment, ConsentDataSharing, ConsentSMS, ConsentEmail, ConsentResearch}
+
+// Consent is a patient's recorded decision on one consent type, against a
+// version of the consent form. The latest decision of each type signed so
+// far is the one in effect: the patient has consented while it is granted,
+// not expired and not revoked. Earlier decisions are kept as history.
+type Consent struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	PatientID        uint       `json:"patient_id" gorm:"not null;index"`
+	Type             string     `json:"type" gorm:"size:30;not null"`
+	Version          string     `json:"version" gorm:"size:50;not null"`
+	Granted          bool       `json:"granted"`
+	SignedAt         time.Time  `json:"signed_at" gorm:"not null"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	RevokedAt        *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy        *uint      `json:"revoked_by,omitempty"`
+	RevocationReason *string    `json:"revocation_reason,omitempty"`
+	RecordedBy       uint       `json:"recorded_by" gorm:"not null"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// Active reports whether the consent grants permission at the given time.
+func (c *Consent) Active(at time.Time) bool {
+	return c.Granted && c.RevokedAt == nil && !c.SignedAt.After(at) &&
+		(c.ExpiresAt == nil || c.ExpiresAt.After(at))
+}
+
+// ConsentStatus is whether a patient has consented to one type of use and
+// the decision in effect, if any.
+type ConsentStatus struct {
+	Type      string   `json:"type"`
+	Consented bool     `json:"consented"`
+	Consent   *Consent `json:"consent,omitempty"`
+}
+
+// ConsentRequest records a decision. SignedAt and ExpiresAt are YYYY-MM-DD;
+// SignedAt defaults to now and the consent lapses at the start of ExpiresAt.
+// Granted defaults to true, so a refusal must be sent explicitly.
+type ConsentRequest struct {
+	Type      string `json:"type" validate:"required,oneof=treatment data_sharing sms email research"`
+	Version   string `json:"version" validate:"required"`
+	Granted   *bool  `json:"granted"`
+	SignedAt  string `json:"signed_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+type ConsentRevokeRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// TableName returns the table name for Consent model
+func (Consent) TableName() string {
+	return "patient_consents"
+}
@@ -6,19 +6,49 @@ import (
 	"gorm.io/gorm"
 )
 
+// Doctor is the directory profile of a user with role doctor. It is looked up
+// by UserID, which is also the doctor_id appointments refer to. A deleted
+// profile is kept, and does not stop the doctor getting a new one.
 type Doctor struct {
 	ID             uint           `json:"id" gorm:"primaryKey"`
+	UserID         uint           `json:"user_id" gorm:"uniqueIndex:idx_doctors_user_id,where:deleted_at IS NULL;not null"`
 	Name           string         `json:"name" gorm:"not null"`
-	Email          string         `json:"email" gorm:"unique;not null"`
+	Email          string         `json:"email" gorm:"uniqueIndex:idx_doctors_email,where:deleted_at IS NULL;not null"`
 	Phone          string         `json:"phone"`
-	Specialization string         `json:"specialization" gorm:"not null"`
+	Specialization string         `json:"specialization" gorm:"not null;index"`
 	Experience     int            `json:"experience"` // Years of experience
-	LicenseNumber  string         `json:"license_number" gorm:"unique;not null"`
-	Department     string         `json:"department"`
+	LicenseNumber  string         `json:"license_number" gorm:"uniqueIndex:idx_doctors_license_number,where:deleted_at IS NULL;not null"`
+	Department     string         `json:"department" gorm:"index"`
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
 	// Relationships
-	Appointments []Appointment `json:"appointments,omitempty" gorm:"foreignKey:DoctorID"`
+	User *User `json:"user,omitempty" gorm:"foreignKey:UserID"`
+}
+
+// Request types for the doctor directory
+type DoctorRequest struct {
+	UserID         uint   `json:"user_id" validate:"required"`
+	Phone          string `json:"phone"`
+	Specialization string `json:"specialization" validate:"required"`
+	Experience     int    `json:"experience" validate:"min=0"`
+	LicenseNumber  string `json:"license_number" validate:"required"`
+	Department     string `json:"department"`
+}
+
+type DoctorUpdateRequest struct {
+	Phone          string `json:"phone"`
+	Specialization string `json:"specialization"`
+	Experience     *int   `json:"experience" validate:"omitempty,min=0"`
+	LicenseNumber  string `json:"license_number"`
+	Department     string `json:"department"`
+}
+
+// DoctorFilter narrows a directory search. Empty fields are ignored.
+type DoctorFilter struct {
+	Specialization string
+	Department     string
+	Query          string // matches name or email
+	LicenseNumber  string // exact match
 }
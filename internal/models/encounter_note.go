@@ -0,0 +1,88 @@
+package models
+
+import "time"
+
+// Encounter note states. A draft can be edited by its author; a signed note
+// is part of the legal record and can only be amended.
+const (
+	NoteStatusDraft  = "draft"
+	NoteStatusSigned = "signed"
+)
+
+// Sections an amendment can add to. General amendments apply to the note as
+// a whole.
+const (
+	NoteSectionSubjective = "subjective"
+	NoteSectionObjective  = "objective"
+	NoteSectionAssessment = "assessment"
+	NoteSectionPlan       = "plan"
+	NoteSectionGeneral    = "general"
+)
+
+// EncounterNote is a clinician's SOAP note for a patient encounter.
+type EncounterNote struct {
+	ID            uint       `json:"id" gorm:"primaryKey"`
+	PatientID     uint       `json:"patient_id" gorm:"not null;index"`
+	AppointmentID *uint      `json:"appointment_id" gorm:"index"`
+	AuthorID      uint       `json:"author_id" gorm:"not null"`
+	Subjective    string     `json:"subjective" gorm:"serializer:encrypted"`
+	Objective     string     `json:"objective" gorm:"serializer:encrypted"`
+	Assessment    string     `json:"assessment" gorm:"serializer:encrypted"`
+	Plan          string     `json:"plan" gorm:"serializer:encrypted"`
+	Status        string     `json:"status" gorm:"size:10;not null"`
+	Version       int        `json:"version" gorm:"not null"`
+	SignedAt      *time.Time `json:"signed_at"`
+	SignedBy      *uint      `json:"signed_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	Revisions  []*NoteRevision  `json:"revisions,omitempty" gorm:"foreignKey:NoteID"`
+	Amendments []*NoteAmendment `json:"amendments,omitempty" gorm:"foreignKey:NoteID"`
+}
+
+// NoteRevision keeps the content a draft had before each edit, so nothing a
+// clinician wrote is lost.
+type NoteRevision struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	NoteID     uint      `json:"note_id" gorm:"not null;index"`
+	Version    int       `json:"version" gorm:"not null"`
+	Subjective string    `json:"subjective" gorm:"serializer:encrypted"`
+	Objective  string    `json:"objective" gorm:"serializer:encrypted"`
+	Assessment string    `json:"assessment" gorm:"serializer:encrypted"`
+	Plan       string    `json:"plan" gorm:"serializer:encrypted"`
+	EditedBy   uint      `json:"edited_by" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (NoteRevision) TableName() string {
+	return "encounter_note_revisions"
+}
+
+// NoteAmendment is an addition to a signed note. Amendments are append-only.
+type NoteAmendment struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	NoteID    uint      `json:"note_id" gorm:"not null;index"`
+	AuthorID  uint      `json:"author_id" gorm:"not null"`
+	Section   string    `json:"section" gorm:"size:20;not null"`
+	Content   string    `json:"content" gorm:"not null;serializer:encrypted"`
+	Reason    string    `json:"reason" gorm:"not null;serializer:encrypted"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (NoteAmendment) TableName() string {
+	return "encounter_note_amendments"
+}
+
+// Request types for encounter notes
+type EncounterNoteRequest struct {
+	Subjective string `json:"subjective"`
+	Objective  string `json:"objective"`
+	Assessment string `json:"assessment"`
+	Plan       string `json:"plan"`
+}
+
+type NoteAmendmentRequest struct {
+	Section string `json:"section" validate:"required,oneof=subjective objective assessment plan general"`
+	Content string `json:"content" validate:"required"`
+	Reason  string `json:"reason" validate:"required"`
+}
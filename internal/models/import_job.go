@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// Bulk import and export formats.
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Import job statuses. A job is pending until a worker picks it up and ends
+// as completed, even when some rows were rejected, or failed when it could
+// not be processed at all.
+const (
+	ImportJobPending   = "pending"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+// Reasons a row of an import is rejected.
+const (
+	ImportRowInvalid   = "invalid"
+	ImportRowDuplicate = "duplicate"
+)
+
+// MaxImportRowErrors bounds the row errors kept on a job; the counts stay
+// exact beyond it.
+const MaxImportRowErrors = 1000
+
+// ImportJob tracks an asynchronous bulk import of patients.
+type ImportJob struct {
+	ID                 uint             `json:"id" gorm:"primaryKey"`
+	Format             string           `json:"format" gorm:"size:10;not null"`
+	FileName           string           `json:"file_name,omitempty"`
+	Status             string           `json:"status" gorm:"size:20;not null"`
+	TotalRows          int              `json:"total_rows"`
+	CreatedRows        int              `json:"created_rows"`
+	DuplicateRows      int              `json:"duplicate_rows"`
+	InvalidRows        int              `json:"invalid_rows"`
+	RowErrors          []ImportRowError `json:"row_errors" gorm:"type:jsonb;serializer:json"`
+	RowErrorsTruncated bool             `json:"row_errors_truncated"`
+	Error              *string          `json:"error,omitempty"` // why a failed job stopped
+	CreatedBy          uint             `json:"created_by" gorm:"not null"`
+	CreatedAt          time.Time        `json:"created_at"`
+	UpdatedAt          time.Time        `json:"updated_at"`
+	StartedAt          *time.Time       `json:"started_at,omitempty"`
+	FinishedAt         *time.Time       `json:"finished_at,omitempty"`
+}
+
+// ImportRowError explains why one row was not imported. Rows are numbered
+// from 1 in the order they appear, not counting a CSV header or blank
+// lines. Errors maps a PatientRequest field name to what is wrong with it.
+type ImportRowError struct {
+	Row    int               `json:"row"`
+	Type   string            `json:"type"`
+	Errors map[string]string `json:"errors"`
+	// PatientID is the existing patient a duplicate row matches, if it is
+	// already registered.
+	PatientID uint `json:"patient_id,omitempty"`
+}
+
+// AddRowError records a rejected row, keeping at most MaxImportRowErrors.
+func (j *ImportJob) AddRowError(rowError ImportRowError) {
+	switch rowError.Type {
+	case ImportRowDuplicate:
+		j.DuplicateRows++
+	default:
+		j.InvalidRows++
+	}
+	if len(j.RowErrors) >= MaxImportRowErrors {
+		j.RowErrorsTruncated = true
+		return
+	}
+	j.RowErrors = append(j.RowErrors, rowError)
+}
+
+// TableName returns the table name for ImportJob model
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
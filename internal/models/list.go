@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Pagination limits for list endpoints.
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListOptions controls sorting and pagination of a list query. Sort is a
+// field name, prefixed with "-" for descending order. Cursor continues from
+// the previous page's NextCursor; when it is empty, Page (1-based) selects an
+// offset page instead.
+type ListOptions struct {
+	Sort   string
+	Limit  int
+	Cursor string
+	Page   int
+}
+
+// ListResult is the envelope every list endpoint responds with.
+type ListResult[T any] struct {
+	Data       []T    `json:"data"`
+	Total      int64  `json:"total"`
+	Limit      int    `json:"limit"`
+	Page       int    `json:"page,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PatientFilter narrows a patient list. Empty fields are ignored. BornFrom
+// is inclusive and BornTo exclusive.
+type PatientFilter struct {
+	Name     string // matches first or last name
+	Phone    string
+	Gender   string
+	BornFrom time.Time
+	BornTo   time.Time
+}
+
+// AppointmentFilter narrows an appointment list. Every non-zero field must
+// match; From is inclusive and To exclusive. Statuses matches any of the
+// listed statuses and Department matches the doctor's directory profile.
+type AppointmentFilter struct {
+	DoctorID   uint
+	PatientID  uint
+	Statuses   []string
+	From       time.Time
+	To         time.Time
+	Department string
+	CreatedBy  uint
+}
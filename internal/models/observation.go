@@ -0,0 +1,173 @@
+package models
+
+import "time"
+
+// Observation codes. BMI is never recorded; it is computed from weight and
+// height.
+const (
+	ObservationSystolicBP      = "bp_systolic"
+	ObservationDiastolicBP     = "bp_diastolic"
+	ObservationHeartRate       = "heart_rate"
+	ObservationRespiratoryRate = "respiratory_rate"
+	ObservationTemperature     = "temperature"
+	ObservationSpO2            = "spo2"
+	ObservationWeight          = "weight"
+	ObservationHeight          = "height"
+	ObservationBMI             = "bmi"
+)
+
+// Observation flags, from comparing a value with its reference range.
+const (
+	FlagNormal       = "normal"
+	FlagLow          = "low"
+	FlagHigh         = "high"
+	FlagCriticalLow  = "critical_low"
+	FlagCriticalHigh = "critical_high"
+)
+
+// Observation statuses. An observation entered in error stays on record but
+// is left out of trends.
+const (
+	ObservationFinal          = "final"
+	ObservationEnteredInError = "entered_in_error"
+)
+
+// ObservationType describes a kind of observation: its canonical unit, the
+// adult reference range and the critical limits beyond it. Values outside
+// Min and Max are rejected as implausible. Zero limits are unset; a type with
+// neither Low nor High has no reference range and is never flagged.
+type ObservationType struct {
+	Code         string  `json:"code"`
+	Display      string  `json:"display"`
+	Unit         string  `json:"unit"`
+	Low          float64 `json:"low,omitempty"`
+	High         float64 `json:"high,omitempty"`
+	CriticalLow  float64 `json:"critical_low,omitempty"`
+	CriticalHigh float64 `json:"critical_high,omitempty"`
+	Min          float64 `json:"-"`
+	Max          float64 `json:"-"`
+	// Computed types cannot be recorded directly.
+	Computed bool `json:"computed,omitempty"`
+}
+
+// HasRange reports whether the type has a reference range.
+func (t ObservationType) HasRange() bool {
+	return t.Low != 0 || t.High != 0
+}
+
+// Flag classifies value against the type's reference range.
+func (t ObservationType) Flag(value float64) string {
+	switch {
+	case !t.HasRange():
+		return FlagNormal
+	case t.CriticalLow != 0 && value <= t.CriticalLow:
+		return FlagCriticalLow
+	case t.CriticalHigh != 0 && value >= t.CriticalHigh:
+		return FlagCriticalHigh
+	case value < t.Low:
+		return FlagLow
+	case value > t.High:
+		return FlagHigh
+	default:
+		return FlagNormal
+	}
+}
+
+// ObservationTypes lists the observations the API accepts, in display order.
+var ObservationTypes = []ObservationType{
+	{Code: ObservationSystolicBP, Display: "Systolic blood pressure", Unit: "mmHg", Low: 90, High: 140, CriticalLow: 70, CriticalHigh: 180, Min: 30, Max: 300},
+	{Code: ObservationDiastolicBP, Display: "Diastolic blood pressure", Unit: "mmHg", Low: 60, High: 90, CriticalLow: 40, CriticalHigh: 120, Min: 10, Max: 200},
+	{Code: ObservationHeartRate, Display: "Heart rate", Unit: "bpm", Low: 60, High: 100, CriticalLow: 40, CriticalHigh: 130, Min: 10, Max: 300},
+	{Code: ObservationRespiratoryRate, Display: "Respiratory rate", Unit: "breaths/min", Low: 12, High: 20, CriticalLow: 8, CriticalHigh: 30, Min: 1, Max: 80},
+	{Code: ObservationTemperature, Display: "Body temperature", Unit: "°C", Low: 36.1, High: 37.8, CriticalLow: 35, CriticalHigh: 40, Min: 25, Max: 45},
+	{Code: ObservationSpO2, Display: "Oxygen saturation", Unit: "%", Low: 95, High: 100, CriticalLow: 90, Min: 40, Max: 100},
+	{Code: ObservationWeight, Display: "Body weight", Unit: "kg", Min: 0.2, Max: 650},
+	{Code: ObservationHeight, Display: "Body height", Unit: "cm", Min: 20, Max: 280},
+	{Code: ObservationBMI, Display: "Body mass index", Unit: "kg/m2", Low: 18.5, High: 25, Min: 5, Max: 200, Computed: true},
+}
+
+// LookupObservationType returns the type registered under code.
+func LookupObservationType(code string) (ObservationType, bool) {
+	for _, t := range ObservationTypes {
+		if t.Code == code {
+			return t, true
+		}
+	}
+	return ObservationType{}, false
+}
+
+// Observation is one measurement of a patient, optionally taken during an
+// appointment. Value is stored in the type's canonical unit, with the
+// reference range that applied when it was recorded.
+type Observation struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	PatientID     uint      `json:"patient_id" gorm:"not null;index"`
+	AppointmentID *uint     `json:"appointment_id,omitempty" gorm:"index"`
+	Code          string    `json:"code" gorm:"size:30;not null"`
+	Value         float64   `json:"value" gorm:"not null"`
+	Unit          string    `json:"unit" gorm:"size:20;not null"`
+	ReferenceLow  *float64  `json:"reference_low,omitempty"`
+	ReferenceHigh *float64  `json:"reference_high,omitempty"`
+	Flag          string    `json:"flag" gorm:"size:20;not null"`
+	Status        string    `json:"status" gorm:"size:20;not null"`
+	Note          string    `json:"note,omitempty" gorm:"serializer:encrypted"`
+	ErrorReason   *string   `json:"error_reason,omitempty" gorm:"serializer:encrypted"`
+	ObservedAt    time.Time `json:"observed_at" gorm:"not null"`
+	RecordedBy    uint      `json:"recorded_by" gorm:"not null"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ObservationFilter narrows a patient's observations. Empty fields match
+// everything.
+type ObservationFilter struct {
+	Codes []string
+	From  *time.Time
+	To    *time.Time
+}
+
+// Request types for observations. A batch records several values taken at
+// the same time, e.g. a set of vital signs.
+type ObservationValue struct {
+	Code  string  `json:"code" validate:"required"`
+	Value float64 `json:"value"`
+	// Unit defaults to the type's canonical unit; a few common alternatives
+	// (°F, lb, in) are converted.
+	Unit string `json:"unit"`
+	Note string `json:"note"`
+}
+
+type ObservationBatchRequest struct {
+	AppointmentID *uint              `json:"appointment_id"`
+	ObservedAt    *time.Time         `json:"observed_at"`
+	Observations  []ObservationValue `json:"observations" validate:"required,min=1"`
+}
+
+type ObservationErrorRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// TrendPoint is one value in an observation trend.
+type TrendPoint struct {
+	ObservedAt    time.Time `json:"observed_at"`
+	Value         float64   `json:"value"`
+	Flag          string    `json:"flag"`
+	ObservationID uint      `json:"observation_id,omitempty"`
+}
+
+// ObservationTrend is a patient's series of one observation over a date
+// range, oldest first, with summary statistics.
+type ObservationTrend struct {
+	Code    string       `json:"code"`
+	Display string       `json:"display"`
+	Unit    string       `json:"unit"`
+	Low     float64      `json:"reference_low,omitempty"`
+	High    float64      `json:"reference_high,omitempty"`
+	Points  []TrendPoint `json:"points"`
+	Min     *float64     `json:"min,omitempty"`
+	Max     *float64     `json:"max,omitempty"`
+	Mean    *float64     `json:"mean,omitempty"`
+	Latest  *TrendPoint  `json:"latest,omitempty"`
+	// Change is the latest value minus the first in the range.
+	Change *float64 `json:"change,omitempty"`
+}
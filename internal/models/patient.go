@@ -1,23 +1,39 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type Patient struct {
-	ID             uint      `json:"id" db:"id"`
-	FirstName      string    `json:"first_name" db:"first_name" validate:"required"`
-	LastName       string    `json:"last_name" db:"last_name" validate:"required"`
-	Email          *string   `json:"email" db:"email" validate:"omitempty,email"`
-	Phone          string    `json:"phone" db:"phone" validate:"required"`
-	DateOfBirth    time.Time `json:"date_of_birth" db:"date_of_birth" validate:"required"`
-	Gender         string    `json:"gender" db:"gender" validate:"required,oneof=male female other"`
-	Address        *string   `json:"address" db:"address"`
-	MedicalHistory *string   `json:"medical_history" db:"medical_history"`
-	Allergies      *string   `json:"allergies" db:"allergies"`
-	Medications    *string   `json:"medications" db:"medications"`
-	CreatedBy      uint      `json:"created_by" db:"created_by" validate:"required"`
-	UpdatedBy      *uint     `json:"updated_by" db:"updated_by"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID          uint      `json:"id" db:"id"`
+	FirstName   string    `json:"first_name" db:"first_name" validate:"required"`
+	LastName    string    `json:"last_name" db:"last_name" validate:"required"`
+	Email       *string   `json:"email" db:"email" validate:"omitempty,email"`
+	Phone       string    `json:"phone" db:"phone" validate:"required" gorm:"serializer:encrypted"`
+	DateOfBirth time.Time `json:"date_of_birth" db:"date_of_birth" validate:"required"`
+	Gender      string    `json:"gender" db:"gender" validate:"required,oneof=male female other"`
+	Address     *string   `json:"address" db:"address" gorm:"serializer:encrypted"`
+	CreatedBy   uint      `json:"created_by" db:"created_by" validate:"required"`
+	UpdatedBy   *uint     `json:"updated_by" db:"updated_by"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// MRN is the medical record number printed on wristbands and shared
+	// with other systems. It is assigned on registration and never changes.
+	MRN *string `json:"mrn" db:"mrn"`
+	// PhoneIndex is the blind index of the encrypted phone number, the
+	// keyed hash of its digits that lookups by phone compare against.
+	PhoneIndex *string `json:"-" db:"phone_index"`
+	// PhoneSuffixIndex is the blind index of the last PhoneSuffixLength
+	// digits, so a number can be found from its ending or whatever prefix it
+	// was written with.
+	PhoneSuffixIndex *string `json:"-" db:"phone_suffix_index"`
+	// MergedIntoID is set once the patient has been merged into another
+	// chart; merged patients are left out of lists and searches.
+	MergedIntoID *uint `json:"merged_into_id,omitempty" db:"merged_into_id"`
+	// PossibleDuplicates lists likely duplicates found when the patient was
+	// registered. It is not stored with the patient.
+	PossibleDuplicates []DuplicateMatch `json:"possible_duplicates,omitempty" db:"-" gorm:"-"`
 }
 
 // Helper method to get full name
@@ -27,36 +43,73 @@ func (p *Patient) GetFullName() string {
 
 // Request/Response types for API
 type PatientRequest struct {
-	FirstName      string `json:"first_name" validate:"required"`
-	LastName       string `json:"last_name" validate:"required"`
-	Email          string `json:"email" validate:"omitempty,email"`
-	Phone          string `json:"phone" validate:"required"`
-	DateOfBirth    string `json:"date_of_birth" validate:"required"` // Will be parsed to time.Time
-	Gender         string `json:"gender" validate:"required,oneof=male female other"`
-	Address        string `json:"address"`
-	MedicalHistory string `json:"medical_history"`
-	Allergies      string `json:"allergies"`
-	Medications    string `json:"medications"`
+	FirstName   string `json:"first_name" validate:"required"`
+	LastName    string `json:"last_name" validate:"required"`
+	Email       string `json:"email" validate:"omitempty,email"`
+	Phone       string `json:"phone" validate:"required"`
+	DateOfBirth string `json:"date_of_birth" validate:"required"` // Will be parsed to time.Time
+	Gender      string `json:"gender" validate:"required,oneof=male female other"`
+	Address     string `json:"address"`
 }
 
 type PatientResponse struct {
-	ID             uint    `json:"id"`
-	FirstName      string  `json:"first_name"`
-	LastName       string  `json:"last_name"`
-	FullName       string  `json:"full_name"`
-	Email          *string `json:"email"`
-	Phone          string  `json:"phone"`
-	DateOfBirth    string  `json:"date_of_birth"` // Formatted as string for API
-	Gender         string  `json:"gender"`
-	Address        *string `json:"address"`
-	MedicalHistory *string `json:"medical_history"`
-	Allergies      *string `json:"allergies"`
-	Medications    *string `json:"medications"`
-	CreatedAt      string  `json:"created_at"`
-	UpdatedAt      string  `json:"updated_at"`
+	ID          uint    `json:"id"`
+	FirstName   string  `json:"first_name"`
+	LastName    string  `json:"last_name"`
+	FullName    string  `json:"full_name"`
+	Email       *string `json:"email"`
+	Phone       string  `json:"phone"`
+	DateOfBirth string  `json:"date_of_birth"` // Formatted as string for API
+	Gender      string  `json:"gender"`
+	Address     *string `json:"address"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedAt   string  `json:"updated_at"`
+}
+
+// PatientSearchTerm is one term of a patient search. A patient matches the
+// term when any of its non-empty criteria matches.
+type PatientSearchTerm struct {
+	Name   string      // a lower-cased word of a name or email address
+	Digits []string    // phone number digits, tried in turn
+	Year   int         // a year of birth
+	Dates  []time.Time // the possible readings of a date of birth
+}
+
+// PatientSearchResult is one patient found by a search. Highlights maps
+// each matched field to its value, HTML-escaped, with the matching part
+// wrapped in <mark> tags.
+type PatientSearchResult struct {
+	Patient    *Patient          `json:"patient"`
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
 }
 
 // TableName returns the table name for Patient model
 func (Patient) TableName() string {
 	return "patients"
 }
+
+// PhoneDigits returns the digits of a phone number, the form phone numbers
+// are compared and blind-indexed in.
+func PhoneDigits(phone string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+}
+
+// PhoneSuffixLength is how many trailing digits of a phone number are
+// indexed for partial lookups.
+const PhoneSuffixLength = 4
+
+// PhoneSuffix returns the last PhoneSuffixLength digits of a phone number,
+// or "" if it has fewer.
+func PhoneSuffix(phone string) string {
+	digits := PhoneDigits(phone)
+	if len(digits) < PhoneSuffixLength {
+		return ""
+	}
+	return digits[len(digits)-PhoneSuffixLength:]
+}
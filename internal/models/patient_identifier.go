@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Patient identifier types.
+const (
+	IdentifierNationalID = "national_id"
+	IdentifierInsurance  = "insurance"
+	IdentifierExternal   = "external"
+)
+
+// IdentifierSystemMRN is the system name medical record numbers are looked
+// up under. It cannot be used for stored identifiers.
+const IdentifierSystemMRN = "mrn"
+
+// PatientIdentifier is an identifier another system has issued for the
+// patient. System names the issuer, e.g. a country's national ID registry,
+// an insurer or another application; Value is unique within it.
+type PatientIdentifier struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PatientID uint      `json:"patient_id" gorm:"not null;index"`
+	Type      string    `json:"type" gorm:"size:20;not null"`
+	System    string    `json:"system" gorm:"size:100;not null"`
+	Value     string    `json:"value" gorm:"size:100;not null"`
+	CreatedBy uint      `json:"created_by" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PatientIdentifierRequest struct {
+	Type   string `json:"type" validate:"required,oneof=national_id insurance external"`
+	System string `json:"system" validate:"required"`
+	Value  string `json:"value" validate:"required"`
+}
+
+// TableName returns the table name for PatientIdentifier model
+func (PatientIdentifier) TableName() string {
+	return "patient_identifiers"
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Duplicate candidate statuses. An open candidate waits for review; it is
+// dismissed when the patients turn out to be different people and marked
+// merged when one was merged into the other.
+const (
+	DuplicateOpen      = "open"
+	DuplicateDismissed = "dismissed"
+	DuplicateMerged    = "merged"
+)
+
+// Where a duplicate candidate was found.
+const (
+	DuplicateSourceCreate = "create"
+	DuplicateSourceScan   = "scan"
+)
+
+// MatchReason is how similar two patients are in one field, from 0 (no
+// match) to 1 (identical).
+type MatchReason struct {
+	Field      string  `json:"field"`
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateMatch is an existing patient that looks like the same person as
+// the one being compared, with a score from 0 to 100.
+type DuplicateMatch struct {
+	PatientID uint          `json:"patient_id"`
+	Score     int           `json:"score"`
+	Reasons   []MatchReason `json:"reasons"`
+}
+
+// DuplicateCandidate is a pair of patients queued for review as possible
+// duplicates. PatientID is always the lower of the two IDs.
+type DuplicateCandidate struct {
+	ID          uint          `json:"id" gorm:"primaryKey"`
+	PatientID   uint          `json:"patient_id" gorm:"not null"`
+	CandidateID uint          `json:"candidate_id" gorm:"not null"`
+	Score       int           `json:"score" gorm:"not null"`
+	Reasons     []MatchReason `json:"reasons" gorm:"type:jsonb;serializer:json"`
+	Status      string        `json:"status" gorm:"size:20;not null"`
+	Source      string        `json:"source" gorm:"size:10;not null"`
+	ReviewedBy  *uint         `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time    `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+
+	Patient   *Patient `json:"patient,omitempty" gorm:"foreignKey:PatientID"`
+	Candidate *Patient `json:"candidate,omitempty" gorm:"foreignKey:CandidateID"`
+}
+
+func (DuplicateCandidate) TableName() string {
+	return "duplicate_candidates"
+}
+
+// PatientMerge records that MergedID was merged into SurvivorID. Moved lists,
+// per table, the IDs of the rows that were moved to the survivor, so that an
+// unmerge moves exactly those rows back.
+type PatientMerge struct {
+	ID         uint              `json:"id" gorm:"primaryKey"`
+	SurvivorID uint              `json:"survivor_id" gorm:"not null"`
+	MergedID   uint              `json:"merged_id" gorm:"not null"`
+	Moved      map[string][]uint `json:"moved" gorm:"type:jsonb;serializer:json"`
+	MergedBy   uint              `json:"merged_by" gorm:"not null"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UnmergedBy *uint             `json:"unmerged_by,omitempty"`
+	UnmergedAt *time.Time        `json:"unmerged_at,omitempty"`
+}
+
+func (PatientMerge) TableName() string {
+	return "patient_merges"
+}
+
+// MergeRequest names the chart to merge into the one in the URL.
+type MergeRequest struct {
+	MergedID uint `json:"merged_id" validate:"required"`
+}
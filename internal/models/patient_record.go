@@ -0,0 +1,97 @@
+package models
+
+import "time"
+
+// Allergy severities.
+const (
+	SeverityMild            = "mild"
+	SeverityModerate        = "moderate"
+	SeveritySevere          = "severe"
+	SeverityLifeThreatening = "life_threatening"
+)
+
+// Clinical statuses shared by allergies and problems.
+const (
+	ClinicalStatusActive   = "active"
+	ClinicalStatusInactive = "inactive"
+	ClinicalStatusResolved = "resolved"
+)
+
+// Allergy is a substance the patient reacts to.
+type Allergy struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	PatientID  uint      `json:"patient_id" gorm:"not null;index"`
+	Substance  string    `json:"substance" gorm:"not null;serializer:encrypted"`
+	Reaction   string    `json:"reaction" gorm:"serializer:encrypted"`
+	Severity   string    `json:"severity" gorm:"size:20;not null"`
+	Status     string    `json:"status" gorm:"size:20;not null"`
+	RecordedBy uint      `json:"recorded_by" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Medication is a drug the patient takes or has taken. It is current until
+// its stop date.
+type Medication struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	PatientID  uint       `json:"patient_id" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null;serializer:encrypted"`
+	Dose       string     `json:"dose" gorm:"serializer:encrypted"`
+	Route      string     `json:"route" gorm:"serializer:encrypted"`
+	Frequency  string     `json:"frequency" gorm:"serializer:encrypted"`
+	StartDate  *time.Time `json:"start_date" gorm:"type:date"`
+	StopDate   *time.Time `json:"stop_date" gorm:"type:date"`
+	RecordedBy uint       `json:"recorded_by" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// IsCurrent reports whether the medication is still being taken on day.
+func (m *Medication) IsCurrent(day time.Time) bool {
+	return m.StopDate == nil || !m.StopDate.Before(day)
+}
+
+// Problem is an entry on the patient's problem list.
+type Problem struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	PatientID  uint       `json:"patient_id" gorm:"not null;index"`
+	Condition  string     `json:"condition" gorm:"not null;serializer:encrypted"`
+	Onset      *time.Time `json:"onset" gorm:"type:date"`
+	Status     string     `json:"status" gorm:"size:20;not null"`
+	RecordedBy uint       `json:"recorded_by" gorm:"not null"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// PatientLegacyNote holds free text from the retired medical_history,
+// allergies and medications fields, kept for reference.
+type PatientLegacyNote struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	PatientID uint      `json:"patient_id" gorm:"not null;index"`
+	Field     string    `json:"field" gorm:"size:30;not null"`
+	Content   string    `json:"content" gorm:"not null;serializer:encrypted"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Request types for patient records. Dates are YYYY-MM-DD.
+type AllergyRequest struct {
+	Substance string `json:"substance" validate:"required"`
+	Reaction  string `json:"reaction"`
+	Severity  string `json:"severity" validate:"required,oneof=mild moderate severe life_threatening"`
+	Status    string `json:"status" validate:"omitempty,oneof=active inactive resolved"`
+}
+
+type MedicationRequest struct {
+	Name      string `json:"name" validate:"required"`
+	Dose      string `json:"dose"`
+	Route     string `json:"route"`
+	Frequency string `json:"frequency"`
+	StartDate string `json:"start_date"`
+	StopDate  string `json:"stop_date"`
+}
+
+type ProblemRequest struct {
+	Condition string `json:"condition" validate:"required"`
+	Onset     string `json:"onset"`
+	Status    string `json:"status" validate:"omitempty,oneof=active inactive resolved"`
+}
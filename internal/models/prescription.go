@@ -0,0 +1,100 @@
+package models
+
+import "time"
+
+// Prescription statuses.
+const (
+	PrescriptionActive    = "active"
+	PrescriptionCancelled = "cancelled"
+)
+
+// Prescription alert types and severities. A blocking alert stops the
+// prescription unless the prescriber gives an override reason; a warning is
+// recorded and shown but does not stop it.
+const (
+	AlertTypeAllergy          = "allergy"
+	AlertTypeDuplicateTherapy = "duplicate_therapy"
+
+	AlertSeverityBlock = "block"
+	AlertSeverityWarn  = "warn"
+)
+
+// PrescriptionAlert is one finding of the interaction checker.
+type PrescriptionAlert struct {
+	Type     string `json:"type"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Source names what the drug clashed with, e.g. "allergy:12",
+	// "medication:4" or "prescription:9".
+	Source string `json:"source"`
+}
+
+// Prescription is a drug prescribed to a patient during an appointment.
+type Prescription struct {
+	ID            uint   `json:"id" gorm:"primaryKey"`
+	PatientID     uint   `json:"patient_id" gorm:"not null;index"`
+	AppointmentID uint   `json:"appointment_id" gorm:"not null;index"`
+	PrescriberID  uint   `json:"prescriber_id" gorm:"not null"`
+	DrugCode      string `json:"drug_code,omitempty" gorm:"serializer:encrypted"`
+	DrugName      string `json:"drug_name" gorm:"not null;serializer:encrypted"`
+	Dose          string `json:"dose" gorm:"not null;serializer:encrypted"`
+	Route         string `json:"route" gorm:"serializer:encrypted"`
+	Frequency     string `json:"frequency" gorm:"not null;serializer:encrypted"`
+	DurationDays  int    `json:"duration_days"`
+	Quantity      string `json:"quantity" gorm:"serializer:encrypted"`
+	Refills       int    `json:"refills"`
+	Instructions  string `json:"instructions" gorm:"serializer:encrypted"`
+	Status        string `json:"status" gorm:"size:20;not null"`
+
+	// Alerts holds what the checker reported when the prescription was
+	// written; OverrideReason is set when a blocking alert was overridden.
+	// Blocked records whether one was, for the database to require the
+	// reason without reading the encrypted alerts.
+	Alerts         []PrescriptionAlert `json:"alerts" gorm:"serializer:encrypted_json;type:text"`
+	Blocked        bool                `json:"-" gorm:"not null"`
+	OverrideReason *string             `json:"override_reason,omitempty" gorm:"serializer:encrypted"`
+
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CancelledBy  *uint      `json:"cancelled_by,omitempty"`
+	CancelReason *string    `json:"cancel_reason,omitempty" gorm:"serializer:encrypted"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// IsCurrent reports whether the prescription is still being taken on day.
+// Prescriptions without a duration run until cancelled.
+func (p *Prescription) IsCurrent(day time.Time) bool {
+	if p.Status != PrescriptionActive {
+		return false
+	}
+	if p.DurationDays <= 0 {
+		return true
+	}
+	return day.Before(p.CreatedAt.AddDate(0, 0, p.DurationDays))
+}
+
+// Request types for prescriptions.
+type PrescriptionRequest struct {
+	Drug           string `json:"drug" validate:"required"`
+	Dose           string `json:"dose" validate:"required"`
+	Route          string `json:"route"`
+	Frequency      string `json:"frequency" validate:"required"`
+	DurationDays   int    `json:"duration_days" validate:"min=0"`
+	Quantity       string `json:"quantity"`
+	Refills        int    `json:"refills" validate:"min=0"`
+	Instructions   string `json:"instructions"`
+	OverrideReason string `json:"override_reason"`
+}
+
+type PrescriptionCancelRequest struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// PrescriptionCheckResult is what the interaction checker reports for a
+// proposed prescription. Blocked is true when an alert needs an override.
+type PrescriptionCheckResult struct {
+	Drug    string              `json:"drug"`
+	Known   bool                `json:"known"`
+	Blocked bool                `json:"blocked"`
+	Alerts  []PrescriptionAlert `json:"alerts"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// QueueEntry is one patient waiting for, or currently with, a doctor.
+type QueueEntry struct {
+	Position      int  `json:"position"`
+	AppointmentID uint `json:"appointment_id"`
+	PatientID     uint `json:"patient_id"`
+	// PatientName is shortened to first name and last initial so the queue
+	// can be shown on a public waiting-room display.
+	PatientName          string     `json:"patient_name"`
+	Status               string     `json:"status"`
+	ScheduledFor         time.Time  `json:"scheduled_for"`
+	CheckedInAt          *time.Time `json:"checked_in_at"`
+	Duration             int        `json:"duration"`
+	EstimatedStart       time.Time  `json:"estimated_start"`
+	EstimatedWaitMinutes int        `json:"estimated_wait_minutes"`
+}
+
+// DoctorQueue is a doctor's waiting room for the current clinic day: the
+// patient in consultation first, then checked-in patients in the order they
+// will be seen.
+type DoctorQueue struct {
+	DoctorID    uint         `json:"doctor_id"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Entries     []QueueEntry `json:"entries"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Session is a login session. Access tokens carry the session ID and stop
+// being accepted once the session is revoked or expires.
+type Session struct {
+	ID        string     `json:"id" gorm:"primaryKey;size:64"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsActive reports whether the session can still be used at time t.
+func (s *Session) IsActive(t time.Time) bool {
+	return s.RevokedAt == nil && t.Before(s.ExpiresAt)
+}
+
+// RefreshToken is a single-use token that rotates a session's access token.
+// Only a hash of the token is stored.
+type RefreshToken struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	SessionID string     `json:"session_id" gorm:"size:64;not null;index"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
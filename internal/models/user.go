@@ -2,12 +2,22 @@ package models
 
 import "time"
 
+// User roles recognised by the access-control policy.
+const (
+	RoleAdmin        = "admin"
+	RoleDoctor       = "doctor"
+	RoleNurse        = "nurse"
+	RoleReceptionist = "receptionist"
+	RoleStaff        = "staff"
+	RolePatient      = "patient"
+)
+
 type User struct {
 	ID        uint      `json:"id" db:"id"`
-	Name      string    `json:"username" db:"username" validate:"required"`
+	Name      string    `json:"username" db:"username" gorm:"column:username" validate:"required"`
 	Email     string    `json:"email" db:"email" validate:"required,email"`
 	Password  string    `json:"-" db:"password" validate:"required,min=6"` // Hidden from JSON
-	Role      string    `json:"role" db:"role" validate:"required,oneof=receptionist doctor"`
+	Role      string    `json:"role" db:"role" validate:"required,oneof=admin doctor nurse receptionist staff patient"`
 	FirstName string    `json:"first_name" db:"first_name" validate:"required"`
 	LastName  string    `json:"last_name" db:"last_name" validate:"required"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
@@ -26,8 +36,10 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token        string       `json:"token"`
+	RefreshToken string       `json:"refresh_token"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+	User         UserResponse `json:"user"`
 }
 
 type UserResponse struct {
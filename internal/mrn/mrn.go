@@ -0,0 +1,89 @@
+// Package mrn generates and checks medical record numbers.
+//
+// An MRN is a fixed prefix, a numeric facility code, a per-facility
+// sequence number zero-padded to a fixed width and a Luhn check digit over
+// the facility code and sequence number, e.g. "MRN0100004217" for prefix
+// "MRN", facility "01", sequence 421 and seven digits.
+package mrn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Generator formats MRNs for one facility.
+type Generator struct {
+	Prefix   string
+	Facility string
+	Digits   int
+}
+
+// NewGenerator checks the MRN settings. The facility code must be numeric
+// and the sequence between 4 and 12 digits wide.
+func NewGenerator(prefix, facility string, digits int) (*Generator, error) {
+	if strings.ContainsAny(prefix, "0123456789") || strings.TrimSpace(prefix) != prefix {
+		return nil, fmt.Errorf("MRN prefix %q must not contain digits or spaces", prefix)
+	}
+	if facility == "" || !isDigits(facility) {
+		return nil, fmt.Errorf("MRN facility %q must be numeric", facility)
+	}
+	if digits < 4 || digits > 12 {
+		return nil, fmt.Errorf("MRN sequence width must be between 4 and 12 digits, got %d", digits)
+	}
+	if len(prefix)+len(facility)+digits+1 > 32 {
+		return nil, fmt.Errorf("MRNs for prefix %q and facility %q would be longer than 32 characters", prefix, facility)
+	}
+	return &Generator{Prefix: prefix, Facility: facility, Digits: digits}, nil
+}
+
+// Format returns the MRN for a sequence number. It fails once the sequence
+// no longer fits in the configured width.
+func (g *Generator) Format(seq int64) (string, error) {
+	if seq <= 0 {
+		return "", fmt.Errorf("MRN sequence number must be positive, got %d", seq)
+	}
+	number := fmt.Sprintf("%0*d", g.Digits, seq)
+	if len(number) > g.Digits {
+		return "", fmt.Errorf("MRN sequence for facility %s is exhausted at %d digits", g.Facility, g.Digits)
+	}
+	body := g.Facility + number
+	return g.Prefix + body + string('0'+luhnDigit(body)), nil
+}
+
+// Valid reports whether mrn has the generator's prefix, the expected length
+// and a correct check digit. MRNs of other facilities are accepted.
+func (g *Generator) Valid(mrn string) bool {
+	body, ok := strings.CutPrefix(mrn, g.Prefix)
+	if !ok || len(body) < g.Digits+2 || !isDigits(body) {
+		return false
+	}
+	check := body[len(body)-1]
+	return luhnDigit(body[:len(body)-1]) == check-'0'
+}
+
+// luhnDigit returns the Luhn check digit for a string of decimal digits.
+func luhnDigit(digits string) byte {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte((10 - sum%10) % 10)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package mrn
+
+import "testing"
+
+func TestLuhnDigit(t *testing.T) {
+	// Reference values of the Luhn algorithm.
+	tests := map[string]byte{
+		"7992739871": 3,
+		"0":          0,
+		"18":         2,
+		"010000421":  7,
+	}
+	for digits, want := range tests {
+		if got := luhnDigit(digits); got != want {
+			t.Errorf("luhnDigit(%q) = %d, want %d", digits, got, want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	g, err := NewGenerator("MRN", "01", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := g.Format(421)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "MRN0100004217" {
+		t.Errorf("Format(421) = %q, want MRN0100004217", got)
+	}
+
+	if _, err := g.Format(9999999); err != nil {
+		t.Errorf("Format(9999999) failed at the top of the range: %v", err)
+	}
+	if _, err := g.Format(10000000); err == nil {
+		t.Error("Format accepted a sequence number wider than the configured digits")
+	}
+	if _, err := g.Format(0); err == nil {
+		t.Error("Format accepted a zero sequence number")
+	}
+}
+
+func TestValid(t *testing.T) {
+	g, err := NewGenerator("MRN", "01", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seq := int64(1); seq <= 200; seq++ {
+		mrn, err := g.Format(seq)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g.Valid(mrn) {
+			t.Fatalf("Valid(%q) = false for a generated MRN", mrn)
+		}
+	}
+
+	tests := map[string]bool{
+		"MRN0100004217": true,
+		// Another facility's MRN with a correct check digit.
+		"MRN1200004214": true,
+		"MRN0100004218": false, // wrong check digit
+		"MRN0100004271": false, // transposed digits
+		"HMS0100004217": false, // other prefix
+		"MRN010004217":  false, // too short
+		"MRN01000042X7": false,
+		"":              false,
+	}
+	for mrn, want := range tests {
+		if got := g.Valid(mrn); got != want {
+			t.Errorf("Valid(%q) = %v, want %v", mrn, got, want)
+		}
+	}
+}
+
+func TestNewGeneratorRejectsBadSettings(t *testing.T) {
+	tests := []struct {
+		prefix, facility string
+		digits           int
+	}{
+		{"MRN1", "01", 7},
+		{" MRN", "01", 7},
+		{"MRN", "", 7},
+		{"MRN", "A1", 7},
+		{"MRN", "01", 3},
+		{"MRN", "01", 13},
+		{"MEDICAL-RECORD-NUMBER-", "0001", 12},
+	}
+	for _, tt := range tests {
+		if _, err := NewGenerator(tt.prefix, tt.facility, tt.digits); err == nil {
+			t.Errorf("NewGenerator(%q, %q, %d) succeeded, want an error", tt.prefix, tt.facility, tt.digits)
+		}
+	}
+}
@@ -2,17 +2,31 @@
 package repository
 
 import (
+	"errors"
 	"fmt"
 	"hospital-management/internal/models"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 )
 
+// ErrScheduleOverlap is returned when the database's double-booking
+// constraint rejects an appointment that overlaps another active one of the
+// same doctor.
+var ErrScheduleOverlap = errors.New("appointment overlaps another active appointment of the doctor")
+
+// exclusionViolation is the SQLSTATE Postgres reports when an exclusion
+// constraint rejects a row.
+const exclusionViolation = "23P01"
+
 type AppointmentRepositoryImpl struct {
 	db *gorm.DB
 }
 
+// doctorScheduleLockSpace namespaces the advisory locks taken per doctor.
+const doctorScheduleLockSpace = 1001
+
 // internal/repository/appointment_repository.go
 func NewAppointmentRepository(db *gorm.DB) AppointmentRepository {
 	return &AppointmentRepositoryImpl{db: db}
@@ -24,7 +38,7 @@ func (r *AppointmentRepositoryImpl) Create(appointment *models.Appointment) (*mo
 	appointment.UpdatedAt = now
 
 	if err := r.db.Create(appointment).Error; err != nil {
-		return nil, fmt.Errorf("failed to create appointment: %w", err)
+		return nil, scheduleError("failed to create appointment", err)
 	}
 
 	return appointment, nil
@@ -59,6 +73,9 @@ func (r *AppointmentRepositoryImpl) GetAll() ([]*models.Appointment, error) {
 	return appointments, nil
 }
 
+// Update saves an appointment's schedule, status and notes. The legacy
+// diagnosis and treatment columns are never changed: they are read-only,
+// and a map update would store them without encryption.
 func (r *AppointmentRepositoryImpl) Update(appointment *models.Appointment) (*models.Appointment, error) {
 	appointment.UpdatedAt = time.Now()
 
@@ -71,16 +88,33 @@ func (r *AppointmentRepositoryImpl) Update(appointment *models.Appointment) (*mo
 			"duration":   appointment.Duration,
 			"status":     appointment.Status,
 			"notes":      appointment.Notes,
-			"diagnosis":  appointment.Diagnosis,
-			"treatment":  appointment.Treatment,
 			"updated_at": appointment.UpdatedAt,
+
+			"scheduled_at":  appointment.ScheduledAt,
+			"confirmed_at":  appointment.ConfirmedAt,
+			"checked_in_at": appointment.CheckedInAt,
+			"started_at":    appointment.StartedAt,
+			"completed_at":  appointment.CompletedAt,
+			"cancelled_at":  appointment.CancelledAt,
+			"no_show_at":    appointment.NoShowAt,
+			"status_reason": appointment.StatusReason,
 		}).Error; err != nil {
-		return nil, fmt.Errorf("failed to update appointment: %w", err)
+		return nil, scheduleError("failed to update appointment", err)
 	}
 
 	return appointment, nil
 }
 
+// scheduleError wraps a failed write with msg, reporting a rejection by
+// the double-booking constraint as ErrScheduleOverlap.
+func scheduleError(msg string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == exclusionViolation {
+		return fmt.Errorf("%s: %w", msg, ErrScheduleOverlap)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
 func (r *AppointmentRepositoryImpl) Delete(id uint) error {
 	result := r.db.Delete(&models.Appointment{}, id)
 	if result.Error != nil {
@@ -163,7 +197,7 @@ func (r *AppointmentRepositoryImpl) GetUpcomingAppointments(doctorID uint, days
 
 	err := r.db.
 		Preload("Patient").
-		Where("doctor_id = ? AND date_time >= ? AND date_time <= ? AND status = ?", doctorID, now, future, "scheduled").
+		Where("doctor_id = ? AND date_time >= ? AND date_time <= ? AND status IN ?", doctorID, now, future, models.ActiveAppointmentStatuses).
 		Order("date_time ASC").
 		Find(&appointments).Error
 
@@ -193,52 +227,159 @@ func (r *AppointmentRepositoryImpl) GetTodaysAppointments(doctorID uint) ([]*mod
 	return appointments, nil
 }
 
-func (r *AppointmentRepositoryImpl) GetWithPagination(offset, limit int) ([]*models.Appointment, error) {
-	var appointments []*models.Appointment
+// appointmentListSpec lists the fields appointments can be sorted by.
+var appointmentListSpec = listSpec[*models.Appointment]{
+	table: "appointments",
+	sorts: map[string]sortColumn[*models.Appointment]{
+		"id":         {column: "id", value: func(a *models.Appointment) interface{} { return int64(a.ID) }},
+		"date_time":  {column: "date_time", value: func(a *models.Appointment) interface{} { return a.DateTime }},
+		"created_at": {column: "created_at", value: func(a *models.Appointment) interface{} { return a.CreatedAt }},
+	},
+	defaultSort: "-date_time",
+	id:          func(a *models.Appointment) uint { return a.ID },
+	preloads:    []string{"Patient", "Doctor"},
+}
 
-	err := r.db.
-		Preload("Patient").
-		Preload("Doctor").
-		Order("date_time DESC").
-		Limit(limit).
-		Offset(offset).
-		Find(&appointments).Error
+// List returns one page of appointments matching every given filter.
+func (r *AppointmentRepositoryImpl) List(filter *models.AppointmentFilter, opts *models.ListOptions) (*models.ListResult[*models.Appointment], error) {
+	result, err := paginate(r.filter(filter), appointmentListSpec, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appointments: %w", err)
+	}
+	return result, nil
+}
 
+// EachBatch streams the appointments matching the filter in batches, in ID
+// order. Patients and doctors are not loaded.
+func (r *AppointmentRepositoryImpl) EachBatch(filter *models.AppointmentFilter, batchSize int, fn func([]*models.Appointment) error) error {
+	var batch []*models.Appointment
+	err := r.filter(filter).FindInBatches(&batch, batchSize, func(*gorm.DB, int) error {
+		return fn(batch)
+	}).Error
 	if err != nil {
-		return nil, fmt.Errorf("failed to get appointments with pagination: %w", err)
+		return fmt.Errorf("failed to read appointments: %w", err)
 	}
+	return nil
+}
 
-	return appointments, nil
+// filter returns a query for the appointments matching every given filter.
+func (r *AppointmentRepositoryImpl) filter(filter *models.AppointmentFilter) *gorm.DB {
+	query := r.db.Model(&models.Appointment{})
+	if filter.DoctorID != 0 {
+		query = query.Where("appointments.doctor_id = ?", filter.DoctorID)
+	}
+	if filter.PatientID != 0 {
+		query = query.Where("appointments.patient_id = ?", filter.PatientID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("appointments.date_time >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("appointments.date_time < ?", filter.To)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("appointments.status IN ?", filter.Statuses)
+	}
+	if filter.Department != "" {
+		query = query.Where(
+			"appointments.doctor_id IN (SELECT user_id FROM doctors WHERE department ILIKE ? AND deleted_at IS NULL)",
+			filter.Department,
+		)
+	}
+	if filter.CreatedBy != 0 {
+		query = query.Where("appointments.created_by = ?", filter.CreatedBy)
+	}
+	return query
 }
 
-func (r *AppointmentRepositoryImpl) Count() (int64, error) {
-	var count int64
-	err := r.db.Model(&models.Appointment{}).Count(&count).Error
-	if err != nil {
-		return 0, fmt.Errorf("failed to count appointments: %w", err)
+// Transaction runs fn against a repository bound to a single database
+// transaction. The transaction commits if fn returns nil.
+func (r *AppointmentRepositoryImpl) Transaction(fn func(tx AppointmentRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&AppointmentRepositoryImpl{db: tx})
+	})
+}
+
+// LockDoctorSchedule takes a transaction-scoped advisory lock on the doctor's
+// schedule so concurrent bookings for the same doctor are serialized. It must
+// be called inside Transaction.
+func (r *AppointmentRepositoryImpl) LockDoctorSchedule(doctorID uint) error {
+	if err := r.db.Exec("SELECT pg_advisory_xact_lock(?, ?)", doctorScheduleLockSpace, int32(doctorID)).Error; err != nil {
+		return fmt.Errorf("failed to lock doctor schedule: %w", err)
 	}
-	return count, nil
+	return nil
 }
 
-func (r *AppointmentRepositoryImpl) CheckConflict(doctorID uint, dateTime time.Time, duration int, excludeID uint) (bool, error) {
+// FindConflicts returns the doctor's active appointments that overlap the
+// interval [dateTime, dateTime+duration). excludeID skips the appointment
+// being rescheduled.
+func (r *AppointmentRepositoryImpl) FindConflicts(doctorID uint, dateTime time.Time, duration int, excludeID uint) ([]*models.Appointment, error) {
 	endTime := dateTime.Add(time.Duration(duration) * time.Minute)
 
-	var count int64
-	err := r.db.Model(&models.Appointment{}).
-		Where("doctor_id = ? AND status = ? AND id != ?", doctorID, "scheduled", excludeID).
-		Where(`
-			(date_time <= ? AND date_time + interval '1 minute' * duration > ?) OR 
-			(date_time < ? AND date_time + interval '1 minute' * duration >= ?) OR 
-			(date_time >= ? AND date_time < ?)`,
-			dateTime, dateTime,
-			endTime, endTime,
-			dateTime, endTime,
-		).
-		Count(&count).Error
+	var conflicts []*models.Appointment
+	err := r.db.
+		Where("doctor_id = ? AND status IN ? AND id != ?", doctorID, models.ActiveAppointmentStatuses, excludeID).
+		Where("date_time < ? AND date_time + interval '1 minute' * duration > ?", endTime, dateTime).
+		Order("date_time ASC").
+		Find(&conflicts).Error
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to check appointment conflict: %w", err)
+	}
+
+	return conflicts, nil
+}
+
+// CreateSeries inserts the series record. Its occurrences are created
+// separately with Create.
+func (r *AppointmentRepositoryImpl) CreateSeries(series *models.AppointmentSeries) (*models.AppointmentSeries, error) {
+	if err := r.db.Omit("Appointments").Create(series).Error; err != nil {
+		return nil, fmt.Errorf("failed to create appointment series: %w", err)
+	}
+	return series, nil
+}
+
+// GetSeries returns a series with its occurrences in date order.
+func (r *AppointmentRepositoryImpl) GetSeries(id uint) (*models.AppointmentSeries, error) {
+	var series models.AppointmentSeries
+	err := r.db.
+		Preload("Appointments", func(db *gorm.DB) *gorm.DB { return db.Order("date_time ASC") }).
+		First(&series, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("appointment series with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get appointment series: %w", err)
+	}
+	return &series, nil
+}
 
+// GetSeriesOccurrences returns the series' occurrences starting at or after
+// from, in date order.
+func (r *AppointmentRepositoryImpl) GetSeriesOccurrences(seriesID uint, from time.Time) ([]*models.Appointment, error) {
+	var appointments []*models.Appointment
+	err := r.db.
+		Where("series_id = ? AND date_time >= ?", seriesID, from).
+		Order("date_time ASC").
+		Find(&appointments).Error
 	if err != nil {
-		return false, fmt.Errorf("failed to check appointment conflict: %w", err)
+		return nil, fmt.Errorf("failed to get series occurrences: %w", err)
 	}
+	return appointments, nil
+}
 
-	return count > 0, nil
+// GetDoctorQueue returns the doctor's checked-in and in-progress appointments
+// scheduled within [from, to), in the order they will be seen.
+func (r *AppointmentRepositoryImpl) GetDoctorQueue(doctorID uint, from, to time.Time) ([]*models.Appointment, error) {
+	var appointments []*models.Appointment
+	err := r.db.
+		Preload("Patient").
+		Where("doctor_id = ? AND status IN ? AND date_time >= ? AND date_time < ?",
+			doctorID, []string{models.AppointmentCheckedIn, models.AppointmentInProgress}, from, to).
+		Order("date_time ASC, checked_in_at ASC, id ASC").
+		Find(&appointments).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor queue: %w", err)
+	}
+	return appointments, nil
 }
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestScheduleErrorDetectsExclusionViolation(t *testing.T) {
+	err := scheduleError("failed to create appointment", &pgconn.PgError{
+		Code:           exclusionViolation,
+		ConstraintName: "appointments_no_double_booking",
+	})
+	if !errors.Is(err, ErrScheduleOverlap) {
+		t.Fatalf("got %v, want ErrScheduleOverlap", err)
+	}
+
+	err = scheduleError("failed to create appointment", &pgconn.PgError{Code: "23503"})
+	if errors.Is(err, ErrScheduleOverlap) {
+		t.Fatal("a foreign key violation was reported as an overlap")
+	}
+}
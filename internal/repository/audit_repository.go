@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository stores audit log entries. Entries can only be appended and
+// queried; there is deliberately no update or delete.
+type AuditRepository interface {
+	Create(entry *models.AuditLog) (*models.AuditLog, error)
+	Find(query *models.AuditQuery) ([]*models.AuditLog, error)
+}
+
+// AuditRepositoryImpl implements AuditRepository using GORM.
+type AuditRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository creates a new AuditRepository.
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &AuditRepositoryImpl{db: db}
+}
+
+// Create appends an entry to the audit log.
+func (r *AuditRepositoryImpl) Create(entry *models.AuditLog) (*models.AuditLog, error) {
+	if err := r.db.Create(entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return entry, nil
+}
+
+// Find returns entries matching the query, newest first.
+func (r *AuditRepositoryImpl) Find(query *models.AuditQuery) ([]*models.AuditLog, error) {
+	db := r.db.Model(&models.AuditLog{})
+	if query.PatientID != 0 {
+		db = db.Where("patient_id = ?", query.PatientID)
+	}
+	if query.UserID != 0 {
+		db = db.Where("actor_id = ?", query.UserID)
+	}
+	if query.Entity != "" {
+		db = db.Where("entity = ?", query.Entity)
+	}
+	if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("created_at < ?", query.To)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+
+	var entries []*models.AuditLog
+	if err := db.Order("created_at DESC, id DESC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AvailabilityRepository stores doctors' weekly templates and exceptions.
+type AvailabilityRepository interface {
+	GetRules(doctorID uint) ([]*models.AvailabilityRule, error)
+	ReplaceRules(doctorID uint, rules []*models.AvailabilityRule) ([]*models.AvailabilityRule, error)
+	GetExceptions(doctorID uint, from, to time.Time) ([]*models.AvailabilityException, error)
+	CreateException(exception *models.AvailabilityException) (*models.AvailabilityException, error)
+	DeleteException(doctorID, id uint) error
+}
+
+// AvailabilityRepositoryImpl implements AvailabilityRepository using GORM.
+type AvailabilityRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewAvailabilityRepository creates a new AvailabilityRepository.
+func NewAvailabilityRepository(db *gorm.DB) AvailabilityRepository {
+	return &AvailabilityRepositoryImpl{db: db}
+}
+
+// GetRules returns the doctor's weekly template ordered by weekday and time.
+func (r *AvailabilityRepositoryImpl) GetRules(doctorID uint) ([]*models.AvailabilityRule, error) {
+	var rules []*models.AvailabilityRule
+	if err := r.db.Where("doctor_id = ?", doctorID).
+		Order("weekday ASC, start_time ASC").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("failed to get availability rules: %w", err)
+	}
+	return rules, nil
+}
+
+// ReplaceRules swaps the doctor's whole weekly template in one transaction.
+func (r *AvailabilityRepositoryImpl) ReplaceRules(doctorID uint, rules []*models.AvailabilityRule) ([]*models.AvailabilityRule, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("doctor_id = ?", doctorID).Delete(&models.AvailabilityRule{}).Error; err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		return tx.Create(&rules).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace availability rules: %w", err)
+	}
+	return rules, nil
+}
+
+// GetExceptions returns the doctor's exceptions dated within [from, to].
+func (r *AvailabilityRepositoryImpl) GetExceptions(doctorID uint, from, to time.Time) ([]*models.AvailabilityException, error) {
+	var exceptions []*models.AvailabilityException
+	if err := r.db.Where("doctor_id = ? AND date BETWEEN ? AND ?", doctorID, from, to).
+		Order("date ASC, start_time ASC").
+		Find(&exceptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get availability exceptions: %w", err)
+	}
+	return exceptions, nil
+}
+
+// CreateException inserts a new exception.
+func (r *AvailabilityRepositoryImpl) CreateException(exception *models.AvailabilityException) (*models.AvailabilityException, error) {
+	if err := r.db.Create(exception).Error; err != nil {
+		return nil, fmt.Errorf("failed to create availability exception: %w", err)
+	}
+	return exception, nil
+}
+
+// DeleteException removes one of the doctor's exceptions.
+func (r *AvailabilityRepositoryImpl) DeleteException(doctorID, id uint) error {
+	result := r.db.Where("doctor_id = ?", doctorID).Delete(&models.AvailabilityException{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete availability exception: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("availability exception with id %d not found", id)
+	}
+	return nil
+}
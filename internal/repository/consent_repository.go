@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ConsentRepository stores patients' consent decisions. Decisions are only
+// ever added or revoked, never edited.
+type ConsentRepository interface {
+	Create(consent *models.Consent) (*models.Consent, error)
+	Get(patientID, id uint) (*models.Consent, error)
+	// GetByPatientID returns the patient's decisions, newest first.
+	GetByPatientID(patientID uint) ([]*models.Consent, error)
+	// GetInEffect returns the patient's decision in effect at the given time
+	// for each consent type, keyed by type.
+	GetInEffect(patientID uint, at time.Time) (map[string]*models.Consent, error)
+	// GetInEffectForPatients returns the decision of one consent type in
+	// effect at the given time for each of the patients that has one, keyed
+	// by patient ID.
+	GetInEffectForPatients(patientIDs []uint, consentType string, at time.Time) (map[uint]*models.Consent, error)
+	// Revoke saves the revocation of a consent that was not revoked yet.
+	Revoke(consent *models.Consent) (*models.Consent, error)
+}
+
+// ConsentRepositoryImpl implements ConsentRepository using GORM.
+type ConsentRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewConsentRepository creates a new ConsentRepository.
+func NewConsentRepository(db *gorm.DB) ConsentRepository {
+	return &ConsentRepositoryImpl{db: db}
+}
+
+func (r *ConsentRepositoryImpl) Create(consent *models.Consent) (*models.Consent, error) {
+	if err := r.db.Create(consent).Error; err != nil {
+		return nil, fmt.Errorf("failed to create consent: %w", err)
+	}
+	return consent, nil
+}
+
+func (r *ConsentRepositoryImpl) Get(patientID, id uint) (*models.Consent, error) {
+	var consent models.Consent
+	if err := r.db.Where("patient_id = ?", patientID).First(&consent, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("consent with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get consent: %w", err)
+	}
+	return &consent, nil
+}
+
+func (r *ConsentRepositoryImpl) GetByPatientID(patientID uint) ([]*models.Consent, error) {
+	var consents []*models.Consent
+	err := r.db.Where("patient_id = ?", patientID).Order("signed_at DESC, id DESC").Find(&consents).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consents for patient: %w", err)
+	}
+	return consents, nil
+}
+
+// GetInEffect picks the latest decision signed by the given time of each
+// type; whether it grants consent is left to the caller.
+func (r *ConsentRepositoryImpl) GetInEffect(patientID uint, at time.Time) (map[string]*models.Consent, error) {
+	var consents []*models.Consent
+	err := r.db.
+		Select("DISTINCT ON (type) *").
+		Where("patient_id = ? AND signed_at <= ?", patientID, at).
+		Order("type, signed_at DESC, id DESC").
+		Find(&consents).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consents in effect: %w", err)
+	}
+	byType := make(map[string]*models.Consent, len(consents))
+	for _, consent := range consents {
+		byType[consent.Type] = consent
+	}
+	return byType, nil
+}
+
+func (r *ConsentRepositoryImpl) GetInEffectForPatients(patientIDs []uint, consentType string, at time.Time) (map[uint]*models.Consent, error) {
+	byPatient := make(map[uint]*models.Consent, len(patientIDs))
+	if len(patientIDs) == 0 {
+		return byPatient, nil
+	}
+	var consents []*models.Consent
+	err := r.db.
+		Select("DISTINCT ON (patient_id) *").
+		Where("patient_id IN ? AND type = ? AND signed_at <= ?", patientIDs, consentType, at).
+		Order("patient_id, signed_at DESC, id DESC").
+		Find(&consents).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consents in effect: %w", err)
+	}
+	for _, consent := range consents {
+		byPatient[consent.PatientID] = consent
+	}
+	return byPatient, nil
+}
+
+func (r *ConsentRepositoryImpl) Revoke(consent *models.Consent) (*models.Consent, error) {
+	result := r.db.Model(&models.Consent{}).
+		Where("id = ? AND revoked_at IS NULL", consent.ID).
+		Updates(map[string]interface{}{
+			"revoked_at":        consent.RevokedAt,
+			"revoked_by":        consent.RevokedBy,
+			"revocation_reason": consent.RevocationReason,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to revoke consent: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("consent with id %d is already revoked", consent.ID)
+	}
+	return consent, nil
+}
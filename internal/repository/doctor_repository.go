@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"hospital-management/internal/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// ErrDoctorProfileTaken is returned when another doctor profile already has
+// the user, email or license number.
+var ErrDoctorProfileTaken = errors.New("another doctor profile has the same user, email or license number")
+
+// uniqueViolation is the SQLSTATE Postgres reports when a unique index
+// rejects a row.
+const uniqueViolation = "23505"
+
+// DoctorRepositoryImpl implements DoctorRepository using GORM.
+type DoctorRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewDoctorRepository creates a new DoctorRepository.
+func NewDoctorRepository(db *gorm.DB) DoctorRepository {
+	return &DoctorRepositoryImpl{db: db}
+}
+
+// Create inserts a new doctor profile.
+func (r *DoctorRepositoryImpl) Create(doctor *models.Doctor) (*models.Doctor, error) {
+	if err := r.db.Create(doctor).Error; err != nil {
+		return nil, doctorWriteError("failed to create doctor", err)
+	}
+	return doctor, nil
+}
+
+// GetByID retrieves a doctor profile by its own ID.
+func (r *DoctorRepositoryImpl) GetByID(id uint) (*models.Doctor, error) {
+	var doctor models.Doctor
+	if err := r.db.Preload("User").First(&doctor, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("doctor with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get doctor: %w", err)
+	}
+	return &doctor, nil
+}
+
+// GetByUserID retrieves the profile linked to a user account.
+func (r *DoctorRepositoryImpl) GetByUserID(userID uint) (*models.Doctor, error) {
+	var doctor models.Doctor
+	if err := r.db.Preload("User").Where("user_id = ?", userID).First(&doctor).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("doctor profile for user %d not found", userID)
+		}
+		return nil, fmt.Errorf("failed to get doctor: %w", err)
+	}
+	return &doctor, nil
+}
+
+// GetByEmail retrieves a doctor profile by email.
+func (r *DoctorRepositoryImpl) GetByEmail(email string) (*models.Doctor, error) {
+	var doctor models.Doctor
+	if err := r.db.Preload("User").Where("email = ?", email).First(&doctor).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("doctor with email %s not found", email)
+		}
+		return nil, fmt.Errorf("failed to get doctor by email: %w", err)
+	}
+	return &doctor, nil
+}
+
+// Update modifies an existing doctor profile.
+func (r *DoctorRepositoryImpl) Update(doctor *models.Doctor) (*models.Doctor, error) {
+	if err := r.db.Omit("User").Save(doctor).Error; err != nil {
+		return nil, doctorWriteError("failed to update doctor", err)
+	}
+	return doctor, nil
+}
+
+// doctorWriteError wraps a failed write with msg, reporting a clash with
+// another profile as ErrDoctorProfileTaken.
+func doctorWriteError(msg string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return fmt.Errorf("%s: %w", msg, ErrDoctorProfileTaken)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// Delete soft-deletes a doctor profile by ID.
+func (r *DoctorRepositoryImpl) Delete(id uint) error {
+	result := r.db.Delete(&models.Doctor{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete doctor: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("doctor with id %d not found", id)
+	}
+	return nil
+}
+
+// GetAll retrieves every doctor profile ordered by name.
+func (r *DoctorRepositoryImpl) GetAll() ([]*models.Doctor, error) {
+	return r.Search(&models.DoctorFilter{})
+}
+
+// Search finds doctors by specialization, department and name or email
+// (all case-insensitive) and by license number, ordered by name.
+func (r *DoctorRepositoryImpl) Search(filter *models.DoctorFilter) ([]*models.Doctor, error) {
+	db := r.db.Preload("User")
+	if filter.Specialization != "" {
+		db = db.Where("specialization ILIKE ?", filter.Specialization)
+	}
+	if filter.Department != "" {
+		db = db.Where("department ILIKE ?", filter.Department)
+	}
+	if filter.Query != "" {
+		pattern := containsPattern(filter.Query)
+		db = db.Where("name ILIKE ? OR email ILIKE ?", pattern, pattern)
+	}
+	if filter.LicenseNumber != "" {
+		db = db.Where("license_number = ?", filter.LicenseNumber)
+	}
+
+	var doctors []*models.Doctor
+	if err := db.Order("name ASC").Find(&doctors).Error; err != nil {
+		return nil, fmt.Errorf("failed to search doctors: %w", err)
+	}
+	return doctors, nil
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestDoctorWriteErrorDetectsUniqueViolation(t *testing.T) {
+	err := doctorWriteError("failed to create doctor", &pgconn.PgError{Code: uniqueViolation, ConstraintName: "idx_doctors_license_number"})
+	if !errors.Is(err, ErrDoctorProfileTaken) {
+		t.Fatalf("got %v, want ErrDoctorProfileTaken", err)
+	}
+
+	err = doctorWriteError("failed to create doctor", errors.New("connection reset"))
+	if errors.Is(err, ErrDoctorProfileTaken) {
+		t.Fatal("a connection error was reported as a clash")
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"hospital-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrNoteChanged is returned when a draft was edited or signed by someone
+// else since it was read.
+var ErrNoteChanged = errors.New("encounter note was changed by another request")
+
+// EncounterNoteRepository stores encounter notes with their revisions and
+// amendments. Revisions and amendments can only be appended.
+type EncounterNoteRepository interface {
+	Create(note *models.EncounterNote) (*models.EncounterNote, error)
+	GetByID(id uint) (*models.EncounterNote, error)
+	GetByPatientID(patientID uint) ([]*models.EncounterNote, error)
+	GetByAppointmentID(appointmentID uint) ([]*models.EncounterNote, error)
+	// UpdateDraft saves the edited draft and the revision holding its
+	// previous content. It fails with ErrNoteChanged unless the stored note
+	// is still the draft at revision.Version.
+	UpdateDraft(note *models.EncounterNote, revision *models.NoteRevision) (*models.EncounterNote, error)
+	// Sign marks a draft as signed. It fails with ErrNoteChanged if the note
+	// is no longer the draft at note.Version.
+	Sign(note *models.EncounterNote) (*models.EncounterNote, error)
+	AddAmendment(amendment *models.NoteAmendment) (*models.NoteAmendment, error)
+}
+
+// EncounterNoteRepositoryImpl implements EncounterNoteRepository using GORM.
+type EncounterNoteRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewEncounterNoteRepository creates a new EncounterNoteRepository.
+func NewEncounterNoteRepository(db *gorm.DB) EncounterNoteRepository {
+	return &EncounterNoteRepositoryImpl{db: db}
+}
+
+// Create inserts a new note.
+func (r *EncounterNoteRepositoryImpl) Create(note *models.EncounterNote) (*models.EncounterNote, error) {
+	if err := r.db.Omit("Revisions", "Amendments").Create(note).Error; err != nil {
+		return nil, fmt.Errorf("failed to create encounter note: %w", err)
+	}
+	return note, nil
+}
+
+// GetByID returns a note with its revisions and amendments, oldest first.
+func (r *EncounterNoteRepositoryImpl) GetByID(id uint) (*models.EncounterNote, error) {
+	var note models.EncounterNote
+	err := r.db.
+		Preload("Revisions", func(db *gorm.DB) *gorm.DB { return db.Order("version ASC") }).
+		Preload("Amendments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC, id ASC") }).
+		First(&note, id).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("encounter note with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get encounter note: %w", err)
+	}
+	return &note, nil
+}
+
+// GetByPatientID returns the patient's notes, newest first, with their
+// amendments.
+func (r *EncounterNoteRepositoryImpl) GetByPatientID(patientID uint) ([]*models.EncounterNote, error) {
+	var notes []*models.EncounterNote
+	err := r.db.
+		Preload("Amendments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC, id ASC") }).
+		Where("patient_id = ?", patientID).
+		Order("created_at DESC").
+		Find(&notes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encounter notes for patient: %w", err)
+	}
+	return notes, nil
+}
+
+// GetByAppointmentID returns the notes written for an appointment, oldest
+// first, with their amendments.
+func (r *EncounterNoteRepositoryImpl) GetByAppointmentID(appointmentID uint) ([]*models.EncounterNote, error) {
+	var notes []*models.EncounterNote
+	err := r.db.
+		Preload("Amendments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC, id ASC") }).
+		Where("appointment_id = ?", appointmentID).
+		Order("created_at ASC").
+		Find(&notes).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get encounter notes for appointment: %w", err)
+	}
+	return notes, nil
+}
+
+func (r *EncounterNoteRepositoryImpl) UpdateDraft(note *models.EncounterNote, revision *models.NoteRevision) (*models.EncounterNote, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		note.UpdatedAt = time.Now()
+		// Updated from the struct rather than a map so that the note's
+		// text goes through the encrypting serializer.
+		result := tx.Model(&models.EncounterNote{}).
+			Where("id = ? AND status = ? AND version = ?", note.ID, models.NoteStatusDraft, revision.Version).
+			Select("subjective", "objective", "assessment", "plan", "version", "updated_at").
+			Updates(note)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrNoteChanged
+		}
+		return tx.Create(revision).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrNoteChanged) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update encounter note: %w", err)
+	}
+	return note, nil
+}
+
+func (r *EncounterNoteRepositoryImpl) Sign(note *models.EncounterNote) (*models.EncounterNote, error) {
+	result := r.db.Model(&models.EncounterNote{}).
+		Where("id = ? AND status = ? AND version = ?", note.ID, models.NoteStatusDraft, note.Version).
+		Updates(map[string]interface{}{
+			"status":     note.Status,
+			"signed_at":  note.SignedAt,
+			"signed_by":  note.SignedBy,
+			"updated_at": note.UpdatedAt,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to sign encounter note: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrNoteChanged
+	}
+	return note, nil
+}
+
+// AddAmendment appends an amendment to a note.
+func (r *EncounterNoteRepositoryImpl) AddAmendment(amendment *models.NoteAmendment) (*models.NoteAmendment, error) {
+	if err := r.db.Create(amendment).Error; err != nil {
+		return nil, fmt.Errorf("failed to add note amendment: %w", err)
+	}
+	return amendment, nil
+}
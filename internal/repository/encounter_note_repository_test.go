@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"hospital-management/internal/encryption"
+	"hospital-management/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, mock
+}
+
+// capturedArg matches any argument and keeps it.
+type capturedArg struct {
+	value driver.Value
+}
+
+func (a *capturedArg) Match(v driver.Value) bool {
+	a.value = v
+	return true
+}
+
+func TestUpdateDraftEncryptsNoteText(t *testing.T) {
+	useTestKeyring(t)
+	db, mock := newMockDB(t)
+
+	note := &models.EncounterNote{
+		ID:         3,
+		Subjective: "chest pain",
+		Objective:  "BP 150/95",
+		Assessment: "hypertension",
+		Plan:       "start amlodipine",
+		Status:     models.NoteStatusDraft,
+		Version:    2,
+	}
+	revision := &models.NoteRevision{NoteID: 3, Version: 1, Subjective: "chest pain", EditedBy: 7, CreatedAt: time.Now()}
+
+	text := []*capturedArg{{}, {}, {}, {}}
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "encounter_notes" SET "subjective"=\$1,"objective"=\$2,"assessment"=\$3,"plan"=\$4,"version"=\$5,"updated_at"=\$6`).
+		WithArgs(text[0], text[1], text[2], text[3], 2, sqlmock.AnyArg(), 3, models.NoteStatusDraft, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	revisionSubjective := &capturedArg{}
+	mock.ExpectQuery(`INSERT INTO "encounter_note_revisions"`).
+		WithArgs(3, 1, revisionSubjective, "", "", "", 7, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectCommit()
+
+	if _, err := NewEncounterNoteRepository(db).UpdateDraft(note, revision); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	k := encryption.Active()
+	want := map[string]string{"subjective": note.Subjective, "objective": note.Objective, "assessment": note.Assessment, "plan": note.Plan}
+	for i, column := range []string{"subjective", "objective", "assessment", "plan"} {
+		stored, _ := text[i].value.(string)
+		if !strings.HasPrefix(stored, encryption.Prefix) {
+			t.Errorf("%s written as %v, want an encrypted value", column, text[i].value)
+			continue
+		}
+		if got, err := k.Decrypt(stored, column); err != nil || got != want[column] {
+			t.Errorf("%s decrypts to %q, %v", column, got, err)
+		}
+	}
+	if stored, _ := revisionSubjective.value.(string); !strings.HasPrefix(stored, encryption.Prefix) {
+		t.Errorf("revision subjective written as %v, want an encrypted value", revisionSubjective.value)
+	}
+}
+
+func TestLatestObservationsDecryptsNotes(t *testing.T) {
+	useTestKeyring(t)
+	db, mock := newMockDB(t)
+
+	note, err := encryption.Active().Encrypt("taken after exercise", "note")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.ExpectQuery(`SELECT DISTINCT ON \(code\) \*`).
+		WithArgs(5, models.ObservationFinal).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "patient_id", "code", "value", "note"}).
+			AddRow(1, 5, models.ObservationHeartRate, 112.0, note))
+
+	observations, err := NewObservationRepository(db).Latest(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(observations) != 1 || observations[0].Note != "taken after exercise" {
+		t.Fatalf("observations = %+v, want the decrypted note", observations)
+	}
+}
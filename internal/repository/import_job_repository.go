@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ImportJobRepository stores bulk import jobs.
+type ImportJobRepository interface {
+	Create(job *models.ImportJob) (*models.ImportJob, error)
+	GetByID(id uint) (*models.ImportJob, error)
+	Update(job *models.ImportJob) (*models.ImportJob, error)
+	// FailUnfinished marks every pending or running job as failed with the
+	// given reason and returns how many there were.
+	FailUnfinished(reason string) (int64, error)
+}
+
+// ImportJobRepositoryImpl implements ImportJobRepository using GORM.
+type ImportJobRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewImportJobRepository creates a new ImportJobRepository.
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &ImportJobRepositoryImpl{db: db}
+}
+
+func (r *ImportJobRepositoryImpl) Create(job *models.ImportJob) (*models.ImportJob, error) {
+	if err := r.db.Create(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to create import job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *ImportJobRepositoryImpl) GetByID(id uint) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := r.db.First(&job, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("import job with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *ImportJobRepositoryImpl) Update(job *models.ImportJob) (*models.ImportJob, error) {
+	if err := r.db.Save(job).Error; err != nil {
+		return nil, fmt.Errorf("failed to update import job: %w", err)
+	}
+	return job, nil
+}
+
+func (r *ImportJobRepositoryImpl) FailUnfinished(reason string) (int64, error) {
+	result := r.db.Model(&models.ImportJob{}).
+		Where("status IN ?", []string{models.ImportJobPending, models.ImportJobRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.ImportJobFailed,
+			"error":       reason,
+			"finished_at": time.Now(),
+		})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to fail unfinished import jobs: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -12,17 +12,28 @@ type AppointmentRepository interface {
 	GetByPatientID(patientID uint) ([]*models.Appointment, error)
 	GetByDoctorID(doctorID uint) ([]*models.Appointment, error)
 	GetByDateRange(start, end time.Time) ([]*models.Appointment, error)
+	List(filter *models.AppointmentFilter, opts *models.ListOptions) (*models.ListResult[*models.Appointment], error)
+	EachBatch(filter *models.AppointmentFilter, batchSize int, fn func([]*models.Appointment) error) error
 	Update(appointment *models.Appointment) (*models.Appointment, error)
 	Delete(id uint) error
+	FindConflicts(doctorID uint, dateTime time.Time, duration int, excludeID uint) ([]*models.Appointment, error)
+	LockDoctorSchedule(doctorID uint) error
+	Transaction(fn func(tx AppointmentRepository) error) error
+	CreateSeries(series *models.AppointmentSeries) (*models.AppointmentSeries, error)
+	GetSeries(id uint) (*models.AppointmentSeries, error)
+	GetSeriesOccurrences(seriesID uint, from time.Time) ([]*models.Appointment, error)
+	GetDoctorQueue(doctorID uint, from, to time.Time) ([]*models.Appointment, error)
 }
 
 type DoctorRepository interface {
 	Create(doctor *models.Doctor) (*models.Doctor, error)
 	GetByID(id uint) (*models.Doctor, error)
+	GetByUserID(userID uint) (*models.Doctor, error)
 	GetByEmail(email string) (*models.Doctor, error)
 	Update(doctor *models.Doctor) (*models.Doctor, error)
 	Delete(id uint) error
 	GetAll() ([]*models.Doctor, error)
+	Search(filter *models.DoctorFilter) ([]*models.Doctor, error)
 }
 type AuthService interface {
 	Login(req *models.LoginRequest) (string, error)
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hospital-management/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidListQuery is returned for an unknown sort field or a malformed
+// cursor.
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// sortColumn maps an API sort field to a column and extracts that field from
+// a row so the next cursor can be built. value must return a time.Time,
+// string or int64.
+type sortColumn[T any] struct {
+	column string
+	value  func(T) interface{}
+}
+
+// listSpec describes how a repository's rows can be sorted and paginated.
+type listSpec[T any] struct {
+	table       string
+	sorts       map[string]sortColumn[T]
+	defaultSort string
+	id          func(T) uint
+	preloads    []string
+}
+
+// listCursor is the decoded form of a keyset pagination cursor: the sort
+// field it was issued for, and the sort value and ID of the last row seen.
+type listCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	Kind  string `json:"k"`
+	ID    uint   `json:"id"`
+}
+
+// paginate applies sorting and cursor or page pagination to an already
+// filtered query and returns one page with the total number of matches.
+// Rows are always ordered by the sort column and then by ID so the cursor is
+// stable when sort values repeat.
+func paginate[T any](query *gorm.DB, spec listSpec[T], opts *models.ListOptions) (*models.ListResult[T], error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = models.DefaultListLimit
+	}
+	if limit > models.MaxListLimit {
+		limit = models.MaxListLimit
+	}
+
+	sortKey := opts.Sort
+	if sortKey == "" {
+		sortKey = spec.defaultSort
+	}
+	desc := strings.HasPrefix(sortKey, "-")
+	field := strings.TrimPrefix(sortKey, "-")
+	sort, ok := spec.sorts[field]
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot sort by %q", ErrInvalidListQuery, field)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count results: %w", err)
+	}
+
+	direction, cmp := "ASC", ">"
+	if desc {
+		direction, cmp = "DESC", "<"
+	}
+	column := spec.table + "." + sort.column
+	idColumn := spec.table + ".id"
+
+	page := query.Session(&gorm.Session{})
+	for _, association := range spec.preloads {
+		page = page.Preload(association)
+	}
+	result := &models.ListResult[T]{Total: total, Limit: limit}
+
+	if opts.Cursor != "" {
+		cursor, value, err := decodeCursor(opts.Cursor)
+		if err != nil || cursor.Sort != sortKey {
+			return nil, fmt.Errorf("%w: bad cursor", ErrInvalidListQuery)
+		}
+		page = page.Where(
+			fmt.Sprintf("(%s %s ?) OR (%s = ? AND %s %s ?)", column, cmp, column, idColumn, cmp),
+			value, value, cursor.ID,
+		)
+	} else if opts.Page > 1 {
+		page = page.Offset((opts.Page - 1) * limit)
+		result.Page = opts.Page
+	}
+
+	var rows []T
+	if err := page.
+		Order(fmt.Sprintf("%s %s, %s %s", column, direction, idColumn, direction)).
+		Limit(limit + 1).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list results: %w", err)
+	}
+
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[len(rows)-1]
+		result.NextCursor = encodeCursor(sortKey, sort.value(last), spec.id(last))
+	}
+	if rows == nil {
+		rows = []T{}
+	}
+	result.Data = rows
+
+	return result, nil
+}
+
+func encodeCursor(sortKey string, value interface{}, id uint) string {
+	cursor := listCursor{Sort: sortKey, ID: id}
+	switch v := value.(type) {
+	case time.Time:
+		cursor.Kind, cursor.Value = "time", v.UTC().Format(time.RFC3339Nano)
+	case int64:
+		cursor.Kind, cursor.Value = "int", fmt.Sprint(v)
+	default:
+		cursor.Kind, cursor.Value = "string", fmt.Sprint(v)
+	}
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(encoded string) (*listCursor, interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, nil, err
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, nil, err
+	}
+
+	switch cursor.Kind {
+	case "time":
+		t, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		return &cursor, t, err
+	case "int":
+		var n int64
+		_, err := fmt.Sscan(cursor.Value, &n)
+		return &cursor, n, err
+	default:
+		return &cursor, cursor.Value, nil
+	}
+}
+
+// likeEscaper escapes the LIKE metacharacters with PostgreSQL's default
+// escape character, the backslash.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns a LIKE pattern matching values that contain s
+// literally.
+func containsPattern(s string) string {
+	return "%" + likeEscaper.Replace(s) + "%"
+}
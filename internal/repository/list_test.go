@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type listRow struct {
+	ID        uint
+	Name      string
+	Rank      int64
+	CreatedAt time.Time
+}
+
+var listRowSpec = listSpec[*listRow]{
+	table: "list_rows",
+	sorts: map[string]sortColumn[*listRow]{
+		"name":       {column: "name", value: func(r *listRow) interface{} { return r.Name }},
+		"rank":       {column: "rank", value: func(r *listRow) interface{} { return r.Rank }},
+		"created_at": {column: "created_at", value: func(r *listRow) interface{} { return r.CreatedAt }},
+	},
+	defaultSort: "-created_at",
+	id:          func(r *listRow) uint { return r.ID },
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	precise := time.Date(2026, 3, 1, 9, 30, 15, 123456789, time.FixedZone("CET", 3600))
+	tests := []struct {
+		sort  string
+		value interface{}
+	}{
+		{"-created_at", precise},
+		{"created_at", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"rank", int64(42)},
+		{"-rank", int64(-7)},
+		{"rank", int64(1) << 62},
+		{"name", "O'Brien"},
+		{"-name", "Zoë: \"quoted\", with , commas"},
+		{"name", ""},
+	}
+	for _, tt := range tests {
+		cursor, value, err := decodeCursor(encodeCursor(tt.sort, tt.value, 17))
+		if err != nil {
+			t.Errorf("%v: %v", tt.value, err)
+			continue
+		}
+		if cursor.Sort != tt.sort || cursor.ID != 17 {
+			t.Errorf("%v: cursor = %+v, want sort %q and ID 17", tt.value, cursor, tt.sort)
+		}
+		if want, ok := tt.value.(time.Time); ok {
+			got, ok := value.(time.Time)
+			if !ok || !got.Equal(want) {
+				t.Errorf("time cursor = %v, want %v to the nanosecond", value, want)
+			}
+			continue
+		}
+		if value != tt.value {
+			t.Errorf("cursor value = %#v, want %#v", value, tt.value)
+		}
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, encoded := range []string{"not base64!", base64.RawURLEncoding.EncodeToString([]byte("not json"))} {
+		if _, _, err := decodeCursor(encoded); err == nil {
+			t.Errorf("decodeCursor(%q) accepted a malformed cursor", encoded)
+		}
+	}
+
+	// A cursor claiming an int or time that does not parse is rejected.
+	for _, kind := range []string{"int", "time"} {
+		encoded := mustCursorJSON(t, &listCursor{Sort: "rank", Kind: kind, Value: "x", ID: 1})
+		if _, _, err := decodeCursor(encoded); err == nil {
+			t.Errorf("a %s cursor with value %q was accepted", kind, "x")
+		}
+	}
+}
+
+func expectCount(mock sqlmock.Sqlmock, total int) {
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "list_rows"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(total))
+}
+
+func TestPaginateRejectsCursorForAnotherSort(t *testing.T) {
+	db, mock := newMockDB(t)
+	cursor := encodeCursor("-created_at", time.Now(), 3)
+
+	for _, sort := range []string{"created_at", "-name", "rank"} {
+		expectCount(mock, 10)
+		_, err := paginate(db.Model(&listRow{}), listRowSpec, &models.ListOptions{Sort: sort, Cursor: cursor})
+		if !errors.Is(err, ErrInvalidListQuery) {
+			t.Errorf("sort %q with a -created_at cursor: got %v, want ErrInvalidListQuery", sort, err)
+		}
+	}
+	for _, bad := range []string{"garbage", encodeCursor("-created_at", "yesterday", 3)[:10]} {
+		expectCount(mock, 10)
+		if _, err := paginate(db.Model(&listRow{}), listRowSpec, &models.ListOptions{Cursor: bad}); !errors.Is(err, ErrInvalidListQuery) {
+			t.Errorf("cursor %q: got %v, want ErrInvalidListQuery", bad, err)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPaginateRejectsUnknownSort(t *testing.T) {
+	db, _ := newMockDB(t)
+	if _, err := paginate(db.Model(&listRow{}), listRowSpec, &models.ListOptions{Sort: "password"}); !errors.Is(err, ErrInvalidListQuery) {
+		t.Fatalf("got %v, want ErrInvalidListQuery", err)
+	}
+}
+
+func TestPaginateDescendingCursor(t *testing.T) {
+	db, mock := newMockDB(t)
+	last := time.Date(2026, 3, 1, 9, 30, 15, 123456789, time.UTC)
+	opts := &models.ListOptions{Limit: 2, Cursor: encodeCursor("-created_at", last, 9)}
+
+	expectCount(mock, 5)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "list_rows" WHERE name <> $1 AND ((list_rows.created_at < $2) OR (list_rows.created_at = $3 AND list_rows.id < $4)) ORDER BY list_rows.created_at DESC, list_rows.id DESC LIMIT $5`)).
+		WithArgs("", last, last, 9, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "created_at"}).
+			AddRow(8, "b", last).
+			AddRow(4, "c", last.Add(-time.Second)).
+			AddRow(2, "d", last.Add(-time.Minute)))
+
+	result, err := paginate(db.Model(&listRow{}).Where("name <> ?", ""), listRowSpec, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Total != 5 || len(result.Data) != 2 || result.Data[0].ID != 8 || result.Data[1].ID != 4 {
+		t.Fatalf("result = %+v, want rows 8 and 4 of 5", result)
+	}
+	next, value, err := decodeCursor(result.NextCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Sort != "-created_at" || next.ID != 4 || !value.(time.Time).Equal(last.Add(-time.Second)) {
+		t.Fatalf("next cursor = %+v %v, want the last row returned", next, value)
+	}
+}
+
+func TestPaginateAscendingCursor(t *testing.T) {
+	db, mock := newMockDB(t)
+	opts := &models.ListOptions{Sort: "rank", Limit: 2, Cursor: encodeCursor("rank", int64(10), 9)}
+
+	expectCount(mock, 2)
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE (list_rows.rank > $1) OR (list_rows.rank = $2 AND list_rows.id > $3) ORDER BY list_rows.rank ASC, list_rows.id ASC LIMIT $4`)).
+		WithArgs(int64(10), int64(10), 9, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "rank"}).AddRow(12, 10).AddRow(3, 11))
+
+	result, err := paginate(db.Model(&listRow{}), listRowSpec, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Data) != 2 || result.NextCursor != "" {
+		t.Fatalf("result = %+v, want the last page without a next cursor", result)
+	}
+}
+
+func mustCursorJSON(t *testing.T, cursor *listCursor) string {
+	t.Helper()
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func TestContainsPattern(t *testing.T) {
+	tests := map[string]string{
+		"smith":   "%smith%",
+		"%":       `%\%%`,
+		"o_brien": `%o\_brien%`,
+		`a\b`:     `%a\\b%`,
+		`50%_\`:   `%50\%\_\\%`,
+	}
+	for in, want := range tests {
+		if got := containsPattern(in); got != want {
+			t.Errorf("containsPattern(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
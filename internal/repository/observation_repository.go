@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ObservationRepository stores patient observations.
+type ObservationRepository interface {
+	// CreateBatch inserts observations taken together, all or none.
+	CreateBatch(observations []*models.Observation) ([]*models.Observation, error)
+	GetByID(patientID, id uint) (*models.Observation, error)
+	// List returns the patient's final observations matching filter, oldest
+	// first.
+	List(patientID uint, filter *models.ObservationFilter) ([]*models.Observation, error)
+	// Latest returns the most recent final observation of each code.
+	Latest(patientID uint) ([]*models.Observation, error)
+	Update(observation *models.Observation) (*models.Observation, error)
+}
+
+// ObservationRepositoryImpl implements ObservationRepository using GORM.
+type ObservationRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewObservationRepository creates a new ObservationRepository.
+func NewObservationRepository(db *gorm.DB) ObservationRepository {
+	return &ObservationRepositoryImpl{db: db}
+}
+
+func (r *ObservationRepositoryImpl) CreateBatch(observations []*models.Observation) ([]*models.Observation, error) {
+	if err := r.db.Create(&observations).Error; err != nil {
+		return nil, fmt.Errorf("failed to create observations: %w", err)
+	}
+	return observations, nil
+}
+
+func (r *ObservationRepositoryImpl) GetByID(patientID, id uint) (*models.Observation, error) {
+	return getPatientRecord[models.Observation](r.db, patientID, id, "observation")
+}
+
+func (r *ObservationRepositoryImpl) List(patientID uint, filter *models.ObservationFilter) ([]*models.Observation, error) {
+	query := r.db.Where("patient_id = ? AND status = ?", patientID, models.ObservationFinal)
+	if len(filter.Codes) > 0 {
+		query = query.Where("code IN ?", filter.Codes)
+	}
+	if filter.From != nil {
+		query = query.Where("observed_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("observed_at < ?", *filter.To)
+	}
+
+	var observations []*models.Observation
+	if err := query.Order("observed_at ASC, id ASC").Find(&observations).Error; err != nil {
+		return nil, fmt.Errorf("failed to get observations: %w", err)
+	}
+	return observations, nil
+}
+
+func (r *ObservationRepositoryImpl) Latest(patientID uint) ([]*models.Observation, error) {
+	var observations []*models.Observation
+	err := r.db.Raw(`
+		SELECT DISTINCT ON (code) *
+		FROM observations
+		WHERE patient_id = ? AND status = ?
+		ORDER BY code, observed_at DESC, id DESC`, patientID, models.ObservationFinal).
+		Scan(&observations).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest observations: %w", err)
+	}
+	return observations, nil
+}
+
+func (r *ObservationRepositoryImpl) Update(observation *models.Observation) (*models.Observation, error) {
+	return updatePatientRecord(r.db, observation, "observation")
+}
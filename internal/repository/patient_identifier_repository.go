@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"hospital-management/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrIdentifierTaken is returned when an identifier's value is already
+// recorded for a patient within the same system.
+var ErrIdentifierTaken = errors.New("identifier is already assigned to a patient in this system")
+
+// PatientIdentifierRepository stores the identifiers other systems have
+// issued for patients.
+type PatientIdentifierRepository interface {
+	// Create fails with ErrIdentifierTaken if the system already has the
+	// value.
+	Create(identifier *models.PatientIdentifier) (*models.PatientIdentifier, error)
+	GetByPatientID(patientID uint) ([]*models.PatientIdentifier, error)
+	Get(patientID, id uint) (*models.PatientIdentifier, error)
+	// Find returns the identifier with the value in the system.
+	Find(system, value string) (*models.PatientIdentifier, error)
+	Delete(patientID, id uint) error
+}
+
+// PatientIdentifierRepositoryImpl implements PatientIdentifierRepository
+// using GORM.
+type PatientIdentifierRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPatientIdentifierRepository creates a new PatientIdentifierRepository.
+func NewPatientIdentifierRepository(db *gorm.DB) PatientIdentifierRepository {
+	return &PatientIdentifierRepositoryImpl{db: db}
+}
+
+// Create relies on the (system, value) unique constraint rather than a
+// prior lookup, so two requests racing for the same value cannot both win.
+func (r *PatientIdentifierRepositoryImpl) Create(identifier *models.PatientIdentifier) (*models.PatientIdentifier, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "system"}, {Name: "value"}},
+		DoNothing: true,
+	}).Create(identifier)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to create patient identifier: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrIdentifierTaken
+	}
+	return identifier, nil
+}
+
+// GetByPatientID returns the patient's identifiers ordered by system.
+func (r *PatientIdentifierRepositoryImpl) GetByPatientID(patientID uint) ([]*models.PatientIdentifier, error) {
+	var identifiers []*models.PatientIdentifier
+	if err := r.db.Where("patient_id = ?", patientID).Order("system, id").Find(&identifiers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get patient identifiers: %w", err)
+	}
+	return identifiers, nil
+}
+
+func (r *PatientIdentifierRepositoryImpl) Get(patientID, id uint) (*models.PatientIdentifier, error) {
+	var identifier models.PatientIdentifier
+	if err := r.db.Where("patient_id = ?", patientID).First(&identifier, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("patient identifier with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get patient identifier: %w", err)
+	}
+	return &identifier, nil
+}
+
+func (r *PatientIdentifierRepositoryImpl) Find(system, value string) (*models.PatientIdentifier, error) {
+	var identifier models.PatientIdentifier
+	if err := r.db.Where("system = ? AND value = ?", system, value).First(&identifier).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("no patient has identifier %s in system %s", value, system)
+		}
+		return nil, fmt.Errorf("failed to find patient identifier: %w", err)
+	}
+	return &identifier, nil
+}
+
+func (r *PatientIdentifierRepositoryImpl) Delete(patientID, id uint) error {
+	result := r.db.Where("patient_id = ?", patientID).Delete(&models.PatientIdentifier{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete patient identifier: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("patient identifier with id %d not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"hospital-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPatientMerged is returned when a merge involves a patient that has
+// already been merged into another chart.
+var ErrPatientMerged = errors.New("patient has already been merged into another patient")
+
+// ErrMergeUndone is returned when a merge that was already undone is undone
+// again.
+var ErrMergeUndone = errors.New("merge has already been undone")
+
+// mergedTables are the tables whose rows belong to a patient and move with
+// them on a merge. The audit log is deliberately absent: it records which
+// chart was touched at the time. A merged patient keeps its MRN, which still
+// resolves to it and through MergedIntoID to the surviving chart.
+var mergedTables = []string{
+	"appointment_series",
+	"appointments",
+	"encounter_notes",
+	"allergies",
+	"medications",
+	"problems",
+	"patient_legacy_notes",
+	"prescriptions",
+	"observations",
+	"patient_identifiers",
+	"patient_consents",
+}
+
+// PatientMergeRepository stores duplicate candidates and performs patient
+// merges.
+type PatientMergeRepository interface {
+	// SaveCandidate records a possible duplicate pair. A pair already on
+	// file keeps its status; an open one gets the new score and reasons.
+	SaveCandidate(candidate *models.DuplicateCandidate) error
+	GetCandidate(id uint) (*models.DuplicateCandidate, error)
+	// ListCandidates returns one page of candidates with the given status
+	// whose patients are both unmerged, with the two patients loaded.
+	ListCandidates(status string, opts *models.ListOptions) (*models.ListResult[*models.DuplicateCandidate], error)
+	UpdateCandidate(candidate *models.DuplicateCandidate) (*models.DuplicateCandidate, error)
+	// Merge moves every row of merge.MergedID to merge.SurvivorID, marks
+	// the merged patient and closes their candidate pair in one
+	// transaction, filling in merge.ID and merge.Moved. It fails with
+	// ErrPatientMerged if either patient is already merged.
+	Merge(merge *models.PatientMerge) error
+	// Unmerge moves the rows listed in merge.Moved back to the merged
+	// patient, reactivates them and dismisses their candidate pair. Rows
+	// added to the survivor since the merge stay with the survivor.
+	Unmerge(merge *models.PatientMerge) error
+	GetMerge(id uint) (*models.PatientMerge, error)
+	GetMergesForPatient(patientID uint) ([]*models.PatientMerge, error)
+}
+
+// PatientMergeRepositoryImpl implements PatientMergeRepository using GORM.
+type PatientMergeRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPatientMergeRepository creates a new PatientMergeRepository.
+func NewPatientMergeRepository(db *gorm.DB) PatientMergeRepository {
+	return &PatientMergeRepositoryImpl{db: db}
+}
+
+func (r *PatientMergeRepositoryImpl) SaveCandidate(candidate *models.DuplicateCandidate) error {
+	err := r.db.Omit("Patient", "Candidate").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "patient_id"}, {Name: "candidate_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"score", "reasons", "updated_at"}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: "duplicate_candidates", Name: "status"}, Value: models.DuplicateOpen},
+		}},
+	}).Create(candidate).Error
+	if err != nil {
+		return fmt.Errorf("failed to save duplicate candidate: %w", err)
+	}
+	return nil
+}
+
+func (r *PatientMergeRepositoryImpl) GetCandidate(id uint) (*models.DuplicateCandidate, error) {
+	var candidate models.DuplicateCandidate
+	if err := r.db.Preload("Patient").Preload("Candidate").First(&candidate, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("duplicate candidate with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get duplicate candidate: %w", err)
+	}
+	return &candidate, nil
+}
+
+// candidateListSpec lists the fields duplicate candidates can be sorted by.
+var candidateListSpec = listSpec[*models.DuplicateCandidate]{
+	table: "duplicate_candidates",
+	sorts: map[string]sortColumn[*models.DuplicateCandidate]{
+		"id":         {column: "id", value: func(c *models.DuplicateCandidate) interface{} { return int64(c.ID) }},
+		"score":      {column: "score", value: func(c *models.DuplicateCandidate) interface{} { return int64(c.Score) }},
+		"created_at": {column: "created_at", value: func(c *models.DuplicateCandidate) interface{} { return c.CreatedAt }},
+	},
+	defaultSort: "-score",
+	id:          func(c *models.DuplicateCandidate) uint { return c.ID },
+	preloads:    []string{"Patient", "Candidate"},
+}
+
+func (r *PatientMergeRepositoryImpl) ListCandidates(status string, opts *models.ListOptions) (*models.ListResult[*models.DuplicateCandidate], error) {
+	active := r.db.Model(&models.Patient{}).Select("id").Where("merged_into_id IS NULL")
+	query := r.db.Model(&models.DuplicateCandidate{}).
+		Where("patient_id IN (?) AND candidate_id IN (?)", active, active)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	result, err := paginate(query, candidateListSpec, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list duplicate candidates: %w", err)
+	}
+	return result, nil
+}
+
+func (r *PatientMergeRepositoryImpl) UpdateCandidate(candidate *models.DuplicateCandidate) (*models.DuplicateCandidate, error) {
+	if err := r.db.Omit("Patient", "Candidate").Save(candidate).Error; err != nil {
+		return nil, fmt.Errorf("failed to update duplicate candidate: %w", err)
+	}
+	return candidate, nil
+}
+
+func (r *PatientMergeRepositoryImpl) Merge(merge *models.PatientMerge) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock both charts so neither can be merged elsewhere meanwhile.
+		var active []uint
+		err := tx.Model(&models.Patient{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND merged_into_id IS NULL", []uint{merge.SurvivorID, merge.MergedID}).
+			Order("id").
+			Pluck("id", &active).Error
+		if err != nil {
+			return err
+		}
+		if len(active) != 2 {
+			return ErrPatientMerged
+		}
+
+		moved, err := movePatientRows(tx, merge.MergedID, merge.SurvivorID, nil)
+		if err != nil {
+			return err
+		}
+		merge.Moved = moved
+
+		err = tx.Model(&models.Patient{}).Where("id = ?", merge.MergedID).
+			Updates(map[string]interface{}{"merged_into_id": merge.SurvivorID, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(merge).Error; err != nil {
+			return err
+		}
+		return reviewPair(tx, merge.SurvivorID, merge.MergedID, models.DuplicateMerged, merge.MergedBy, merge.CreatedAt)
+	})
+	if err != nil {
+		if errors.Is(err, ErrPatientMerged) {
+			return err
+		}
+		return fmt.Errorf("failed to merge patients: %w", err)
+	}
+	return nil
+}
+
+func (r *PatientMergeRepositoryImpl) Unmerge(merge *models.PatientMerge) error {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PatientMerge{}).
+			Where("id = ? AND unmerged_at IS NULL", merge.ID).
+			Updates(map[string]interface{}{"unmerged_by": merge.UnmergedBy, "unmerged_at": merge.UnmergedAt})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrMergeUndone
+		}
+
+		// The survivor must not have been merged away itself, or its rows
+		// now belong to yet another chart.
+		var active []uint
+		err := tx.Model(&models.Patient{}).
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND merged_into_id IS NULL", merge.SurvivorID).
+			Pluck("id", &active).Error
+		if err != nil {
+			return err
+		}
+		if len(active) == 0 {
+			return ErrPatientMerged
+		}
+
+		if _, err := movePatientRows(tx, merge.SurvivorID, merge.MergedID, merge.Moved); err != nil {
+			return err
+		}
+		err = tx.Model(&models.Patient{}).Where("id = ?", merge.MergedID).
+			Updates(map[string]interface{}{"merged_into_id": nil, "updated_at": time.Now()}).Error
+		if err != nil {
+			return err
+		}
+		return reviewPair(tx, merge.SurvivorID, merge.MergedID, models.DuplicateDismissed, *merge.UnmergedBy, *merge.UnmergedAt)
+	})
+	if err != nil {
+		if errors.Is(err, ErrMergeUndone) || errors.Is(err, ErrPatientMerged) {
+			return err
+		}
+		return fmt.Errorf("failed to undo patient merge: %w", err)
+	}
+	return nil
+}
+
+// movePatientRows re-points rows from one patient to another and returns
+// the moved IDs per table. When only is non-nil, just the listed rows are
+// moved. Signed encounter notes may only change patient inside a merge, so
+// the transaction says it is one.
+func movePatientRows(tx *gorm.DB, from, to uint, only map[string][]uint) (map[string][]uint, error) {
+	if err := tx.Exec("SET LOCAL hms.patient_merge = 'on'").Error; err != nil {
+		return nil, err
+	}
+
+	moved := make(map[string][]uint)
+	for _, table := range mergedTables {
+		sql := fmt.Sprintf("UPDATE %s SET patient_id = ? WHERE patient_id = ? RETURNING id", table)
+		args := []interface{}{to, from}
+		if only != nil {
+			if len(only[table]) == 0 {
+				continue
+			}
+			sql = fmt.Sprintf("UPDATE %s SET patient_id = ? WHERE patient_id = ? AND id IN ? RETURNING id", table)
+			args = append(args, only[table])
+		}
+
+		var ids []uint
+		if err := tx.Raw(sql, args...).Scan(&ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to move %s: %w", table, err)
+		}
+		if len(ids) > 0 {
+			moved[table] = ids
+		}
+	}
+	return moved, nil
+}
+
+// reviewPair closes the duplicate candidate for two patients, if there is
+// one.
+func reviewPair(tx *gorm.DB, a, b uint, status string, reviewer uint, at time.Time) error {
+	if a > b {
+		a, b = b, a
+	}
+	return tx.Model(&models.DuplicateCandidate{}).
+		Where("patient_id = ? AND candidate_id = ?", a, b).
+		Updates(map[string]interface{}{
+			"status":      status,
+			"reviewed_by": reviewer,
+			"reviewed_at": at,
+			"updated_at":  at,
+		}).Error
+}
+
+func (r *PatientMergeRepositoryImpl) GetMerge(id uint) (*models.PatientMerge, error) {
+	var merge models.PatientMerge
+	if err := r.db.First(&merge, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("patient merge with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get patient merge: %w", err)
+	}
+	return &merge, nil
+}
+
+func (r *PatientMergeRepositoryImpl) GetMergesForPatient(patientID uint) ([]*models.PatientMerge, error) {
+	var merges []*models.PatientMerge
+	err := r.db.Where("survivor_id = ? OR merged_id = ?", patientID, patientID).
+		Order("created_at DESC").
+		Find(&merges).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient merges: %w", err)
+	}
+	return merges, nil
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PatientRecordRepository stores a patient's allergies, medications, problem
+// list and legacy free-text notes. Every lookup is scoped to the patient.
+type PatientRecordRepository interface {
+	GetAllergies(patientID uint) ([]*models.Allergy, error)
+	GetAllergy(patientID, id uint) (*models.Allergy, error)
+	CreateAllergy(allergy *models.Allergy) (*models.Allergy, error)
+	UpdateAllergy(allergy *models.Allergy) (*models.Allergy, error)
+	DeleteAllergy(patientID, id uint) error
+
+	GetMedications(patientID uint) ([]*models.Medication, error)
+	GetMedication(patientID, id uint) (*models.Medication, error)
+	CreateMedication(medication *models.Medication) (*models.Medication, error)
+	UpdateMedication(medication *models.Medication) (*models.Medication, error)
+	DeleteMedication(patientID, id uint) error
+
+	GetProblems(patientID uint) ([]*models.Problem, error)
+	GetProblem(patientID, id uint) (*models.Problem, error)
+	CreateProblem(problem *models.Problem) (*models.Problem, error)
+	UpdateProblem(problem *models.Problem) (*models.Problem, error)
+	DeleteProblem(patientID, id uint) error
+
+	GetLegacyNotes(patientID uint) ([]*models.PatientLegacyNote, error)
+}
+
+// PatientRecordRepositoryImpl implements PatientRecordRepository using GORM.
+type PatientRecordRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPatientRecordRepository creates a new PatientRecordRepository.
+func NewPatientRecordRepository(db *gorm.DB) PatientRecordRepository {
+	return &PatientRecordRepositoryImpl{db: db}
+}
+
+func (r *PatientRecordRepositoryImpl) GetAllergies(patientID uint) ([]*models.Allergy, error) {
+	return findPatientRecords[models.Allergy](r.db, patientID, "allergies", "created_at DESC")
+}
+
+func (r *PatientRecordRepositoryImpl) GetAllergy(patientID, id uint) (*models.Allergy, error) {
+	return getPatientRecord[models.Allergy](r.db, patientID, id, "allergy")
+}
+
+func (r *PatientRecordRepositoryImpl) CreateAllergy(allergy *models.Allergy) (*models.Allergy, error) {
+	return createPatientRecord(r.db, allergy, "allergy")
+}
+
+func (r *PatientRecordRepositoryImpl) UpdateAllergy(allergy *models.Allergy) (*models.Allergy, error) {
+	return updatePatientRecord(r.db, allergy, "allergy")
+}
+
+func (r *PatientRecordRepositoryImpl) DeleteAllergy(patientID, id uint) error {
+	return deletePatientRecord[models.Allergy](r.db, patientID, id, "allergy")
+}
+
+func (r *PatientRecordRepositoryImpl) GetMedications(patientID uint) ([]*models.Medication, error) {
+	return findPatientRecords[models.Medication](r.db, patientID, "medications", "stop_date DESC NULLS FIRST, start_date DESC")
+}
+
+func (r *PatientRecordRepositoryImpl) GetMedication(patientID, id uint) (*models.Medication, error) {
+	return getPatientRecord[models.Medication](r.db, patientID, id, "medication")
+}
+
+func (r *PatientRecordRepositoryImpl) CreateMedication(medication *models.Medication) (*models.Medication, error) {
+	return createPatientRecord(r.db, medication, "medication")
+}
+
+func (r *PatientRecordRepositoryImpl) UpdateMedication(medication *models.Medication) (*models.Medication, error) {
+	return updatePatientRecord(r.db, medication, "medication")
+}
+
+func (r *PatientRecordRepositoryImpl) DeleteMedication(patientID, id uint) error {
+	return deletePatientRecord[models.Medication](r.db, patientID, id, "medication")
+}
+
+func (r *PatientRecordRepositoryImpl) GetProblems(patientID uint) ([]*models.Problem, error) {
+	return findPatientRecords[models.Problem](r.db, patientID, "problems", "onset DESC NULLS LAST, created_at DESC")
+}
+
+func (r *PatientRecordRepositoryImpl) GetProblem(patientID, id uint) (*models.Problem, error) {
+	return getPatientRecord[models.Problem](r.db, patientID, id, "problem")
+}
+
+func (r *PatientRecordRepositoryImpl) CreateProblem(problem *models.Problem) (*models.Problem, error) {
+	return createPatientRecord(r.db, problem, "problem")
+}
+
+func (r *PatientRecordRepositoryImpl) UpdateProblem(problem *models.Problem) (*models.Problem, error) {
+	return updatePatientRecord(r.db, problem, "problem")
+}
+
+func (r *PatientRecordRepositoryImpl) DeleteProblem(patientID, id uint) error {
+	return deletePatientRecord[models.Problem](r.db, patientID, id, "problem")
+}
+
+func (r *PatientRecordRepositoryImpl) GetLegacyNotes(patientID uint) ([]*models.PatientLegacyNote, error) {
+	return findPatientRecords[models.PatientLegacyNote](r.db, patientID, "legacy notes", "id ASC")
+}
+
+// The helpers below implement the shared CRUD for the patient child tables;
+// name is used in error messages.
+
+func findPatientRecords[T any](db *gorm.DB, patientID uint, name, order string) ([]*T, error) {
+	var records []*T
+	if err := db.Where("patient_id = ?", patientID).Order(order).Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+	return records, nil
+}
+
+func getPatientRecord[T any](db *gorm.DB, patientID, id uint, name string) (*T, error) {
+	var record T
+	if err := db.Where("patient_id = ?", patientID).First(&record, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("%s with id %d not found", name, id)
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", name, err)
+	}
+	return &record, nil
+}
+
+func createPatientRecord[T any](db *gorm.DB, record *T, name string) (*T, error) {
+	if err := db.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	return record, nil
+}
+
+func updatePatientRecord[T any](db *gorm.DB, record *T, name string) (*T, error) {
+	if err := db.Save(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", name, err)
+	}
+	return record, nil
+}
+
+func deletePatientRecord[T any](db *gorm.DB, patientID, id uint, name string) error {
+	result := db.Where("patient_id = ?", patientID).Delete(new(T), id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete %s: %w", name, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%s with id %d not found", name, id)
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"fmt"
+	"hospital-management/internal/encryption"
 	"hospital-management/internal/models"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -15,7 +18,31 @@ type PatientRepository interface {
 	Update(patient *models.Patient) (*models.Patient, error)
 	Delete(id int) error
 	GetAll() ([]*models.Patient, error)
-	Search(query string) ([]*models.Patient, error)
+	// Search returns one page of the unmerged patients matching every term,
+	// most relevant first. Results are paged by page number; sort and cursor
+	// options are rejected.
+	Search(terms []models.PatientSearchTerm, opts *models.ListOptions) (*models.ListResult[*models.PatientSearchResult], error)
+	List(filter *models.PatientFilter, opts *models.ListOptions) (*models.ListResult[*models.Patient], error)
+	// EachBatch calls fn with successive batches of the patients matching
+	// the filter, in ID order, until they run out or fn returns an error.
+	EachBatch(filter *models.PatientFilter, batchSize int, fn func([]*models.Patient) error) error
+	// FindMatchCandidates returns up to limit other unmerged patients that
+	// share the patient's date of birth (or its day/month transposition),
+	// phone number or email address: the pool the duplicate matcher scores.
+	FindMatchCandidates(patient *models.Patient, limit int) ([]*models.Patient, error)
+	// GetByMRN returns the patient with the medical record number, merged
+	// or not.
+	GetByMRN(mrn string) (*models.Patient, error)
+	// NextMRNSequence returns the next sequence number of the facility's
+	// MRN counter. Numbers are never handed out twice, even if the patient
+	// they were drawn for is not saved.
+	NextMRNSequence(facility string) (int64, error)
+	// GetIDsWithoutMRN returns up to limit IDs of patients, merged or not,
+	// that have no MRN yet, in ID order.
+	GetIDsWithoutMRN(limit int) ([]uint, error)
+	// AssignMRN sets the patient's MRN if it has none. It reports whether
+	// the MRN was set.
+	AssignMRN(id uint, mrn string) (bool, error)
 }
 
 // PatientRepositoryImpl implements PatientRepository using GORM.
@@ -32,6 +59,9 @@ func NewPatientRepository(db *gorm.DB) PatientRepository {
 
 // Create inserts a new patient record into the database.
 func (r *PatientRepositoryImpl) Create(patient *models.Patient) (*models.Patient, error) {
+	if err := setPhoneIndex(patient); err != nil {
+		return nil, err
+	}
 	if err := r.db.Create(patient).Error; err != nil {
 		return nil, fmt.Errorf("failed to create patient: %w", err)
 	}
@@ -50,10 +80,14 @@ func (r *PatientRepositoryImpl) GetByID(id int) (*models.Patient, error) {
 	return &patient, nil
 }
 
-// GetByPhone retrieves a patient by their phone number.
+// GetByPhone retrieves a patient by the digits of their phone number.
 func (r *PatientRepositoryImpl) GetByPhone(phone string) (*models.Patient, error) {
+	match, args, err := phoneMatchSQL([]string{models.PhoneDigits(phone)})
+	if err != nil {
+		return nil, err
+	}
 	var patient models.Patient
-	if err := r.db.Where("phone = ?", phone).First(&patient).Error; err != nil {
+	if err := r.db.Where(match, args...).Where("merged_into_id IS NULL").First(&patient).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("patient with phone %s not found", phone)
 		}
@@ -64,6 +98,9 @@ func (r *PatientRepositoryImpl) GetByPhone(phone string) (*models.Patient, error
 
 // Update modifies an existing patient record.
 func (r *PatientRepositoryImpl) Update(patient *models.Patient) (*models.Patient, error) {
+	if err := setPhoneIndex(patient); err != nil {
+		return nil, err
+	}
 	if err := r.db.Save(patient).Error; err != nil {
 		return nil, fmt.Errorf("failed to update patient: %w", err)
 	}
@@ -85,21 +122,342 @@ func (r *PatientRepositoryImpl) Delete(id int) error {
 // GetAll retrieves all patients, ordered by creation date descending.
 func (r *PatientRepositoryImpl) GetAll() ([]*models.Patient, error) {
 	var patients []*models.Patient
-	if err := r.db.Order("created_at desc").Find(&patients).Error; err != nil {
+	if err := r.db.Where("merged_into_id IS NULL").Order("created_at desc").Find(&patients).Error; err != nil {
 		return nil, fmt.Errorf("failed to get patients: %w", err)
 	}
 	return patients, nil
 }
 
-// Search finds patients by first name, last name, email, or phone (case-insensitive).
-func (r *PatientRepositoryImpl) Search(query string) ([]*models.Patient, error) {
-	var patients []*models.Patient
-	searchPattern := "%" + query + "%"
-	if err := r.db.Where(
-		"first_name ILIKE ? OR last_name ILIKE ? OR email ILIKE ? OR phone ILIKE ?",
-		searchPattern, searchPattern, searchPattern, searchPattern,
-	).Order("created_at desc").Find(&patients).Error; err != nil {
+// Search matches each name term by full-text prefix, trigram similarity,
+// double metaphone or email substring; digit terms against the blind index
+// of the encrypted phone number, which finds whole numbers only, or the year
+// of birth; and date terms against the date of birth. Every kind of match
+// adds to the score.
+func (r *PatientRepositoryImpl) Search(terms []models.PatientSearchTerm, opts *models.ListOptions) (*models.ListResult[*models.PatientSearchResult], error) {
+	if opts.Sort != "" || opts.Cursor != "" {
+		return nil, fmt.Errorf("%w: search results are ordered by relevance and paged by page number", ErrInvalidListQuery)
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = models.DefaultListLimit
+	}
+	if limit > models.MaxListLimit {
+		limit = models.MaxListLimit
+	}
+	page := max(opts.Page, 1)
+
+	query := r.db.Model(&models.Patient{}).Where("patients.merged_into_id IS NULL")
+	var ranks []string
+	var rankArgs []interface{}
+	for _, term := range terms {
+		match, matchArgs, rank, args, err := searchTermSQL(term)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(match, matchArgs...)
+		ranks = append(ranks, rank)
+		rankArgs = append(rankArgs, args...)
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	var rows []struct {
+		models.Patient
+		SearchRank float64
+	}
+	err := query.
+		Select("patients.*, ("+strings.Join(ranks, " + ")+") AS search_rank", rankArgs...).
+		Order("search_rank DESC, patients.last_name, patients.first_name, patients.id").
+		Limit(limit).
+		Offset((page - 1) * limit).
+		Scan(&rows).Error
+	if err != nil {
 		return nil, fmt.Errorf("failed to search patients: %w", err)
 	}
+
+	result := &models.ListResult[*models.PatientSearchResult]{
+		Data:  make([]*models.PatientSearchResult, 0, len(rows)),
+		Total: total,
+		Limit: limit,
+		Page:  page,
+	}
+	for i := range rows {
+		patient := rows[i].Patient
+		result.Data = append(result.Data, &models.PatientSearchResult{Patient: &patient, Score: rows[i].SearchRank})
+	}
+	return result, nil
+}
+
+// searchTermSQL returns the condition a patient must meet to match the
+// term and the expression that scores the match, with their arguments.
+func searchTermSQL(term models.PatientSearchTerm) (string, []interface{}, string, []interface{}, error) {
+	var matches, ranks []string
+	var matchArgs, rankArgs []interface{}
+
+	if term.Name != "" {
+		name := term.Name
+		matches = append(matches,
+			"patients.search_vector @@ to_tsquery('simple', ?)",
+			"lower(patients.first_name) % ?",
+			"lower(patients.last_name) % ?",
+			"lower(patients.email) LIKE ?")
+		matchArgs = append(matchArgs, name+":*", name, name, "%"+name+"%")
+		ranks = append(ranks,
+			"CASE WHEN lower(patients.first_name) = ? OR lower(patients.last_name) = ? THEN 1 "+
+				"WHEN patients.search_vector @@ to_tsquery('simple', ?) THEN 0.8 ELSE 0 END",
+			"GREATEST(similarity(lower(patients.first_name), ?), similarity(lower(patients.last_name), ?))")
+		rankArgs = append(rankArgs, name, name, name+":*", name, name)
+
+		// Short terms sound like too many names to be worth matching
+		// phonetically.
+		if len([]rune(name)) >= 3 {
+			matches = append(matches, "dmetaphone(patients.first_name) = dmetaphone(?)", "dmetaphone(patients.last_name) = dmetaphone(?)")
+			matchArgs = append(matchArgs, name, name)
+			ranks = append(ranks, "CASE WHEN dmetaphone(patients.first_name) = dmetaphone(?) OR dmetaphone(patients.last_name) = dmetaphone(?) THEN 0.4 ELSE 0 END")
+			rankArgs = append(rankArgs, name, name)
+		}
+	}
+
+	if len(term.Digits) > 0 {
+		// Phone numbers are encrypted, so whole numbers are matched by their
+		// blind index and anything else by the index of its last digits,
+		// which ranks lower as it may come from another number.
+		match, args, err := phoneMatchSQL(term.Digits)
+		if err != nil {
+			return "", nil, "", nil, err
+		}
+		matches = append(matches, match)
+		matchArgs = append(matchArgs, args...)
+		ranks = append(ranks, "CASE WHEN "+match+" THEN 1.5 ELSE 0 END")
+		rankArgs = append(rankArgs, args...)
+
+		suffixMatch, suffixArgs, err := phoneSuffixMatchSQL(term.Digits[0])
+		if err != nil {
+			return "", nil, "", nil, err
+		}
+		matches = append(matches, suffixMatch)
+		matchArgs = append(matchArgs, suffixArgs...)
+		ranks = append(ranks, "CASE WHEN "+suffixMatch+" THEN 0.75 ELSE 0 END")
+		rankArgs = append(rankArgs, suffixArgs...)
+	}
+
+	if term.Year != 0 {
+		matches = append(matches, "EXTRACT(YEAR FROM patients.date_of_birth) = ?")
+		matchArgs = append(matchArgs, term.Year)
+		ranks = append(ranks, "CASE WHEN EXTRACT(YEAR FROM patients.date_of_birth) = ? THEN 0.5 ELSE 0 END")
+		rankArgs = append(rankArgs, term.Year)
+	}
+
+	if len(term.Dates) > 0 {
+		// Compare as date strings so the session time zone cannot shift them.
+		dates := make([]string, len(term.Dates))
+		for i, date := range term.Dates {
+			dates[i] = date.Format("2006-01-02")
+		}
+		matches = append(matches, "patients.date_of_birth IN ?")
+		matchArgs = append(matchArgs, dates)
+		ranks = append(ranks, "CASE WHEN patients.date_of_birth IN ? THEN 1.5 ELSE 0 END")
+		rankArgs = append(rankArgs, dates)
+	}
+
+	return "(" + strings.Join(matches, " OR ") + ")", matchArgs, "(" + strings.Join(ranks, " + ") + ")", rankArgs, nil
+}
+
+// phoneMatchSQL returns a condition matching the patients whose phone
+// number has any of the given digits, compared by blind index. Rows written
+// before phone numbers were encrypted have no index until the keys rotate
+// command has run; their plaintext digits are compared instead.
+func phoneMatchSQL(digits []string) (string, []interface{}, error) {
+	var indexes, plain []string
+	for _, d := range digits {
+		index, err := encryption.IndexPhone(d)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to index phone number: %w", err)
+		}
+		if index != nil {
+			indexes = append(indexes, *index)
+			plain = append(plain, d)
+		}
+	}
+	if len(indexes) == 0 {
+		return "FALSE", nil, nil
+	}
+	return "(patients.phone_index IN ? OR (patients.phone_index IS NULL AND patients.phone NOT LIKE ? AND " +
+			"regexp_replace(patients.phone, '[^0-9]', '', 'g') IN ?))",
+		[]interface{}{indexes, encryption.Prefix + "%", plain}, nil
+}
+
+// phoneSuffixMatchSQL returns a condition matching the patients whose phone
+// number ends in the same digits as number, compared by the suffix blind
+// index, or FALSE if number is too short to have a suffix. Rows not yet
+// indexed are compared in plain text, as in phoneMatchSQL.
+func phoneSuffixMatchSQL(number string) (string, []interface{}, error) {
+	index, err := encryption.IndexPhoneSuffix(number)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to index phone number: %w", err)
+	}
+	if index == nil {
+		return "FALSE", nil, nil
+	}
+	return "(patients.phone_suffix_index = ? OR (patients.phone_suffix_index IS NULL AND patients.phone NOT LIKE ? AND " +
+			"regexp_replace(patients.phone, '[^0-9]', '', 'g') LIKE ?))",
+		[]interface{}{*index, encryption.Prefix + "%", "%" + models.PhoneSuffix(number)}, nil
+}
+
+// setPhoneIndex recomputes the blind indexes of the patient's phone number.
+func setPhoneIndex(patient *models.Patient) error {
+	index, err := encryption.IndexPhone(patient.Phone)
+	if err != nil {
+		return fmt.Errorf("failed to index phone number: %w", err)
+	}
+	suffix, err := encryption.IndexPhoneSuffix(patient.Phone)
+	if err != nil {
+		return fmt.Errorf("failed to index phone number: %w", err)
+	}
+	patient.PhoneIndex = index
+	patient.PhoneSuffixIndex = suffix
+	return nil
+}
+
+// patientListSpec lists the fields patients can be sorted by.
+var patientListSpec = listSpec[*models.Patient]{
+	table: "patients",
+	sorts: map[string]sortColumn[*models.Patient]{
+		"id":            {column: "id", value: func(p *models.Patient) interface{} { return int64(p.ID) }},
+		"created_at":    {column: "created_at", value: func(p *models.Patient) interface{} { return p.CreatedAt }},
+		"last_name":     {column: "last_name", value: func(p *models.Patient) interface{} { return p.LastName }},
+		"first_name":    {column: "first_name", value: func(p *models.Patient) interface{} { return p.FirstName }},
+		"date_of_birth": {column: "date_of_birth", value: func(p *models.Patient) interface{} { return p.DateOfBirth }},
+	},
+	defaultSort: "-created_at",
+	id:          func(p *models.Patient) uint { return p.ID },
+}
+
+// List returns one page of patients matching the filter.
+func (r *PatientRepositoryImpl) List(filter *models.PatientFilter, opts *models.ListOptions) (*models.ListResult[*models.Patient], error) {
+	result, err := paginate(r.filter(filter), patientListSpec, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list patients: %w", err)
+	}
+	return result, nil
+}
+
+// EachBatch streams the patients matching the filter in batches.
+func (r *PatientRepositoryImpl) EachBatch(filter *models.PatientFilter, batchSize int, fn func([]*models.Patient) error) error {
+	var batch []*models.Patient
+	err := r.filter(filter).FindInBatches(&batch, batchSize, func(*gorm.DB, int) error {
+		return fn(batch)
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to read patients: %w", err)
+	}
+	return nil
+}
+
+// FindMatchCandidates blocks on exact date of birth, phone or email so the
+// matcher only scores a handful of patients rather than the whole table.
+func (r *PatientRepositoryImpl) FindMatchCandidates(patient *models.Patient, limit int) ([]*models.Patient, error) {
+	dates := []time.Time{patient.DateOfBirth}
+	dob := patient.DateOfBirth
+	if dob.Day() <= 12 && dob.Day() != int(dob.Month()) {
+		swapped := time.Date(dob.Year(), time.Month(dob.Day()), int(dob.Month()), 0, 0, 0, 0, dob.Location())
+		dates = append(dates, swapped)
+	}
+
+	phoneMatch, phoneArgs, err := phoneMatchSQL([]string{models.PhoneDigits(patient.Phone)})
+	if err != nil {
+		return nil, err
+	}
+	match := r.db.Where("date_of_birth IN ?", dates).Or(phoneMatch, phoneArgs...)
+	if email := models.StringValue(patient.Email); email != "" {
+		match = match.Or("LOWER(email) = LOWER(?)", email)
+	}
+
+	var patients []*models.Patient
+	err = r.db.
+		Where("id <> ? AND merged_into_id IS NULL", patient.ID).
+		Where(match).
+		Order("id").
+		Limit(limit).
+		Find(&patients).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find match candidates: %w", err)
+	}
 	return patients, nil
 }
+
+func (r *PatientRepositoryImpl) GetByMRN(mrn string) (*models.Patient, error) {
+	var patient models.Patient
+	if err := r.db.Where("mrn = ?", mrn).First(&patient).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("patient with MRN %s not found", mrn)
+		}
+		return nil, fmt.Errorf("failed to get patient by MRN: %w", err)
+	}
+	return &patient, nil
+}
+
+// NextMRNSequence increments the facility's counter in a single statement,
+// creating it at 1 on first use, so concurrent registrations never share a
+// number.
+func (r *PatientRepositoryImpl) NextMRNSequence(facility string) (int64, error) {
+	var next int64
+	err := r.db.Raw(`INSERT INTO mrn_sequences (facility, last_value) VALUES (?, 1)
+		ON CONFLICT (facility) DO UPDATE SET last_value = mrn_sequences.last_value + 1
+		RETURNING last_value`, facility).Scan(&next).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate MRN sequence number: %w", err)
+	}
+	return next, nil
+}
+
+func (r *PatientRepositoryImpl) GetIDsWithoutMRN(limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Patient{}).Where("mrn IS NULL").Order("id").Limit(limit).Pluck("id", &ids).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find patients without MRN: %w", err)
+	}
+	return ids, nil
+}
+
+func (r *PatientRepositoryImpl) AssignMRN(id uint, mrn string) (bool, error) {
+	result := r.db.Model(&models.Patient{}).Where("id = ? AND mrn IS NULL", id).Update("mrn", mrn)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to assign MRN: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// filter returns a query for the unmerged patients matching filter.
+func (r *PatientRepositoryImpl) filter(filter *models.PatientFilter) *gorm.DB {
+	query := r.db.Model(&models.Patient{}).Where("merged_into_id IS NULL")
+	if filter.Name != "" {
+		pattern := containsPattern(filter.Name)
+		query = query.Where("first_name ILIKE ? OR last_name ILIKE ?", pattern, pattern)
+	}
+	if filter.Phone != "" {
+		number := models.PhoneDigits(filter.Phone)
+		match, args, err := phoneMatchSQL([]string{number})
+		if err != nil {
+			query.AddError(err)
+		}
+		suffixMatch, suffixArgs, err := phoneSuffixMatchSQL(number)
+		if err != nil {
+			query.AddError(err)
+		}
+		query = query.Where("("+match+" OR "+suffixMatch+")", append(args, suffixArgs...)...)
+	}
+	if filter.Gender != "" {
+		query = query.Where("gender = ?", filter.Gender)
+	}
+	if !filter.BornFrom.IsZero() {
+		query = query.Where("date_of_birth >= ?", filter.BornFrom)
+	}
+	if !filter.BornTo.IsZero() {
+		query = query.Where("date_of_birth < ?", filter.BornTo)
+	}
+	return query
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"regexp"
+	"testing"
+
+	"hospital-management/internal/encryption"
+	"hospital-management/internal/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func useTestKeyring(t *testing.T) {
+	t.Helper()
+	master, err := encryption.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	indexKey, err := encryption.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyring, err := encryption.NewKeyring(map[string]string{"k1": master}, "", indexKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := encryption.Active()
+	encryption.Use(keyring)
+	t.Cleanup(func() { encryption.Use(previous) })
+}
+
+func TestSetPhoneIndexKeepsSuffixAcrossFormats(t *testing.T) {
+	useTestKeyring(t)
+
+	international := &models.Patient{Phone: "+1 (555) 123-4567"}
+	national := &models.Patient{Phone: "555.123.4567"}
+	for _, patient := range []*models.Patient{international, national} {
+		if err := setPhoneIndex(patient); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if *international.PhoneIndex == *national.PhoneIndex {
+		t.Error("numbers with different digits share a whole-number index")
+	}
+	if *international.PhoneSuffixIndex != *national.PhoneSuffixIndex {
+		t.Error("numbers ending in the same digits have different suffix indexes")
+	}
+	if *national.PhoneSuffixIndex == *national.PhoneIndex {
+		t.Error("suffix index is not separated from the whole-number index")
+	}
+}
+
+func TestPhoneSuffixMatchSQL(t *testing.T) {
+	useTestKeyring(t)
+
+	patient := &models.Patient{Phone: "020 7946 0018"}
+	if err := setPhoneIndex(patient); err != nil {
+		t.Fatal(err)
+	}
+
+	match, args, err := phoneSuffixMatchSQL("0018")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match == "FALSE" || len(args) != 3 {
+		t.Fatalf("match = %q, args = %v", match, args)
+	}
+	if args[0] != *patient.PhoneSuffixIndex {
+		t.Error("last four digits do not match the stored suffix index")
+	}
+	if args[2] != "%0018" {
+		t.Errorf("plaintext pattern = %v, want %%0018", args[2])
+	}
+
+	if match, _, err := phoneSuffixMatchSQL("018"); err != nil || match != "FALSE" {
+		t.Errorf("three digits: match = %q, err = %v, want FALSE", match, err)
+	}
+}
+
+func TestListPatientsMatchesNameLiterally(t *testing.T) {
+	useTestKeyring(t)
+	db, mock := newMockDB(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "patients" WHERE merged_into_id IS NULL AND (first_name ILIKE $1 OR last_name ILIKE $2)`)).
+		WithArgs(`%\%%`, `%\%%`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "patients"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	if _, err := NewPatientRepository(db).List(&models.PatientFilter{Name: "%"}, &models.ListOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
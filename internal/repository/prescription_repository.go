@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PrescriptionRepository stores prescriptions.
+type PrescriptionRepository interface {
+	Create(prescription *models.Prescription) (*models.Prescription, error)
+	GetByID(id uint) (*models.Prescription, error)
+	GetByPatientID(patientID uint) ([]*models.Prescription, error)
+	GetByAppointmentID(appointmentID uint) ([]*models.Prescription, error)
+	// GetActiveByPatientID returns the patient's prescriptions that have not
+	// been cancelled. Callers decide whether each is still current.
+	GetActiveByPatientID(patientID uint) ([]*models.Prescription, error)
+	Update(prescription *models.Prescription) (*models.Prescription, error)
+}
+
+// PrescriptionRepositoryImpl implements PrescriptionRepository using GORM.
+type PrescriptionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewPrescriptionRepository creates a new PrescriptionRepository.
+func NewPrescriptionRepository(db *gorm.DB) PrescriptionRepository {
+	return &PrescriptionRepositoryImpl{db: db}
+}
+
+func (r *PrescriptionRepositoryImpl) Create(prescription *models.Prescription) (*models.Prescription, error) {
+	if err := r.db.Create(prescription).Error; err != nil {
+		return nil, fmt.Errorf("failed to create prescription: %w", err)
+	}
+	return prescription, nil
+}
+
+func (r *PrescriptionRepositoryImpl) GetByID(id uint) (*models.Prescription, error) {
+	var prescription models.Prescription
+	if err := r.db.First(&prescription, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("prescription with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get prescription: %w", err)
+	}
+	return &prescription, nil
+}
+
+func (r *PrescriptionRepositoryImpl) GetByPatientID(patientID uint) ([]*models.Prescription, error) {
+	var prescriptions []*models.Prescription
+	if err := r.db.Where("patient_id = ?", patientID).Order("created_at DESC").Find(&prescriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get prescriptions for patient: %w", err)
+	}
+	return prescriptions, nil
+}
+
+func (r *PrescriptionRepositoryImpl) GetByAppointmentID(appointmentID uint) ([]*models.Prescription, error) {
+	var prescriptions []*models.Prescription
+	if err := r.db.Where("appointment_id = ?", appointmentID).Order("created_at ASC").Find(&prescriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get prescriptions for appointment: %w", err)
+	}
+	return prescriptions, nil
+}
+
+func (r *PrescriptionRepositoryImpl) GetActiveByPatientID(patientID uint) ([]*models.Prescription, error) {
+	var prescriptions []*models.Prescription
+	err := r.db.
+		Where("patient_id = ? AND status = ?", patientID, models.PrescriptionActive).
+		Order("created_at DESC").
+		Find(&prescriptions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active prescriptions: %w", err)
+	}
+	return prescriptions, nil
+}
+
+func (r *PrescriptionRepositoryImpl) Update(prescription *models.Prescription) (*models.Prescription, error) {
+	if err := r.db.Save(prescription).Error; err != nil {
+		return nil, fmt.Errorf("failed to update prescription: %w", err)
+	}
+	return prescription, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository stores login sessions and their refresh tokens.
+type SessionRepository interface {
+	CreateSession(session *models.Session) (*models.Session, error)
+	GetSession(id string) (*models.Session, error)
+	RevokeSession(id string) error
+	CreateRefreshToken(token *models.RefreshToken) (*models.RefreshToken, error)
+	GetRefreshTokenByHash(hash string) (*models.RefreshToken, error)
+	// MarkRefreshTokenUsed atomically marks the token as used. It returns
+	// false if the token had already been used.
+	MarkRefreshTokenUsed(id uint) (bool, error)
+}
+
+// SessionRepositoryImpl implements SessionRepository using GORM.
+type SessionRepositoryImpl struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new Postgres-backed SessionRepository.
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &SessionRepositoryImpl{db: db}
+}
+
+// CreateSession inserts a new session.
+func (r *SessionRepositoryImpl) CreateSession(session *models.Session) (*models.Session, error) {
+	if err := r.db.Create(session).Error; err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return session, nil
+}
+
+// GetSession retrieves a session by its ID.
+func (r *SessionRepositoryImpl) GetSession(id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, "id = ?", id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("session %s not found", id)
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	return &session, nil
+}
+
+// RevokeSession marks a session as revoked. Revoking an already revoked
+// session is a no-op.
+func (r *SessionRepositoryImpl) RevokeSession(id string) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at": time.Now(),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke session: %w", result.Error)
+	}
+	return nil
+}
+
+// CreateRefreshToken inserts a new refresh token.
+func (r *SessionRepositoryImpl) CreateRefreshToken(token *models.RefreshToken) (*models.RefreshToken, error) {
+	if err := r.db.Create(token).Error; err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by the hash of its value.
+func (r *SessionRepositoryImpl) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("refresh token not found")
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+	return &token, nil
+}
+
+// MarkRefreshTokenUsed sets used_at on an unused token.
+func (r *SessionRepositoryImpl) MarkRefreshTokenUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark refresh token used: %w", result.Error)
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"sync"
+	"time"
+)
+
+// InMemorySessionRepository is a SessionRepository kept in process memory.
+// It is intended for tests and single-instance development setups.
+type InMemorySessionRepository struct {
+	mu       sync.Mutex
+	sessions map[string]models.Session
+	tokens   map[uint]models.RefreshToken
+	nextID   uint
+}
+
+// NewInMemorySessionRepository creates an empty in-memory SessionRepository.
+func NewInMemorySessionRepository() SessionRepository {
+	return &InMemorySessionRepository{
+		sessions: make(map[string]models.Session),
+		tokens:   make(map[uint]models.RefreshToken),
+	}
+}
+
+func (r *InMemorySessionRepository) CreateSession(session *models.Session) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.sessions[session.ID]; exists {
+		return nil, fmt.Errorf("failed to create session: duplicate id %s", session.ID)
+	}
+	now := time.Now()
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	r.sessions[session.ID] = *session
+	return session, nil
+}
+
+func (r *InMemorySessionRepository) GetSession(id string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return &session, nil
+}
+
+func (r *InMemorySessionRepository) RevokeSession(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok || session.RevokedAt != nil {
+		return nil
+	}
+	now := time.Now()
+	session.RevokedAt = &now
+	session.UpdatedAt = now
+	r.sessions[id] = session
+	return nil
+}
+
+func (r *InMemorySessionRepository) CreateRefreshToken(token *models.RefreshToken) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.tokens {
+		if existing.TokenHash == token.TokenHash {
+			return nil, fmt.Errorf("failed to create refresh token: duplicate hash")
+		}
+	}
+	r.nextID++
+	token.ID = r.nextID
+	token.CreatedAt = time.Now()
+	r.tokens[token.ID] = *token
+	return token, nil
+}
+
+func (r *InMemorySessionRepository) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			return &token, nil
+		}
+	}
+	return nil, fmt.Errorf("refresh token not found")
+}
+
+func (r *InMemorySessionRepository) MarkRefreshTokenUsed(id uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[id]
+	if !ok || token.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	token.UsedAt = &now
+	r.tokens[id] = token
+	return true, nil
+}
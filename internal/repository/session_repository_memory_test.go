@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+)
+
+func TestInMemorySessionRevoke(t *testing.T) {
+	r := NewInMemorySessionRepository()
+	if _, err := r.CreateSession(&models.Session{ID: "s1", UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CreateSession(&models.Session{ID: "s1", UserID: 2}); err == nil {
+		t.Fatal("expected a duplicate session ID to be rejected")
+	}
+
+	session, err := r.GetSession("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !session.IsActive(time.Now()) {
+		t.Fatal("new session is not active")
+	}
+
+	if err := r.RevokeSession("s1"); err != nil {
+		t.Fatal(err)
+	}
+	session, err = r.GetSession("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.IsActive(time.Now()) {
+		t.Fatal("revoked session is still active")
+	}
+	if err := r.RevokeSession("missing"); err != nil {
+		t.Fatalf("revoking an unknown session: %v", err)
+	}
+}
+
+func TestInMemoryRefreshTokenUsedOnce(t *testing.T) {
+	r := NewInMemorySessionRepository()
+	token, err := r.CreateRefreshToken(&models.RefreshToken{SessionID: "s1", TokenHash: "abc", ExpiresAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CreateRefreshToken(&models.RefreshToken{SessionID: "s1", TokenHash: "abc"}); err == nil {
+		t.Fatal("expected a duplicate token hash to be rejected")
+	}
+
+	found, err := r.GetRefreshTokenByHash("abc")
+	if err != nil || found.ID != token.ID {
+		t.Fatalf("GetRefreshTokenByHash = %+v, %v", found, err)
+	}
+
+	fresh, err := r.MarkRefreshTokenUsed(token.ID)
+	if err != nil || !fresh {
+		t.Fatalf("first use = %v, %v; want true", fresh, err)
+	}
+	fresh, err = r.MarkRefreshTokenUsed(token.ID)
+	if err != nil || fresh {
+		t.Fatalf("second use = %v, %v; want false", fresh, err)
+	}
+}
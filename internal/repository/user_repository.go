@@ -12,6 +12,8 @@ type UserRepository interface {
 	GetByEmail(email string) (*models.User, error)
 	GetByUsername(username string) (*models.User, error)
 	Create(user *models.User) (*models.User, error)
+	// HasRole reports whether any user has the role.
+	HasRole(role string) (bool, error)
 	// Add other methods as needed
 }
 
@@ -41,7 +43,7 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 
 func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	var user models.User
-	if err := r.db.Where("name = ?", username).First(&user).Error; err != nil {
+	if err := r.db.Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -53,3 +55,11 @@ func (r *userRepository) Create(user *models.User) (*models.User, error) {
 	}
 	return user, nil
 }
+
+func (r *userRepository) HasRole(role string) (bool, error) {
+	var count int64
+	if err := r.db.Model(&models.User{}).Where("role = ?", role).Limit(1).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"hospital-management/internal/models"
+)
+
+// Appointment event types.
+const (
+	AppointmentEventCreated = "created"
+	AppointmentEventUpdated = "updated"
+	AppointmentEventDeleted = "deleted"
+)
+
+// AppointmentEvent describes a committed change to an appointment. Before is
+// nil for created appointments and Appointment is nil for deleted ones.
+type AppointmentEvent struct {
+	Type        string
+	Appointment *models.Appointment
+	Before      *models.Appointment
+}
+
+// DoctorID returns the doctor whose schedule the event touches.
+func (e AppointmentEvent) DoctorID() uint {
+	if e.Appointment != nil {
+		return e.Appointment.DoctorID
+	}
+	return e.Before.DoctorID
+}
+
+// AppointmentListener is called after every committed appointment change. It
+// runs on the request goroutine and must not block.
+type AppointmentListener func(event AppointmentEvent)
+
+func (s *appointmentService) Subscribe(listener AppointmentListener) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *appointmentService) publish(eventType string, before, after *models.Appointment) {
+	s.listenersMu.RLock()
+	defer s.listenersMu.RUnlock()
+
+	event := AppointmentEvent{Type: eventType, Appointment: after, Before: before}
+	for _, listener := range s.listeners {
+		listener(event)
+	}
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"hospital-management/internal/auth"
+	"hospital-management/internal/models"
+	"time"
+)
+
+// appointmentTransitions lists, for every status, the statuses it may move to
+// and the permission needed to make that move. Completed, cancelled and
+// no-show appointments are final.
+var appointmentTransitions = map[string]map[string]auth.Permission{
+	models.AppointmentRequested: {
+		models.AppointmentScheduled: auth.PermAppointmentsBook,
+		models.AppointmentCancelled: auth.PermAppointmentsBook,
+	},
+	models.AppointmentScheduled: {
+		models.AppointmentConfirmed: auth.PermAppointmentsBook,
+		models.AppointmentCheckedIn: auth.PermAppointmentsCheckIn,
+		models.AppointmentCancelled: auth.PermAppointmentsBook,
+		models.AppointmentNoShow:    auth.PermAppointmentsCheckIn,
+	},
+	models.AppointmentConfirmed: {
+		models.AppointmentCheckedIn: auth.PermAppointmentsCheckIn,
+		models.AppointmentCancelled: auth.PermAppointmentsBook,
+		models.AppointmentNoShow:    auth.PermAppointmentsCheckIn,
+	},
+	models.AppointmentCheckedIn: {
+		models.AppointmentInProgress: auth.PermAppointmentsClinical,
+		models.AppointmentCancelled:  auth.PermAppointmentsBook,
+	},
+	models.AppointmentInProgress: {
+		models.AppointmentCompleted: auth.PermAppointmentsClinical,
+	},
+}
+
+// reschedulableStatuses are the statuses in which the time of an appointment
+// may still change.
+var reschedulableStatuses = []string{
+	models.AppointmentRequested,
+	models.AppointmentScheduled,
+	models.AppointmentConfirmed,
+}
+
+// transitionAppointment validates a move of appointment to status and applies
+// it, stamping the time the new status was entered. A reason is required for
+// cancellations and no-shows.
+func transitionAppointment(actor *models.Actor, appointment *models.Appointment, status, reason string, at time.Time) error {
+	perm, ok := appointmentTransitions[appointment.Status][status]
+	if !ok {
+		return &TransitionError{From: appointment.Status, To: status}
+	}
+	if !auth.Allowed(actor.Role, perm) {
+		return ErrTransitionForbidden
+	}
+	if (status == models.AppointmentCancelled || status == models.AppointmentNoShow) && reason == "" {
+		return ErrReasonRequired
+	}
+
+	stamp := &at
+	switch status {
+	case models.AppointmentScheduled:
+		appointment.ScheduledAt = stamp
+	case models.AppointmentConfirmed:
+		appointment.ConfirmedAt = stamp
+	case models.AppointmentCheckedIn:
+		appointment.CheckedInAt = stamp
+	case models.AppointmentInProgress:
+		appointment.StartedAt = stamp
+	case models.AppointmentCompleted:
+		appointment.CompletedAt = stamp
+	case models.AppointmentCancelled:
+		appointment.CancelledAt = stamp
+	case models.AppointmentNoShow:
+		appointment.NoShowAt = stamp
+	}
+	if reason != "" {
+		appointment.StatusReason = models.StringPtr(reason)
+	}
+	appointment.Status = status
+	return nil
+}
+
+func isReschedulable(status string) bool {
+	for _, s := range reschedulableStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
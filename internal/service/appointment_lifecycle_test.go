@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+)
+
+var allStatuses = []string{
+	models.AppointmentRequested,
+	models.AppointmentScheduled,
+	models.AppointmentConfirmed,
+	models.AppointmentCheckedIn,
+	models.AppointmentInProgress,
+	models.AppointmentCompleted,
+	models.AppointmentCancelled,
+	models.AppointmentNoShow,
+}
+
+// TestAppointmentTransitionTable checks every pair of statuses as an admin,
+// who holds every permission, so only the table decides.
+func TestAppointmentTransitionTable(t *testing.T) {
+	allowed := map[string][]string{
+		models.AppointmentRequested:  {models.AppointmentScheduled, models.AppointmentCancelled},
+		models.AppointmentScheduled:  {models.AppointmentConfirmed, models.AppointmentCheckedIn, models.AppointmentCancelled, models.AppointmentNoShow},
+		models.AppointmentConfirmed:  {models.AppointmentCheckedIn, models.AppointmentCancelled, models.AppointmentNoShow},
+		models.AppointmentCheckedIn:  {models.AppointmentInProgress, models.AppointmentCancelled},
+		models.AppointmentInProgress: {models.AppointmentCompleted},
+	}
+	admin := &models.Actor{UserID: 1, Role: models.RoleAdmin}
+	at := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	for _, from := range allStatuses {
+		for _, to := range allStatuses {
+			want := false
+			for _, s := range allowed[from] {
+				want = want || s == to
+			}
+
+			appointment := &models.Appointment{Status: from}
+			err := transitionAppointment(admin, appointment, to, "patient asked", at)
+
+			var transitionErr *TransitionError
+			switch {
+			case want && err != nil:
+				t.Errorf("%s -> %s: got %v, want allowed", from, to, err)
+			case want && appointment.Status != to:
+				t.Errorf("%s -> %s: status is %s", from, to, appointment.Status)
+			case !want && !errors.As(err, &transitionErr):
+				t.Errorf("%s -> %s: got %v, want a TransitionError", from, to, err)
+			case !want && appointment.Status != from:
+				t.Errorf("%s -> %s: status changed to %s on a refused transition", from, to, appointment.Status)
+			}
+		}
+	}
+}
+
+func TestAppointmentTransitionStampsTime(t *testing.T) {
+	at := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	admin := &models.Actor{UserID: 1, Role: models.RoleAdmin}
+	stamps := map[string]func(*models.Appointment) *time.Time{
+		models.AppointmentConfirmed:  func(a *models.Appointment) *time.Time { return a.ConfirmedAt },
+		models.AppointmentCheckedIn:  func(a *models.Appointment) *time.Time { return a.CheckedInAt },
+		models.AppointmentCancelled:  func(a *models.Appointment) *time.Time { return a.CancelledAt },
+		models.AppointmentNoShow:     func(a *models.Appointment) *time.Time { return a.NoShowAt },
+		models.AppointmentInProgress: func(a *models.Appointment) *time.Time { return a.StartedAt },
+		models.AppointmentCompleted:  func(a *models.Appointment) *time.Time { return a.CompletedAt },
+	}
+	from := map[string]string{
+		models.AppointmentConfirmed:  models.AppointmentScheduled,
+		models.AppointmentCheckedIn:  models.AppointmentScheduled,
+		models.AppointmentCancelled:  models.AppointmentScheduled,
+		models.AppointmentNoShow:     models.AppointmentScheduled,
+		models.AppointmentInProgress: models.AppointmentCheckedIn,
+		models.AppointmentCompleted:  models.AppointmentInProgress,
+	}
+	for status, stamp := range stamps {
+		appointment := &models.Appointment{Status: from[status]}
+		if err := transitionAppointment(admin, appointment, status, "reason", at); err != nil {
+			t.Fatalf("%s: %v", status, err)
+		}
+		if got := stamp(appointment); got == nil || !got.Equal(at) {
+			t.Errorf("%s: stamped %v, want %v", status, got, at)
+		}
+	}
+}
+
+func TestAppointmentTransitionRequiresReason(t *testing.T) {
+	admin := &models.Actor{UserID: 1, Role: models.RoleAdmin}
+	for _, status := range []string{models.AppointmentCancelled, models.AppointmentNoShow} {
+		appointment := &models.Appointment{Status: models.AppointmentScheduled}
+		if err := transitionAppointment(admin, appointment, status, "", time.Now()); !errors.Is(err, ErrReasonRequired) {
+			t.Errorf("%s without a reason: got %v, want ErrReasonRequired", status, err)
+		}
+	}
+}
+
+func TestAppointmentTransitionChecksRole(t *testing.T) {
+	tests := []struct {
+		role     string
+		from, to string
+		want     error
+	}{
+		{models.RoleReceptionist, models.AppointmentScheduled, models.AppointmentCheckedIn, nil},
+		{models.RoleReceptionist, models.AppointmentCheckedIn, models.AppointmentInProgress, ErrTransitionForbidden},
+		{models.RoleDoctor, models.AppointmentCheckedIn, models.AppointmentInProgress, nil},
+		{models.RoleDoctor, models.AppointmentScheduled, models.AppointmentCancelled, ErrTransitionForbidden},
+		{models.RoleNurse, models.AppointmentScheduled, models.AppointmentNoShow, nil},
+		{models.RoleStaff, models.AppointmentScheduled, models.AppointmentConfirmed, ErrTransitionForbidden},
+	}
+	for _, tt := range tests {
+		appointment := &models.Appointment{Status: tt.from}
+		err := transitionAppointment(&models.Actor{UserID: 1, Role: tt.role}, appointment, tt.to, "reason", time.Now())
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s %s -> %s: got %v, want %v", tt.role, tt.from, tt.to, err, tt.want)
+		}
+	}
+}
+
+func TestIsReschedulable(t *testing.T) {
+	for _, status := range allStatuses {
+		want := status == models.AppointmentRequested || status == models.AppointmentScheduled || status == models.AppointmentConfirmed
+		if got := isReschedulable(status); got != want {
+			t.Errorf("isReschedulable(%s) = %v, want %v", status, got, want)
+		}
+	}
+}
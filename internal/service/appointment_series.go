@@ -0,0 +1,413 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"sort"
+	"time"
+)
+
+func (s *appointmentService) CreateAppointmentSeries(actor *models.Actor, req *models.AppointmentSeriesRequest) (*models.AppointmentSeries, error) {
+	if err := s.validateParticipants(req.PatientID, req.DoctorID); err != nil {
+		return nil, err
+	}
+
+	start, err := time.Parse(time.RFC3339, req.DateTime)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date_time format: %w", err)
+	}
+	starts, err := expandRecurrence(start.In(s.location), req.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []OccurrenceConflict
+	for _, occurrence := range starts {
+		if err := s.availability.CheckBookable(req.DoctorID, occurrence, req.Duration); err != nil {
+			if !errors.Is(err, ErrOutsideAvailability) {
+				return nil, err
+			}
+			problems = append(problems, OccurrenceConflict{Start: occurrence, Reason: err.Error()})
+		}
+	}
+
+	series := &models.AppointmentSeries{
+		PatientID:  req.PatientID,
+		DoctorID:   req.DoctorID,
+		StartsAt:   start,
+		Duration:   req.Duration,
+		Recurrence: req.Recurrence,
+		Notes:      models.StringPtr(req.Notes),
+		CreatedBy:  actor.UserID,
+	}
+	if series.Recurrence.Interval == 0 {
+		series.Recurrence.Interval = 1
+	}
+
+	// overlapping is the occurrence the double-booking constraint refused
+	var overlapping *models.Appointment
+	err = s.appointmentRepo.Transaction(func(tx repository.AppointmentRepository) error {
+		if err := tx.LockDoctorSchedule(req.DoctorID); err != nil {
+			return err
+		}
+		for _, occurrence := range starts {
+			conflicts, err := tx.FindConflicts(req.DoctorID, occurrence, req.Duration, 0)
+			if err != nil {
+				return err
+			}
+			if len(conflicts) > 0 {
+				problems = append(problems, OccurrenceConflict{Start: occurrence, Reason: "overlaps existing appointments", Conflicts: conflicts})
+			}
+		}
+		if len(problems) > 0 {
+			sort.Slice(problems, func(i, j int) bool { return problems[i].Start.Before(problems[j].Start) })
+			return &SeriesConflictError{Occurrences: problems}
+		}
+
+		if _, err := tx.CreateSeries(series); err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, occurrence := range starts {
+			appointment := &models.Appointment{
+				PatientID:   req.PatientID,
+				DoctorID:    req.DoctorID,
+				DateTime:    occurrence,
+				Duration:    req.Duration,
+				Status:      models.AppointmentScheduled,
+				Notes:       models.StringPtr(req.Notes),
+				CreatedBy:   actor.UserID,
+				SeriesID:    &series.ID,
+				ScheduledAt: &now,
+			}
+			if _, err := tx.Create(appointment); err != nil {
+				if errors.Is(err, repository.ErrScheduleOverlap) {
+					overlapping = appointment
+				}
+				return err
+			}
+			series.Appointments = append(series.Appointments, appointment)
+		}
+		return nil
+	})
+	if err != nil {
+		var seriesErr *SeriesConflictError
+		if errors.As(err, &seriesErr) {
+			return nil, seriesErr
+		}
+		if overlapping != nil {
+			return nil, s.seriesOverlapConflict(overlapping)
+		}
+		return nil, fmt.Errorf("failed to create appointment series: %w", err)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityAppointmentSeries, series.ID, series.PatientID, nil, series); err != nil {
+		return nil, err
+	}
+	for _, appointment := range series.Appointments {
+		if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityAppointment, appointment.ID, appointment.PatientID, nil, appointment); err != nil {
+			return nil, err
+		}
+		s.publish(AppointmentEventCreated, nil, appointment)
+	}
+
+	return series, nil
+}
+
+func (s *appointmentService) GetAppointmentSeries(actor *models.Actor, id uint) (*models.AppointmentSeries, error) {
+	series, err := s.appointmentRepo.GetSeries(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityAppointmentSeries, series.ID, series.PatientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (s *appointmentService) UpdateAppointmentSeries(actor *models.Actor, appointmentID uint, req *models.AppointmentSeriesUpdateRequest) ([]*models.Appointment, error) {
+	target, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("appointment not found: %w", err)
+	}
+	if target.SeriesID == nil {
+		return nil, ErrNotInSeries
+	}
+
+	if req.Scope == models.SeriesScopeThis {
+		updated, err := s.UpdateAppointment(actor, appointmentID, &models.AppointmentUpdateRequest{
+			DateTime: req.DateTime,
+			Duration: req.Duration,
+			Notes:    req.Notes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Appointment{updated}, nil
+	}
+
+	var shift time.Duration
+	if req.DateTime != "" {
+		newStart, err := time.Parse(time.RFC3339, req.DateTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date_time format: %w", err)
+		}
+		shift = newStart.Sub(target.DateTime)
+	}
+
+	occurrences, err := s.seriesOccurrences(target, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// Occurrences that have started or ended keep their original details
+	var changes []occurrenceChange
+	for _, occurrence := range occurrences {
+		if !isReschedulable(occurrence.Status) {
+			continue
+		}
+		change := occurrenceChange{before: *occurrence, after: occurrence}
+		occurrence.DateTime = occurrence.DateTime.Add(shift)
+		if req.Duration != 0 {
+			occurrence.Duration = req.Duration
+		}
+		if req.Notes != "" {
+			occurrence.Notes = models.StringPtr(req.Notes)
+		}
+		changes = append(changes, change)
+	}
+
+	// Move occurrences in the direction of the shift so none overlaps a
+	// sibling that has not been moved yet
+	if shift > 0 {
+		sort.Slice(changes, func(i, j int) bool { return changes[i].after.DateTime.After(changes[j].after.DateTime) })
+	}
+
+	var problems []OccurrenceConflict
+	for _, change := range changes {
+		if change.moved() && isActiveStatus(change.after.Status) {
+			if err := s.availability.CheckBookable(change.after.DoctorID, change.after.DateTime, change.after.Duration); err != nil {
+				if !errors.Is(err, ErrOutsideAvailability) {
+					return nil, err
+				}
+				problems = append(problems, OccurrenceConflict{Start: change.after.DateTime, Reason: err.Error()})
+			}
+		}
+	}
+
+	// overlapping is the occurrence the double-booking constraint refused
+	var overlapping *models.Appointment
+	err = s.appointmentRepo.Transaction(func(tx repository.AppointmentRepository) error {
+		if err := tx.LockDoctorSchedule(target.DoctorID); err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if change.moved() && isActiveStatus(change.after.Status) {
+				conflicts, err := tx.FindConflicts(change.after.DoctorID, change.after.DateTime, change.after.Duration, change.after.ID)
+				if err != nil {
+					return err
+				}
+				if len(conflicts) > 0 {
+					problems = append(problems, OccurrenceConflict{Start: change.after.DateTime, Reason: "overlaps existing appointments", Conflicts: conflicts})
+					continue
+				}
+			}
+			// Once anything has failed the transaction will be rolled back;
+			// keep checking the rest only to report every problem at once
+			if len(problems) > 0 {
+				continue
+			}
+			if _, err := tx.Update(change.after); err != nil {
+				if errors.Is(err, repository.ErrScheduleOverlap) {
+					overlapping = change.after
+				}
+				return err
+			}
+		}
+		if len(problems) > 0 {
+			sort.Slice(problems, func(i, j int) bool { return problems[i].Start.Before(problems[j].Start) })
+			return &SeriesConflictError{Occurrences: problems}
+		}
+		return nil
+	})
+	if err != nil {
+		var seriesErr *SeriesConflictError
+		if errors.As(err, &seriesErr) {
+			return nil, seriesErr
+		}
+		if overlapping != nil {
+			return nil, s.seriesOverlapConflict(overlapping)
+		}
+		return nil, fmt.Errorf("failed to update appointment series: %w", err)
+	}
+
+	return s.recordOccurrenceChanges(actor, changes)
+}
+
+func (s *appointmentService) CancelAppointmentSeries(actor *models.Actor, appointmentID uint, req *models.AppointmentSeriesCancelRequest) ([]*models.Appointment, error) {
+	target, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("appointment not found: %w", err)
+	}
+	if target.SeriesID == nil {
+		return nil, ErrNotInSeries
+	}
+
+	if req.Scope == models.SeriesScopeThis {
+		cancelled, err := s.ChangeAppointmentStatus(actor, appointmentID, &models.AppointmentStatusRequest{
+			Status: models.AppointmentCancelled,
+			Reason: req.Reason,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []*models.Appointment{cancelled}, nil
+	}
+
+	occurrences, err := s.seriesOccurrences(target, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// Occurrences that can no longer be cancelled are left as they are
+	now := time.Now()
+	var changes []occurrenceChange
+	for _, occurrence := range occurrences {
+		if _, ok := appointmentTransitions[occurrence.Status][models.AppointmentCancelled]; !ok {
+			continue
+		}
+		change := occurrenceChange{before: *occurrence, after: occurrence}
+		if err := transitionAppointment(actor, occurrence, models.AppointmentCancelled, req.Reason, now); err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	err = s.appointmentRepo.Transaction(func(tx repository.AppointmentRepository) error {
+		for _, change := range changes {
+			if _, err := tx.Update(change.after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel appointment series: %w", err)
+	}
+
+	return s.recordOccurrenceChanges(actor, changes)
+}
+
+// seriesOccurrences returns the occurrences of target's series covered by
+// scope: target and every later occurrence, or the whole series.
+func (s *appointmentService) seriesOccurrences(target *models.Appointment, scope string) ([]*models.Appointment, error) {
+	var from time.Time
+	switch scope {
+	case models.SeriesScopeFollowing:
+		from = target.DateTime
+	case models.SeriesScopeAll:
+	default:
+		return nil, fmt.Errorf("scope must be one of: this following all")
+	}
+
+	occurrences, err := s.appointmentRepo.GetSeriesOccurrences(*target.SeriesID, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get appointment series: %w", err)
+	}
+	return occurrences, nil
+}
+
+// recordOccurrenceChanges audits and publishes every changed occurrence and
+// returns them in date order.
+func (s *appointmentService) recordOccurrenceChanges(actor *models.Actor, changes []occurrenceChange) ([]*models.Appointment, error) {
+	updated := make([]*models.Appointment, 0, len(changes))
+	for _, change := range changes {
+		if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityAppointment, change.after.ID, change.after.PatientID, &change.before, change.after); err != nil {
+			return nil, err
+		}
+		before := change.before
+		s.publish(AppointmentEventUpdated, &before, change.after)
+		updated = append(updated, change.after)
+	}
+	sort.Slice(updated, func(i, j int) bool { return updated[i].DateTime.Before(updated[j].DateTime) })
+	return updated, nil
+}
+
+// occurrenceChange pairs an occurrence with its state before a series edit.
+type occurrenceChange struct {
+	before models.Appointment
+	after  *models.Appointment
+}
+
+func (c occurrenceChange) moved() bool {
+	return !c.after.DateTime.Equal(c.before.DateTime) || c.after.Duration != c.before.Duration
+}
+
+// expandRecurrence lists the start of every occurrence of rule, beginning
+// with start. Times are stepped in start's location so occurrences keep their
+// wall-clock time across daylight-saving changes. As in RRULE, monthly
+// occurrences that would fall on a day the month does not have are skipped.
+func expandRecurrence(start time.Time, rule models.Recurrence) ([]time.Time, error) {
+	var days, months int
+	switch rule.Frequency {
+	case models.RecurrenceDaily:
+		days = 1
+	case models.RecurrenceWeekly:
+		days = 7
+	case models.RecurrenceMonthly:
+		months = 1
+	default:
+		return nil, fmt.Errorf("freq must be one of: daily weekly monthly")
+	}
+
+	interval := rule.Interval
+	if interval == 0 {
+		interval = 1
+	}
+	if interval < 0 {
+		return nil, fmt.Errorf("interval must be positive")
+	}
+	if (rule.Count == 0) == (rule.Until == nil) {
+		return nil, fmt.Errorf("exactly one of count or until is required")
+	}
+	if rule.Count < 0 || rule.Count > models.MaxSeriesOccurrences {
+		return nil, fmt.Errorf("count must be between 1 and %d", models.MaxSeriesOccurrences)
+	}
+	if rule.Until != nil && rule.Until.Before(start) {
+		return nil, fmt.Errorf("until must not be before the first occurrence")
+	}
+
+	var starts []time.Time
+	for i := 0; ; i++ {
+		next := time.Date(start.Year(), start.Month()+time.Month(i*interval*months), start.Day()+i*interval*days,
+			start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+		if rule.Until != nil && next.After(*rule.Until) {
+			break
+		}
+		if months > 0 && next.Day() != start.Day() {
+			continue
+		}
+		if len(starts) == models.MaxSeriesOccurrences {
+			return nil, fmt.Errorf("a series cannot have more than %d occurrences", models.MaxSeriesOccurrences)
+		}
+		starts = append(starts, next)
+		if rule.Count > 0 && len(starts) == rule.Count {
+			break
+		}
+	}
+	return starts, nil
+}
+
+// seriesOverlapConflict reports an occurrence the database refused as a
+// double booking the same way as one found before writing.
+func (s *appointmentService) seriesOverlapConflict(occurrence *models.Appointment) *SeriesConflictError {
+	return &SeriesConflictError{Occurrences: []OccurrenceConflict{{
+		Start:     occurrence.DateTime,
+		Reason:    "overlaps existing appointments",
+		Conflicts: s.overlapConflict(occurrence).Conflicts,
+	}}}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"hospital-management/internal/models"
+)
+
+func TestExpandRecurrence(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(loc *time.Location, year int, month time.Month, day, hour int) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, loc)
+	}
+	until := func(t time.Time) *time.Time { return &t }
+
+	tests := []struct {
+		name  string
+		start time.Time
+		rule  models.Recurrence
+		want  []time.Time
+	}{
+		{
+			name:  "daily count",
+			start: date(time.UTC, 2030, 3, 4, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceDaily, Count: 3},
+			want:  []time.Time{date(time.UTC, 2030, 3, 4, 9), date(time.UTC, 2030, 3, 5, 9), date(time.UTC, 2030, 3, 6, 9)},
+		},
+		{
+			name:  "daily interval crosses month end",
+			start: date(time.UTC, 2030, 1, 30, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceDaily, Interval: 2, Count: 3},
+			want:  []time.Time{date(time.UTC, 2030, 1, 30, 9), date(time.UTC, 2030, 2, 1, 9), date(time.UTC, 2030, 2, 3, 9)},
+		},
+		{
+			name:  "weekly until is inclusive",
+			start: date(time.UTC, 2030, 3, 4, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceWeekly, Until: until(date(time.UTC, 2030, 3, 18, 9))},
+			want:  []time.Time{date(time.UTC, 2030, 3, 4, 9), date(time.UTC, 2030, 3, 11, 9), date(time.UTC, 2030, 3, 18, 9)},
+		},
+		{
+			name:  "fortnightly until between occurrences",
+			start: date(time.UTC, 2030, 3, 4, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceWeekly, Interval: 2, Until: until(date(time.UTC, 2030, 3, 31, 0))},
+			want:  []time.Time{date(time.UTC, 2030, 3, 4, 9), date(time.UTC, 2030, 3, 18, 9)},
+		},
+		{
+			name:  "monthly count",
+			start: date(time.UTC, 2030, 1, 15, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceMonthly, Count: 3},
+			want:  []time.Time{date(time.UTC, 2030, 1, 15, 9), date(time.UTC, 2030, 2, 15, 9), date(time.UTC, 2030, 3, 15, 9)},
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			start: date(time.UTC, 2030, 1, 31, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceMonthly, Count: 4},
+			want:  []time.Time{date(time.UTC, 2030, 1, 31, 9), date(time.UTC, 2030, 3, 31, 9), date(time.UTC, 2030, 5, 31, 9), date(time.UTC, 2030, 7, 31, 9)},
+		},
+		{
+			name:  "monthly on the 30th skips February",
+			start: date(time.UTC, 2030, 1, 30, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceMonthly, Until: until(date(time.UTC, 2030, 4, 30, 9))},
+			want:  []time.Time{date(time.UTC, 2030, 1, 30, 9), date(time.UTC, 2030, 3, 30, 9), date(time.UTC, 2030, 4, 30, 9)},
+		},
+		{
+			name:  "monthly on the 29th keeps leap-year February",
+			start: date(time.UTC, 2031, 12, 29, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceMonthly, Count: 3},
+			want:  []time.Time{date(time.UTC, 2031, 12, 29, 9), date(time.UTC, 2032, 1, 29, 9), date(time.UTC, 2032, 2, 29, 9)},
+		},
+		{
+			name:  "daily keeps wall-clock time into daylight saving",
+			start: date(newYork, 2030, 3, 9, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceDaily, Count: 3},
+			want:  []time.Time{date(newYork, 2030, 3, 9, 9), date(newYork, 2030, 3, 10, 9), date(newYork, 2030, 3, 11, 9)},
+		},
+		{
+			name:  "weekly keeps wall-clock time out of daylight saving",
+			start: date(newYork, 2030, 10, 28, 9),
+			rule:  models.Recurrence{Frequency: models.RecurrenceWeekly, Count: 2},
+			want:  []time.Time{date(newYork, 2030, 10, 28, 9), date(newYork, 2030, 11, 4, 9)},
+		},
+	}
+	for _, tt := range tests {
+		got, err := expandRecurrence(tt.start, tt.rule)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tt.want[i]) || got[i].Location() != tt.start.Location() {
+				t.Errorf("%s: occurrence %d = %v, want %v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+
+	// The daylight-saving cases really span an offset change.
+	spring, _ := expandRecurrence(date(newYork, 2030, 3, 9, 9), models.Recurrence{Frequency: models.RecurrenceDaily, Count: 2})
+	if gap := spring[1].Sub(spring[0]); gap != 23*time.Hour {
+		t.Errorf("occurrences across the spring change are %v apart, want 23h", gap)
+	}
+}
+
+func TestExpandRecurrenceRejectsInvalidRules(t *testing.T) {
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+	before := start.AddDate(0, 0, -1)
+	farAway := start.AddDate(1, 0, 0)
+
+	tests := []struct {
+		name string
+		rule models.Recurrence
+		want string
+	}{
+		{"unknown frequency", models.Recurrence{Frequency: "yearly", Count: 2}, "freq must be one of"},
+		{"negative interval", models.Recurrence{Frequency: models.RecurrenceDaily, Interval: -1, Count: 2}, "interval must be positive"},
+		{"neither count nor until", models.Recurrence{Frequency: models.RecurrenceDaily}, "exactly one of count or until"},
+		{"both count and until", models.Recurrence{Frequency: models.RecurrenceDaily, Count: 2, Until: &farAway}, "exactly one of count or until"},
+		{"negative count", models.Recurrence{Frequency: models.RecurrenceDaily, Count: -1}, "count must be between"},
+		{"count over the cap", models.Recurrence{Frequency: models.RecurrenceDaily, Count: models.MaxSeriesOccurrences + 1}, "count must be between"},
+		{"until before start", models.Recurrence{Frequency: models.RecurrenceDaily, Until: &before}, "until must not be before"},
+		{"until past the cap", models.Recurrence{Frequency: models.RecurrenceDaily, Until: &farAway}, fmt.Sprintf("more than %d occurrences", models.MaxSeriesOccurrences)},
+	}
+	for _, tt := range tests {
+		_, err := expandRecurrence(start, tt.rule)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want an error containing %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestExpandRecurrenceCap(t *testing.T) {
+	start := time.Date(2030, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	got, err := expandRecurrence(start, models.Recurrence{Frequency: models.RecurrenceDaily, Count: models.MaxSeriesOccurrences})
+	if err != nil || len(got) != models.MaxSeriesOccurrences {
+		t.Fatalf("got %d occurrences, %v, want %d", len(got), err, models.MaxSeriesOccurrences)
+	}
+
+	last := got[len(got)-1]
+	got, err = expandRecurrence(start, models.Recurrence{Frequency: models.RecurrenceDaily, Until: &last})
+	if err != nil || len(got) != models.MaxSeriesOccurrences {
+		t.Fatalf("until the last allowed occurrence: got %d, %v, want %d", len(got), err, models.MaxSeriesOccurrences)
+	}
+}
+
+// closedDaysAvailability refuses bookings on the given days of the month.
+type closedDaysAvailability struct {
+	AvailabilityService
+	closed map[int]bool
+}
+
+func (a closedDaysAvailability) CheckBookable(doctorID uint, start time.Time, duration int) error {
+	if a.closed[start.Day()] {
+		return fmt.Errorf("%w: the clinic is closed", ErrOutsideAvailability)
+	}
+	return nil
+}
+
+func seriesRequest(count int) *models.AppointmentSeriesRequest {
+	return &models.AppointmentSeriesRequest{
+		PatientID:  7,
+		DoctorID:   testDoctorID,
+		DateTime:   "2030-03-04T09:00:00Z",
+		Duration:   30,
+		Recurrence: models.Recurrence{Frequency: models.RecurrenceDaily, Count: count},
+	}
+}
+
+func TestCreateAppointmentSeries(t *testing.T) {
+	repo := newFakeAppointmentRepository()
+
+	series, err := newTestAppointmentService(repo).CreateAppointmentSeries(&models.Actor{UserID: 2}, seriesRequest(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(series.Appointments) != 3 || len(repo.appointments) != 3 {
+		t.Fatalf("booked %d occurrences, stored %d, want 3", len(series.Appointments), len(repo.appointments))
+	}
+	if series.Recurrence.Interval != 1 {
+		t.Errorf("interval = %d, want the default of 1 recorded", series.Recurrence.Interval)
+	}
+	for i, appointment := range series.Appointments {
+		want := time.Date(2030, 3, 4+i, 9, 0, 0, 0, time.UTC)
+		if !appointment.DateTime.Equal(want) || appointment.SeriesID == nil || *appointment.SeriesID != series.ID {
+			t.Errorf("occurrence %d = %v in series %v, want %v in series %d", i, appointment.DateTime, appointment.SeriesID, want, series.ID)
+		}
+	}
+}
+
+func TestCreateAppointmentSeriesReportsEveryConflict(t *testing.T) {
+	repo := newFakeAppointmentRepository()
+	existing := &models.Appointment{ID: 41, DoctorID: testDoctorID, Status: models.AppointmentScheduled}
+	// The first occurrence is free, the second overlaps appointment 41, the
+	// third is free and the fourth falls on a closed day.
+	repo.conflicts = [][]*models.Appointment{nil, {existing}, nil, nil}
+	users := &memoryUserRepository{}
+	users.Create(&models.User{Name: "dr_smith", Role: models.RoleDoctor})
+	availability := closedDaysAvailability{closed: map[int]bool{7: true}}
+	s := NewAppointmentService(repo, fakePatientRepository{}, users, fakeAuditService{}, availability, time.UTC)
+
+	_, err := s.CreateAppointmentSeries(&models.Actor{UserID: 2}, seriesRequest(4))
+
+	var seriesErr *SeriesConflictError
+	if !errors.As(err, &seriesErr) {
+		t.Fatalf("got %v, want a SeriesConflictError", err)
+	}
+	if len(seriesErr.Occurrences) != 2 {
+		t.Fatalf("reported %d occurrences, want 2: %+v", len(seriesErr.Occurrences), seriesErr.Occurrences)
+	}
+	overlap, closed := seriesErr.Occurrences[0], seriesErr.Occurrences[1]
+	if !overlap.Start.Equal(time.Date(2030, 3, 5, 9, 0, 0, 0, time.UTC)) || len(overlap.Conflicts) != 1 || overlap.Conflicts[0].ID != existing.ID {
+		t.Errorf("first problem = %+v, want the 5th overlapping appointment %d", overlap, existing.ID)
+	}
+	if !closed.Start.Equal(time.Date(2030, 3, 7, 9, 0, 0, 0, time.UTC)) || !strings.Contains(closed.Reason, "closed") {
+		t.Errorf("second problem = %+v, want the 7th outside availability", closed)
+	}
+	if len(repo.appointments) != 0 || len(repo.series) != 0 {
+		t.Fatal("a series with conflicts was saved")
+	}
+}
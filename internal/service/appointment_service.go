@@ -1,52 +1,58 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"hospital-management/internal/models"
 	"hospital-management/internal/repository"
+	"sync"
 	"time"
 )
 
 type AppointmentService interface {
-	CreateAppointment(req *models.AppointmentRequest) (*models.Appointment, error)
-	GetAppointmentByID(id uint) (*models.Appointment, error)
-	UpdateAppointment(id uint, req *models.AppointmentUpdateRequest) (*models.Appointment, error)
-	DeleteAppointment(id uint) error
-	GetAppointmentsByPatient(patientID uint) ([]*models.Appointment, error)
-	GetAppointmentsByDoctor(doctorID uint) ([]*models.Appointment, error)
-	GetAllAppointments() ([]*models.Appointment, error)
+	CreateAppointment(actor *models.Actor, req *models.AppointmentRequest) (*models.Appointment, error)
+	GetAppointmentByID(actor *models.Actor, id uint) (*models.Appointment, error)
+	UpdateAppointment(actor *models.Actor, id uint, req *models.AppointmentUpdateRequest) (*models.Appointment, error)
+	ChangeAppointmentStatus(actor *models.Actor, id uint, req *models.AppointmentStatusRequest) (*models.Appointment, error)
+	DeleteAppointment(actor *models.Actor, id uint) error
+	GetAppointmentsByPatient(actor *models.Actor, patientID uint) ([]*models.Appointment, error)
+	GetAppointmentsByDoctor(actor *models.Actor, doctorID uint) ([]*models.Appointment, error)
+	GetAllAppointments(actor *models.Actor) ([]*models.Appointment, error)
+	ListAppointments(actor *models.Actor, filter *models.AppointmentFilter, opts *models.ListOptions) (*models.ListResult[*models.Appointment], error)
+	CreateAppointmentSeries(actor *models.Actor, req *models.AppointmentSeriesRequest) (*models.AppointmentSeries, error)
+	GetAppointmentSeries(actor *models.Actor, id uint) (*models.AppointmentSeries, error)
+	UpdateAppointmentSeries(actor *models.Actor, appointmentID uint, req *models.AppointmentSeriesUpdateRequest) ([]*models.Appointment, error)
+	CancelAppointmentSeries(actor *models.Actor, appointmentID uint, req *models.AppointmentSeriesCancelRequest) ([]*models.Appointment, error)
+	// Subscribe registers a listener for committed appointment changes.
+	Subscribe(listener AppointmentListener)
 }
 
 type appointmentService struct {
 	appointmentRepo repository.AppointmentRepository
 	patientRepo     repository.PatientRepository
 	userRepo        repository.UserRepository
+	auditService    AuditService
+	availability    AvailabilityService
+	location        *time.Location
+
+	listenersMu sync.RWMutex
+	listeners   []AppointmentListener
 }
 
-func NewAppointmentService(appointmentRepo repository.AppointmentRepository, patientRepo repository.PatientRepository, userRepo repository.UserRepository) AppointmentService {
+func NewAppointmentService(appointmentRepo repository.AppointmentRepository, patientRepo repository.PatientRepository, userRepo repository.UserRepository, auditService AuditService, availability AvailabilityService, location *time.Location) AppointmentService {
 	return &appointmentService{
 		appointmentRepo: appointmentRepo,
 		patientRepo:     patientRepo,
 		userRepo:        userRepo,
+		auditService:    auditService,
+		availability:    availability,
+		location:        location,
 	}
 }
 
-func (s *appointmentService) CreateAppointment(req *models.AppointmentRequest) (*models.Appointment, error) {
-	// Validate patient exists - convert uint to int
-	_, err := s.patientRepo.GetByID(int(req.PatientID))
-	if err != nil {
-		return nil, fmt.Errorf("patient not found: %w", err)
-	}
-
-	// Validate doctor exists - convert uint to int
-	doctor, err := s.userRepo.GetByID(uint(req.DoctorID))
-	if err != nil {
-		return nil, fmt.Errorf("doctor not found: %w", err)
-	}
-
-	// Validate doctor role
-	if doctor.Role != "doctor" {
-		return nil, fmt.Errorf("user is not a doctor")
+func (s *appointmentService) CreateAppointment(actor *models.Actor, req *models.AppointmentRequest) (*models.Appointment, error) {
+	if err := s.validateParticipants(req.PatientID, req.DoctorID); err != nil {
+		return nil, err
 	}
 
 	// Parse DateTime from string to time.Time
@@ -55,98 +61,289 @@ func (s *appointmentService) CreateAppointment(req *models.AppointmentRequest) (
 		return nil, fmt.Errorf("invalid date_time format: %w", err)
 	}
 
+	now := time.Now()
 	appointment := &models.Appointment{
 		PatientID: req.PatientID,
 		DoctorID:  req.DoctorID,
 		DateTime:  parsedDateTime,
 		Duration:  req.Duration,
-		Status:    "scheduled",
+		Status:    models.AppointmentScheduled,
 		Notes:     models.StringPtr(req.Notes),
-		CreatedBy: 0, // Set appropriately if needed
+		CreatedBy: actor.UserID,
+	}
+	switch req.Status {
+	case "", models.AppointmentScheduled:
+		appointment.ScheduledAt = &now
+	case models.AppointmentRequested:
+		appointment.Status = models.AppointmentRequested
+	default:
+		return nil, &TransitionError{From: "new", To: req.Status}
+	}
+
+	if err := s.availability.CheckBookable(appointment.DoctorID, appointment.DateTime, appointment.Duration); err != nil {
+		return nil, err
 	}
 
-	createdAppointment, err := s.appointmentRepo.Create(appointment)
+	var createdAppointment *models.Appointment
+	err = s.appointmentRepo.Transaction(func(tx repository.AppointmentRepository) error {
+		if err := checkConflicts(tx, appointment, 0); err != nil {
+			return err
+		}
+		createdAppointment, err = tx.Create(appointment)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create appointment: %w", err)
+		return nil, s.wrapBookingError("failed to create appointment", appointment, err)
 	}
 
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityAppointment, createdAppointment.ID, createdAppointment.PatientID, nil, createdAppointment); err != nil {
+		return nil, err
+	}
+
+	s.publish(AppointmentEventCreated, nil, createdAppointment)
 	return createdAppointment, nil
 }
 
-func (s *appointmentService) GetAppointmentByID(id uint) (*models.Appointment, error) {
+func (s *appointmentService) GetAppointmentByID(actor *models.Actor, id uint) (*models.Appointment, error) {
 	appointment, err := s.appointmentRepo.GetByID(uint(id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get appointment: %w", err)
 	}
+
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityAppointment, appointment.ID, appointment.PatientID, nil, nil); err != nil {
+		return nil, err
+	}
+
 	return appointment, nil
 }
 
-func (s *appointmentService) UpdateAppointment(id uint, req *models.AppointmentUpdateRequest) (*models.Appointment, error) {
+func (s *appointmentService) UpdateAppointment(actor *models.Actor, id uint, req *models.AppointmentUpdateRequest) (*models.Appointment, error) {
 	// Get existing appointment
 	appointment, err := s.appointmentRepo.GetByID(uint(id))
 	if err != nil {
 		return nil, fmt.Errorf("appointment not found: %w", err)
 	}
+	before := *appointment
 
 	// Update fields
 	if req.DateTime != "" {
 		parsedDateTime, err := time.Parse(time.RFC3339, req.DateTime)
-		if err == nil {
-			appointment.DateTime = parsedDateTime
+		if err != nil {
+			return nil, fmt.Errorf("invalid date_time format: %w", err)
 		}
+		appointment.DateTime = parsedDateTime
 	}
 	if req.Duration != 0 {
 		appointment.Duration = req.Duration
 	}
-	if req.Status != "" {
-		appointment.Status = req.Status
-	}
 	if req.Notes != "" {
 		appointment.Notes = models.StringPtr(req.Notes)
 	}
-	if req.Diagnosis != "" {
-		appointment.Diagnosis = models.StringPtr(req.Diagnosis)
+
+	moved := !appointment.DateTime.Equal(before.DateTime) || appointment.Duration != before.Duration
+	if moved && !isReschedulable(before.Status) {
+		return nil, ErrNotReschedulable
 	}
-	if req.Treatment != "" {
-		appointment.Treatment = models.StringPtr(req.Treatment)
+	if req.Status != "" && req.Status != before.Status {
+		if err := transitionAppointment(actor, appointment, req.Status, req.Reason, time.Now()); err != nil {
+			return nil, err
+		}
 	}
 
-	updatedAppointment, err := s.appointmentRepo.Update(appointment)
+	return s.saveAppointment(actor, &before, appointment)
+}
+
+func (s *appointmentService) ChangeAppointmentStatus(actor *models.Actor, id uint, req *models.AppointmentStatusRequest) (*models.Appointment, error) {
+	appointment, err := s.appointmentRepo.GetByID(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update appointment: %w", err)
+		return nil, fmt.Errorf("appointment not found: %w", err)
 	}
+	before := *appointment
 
+	if err := transitionAppointment(actor, appointment, req.Status, req.Reason, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.saveAppointment(actor, &before, appointment)
+}
+
+// saveAppointment persists changes to an existing appointment. A change to
+// when it happens, or a move into an active status, is checked against the
+// doctor's availability and schedule before it is written.
+func (s *appointmentService) saveAppointment(actor *models.Actor, before, appointment *models.Appointment) (*models.Appointment, error) {
+	rebooked := isActiveStatus(appointment.Status) &&
+		(!appointment.DateTime.Equal(before.DateTime) ||
+			appointment.Duration != before.Duration ||
+			!isActiveStatus(before.Status))
+
+	if rebooked {
+		if err := s.availability.CheckBookable(appointment.DoctorID, appointment.DateTime, appointment.Duration); err != nil {
+			return nil, err
+		}
+	}
+
+	var updatedAppointment *models.Appointment
+	err := s.appointmentRepo.Transaction(func(tx repository.AppointmentRepository) error {
+		if rebooked {
+			if err := checkConflicts(tx, appointment, appointment.ID); err != nil {
+				return err
+			}
+		}
+		var err error
+		updatedAppointment, err = tx.Update(appointment)
+		return err
+	})
+	if err != nil {
+		return nil, s.wrapBookingError("failed to update appointment", appointment, err)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityAppointment, updatedAppointment.ID, updatedAppointment.PatientID, before, updatedAppointment); err != nil {
+		return nil, err
+	}
+
+	s.publish(AppointmentEventUpdated, before, updatedAppointment)
 	return updatedAppointment, nil
 }
 
-func (s *appointmentService) DeleteAppointment(id uint) error {
-	err := s.appointmentRepo.Delete(uint(id))
+func (s *appointmentService) DeleteAppointment(actor *models.Actor, id uint) error {
+	appointment, err := s.appointmentRepo.GetByID(uint(id))
 	if err != nil {
 		return fmt.Errorf("failed to delete appointment: %w", err)
 	}
+
+	if err := s.appointmentRepo.Delete(uint(id)); err != nil {
+		return fmt.Errorf("failed to delete appointment: %w", err)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionDelete, models.AuditEntityAppointment, appointment.ID, appointment.PatientID, appointment, nil); err != nil {
+		return err
+	}
+
+	s.publish(AppointmentEventDeleted, appointment, nil)
 	return nil
 }
 
-func (s *appointmentService) GetAppointmentsByPatient(patientID uint) ([]*models.Appointment, error) {
+func (s *appointmentService) GetAppointmentsByPatient(actor *models.Actor, patientID uint) ([]*models.Appointment, error) {
 	appointments, err := s.appointmentRepo.GetByPatientID(uint(patientID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get appointments for patient: %w", err)
 	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityAppointment, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
 	return appointments, nil
 }
 
-func (s *appointmentService) GetAppointmentsByDoctor(doctorID uint) ([]*models.Appointment, error) {
+func (s *appointmentService) GetAppointmentsByDoctor(actor *models.Actor, doctorID uint) ([]*models.Appointment, error) {
 	appointments, err := s.appointmentRepo.GetByDoctorID(uint(doctorID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get appointments for doctor: %w", err)
 	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityAppointment, 0, 0, nil, nil); err != nil {
+		return nil, err
+	}
+
 	return appointments, nil
 }
 
-func (s *appointmentService) GetAllAppointments() ([]*models.Appointment, error) {
+func (s *appointmentService) GetAllAppointments(actor *models.Actor) ([]*models.Appointment, error) {
 	appointments, err := s.appointmentRepo.GetAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all appointments: %w", err)
 	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityAppointment, 0, 0, nil, nil); err != nil {
+		return nil, err
+	}
+
 	return appointments, nil
 }
+
+func (s *appointmentService) ListAppointments(actor *models.Actor, filter *models.AppointmentFilter, opts *models.ListOptions) (*models.ListResult[*models.Appointment], error) {
+	result, err := s.appointmentRepo.List(filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list appointments: %w", err)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityAppointment, 0, filter.PatientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// validateParticipants checks that the patient exists and that the doctor is
+// a user with the doctor role.
+func (s *appointmentService) validateParticipants(patientID, doctorID uint) error {
+	if _, err := s.patientRepo.GetByID(int(patientID)); err != nil {
+		return fmt.Errorf("patient not found: %w", err)
+	}
+
+	doctor, err := s.userRepo.GetByID(doctorID)
+	if err != nil {
+		return fmt.Errorf("doctor not found: %w", err)
+	}
+	if doctor.Role != models.RoleDoctor {
+		return fmt.Errorf("user is not a doctor")
+	}
+	return nil
+}
+
+// checkConflicts locks the doctor's schedule for the rest of the transaction
+// and fails with a ConflictError if the appointment overlaps another active
+// one. Appointments that are not active never conflict.
+func checkConflicts(tx repository.AppointmentRepository, appointment *models.Appointment, excludeID uint) error {
+	if !isActiveStatus(appointment.Status) {
+		return nil
+	}
+
+	if err := tx.LockDoctorSchedule(appointment.DoctorID); err != nil {
+		return err
+	}
+
+	conflicts, err := tx.FindConflicts(appointment.DoctorID, appointment.DateTime, appointment.Duration, excludeID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+func isActiveStatus(status string) bool {
+	for _, active := range models.ActiveAppointmentStatuses {
+		if status == active {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapBookingError passes conflicts through unchanged so callers can detect
+// them, and wraps any other error with msg. An overlap the database's
+// double-booking constraint caught is reported as a conflict as well.
+func (s *appointmentService) wrapBookingError(msg string, appointment *models.Appointment, err error) error {
+	var conflictErr *ConflictError
+	if errors.As(err, &conflictErr) {
+		return conflictErr
+	}
+	if errors.Is(err, repository.ErrScheduleOverlap) {
+		return s.overlapConflict(appointment)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// overlapConflict builds the ConflictError for an appointment the database
+// refused as a double booking. The transaction that found it has been rolled
+// back, so the overlapping appointments are looked up again.
+func (s *appointmentService) overlapConflict(appointment *models.Appointment) *ConflictError {
+	conflicts, err := s.appointmentRepo.FindConflicts(appointment.DoctorID, appointment.DateTime, appointment.Duration, appointment.ID)
+	if err != nil {
+		return &ConflictError{}
+	}
+	return &ConflictError{Conflicts: conflicts}
+}
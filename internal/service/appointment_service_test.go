@@ -0,0 +1,198 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+)
+
+// fakeAppointmentRepository keeps appointments in a map. Methods the tests
+// do not need are left to the embedded interface and panic if called.
+type fakeAppointmentRepository struct {
+	repository.AppointmentRepository
+	appointments map[uint]*models.Appointment
+	// conflicts is what FindConflicts returns on each call, in turn.
+	conflicts [][]*models.Appointment
+	// writeErr fails the next Create or Update.
+	writeErr error
+	nextID   uint
+	series   []*models.AppointmentSeries
+}
+
+func newFakeAppointmentRepository() *fakeAppointmentRepository {
+	return &fakeAppointmentRepository{appointments: make(map[uint]*models.Appointment)}
+}
+
+func (r *fakeAppointmentRepository) Transaction(fn func(tx repository.AppointmentRepository) error) error {
+	return fn(r)
+}
+
+func (r *fakeAppointmentRepository) LockDoctorSchedule(doctorID uint) error {
+	return nil
+}
+
+func (r *fakeAppointmentRepository) FindConflicts(doctorID uint, dateTime time.Time, duration int, excludeID uint) ([]*models.Appointment, error) {
+	if len(r.conflicts) == 0 {
+		return nil, nil
+	}
+	conflicts := r.conflicts[0]
+	r.conflicts = r.conflicts[1:]
+	return conflicts, nil
+}
+
+func (r *fakeAppointmentRepository) Create(appointment *models.Appointment) (*models.Appointment, error) {
+	if err := r.takeWriteErr(); err != nil {
+		return nil, err
+	}
+	r.nextID++
+	appointment.ID = r.nextID
+	copied := *appointment
+	r.appointments[appointment.ID] = &copied
+	return appointment, nil
+}
+
+func (r *fakeAppointmentRepository) CreateSeries(series *models.AppointmentSeries) (*models.AppointmentSeries, error) {
+	series.ID = uint(len(r.series) + 1)
+	r.series = append(r.series, series)
+	return series, nil
+}
+
+func (r *fakeAppointmentRepository) Update(appointment *models.Appointment) (*models.Appointment, error) {
+	if err := r.takeWriteErr(); err != nil {
+		return nil, err
+	}
+	copied := *appointment
+	r.appointments[appointment.ID] = &copied
+	return appointment, nil
+}
+
+func (r *fakeAppointmentRepository) GetByID(id uint) (*models.Appointment, error) {
+	appointment, ok := r.appointments[id]
+	if !ok {
+		return nil, fmt.Errorf("appointment with id %d not found", id)
+	}
+	copied := *appointment
+	return &copied, nil
+}
+
+func (r *fakeAppointmentRepository) takeWriteErr() error {
+	err := r.writeErr
+	r.writeErr = nil
+	return err
+}
+
+type fakePatientRepository struct {
+	repository.PatientRepository
+}
+
+func (fakePatientRepository) GetByID(id int) (*models.Patient, error) {
+	return &models.Patient{ID: uint(id)}, nil
+}
+
+type fakeAvailabilityService struct {
+	AvailabilityService
+}
+
+func (fakeAvailabilityService) CheckBookable(doctorID uint, start time.Time, duration int) error {
+	return nil
+}
+
+type fakeAuditService struct {
+	AuditService
+}
+
+func (fakeAuditService) Record(actor *models.Actor, action, entity string, entityID, patientID uint, before, after interface{}) error {
+	return nil
+}
+
+const testDoctorID = 1
+
+func newTestAppointmentService(repo *fakeAppointmentRepository) AppointmentService {
+	users := &memoryUserRepository{}
+	users.Create(&models.User{Name: "dr_smith", Role: models.RoleDoctor})
+	return NewAppointmentService(repo, fakePatientRepository{}, users, fakeAuditService{}, fakeAvailabilityService{}, time.UTC)
+}
+
+func bookingRequest() *models.AppointmentRequest {
+	return &models.AppointmentRequest{
+		PatientID: 7,
+		DoctorID:  testDoctorID,
+		DateTime:  "2030-03-04T09:00:00Z",
+		Duration:  30,
+	}
+}
+
+func TestCreateAppointmentRejectsOverlap(t *testing.T) {
+	repo := newFakeAppointmentRepository()
+	existing := &models.Appointment{ID: 41, DoctorID: testDoctorID, Status: models.AppointmentScheduled}
+	repo.conflicts = [][]*models.Appointment{{existing}}
+
+	_, err := newTestAppointmentService(repo).CreateAppointment(&models.Actor{UserID: 2}, bookingRequest())
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("got %v, want a ConflictError", err)
+	}
+	if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].ID != existing.ID {
+		t.Fatalf("conflicts = %+v, want appointment %d", conflictErr.Conflicts, existing.ID)
+	}
+	if len(repo.appointments) != 0 {
+		t.Fatal("the overlapping appointment was saved")
+	}
+}
+
+// TestCreateAppointmentReportsConstraintOverlap covers a booking that passes
+// the application check but is refused by the exclusion constraint.
+func TestCreateAppointmentReportsConstraintOverlap(t *testing.T) {
+	repo := newFakeAppointmentRepository()
+	existing := &models.Appointment{ID: 41, DoctorID: testDoctorID, Status: models.AppointmentScheduled}
+	repo.conflicts = [][]*models.Appointment{nil, {existing}}
+	repo.writeErr = fmt.Errorf("failed to create appointment: %w", repository.ErrScheduleOverlap)
+
+	_, err := newTestAppointmentService(repo).CreateAppointment(&models.Actor{UserID: 2}, bookingRequest())
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("got %v, want a ConflictError", err)
+	}
+	if len(conflictErr.Conflicts) != 1 || conflictErr.Conflicts[0].ID != existing.ID {
+		t.Fatalf("conflicts = %+v, want appointment %d", conflictErr.Conflicts, existing.ID)
+	}
+}
+
+func TestCreateAppointmentWithoutOverlap(t *testing.T) {
+	repo := newFakeAppointmentRepository()
+
+	created, err := newTestAppointmentService(repo).CreateAppointment(&models.Actor{UserID: 2}, bookingRequest())
+	if err != nil {
+		t.Fatalf("booking failed: %v", err)
+	}
+	if created.Status != models.AppointmentScheduled || created.ScheduledAt == nil {
+		t.Fatalf("created %+v, want a scheduled appointment", created)
+	}
+}
+
+func TestRescheduleRejectsOverlap(t *testing.T) {
+	repo := newFakeAppointmentRepository()
+	s := newTestAppointmentService(repo)
+	created, err := s.CreateAppointment(&models.Actor{UserID: 2}, bookingRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := &models.Appointment{ID: 41, DoctorID: testDoctorID, Status: models.AppointmentScheduled}
+	repo.conflicts = [][]*models.Appointment{{existing}}
+	_, err = s.UpdateAppointment(&models.Actor{UserID: 2}, created.ID, &models.AppointmentUpdateRequest{DateTime: "2030-03-04T10:00:00Z"})
+
+	var conflictErr *ConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("got %v, want a ConflictError", err)
+	}
+	if !repo.appointments[created.ID].DateTime.Equal(created.DateTime) {
+		t.Fatal("the appointment was moved despite the conflict")
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/encryption"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type AuditService interface {
+	// Record appends an audit entry. before and after are snapshots of the
+	// entity (nil for creates and deletes respectively); for updates only the
+	// fields that differ are stored.
+	Record(actor *models.Actor, action, entity string, entityID, patientID uint, before, after interface{}) error
+	Query(query *models.AuditQuery) ([]*models.AuditLog, error)
+}
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+func (s *auditService) Record(actor *models.Actor, action, entity string, entityID, patientID uint, before, after interface{}) error {
+	if actor == nil {
+		return fmt.Errorf("audit: missing actor")
+	}
+
+	entry := &models.AuditLog{
+		ActorID:   actor.UserID,
+		ActorRole: actor.Role,
+		Action:    action,
+		Entity:    entity,
+		EntityID:  models.UintPtr(entityID),
+		PatientID: models.UintPtr(patientID),
+		ClientIP:  actor.ClientIP,
+	}
+	if before != nil || after != nil {
+		entry.Changes = diffFields(before, after)
+	}
+
+	if _, err := s.auditRepo.Create(entry); err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	return nil
+}
+
+func (s *auditService) Query(query *models.AuditQuery) ([]*models.AuditLog, error) {
+	if query.Limit <= 0 || query.Limit > 500 {
+		query.Limit = 100
+	}
+	entries, err := s.auditRepo.Find(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// diffFields compares two snapshots of the same struct type field by field,
+// keyed by JSON name. Timestamps and preloaded relations are ignored, and
+// the values of fields encrypted at rest are redacted so that the audit log
+// does not hold them in plain text. Either snapshot may be nil, in which case
+// every set field of the other is reported.
+func diffFields(before, after interface{}) []models.FieldChange {
+	bv := indirect(reflect.ValueOf(before))
+	av := indirect(reflect.ValueOf(after))
+
+	var t reflect.Type
+	switch {
+	case av.IsValid():
+		t = av.Type()
+	case bv.IsValid():
+		t = bv.Type()
+	default:
+		return nil
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var changes []models.FieldChange
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := auditFieldName(field)
+		if !ok {
+			continue
+		}
+
+		var oldVal, newVal interface{}
+		if bv.IsValid() {
+			oldVal = fieldValue(bv.Field(i))
+		}
+		if av.IsValid() {
+			newVal = fieldValue(av.Field(i))
+		}
+		if reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+		if isEncryptedField(field) {
+			oldVal, newVal = redact(oldVal), redact(newVal)
+		}
+
+		changes = append(changes, models.FieldChange{Field: name, Before: oldVal, After: newVal})
+	}
+	return changes
+}
+
+// auditFieldName returns the JSON name of a field worth auditing. Slices and
+// structs are skipped as preloaded relations, unless they are encrypted
+// columns of their own.
+func auditFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	switch field.Name {
+	case "CreatedAt", "UpdatedAt":
+		return "", false
+	}
+
+	ft := field.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	isRelation := ft.Kind() == reflect.Slice || (ft.Kind() == reflect.Struct && ft != reflect.TypeOf(time.Time{}))
+	if isRelation && !isEncryptedField(field) {
+		return "", false
+	}
+
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// isEncryptedField reports whether a field is encrypted at rest.
+func isEncryptedField(field reflect.StructField) bool {
+	for _, setting := range strings.Split(field.Tag.Get("gorm"), ";") {
+		setting = strings.TrimSpace(setting)
+		if strings.EqualFold(setting, "serializer:"+encryption.SerializerName) ||
+			strings.EqualFold(setting, "serializer:"+encryption.JSONSerializerName) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact hides a value that must not be stored in plain text, keeping
+// whether it was set.
+func redact(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Slice && v.Len() == 0 {
+		return nil
+	}
+	return "[redacted]"
+}
+
+// fieldValue dereferences pointers so that equal values behind different
+// pointers compare equal; nil pointers become nil.
+func fieldValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+)
+
+type memoryAuditRepository struct {
+	entries []*models.AuditLog
+}
+
+func (r *memoryAuditRepository) Create(entry *models.AuditLog) (*models.AuditLog, error) {
+	r.entries = append(r.entries, entry)
+	return entry, nil
+}
+
+func (r *memoryAuditRepository) Find(query *models.AuditQuery) ([]*models.AuditLog, error) {
+	return r.entries, nil
+}
+
+func changesByField(changes []models.FieldChange) map[string]models.FieldChange {
+	byField := make(map[string]models.FieldChange, len(changes))
+	for _, change := range changes {
+		byField[change.Field] = change
+	}
+	return byField
+}
+
+func TestDiffFieldsReportsOnlyChangedFields(t *testing.T) {
+	email := "jane@example.com"
+	before := &models.Patient{ID: 1, FirstName: "Jane", LastName: "Doe", Gender: "female", UpdatedAt: time.Now()}
+	after := *before
+	after.LastName = "Smith"
+	after.Email = &email
+	after.UpdatedAt = before.UpdatedAt.Add(time.Hour)
+
+	got := diffFields(before, &after)
+	want := []models.FieldChange{
+		{Field: "last_name", Before: "Doe", After: "Smith"},
+		{Field: "email", Before: nil, After: email},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v, want %+v", got, want)
+	}
+}
+
+func TestDiffFieldsRedactsEncryptedFields(t *testing.T) {
+	oldAddress, newAddress := "1 Old Road", "2 New Street"
+	before := &models.Patient{ID: 1, FirstName: "Jane", Phone: "5550001", Address: &oldAddress}
+	after := *before
+	after.FirstName = "Janet"
+	after.Phone = "5550002"
+	after.Address = &newAddress
+
+	changes := changesByField(diffFields(before, &after))
+	if got := changes["first_name"]; got.Before != "Jane" || got.After != "Janet" {
+		t.Errorf("first_name = %+v, want plain values", got)
+	}
+	for _, field := range []string{"phone", "address"} {
+		got, ok := changes[field]
+		if !ok {
+			t.Errorf("%s was not reported as changed", field)
+			continue
+		}
+		if got.Before != "[redacted]" || got.After != "[redacted]" {
+			t.Errorf("%s = %+v, want redacted values", field, got)
+		}
+	}
+
+	// Clearing an encrypted field shows that it was cleared, not its value.
+	after.Address = nil
+	if got := changesByField(diffFields(before, &after))["address"]; got.Before != "[redacted]" || got.After != nil {
+		t.Errorf("cleared address = %+v, want redacted before and nil after", got)
+	}
+}
+
+func TestDiffFieldsRedactsEncryptedJSONFields(t *testing.T) {
+	before := &models.Prescription{ID: 4, DrugName: "Warfarin", Status: "active"}
+	after := *before
+	after.Alerts = []models.PrescriptionAlert{{Severity: "major", Message: "interacts with aspirin", Source: "medication:2"}}
+	after.Status = "cancelled"
+
+	changes := changesByField(diffFields(before, &after))
+	if got, ok := changes["alerts"]; !ok || got.Before != nil || got.After != "[redacted]" {
+		t.Errorf("alerts = %+v (reported %v), want redacted after", got, ok)
+	}
+	if got := changes["status"]; got.Before != "active" || got.After != "cancelled" {
+		t.Errorf("status = %+v, want plain values", got)
+	}
+	if _, ok := changes["drug_name"]; ok {
+		t.Error("unchanged drug_name was reported")
+	}
+}
+
+func TestDiffFieldsAgainstNil(t *testing.T) {
+	created := &models.Prescription{ID: 4, DrugName: "Warfarin", Refills: 2}
+
+	changes := changesByField(diffFields(nil, created))
+	if got := changes["drug_name"]; got.Before != nil || got.After != "[redacted]" {
+		t.Errorf("drug_name = %+v, want redacted after", got)
+	}
+	if got := changes["refills"]; got.Before != nil || got.After != 2 {
+		t.Errorf("refills = %+v, want 2", got)
+	}
+	if got := changes["route"]; got.After != "" {
+		t.Errorf("empty route = %+v, want it left empty rather than redacted", got)
+	}
+	if diffFields(nil, nil) != nil {
+		t.Error("diff of two nil snapshots is not empty")
+	}
+}
+
+func TestRecordStoresRedactedChanges(t *testing.T) {
+	repo := &memoryAuditRepository{}
+	svc := NewAuditService(repo)
+	actor := &models.Actor{UserID: 7, Role: models.RoleDoctor, ClientIP: "10.0.0.1"}
+
+	before := &models.Patient{ID: 1, Phone: "5550001"}
+	after := &models.Patient{ID: 1, Phone: "5550002"}
+	if err := svc.Record(actor, models.AuditActionUpdate, models.AuditEntityPatient, 1, 1, before, after); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.entries) != 1 {
+		t.Fatalf("stored %d entries, want 1", len(repo.entries))
+	}
+	want := []models.FieldChange{{Field: "phone", Before: "[redacted]", After: "[redacted]"}}
+	if got := repo.entries[0].Changes; !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v, want %+v", got, want)
+	}
+
+	if err := svc.Record(nil, models.AuditActionView, models.AuditEntityPatient, 1, 1, nil, nil); err == nil {
+		t.Error("entry without an actor was recorded")
+	}
+}
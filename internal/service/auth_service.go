@@ -2,27 +2,45 @@
 package service
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
+	"hospital-management/internal/auth"
 	"hospital-management/internal/models"
 	"hospital-management/internal/repository"
-	"hospital-management/internal/utils"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
 	Login(req *models.LoginRequest) (*models.LoginResponse, error)
+	Refresh(refreshToken string) (*models.LoginResponse, error)
+	Logout(sessionID string) error
 	Register(req *models.RegisterRequest) (*models.User, error)
+	// BootstrapAdmin creates the first admin account, so that a fresh
+	// install has someone who can register the other users. It does
+	// nothing and returns nil once any admin exists.
+	BootstrapAdmin(email, password string) (*models.User, error)
 	ValidateToken(tokenString string) (*models.User, error)
+	IsSessionActive(sessionID string) (bool, error)
 }
 
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo      repository.UserRepository
+	sessionRepo   repository.SessionRepository
+	jwtManager    *auth.JWTManager
+	sessionExpiry time.Duration
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
+func NewAuthService(userRepo repository.UserRepository, sessionRepo repository.SessionRepository, jwtManager *auth.JWTManager, sessionExpiry time.Duration) AuthService {
 	return &authService{
-		userRepo: userRepo,
+		userRepo:      userRepo,
+		sessionRepo:   sessionRepo,
+		jwtManager:    jwtManager,
+		sessionExpiry: sessionExpiry,
 	}
 }
 
@@ -47,14 +65,95 @@ func (s *authService) Login(req *models.LoginRequest) (*models.LoginResponse, er
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	// Generate JWT token with 2 parameters (userID and role)
-	token, err := utils.GenerateJWT(user.ID, user.Role)
+	session := &models.Session{
+		ID:        randomToken(16),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(s.sessionExpiry),
+	}
+	if _, err := s.sessionRepo.CreateSession(session); err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	return s.issueTokens(user, session)
+}
+
+// Refresh exchanges a refresh token for a new access token and a new refresh
+// token. Each refresh token can be used once; presenting a used token again
+// revokes the whole session, since it means the token was leaked.
+func (s *authService) Refresh(refreshToken string) (*models.LoginResponse, error) {
+	stored, err := s.sessionRepo.GetRefreshTokenByHash(hashToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+
+	session, err := s.sessionRepo.GetSession(stored.SessionID)
+	if err != nil || !session.IsActive(time.Now()) {
+		return nil, fmt.Errorf("session has expired or been revoked")
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, fmt.Errorf("invalid refresh token")
+	}
+
+	fresh, err := s.sessionRepo.MarkRefreshTokenUsed(stored.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !fresh {
+		if err := s.sessionRepo.RevokeSession(session.ID); err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		return nil, fmt.Errorf("refresh token reuse detected; session revoked")
+	}
+
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	return s.issueTokens(user, session)
+}
+
+// Logout revokes the session so its access and refresh tokens stop working.
+func (s *authService) Logout(sessionID string) error {
+	if err := s.sessionRepo.RevokeSession(sessionID); err != nil {
+		return fmt.Errorf("failed to logout: %w", err)
+	}
+	return nil
+}
+
+// IsSessionActive implements auth.SessionChecker.
+func (s *authService) IsSessionActive(sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+	session, err := s.sessionRepo.GetSession(sessionID)
+	if err != nil {
+		return false, nil
+	}
+	return session.IsActive(time.Now()), nil
+}
+
+// issueTokens creates an access token and a fresh refresh token for session.
+func (s *authService) issueTokens(user *models.User, session *models.Session) (*models.LoginResponse, error) {
+	token, expiresAt, err := s.jwtManager.GenerateToken(user, session.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	refreshToken := randomToken(32)
+	if _, err := s.sessionRepo.CreateRefreshToken(&models.RefreshToken{
+		SessionID: session.ID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: session.ExpiresAt,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to issue refresh token: %w", err)
+	}
+
 	return &models.LoginResponse{
-		Token: token,
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
 		User: models.UserResponse{
 			ID:        user.ID,
 			Username:  user.Name,
@@ -109,14 +208,34 @@ func (s *authService) Register(req *models.RegisterRequest) (*models.User, error
 	return createdUser, nil
 }
 
+func (s *authService) BootstrapAdmin(email, password string) (*models.User, error) {
+	exists, err := s.userRepo.HasRole(models.RoleAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up admins: %w", err)
+	}
+	if exists {
+		return nil, nil
+	}
+	if email == "" || len(password) < 6 {
+		return nil, fmt.Errorf("no admin exists; set BOOTSTRAP_ADMIN_EMAIL and a BOOTSTRAP_ADMIN_PASSWORD of at least 6 characters to create one")
+	}
+
+	return s.Register(&models.RegisterRequest{
+		Name:     "admin",
+		Email:    email,
+		Password: password,
+		Role:     models.RoleAdmin,
+	})
+}
+
 func (s *authService) ValidateToken(tokenString string) (*models.User, error) {
-	claims, err := utils.ValidateJWT(tokenString)
+	claims, err := s.jwtManager.ValidateToken(tokenString)
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
-	// Get user from database - convert uint to int
-	user, err := s.userRepo.GetByID(uint(claims.UserID))
+	// Get user from database
+	user, err := s.userRepo.GetByID(claims.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
@@ -126,3 +245,19 @@ func (s *authService) ValidateToken(tokenString string) (*models.User, error) {
 
 	return user, nil
 }
+
+// randomToken returns n random bytes encoded as URL-safe base64.
+func randomToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// hashToken returns the hex SHA-256 of a refresh token, which is what the
+// session store keeps.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"hospital-management/internal/auth"
+	"hospital-management/internal/config"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// memoryUserRepository is a UserRepository over a slice, for tests.
+type memoryUserRepository struct {
+	users []*models.User
+}
+
+func (r *memoryUserRepository) GetByID(id uint) (*models.User, error) {
+	for _, u := range r.users {
+		if u.ID == id {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("user %d not found", id)
+}
+
+func (r *memoryUserRepository) GetByEmail(email string) (*models.User, error) {
+	for _, u := range r.users {
+		if u.Email == email {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("user %s not found", email)
+}
+
+func (r *memoryUserRepository) GetByUsername(username string) (*models.User, error) {
+	for _, u := range r.users {
+		if u.Name == username {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, fmt.Errorf("user %s not found", username)
+}
+
+func (r *memoryUserRepository) Create(user *models.User) (*models.User, error) {
+	user.ID = uint(len(r.users) + 1)
+	copied := *user
+	r.users = append(r.users, &copied)
+	return user, nil
+}
+
+func (r *memoryUserRepository) HasRole(role string) (bool, error) {
+	for _, u := range r.users {
+		if u.Role == role {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newTestJWTManager(t *testing.T) *auth.JWTManager {
+	t.Helper()
+	jwtManager, err := auth.NewJWTManager(&config.Config{
+		JWTKeys:        map[string]string{"test": "test-secret"},
+		JWTActiveKeyID: "test",
+		JWTIssuer:      "test",
+		JWTAudience:    "test",
+		JWTExpiry:      time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return jwtManager
+}
+
+func newTestAuthService(t *testing.T, sessionExpiry time.Duration) (AuthService, *memoryUserRepository) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret-password"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := &memoryUserRepository{}
+	users.Create(&models.User{Name: "dr_smith", Email: "dr.smith@example.com", Password: string(hash), Role: models.RoleDoctor})
+
+	return NewAuthService(users, repository.NewInMemorySessionRepository(), newTestJWTManager(t), sessionExpiry), users
+}
+
+func login(t *testing.T, s AuthService) *models.LoginResponse {
+	t.Helper()
+	resp, err := s.Login(&models.LoginRequest{Email: "dr.smith@example.com", Password: "secret-password"})
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	return resp
+}
+
+// sessionOf returns the session ID an access token was issued for.
+func sessionOf(t *testing.T, token string) string {
+	t.Helper()
+	claims, err := newTestJWTManager(t).ValidateToken(token)
+	if err != nil {
+		t.Fatalf("invalid access token: %v", err)
+	}
+	return claims.SessionID
+}
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	s, _ := newTestAuthService(t, time.Hour)
+	if _, err := s.Login(&models.LoginRequest{Email: "dr.smith@example.com", Password: "wrong"}); err == nil {
+		t.Fatal("expected login with a wrong password to fail")
+	}
+}
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	s, _ := newTestAuthService(t, time.Hour)
+	first := login(t, s)
+
+	second, err := s.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("refresh returned the same refresh token")
+	}
+	if sessionOf(t, second.Token) != sessionOf(t, first.Token) {
+		t.Fatal("refresh started a new session")
+	}
+
+	third, err := s.Refresh(second.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh with the rotated token failed: %v", err)
+	}
+	if third.RefreshToken == second.RefreshToken {
+		t.Fatal("second refresh returned the same refresh token")
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	s, _ := newTestAuthService(t, time.Hour)
+	first := login(t, s)
+	sessionID := sessionOf(t, first.Token)
+
+	second, err := s.Refresh(first.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	if _, err := s.Refresh(first.RefreshToken); err == nil {
+		t.Fatal("expected a used refresh token to be rejected")
+	}
+
+	active, err := s.IsSessionActive(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active {
+		t.Fatal("session is still active after refresh token reuse")
+	}
+	if _, err := s.Refresh(second.RefreshToken); err == nil {
+		t.Fatal("expected the latest refresh token of a revoked session to be rejected")
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	s, _ := newTestAuthService(t, time.Hour)
+	resp := login(t, s)
+	sessionID := sessionOf(t, resp.Token)
+
+	if err := s.Logout(sessionID); err != nil {
+		t.Fatalf("logout failed: %v", err)
+	}
+	active, err := s.IsSessionActive(sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active {
+		t.Fatal("session is still active after logout")
+	}
+	if _, err := s.Refresh(resp.RefreshToken); err == nil {
+		t.Fatal("expected refresh after logout to fail")
+	}
+}
+
+func TestExpiredSessionCannotRefresh(t *testing.T) {
+	s, _ := newTestAuthService(t, -time.Second)
+	resp := login(t, s)
+
+	active, err := s.IsSessionActive(sessionOf(t, resp.Token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active {
+		t.Fatal("expired session is reported active")
+	}
+	if _, err := s.Refresh(resp.RefreshToken); err == nil {
+		t.Fatal("expected refresh of an expired session to fail")
+	}
+}
+
+func TestBootstrapAdmin(t *testing.T) {
+	s, users := newTestAuthService(t, time.Hour)
+
+	if _, err := s.BootstrapAdmin("", ""); err == nil {
+		t.Fatal("expected bootstrap without credentials to fail while no admin exists")
+	}
+
+	admin, err := s.BootstrapAdmin("admin@example.com", "admin-password")
+	if err != nil {
+		t.Fatalf("bootstrap failed: %v", err)
+	}
+	if admin == nil || admin.Role != models.RoleAdmin {
+		t.Fatalf("bootstrap created %+v, want an admin", admin)
+	}
+	if _, err := s.Login(&models.LoginRequest{Email: "admin@example.com", Password: "admin-password"}); err != nil {
+		t.Fatalf("bootstrapped admin cannot log in: %v", err)
+	}
+
+	again, err := s.BootstrapAdmin("other@example.com", "other-password")
+	if err != nil || again != nil {
+		t.Fatalf("second bootstrap = %+v, %v; want nothing created", again, err)
+	}
+	if len(users.users) != 2 {
+		t.Fatalf("got %d users, want 2", len(users.users))
+	}
+}
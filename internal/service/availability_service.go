@@ -0,0 +1,350 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"sort"
+	"time"
+)
+
+// maxSlotSearchRange bounds a single slot search.
+const maxSlotSearchRange = 31 * 24 * time.Hour
+
+type AvailabilityService interface {
+	GetAvailability(doctorID uint) (*models.DoctorAvailability, error)
+	SetWeeklyTemplate(doctorID uint, req *models.AvailabilityTemplateRequest) ([]*models.AvailabilityRule, error)
+	AddException(doctorID uint, req *models.AvailabilityExceptionRequest) (*models.AvailabilityException, error)
+	RemoveException(doctorID, exceptionID uint) error
+	FindSlots(doctorID uint, from, to time.Time, duration int) ([]models.Slot, error)
+	// CheckBookable returns ErrOutsideAvailability if the interval does not
+	// fit inside one of the doctor's open windows. Doctors without a weekly
+	// template are treated as available around the clock, except during
+	// their unavailable exceptions.
+	CheckBookable(doctorID uint, start time.Time, duration int) error
+}
+
+type availabilityService struct {
+	availabilityRepo repository.AvailabilityRepository
+	appointmentRepo  repository.AppointmentRepository
+	userRepo         repository.UserRepository
+	location         *time.Location
+}
+
+func NewAvailabilityService(availabilityRepo repository.AvailabilityRepository, appointmentRepo repository.AppointmentRepository, userRepo repository.UserRepository, location *time.Location) AvailabilityService {
+	return &availabilityService{
+		availabilityRepo: availabilityRepo,
+		appointmentRepo:  appointmentRepo,
+		userRepo:         userRepo,
+		location:         location,
+	}
+}
+
+func (s *availabilityService) GetAvailability(doctorID uint) (*models.DoctorAvailability, error) {
+	if err := s.requireDoctor(doctorID); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.availabilityRepo.GetRules(doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability: %w", err)
+	}
+
+	today := startOfDay(time.Now().In(s.location))
+	exceptions, err := s.availabilityRepo.GetExceptions(doctorID, today, today.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability: %w", err)
+	}
+
+	return &models.DoctorAvailability{
+		DoctorID:   doctorID,
+		Rules:      rules,
+		Exceptions: exceptions,
+	}, nil
+}
+
+func (s *availabilityService) SetWeeklyTemplate(doctorID uint, req *models.AvailabilityTemplateRequest) ([]*models.AvailabilityRule, error) {
+	if err := s.requireDoctor(doctorID); err != nil {
+		return nil, err
+	}
+
+	rules := make([]*models.AvailabilityRule, 0, len(req.Rules))
+	for _, r := range req.Rules {
+		if r.Weekday < 0 || r.Weekday > 6 {
+			return nil, fmt.Errorf("weekday must be between 0 (Sunday) and 6 (Saturday)")
+		}
+		if r.Kind != models.AvailabilityShift && r.Kind != models.AvailabilityBreak {
+			return nil, fmt.Errorf("kind must be one of: shift break")
+		}
+		if err := validateTimeRange(r.StartTime, r.EndTime); err != nil {
+			return nil, err
+		}
+		rules = append(rules, &models.AvailabilityRule{
+			DoctorID:  doctorID,
+			Weekday:   r.Weekday,
+			Kind:      r.Kind,
+			StartTime: r.StartTime,
+			EndTime:   r.EndTime,
+		})
+	}
+
+	saved, err := s.availabilityRepo.ReplaceRules(doctorID, rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save availability: %w", err)
+	}
+	return saved, nil
+}
+
+func (s *availabilityService) AddException(doctorID uint, req *models.AvailabilityExceptionRequest) (*models.AvailabilityException, error) {
+	if err := s.requireDoctor(doctorID); err != nil {
+		return nil, err
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date format: %w", err)
+	}
+	if req.Kind != models.ExceptionUnavailable && req.Kind != models.ExceptionAvailable {
+		return nil, fmt.Errorf("kind must be one of: unavailable available")
+	}
+
+	wholeDay := req.StartTime == "" && req.EndTime == ""
+	if wholeDay && req.Kind == models.ExceptionAvailable {
+		return nil, fmt.Errorf("start_time and end_time are required for an available exception")
+	}
+	if !wholeDay {
+		if err := validateTimeRange(req.StartTime, req.EndTime); err != nil {
+			return nil, err
+		}
+	}
+
+	exception := &models.AvailabilityException{
+		DoctorID:  doctorID,
+		Date:      date,
+		Kind:      req.Kind,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Reason:    req.Reason,
+	}
+
+	created, err := s.availabilityRepo.CreateException(exception)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add availability exception: %w", err)
+	}
+	return created, nil
+}
+
+func (s *availabilityService) RemoveException(doctorID, exceptionID uint) error {
+	if err := s.availabilityRepo.DeleteException(doctorID, exceptionID); err != nil {
+		return fmt.Errorf("failed to remove availability exception: %w", err)
+	}
+	return nil
+}
+
+func (s *availabilityService) FindSlots(doctorID uint, from, to time.Time, duration int) ([]models.Slot, error) {
+	if duration < 15 || duration > 240 {
+		return nil, fmt.Errorf("duration must be between 15 and 240 minutes")
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("to must be after from")
+	}
+	if to.Sub(from) > maxSlotSearchRange {
+		return nil, fmt.Errorf("search range cannot exceed 31 days")
+	}
+	if err := s.requireDoctor(doctorID); err != nil {
+		return nil, err
+	}
+
+	windows, hasTemplate, err := s.openWindows(doctorID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// Without working hours there is nothing to offer as a slot
+	if !hasTemplate {
+		return []models.Slot{}, nil
+	}
+
+	booked, err := s.appointmentRepo.FindConflicts(doctorID, from, int(to.Sub(from).Minutes()), 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load appointments: %w", err)
+	}
+	for _, appt := range booked {
+		windows = subtractInterval(windows, interval{
+			start: appt.DateTime,
+			end:   appt.DateTime.Add(time.Duration(appt.Duration) * time.Minute),
+		})
+	}
+
+	earliest := from
+	if now := time.Now(); now.After(earliest) {
+		earliest = now
+	}
+
+	length := time.Duration(duration) * time.Minute
+	slots := []models.Slot{}
+	for _, w := range windows {
+		for start := w.start; !start.Add(length).After(w.end); start = start.Add(length) {
+			if start.Before(earliest) || start.Add(length).After(to) {
+				continue
+			}
+			slots = append(slots, models.Slot{Start: start, End: start.Add(length)})
+		}
+	}
+	return slots, nil
+}
+
+func (s *availabilityService) CheckBookable(doctorID uint, start time.Time, duration int) error {
+	end := start.Add(time.Duration(duration) * time.Minute)
+
+	windows, _, err := s.openWindows(doctorID, start, end)
+	if err != nil {
+		return err
+	}
+
+	for _, w := range windows {
+		if !start.Before(w.start) && !end.After(w.end) {
+			return nil
+		}
+	}
+	return ErrOutsideAvailability
+}
+
+// openWindows computes the doctor's working intervals for every clinic-local
+// day touching [from, to): weekly shifts minus breaks, adjusted by that day's
+// exceptions. A doctor without a weekly template works all day, so only the
+// exceptions apply. It also reports whether the doctor has a template.
+func (s *availabilityService) openWindows(doctorID uint, from, to time.Time) ([]interval, bool, error) {
+	rules, err := s.availabilityRepo.GetRules(doctorID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get availability: %w", err)
+	}
+	hasTemplate := len(rules) > 0
+
+	firstDay := startOfDay(from.In(s.location))
+	lastDay := startOfDay(to.In(s.location))
+	exceptions, err := s.availabilityRepo.GetExceptions(doctorID, firstDay, lastDay)
+	if err != nil {
+		return nil, hasTemplate, fmt.Errorf("failed to get availability: %w", err)
+	}
+
+	var windows []interval
+	for day := firstDay; !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		var dayWindows []interval
+		if !hasTemplate {
+			dayWindows = append(dayWindows, interval{start: day, end: day.AddDate(0, 0, 1)})
+		}
+		for _, r := range rules {
+			if r.Kind == models.AvailabilityShift && time.Weekday(r.Weekday) == day.Weekday() {
+				dayWindows = append(dayWindows, s.clockInterval(day, r.StartTime, r.EndTime))
+			}
+		}
+		for _, r := range rules {
+			if r.Kind == models.AvailabilityBreak && time.Weekday(r.Weekday) == day.Weekday() {
+				dayWindows = subtractInterval(dayWindows, s.clockInterval(day, r.StartTime, r.EndTime))
+			}
+		}
+
+		// Extra sessions are added before leave is removed, so leave wins
+		for _, kind := range []string{models.ExceptionAvailable, models.ExceptionUnavailable} {
+			for _, e := range exceptions {
+				if e.Kind != kind || e.Date.Format("2006-01-02") != day.Format("2006-01-02") {
+					continue
+				}
+				span := interval{start: day, end: day.AddDate(0, 0, 1)}
+				if e.StartTime != "" {
+					span = s.clockInterval(day, e.StartTime, e.EndTime)
+				}
+				if kind == models.ExceptionUnavailable {
+					dayWindows = subtractInterval(dayWindows, span)
+				} else {
+					dayWindows = append(dayWindows, span)
+				}
+			}
+		}
+
+		windows = append(windows, dayWindows...)
+	}
+	return mergeIntervals(windows), hasTemplate, nil
+}
+
+// clockInterval turns two validated "HH:MM" strings into an interval on day.
+func (s *availabilityService) clockInterval(day time.Time, start, end string) interval {
+	st, _ := time.Parse("15:04", start)
+	et, _ := time.Parse("15:04", end)
+	return interval{
+		start: time.Date(day.Year(), day.Month(), day.Day(), st.Hour(), st.Minute(), 0, 0, s.location),
+		end:   time.Date(day.Year(), day.Month(), day.Day(), et.Hour(), et.Minute(), 0, 0, s.location),
+	}
+}
+
+func (s *availabilityService) requireDoctor(doctorID uint) error {
+	doctor, err := s.userRepo.GetByID(doctorID)
+	if err != nil {
+		return fmt.Errorf("doctor not found: %w", err)
+	}
+	if doctor.Role != models.RoleDoctor {
+		return fmt.Errorf("user is not a doctor")
+	}
+	return nil
+}
+
+func validateTimeRange(start, end string) error {
+	st, err := time.Parse("15:04", start)
+	if err != nil {
+		return fmt.Errorf("invalid start_time %q, expected HH:MM", start)
+	}
+	et, err := time.Parse("15:04", end)
+	if err != nil {
+		return fmt.Errorf("invalid end_time %q, expected HH:MM", end)
+	}
+	if !et.After(st) {
+		return fmt.Errorf("end_time must be after start_time")
+	}
+	return nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// interval is a half-open time range [start, end).
+type interval struct {
+	start, end time.Time
+}
+
+// subtractInterval removes cut from every interval in windows.
+func subtractInterval(windows []interval, cut interval) []interval {
+	var result []interval
+	for _, w := range windows {
+		if !cut.start.Before(w.end) || !cut.end.After(w.start) {
+			result = append(result, w)
+			continue
+		}
+		if w.start.Before(cut.start) {
+			result = append(result, interval{start: w.start, end: cut.start})
+		}
+		if cut.end.Before(w.end) {
+			result = append(result, interval{start: cut.end, end: w.end})
+		}
+	}
+	return result
+}
+
+// mergeIntervals sorts intervals and joins any that overlap or touch.
+func mergeIntervals(windows []interval) []interval {
+	sort.Slice(windows, func(i, j int) bool {
+		return windows[i].start.Before(windows[j].start)
+	})
+
+	var merged []interval
+	for _, w := range windows {
+		if n := len(merged); n > 0 && !w.start.After(merged[n-1].end) {
+			if w.end.After(merged[n-1].end) {
+				merged[n-1].end = w.end
+			}
+			continue
+		}
+		merged = append(merged, w)
+	}
+	return merged
+}
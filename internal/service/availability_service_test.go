@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+)
+
+type fakeAvailabilityRepository struct {
+	repository.AvailabilityRepository
+	rules      []*models.AvailabilityRule
+	exceptions []*models.AvailabilityException
+}
+
+func (r *fakeAvailabilityRepository) GetRules(doctorID uint) ([]*models.AvailabilityRule, error) {
+	return r.rules, nil
+}
+
+func (r *fakeAvailabilityRepository) GetExceptions(doctorID uint, from, to time.Time) ([]*models.AvailabilityException, error) {
+	return r.exceptions, nil
+}
+
+func newTestAvailabilityService(repo *fakeAvailabilityRepository) AvailabilityService {
+	return NewAvailabilityService(repo, newFakeAppointmentRepository(), &memoryUserRepository{}, time.UTC)
+}
+
+// 2030-03-04 is a Monday.
+var monday = time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC)
+
+func TestCheckBookableWithinShift(t *testing.T) {
+	s := newTestAvailabilityService(&fakeAvailabilityRepository{
+		rules: []*models.AvailabilityRule{
+			{Weekday: int(time.Monday), Kind: models.AvailabilityShift, StartTime: "09:00", EndTime: "17:00"},
+			{Weekday: int(time.Monday), Kind: models.AvailabilityBreak, StartTime: "12:00", EndTime: "13:00"},
+		},
+	})
+
+	tests := []struct {
+		start time.Time
+		want  error
+	}{
+		{monday.Add(9 * time.Hour), nil},
+		{monday.Add(8 * time.Hour), ErrOutsideAvailability},
+		{monday.Add(12 * time.Hour), ErrOutsideAvailability},
+		{monday.Add(16*time.Hour + 45*time.Minute), ErrOutsideAvailability},
+		{monday.AddDate(0, 0, 1).Add(10 * time.Hour), ErrOutsideAvailability},
+	}
+	for _, tt := range tests {
+		if err := s.CheckBookable(testDoctorID, tt.start, 30); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.start, err, tt.want)
+		}
+	}
+}
+
+func TestCheckBookableRespectsLeave(t *testing.T) {
+	s := newTestAvailabilityService(&fakeAvailabilityRepository{
+		rules: []*models.AvailabilityRule{
+			{Weekday: int(time.Monday), Kind: models.AvailabilityShift, StartTime: "09:00", EndTime: "17:00"},
+		},
+		exceptions: []*models.AvailabilityException{
+			{Date: monday, Kind: models.ExceptionUnavailable},
+		},
+	})
+
+	if err := s.CheckBookable(testDoctorID, monday.Add(10*time.Hour), 30); !errors.Is(err, ErrOutsideAvailability) {
+		t.Fatalf("got %v, want ErrOutsideAvailability", err)
+	}
+}
+
+func TestCheckBookableWithoutTemplate(t *testing.T) {
+	s := newTestAvailabilityService(&fakeAvailabilityRepository{})
+
+	if err := s.CheckBookable(testDoctorID, monday.Add(3*time.Hour), 30); err != nil {
+		t.Fatalf("got %v, want a doctor without a template to be bookable", err)
+	}
+	if err := s.CheckBookable(testDoctorID, monday.Add(23*time.Hour+45*time.Minute), 30); err != nil {
+		t.Fatalf("got %v, want a booking across midnight to be allowed", err)
+	}
+}
+
+func TestCheckBookableWithoutTemplateRespectsLeave(t *testing.T) {
+	s := newTestAvailabilityService(&fakeAvailabilityRepository{
+		exceptions: []*models.AvailabilityException{
+			{Date: monday, Kind: models.ExceptionUnavailable},
+			{Date: monday.AddDate(0, 0, 1), Kind: models.ExceptionUnavailable, StartTime: "14:00", EndTime: "16:00"},
+		},
+	})
+
+	tests := []struct {
+		start time.Time
+		want  error
+	}{
+		{monday.Add(10 * time.Hour), ErrOutsideAvailability},
+		{monday.AddDate(0, 0, 1).Add(15 * time.Hour), ErrOutsideAvailability},
+		{monday.AddDate(0, 0, 1).Add(10 * time.Hour), nil},
+		{monday.AddDate(0, 0, 2).Add(10 * time.Hour), nil},
+	}
+	for _, tt := range tests {
+		if err := s.CheckBookable(testDoctorID, tt.start, 30); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.start, err, tt.want)
+		}
+	}
+}
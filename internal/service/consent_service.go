@@ -0,0 +1,206 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"strings"
+	"time"
+)
+
+// ConsentService records what patients have agreed to and answers whether
+// they have consented to a use of their data.
+type ConsentService interface {
+	// GetConsents returns every decision the patient has made, newest first.
+	GetConsents(actor *models.Actor, patientID uint) ([]*models.Consent, error)
+	// GetConsentStatus returns whether the patient currently consents to
+	// each consent type, with the decision in effect.
+	GetConsentStatus(actor *models.Actor, patientID uint) ([]models.ConsentStatus, error)
+	RecordConsent(actor *models.Actor, patientID uint, req *models.ConsentRequest) (*models.Consent, error)
+	RevokeConsent(actor *models.Actor, patientID, id uint, req *models.ConsentRevokeRequest) (*models.Consent, error)
+	// ConsentedPatients reports which of the patients currently consent to
+	// the consent type. Anything that contacts patients or shares their data
+	// checks it first: exports and outbound HL7 check data_sharing, and
+	// reminders must check sms or email.
+	ConsentedPatients(consentType string, patientIDs []uint) (map[uint]bool, error)
+}
+
+type consentService struct {
+	consentRepo  repository.ConsentRepository
+	patientRepo  repository.PatientRepository
+	auditService AuditService
+	consents     *consentChecker
+}
+
+func NewConsentService(consentRepo repository.ConsentRepository, patientRepo repository.PatientRepository, auditService AuditService) ConsentService {
+	return &consentService{
+		consentRepo:  consentRepo,
+		patientRepo:  patientRepo,
+		auditService: auditService,
+		consents:     &consentChecker{consentRepo: consentRepo},
+	}
+}
+
+func (s *consentService) GetConsents(actor *models.Actor, patientID uint) ([]*models.Consent, error) {
+	consents, err := s.consentRepo.GetByPatientID(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityConsent, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+	return consents, nil
+}
+
+func (s *consentService) GetConsentStatus(actor *models.Actor, patientID uint) ([]models.ConsentStatus, error) {
+	if _, err := s.patientRepo.GetByID(int(patientID)); err != nil {
+		return nil, fmt.Errorf("patient not found: %w", err)
+	}
+	now := time.Now()
+	inEffect, err := s.consentRepo.GetInEffect(patientID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]models.ConsentStatus, 0, len(models.ConsentTypes))
+	for _, consentType := range models.ConsentTypes {
+		consent := inEffect[consentType]
+		statuses = append(statuses, models.ConsentStatus{
+			Type:      consentType,
+			Consented: consent != nil && consent.Active(now),
+			Consent:   consent,
+		})
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityConsent, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+func (s *consentService) RecordConsent(actor *models.Actor, patientID uint, req *models.ConsentRequest) (*models.Consent, error) {
+	patient, err := s.patientRepo.GetByID(int(patientID))
+	if err != nil {
+		return nil, fmt.Errorf("patient not found: %w", err)
+	}
+	if patient.MergedIntoID != nil {
+		return nil, repository.ErrPatientMerged
+	}
+
+	consent := &models.Consent{
+		PatientID:  patientID,
+		Granted:    req.Granted == nil || *req.Granted,
+		RecordedBy: actor.UserID,
+	}
+	if err := applyConsentRequest(consent, req, time.Now()); err != nil {
+		return nil, err
+	}
+
+	created, err := s.consentRepo.Create(consent)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityConsent, created.ID, patientID, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *consentService) RevokeConsent(actor *models.Actor, patientID, id uint, req *models.ConsentRevokeRequest) (*models.Consent, error) {
+	consent, err := s.consentRepo.Get(patientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !consent.Granted {
+		return nil, ErrConsentNotGranted
+	}
+	if consent.RevokedAt != nil {
+		return nil, ErrConsentRevoked
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+	before := *consent
+
+	now := time.Now()
+	consent.RevokedAt = &now
+	consent.RevokedBy = models.UintPtr(actor.UserID)
+	consent.RevocationReason = &reason
+
+	revoked, err := s.consentRepo.Revoke(consent)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionRevoke, models.AuditEntityConsent, revoked.ID, patientID, &before, revoked); err != nil {
+		return nil, err
+	}
+	return revoked, nil
+}
+
+func (s *consentService) ConsentedPatients(consentType string, patientIDs []uint) (map[uint]bool, error) {
+	return s.consents.consented(consentType, patientIDs, time.Now())
+}
+
+// consentChecker tells which patients consent to a use of their data. It is
+// shared by the services that contact patients or share their data.
+type consentChecker struct {
+	consentRepo repository.ConsentRepository
+}
+
+// consented returns the patients among patientIDs whose decision in effect
+// at the given time grants the consent type. Patients who never decided do
+// not consent.
+func (c *consentChecker) consented(consentType string, patientIDs []uint, at time.Time) (map[uint]bool, error) {
+	inEffect, err := c.consentRepo.GetInEffectForPatients(patientIDs, consentType, at)
+	if err != nil {
+		return nil, err
+	}
+	consented := make(map[uint]bool, len(inEffect))
+	for patientID, consent := range inEffect {
+		if consent.Active(at) {
+			consented[patientID] = true
+		}
+	}
+	return consented, nil
+}
+
+func applyConsentRequest(consent *models.Consent, req *models.ConsentRequest, now time.Time) error {
+	switch req.Type {
+	case models.ConsentTreatment, models.ConsentDataSharing, models.ConsentSMS, models.ConsentEmail, models.ConsentResearch:
+	default:
+		return fmt.Errorf("type must be one of: %s", strings.Join(models.ConsentTypes, " "))
+	}
+	version := strings.TrimSpace(req.Version)
+	if version == "" {
+		return fmt.Errorf("version is required")
+	}
+	if len(version) > 50 {
+		return fmt.Errorf("version must be at most 50 characters")
+	}
+
+	signedAt := now
+	signed, err := parseOptionalDate("signed_at", req.SignedAt)
+	if err != nil {
+		return err
+	}
+	if signed != nil {
+		if signed.After(now) {
+			return fmt.Errorf("signed_at cannot be in the future")
+		}
+		signedAt = *signed
+	}
+	expiresAt, err := parseOptionalDate("expires_at", req.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	if expiresAt != nil && !expiresAt.After(signedAt) {
+		return fmt.Errorf("expires_at must be after signed_at")
+	}
+
+	consent.Type = req.Type
+	consent.Version = version
+	consent.SignedAt = signedAt
+	consent.ExpiresAt = expiresAt
+	return nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"strings"
+)
+
+// DoctorService manages doctor directory profiles. Profiles are addressed by
+// the doctor's user ID.
+type DoctorService interface {
+	CreateDoctor(req *models.DoctorRequest) (*models.Doctor, error)
+	GetDoctor(userID uint) (*models.Doctor, error)
+	UpdateDoctor(userID uint, req *models.DoctorUpdateRequest) (*models.Doctor, error)
+	DeleteDoctor(userID uint) error
+	SearchDoctors(filter *models.DoctorFilter) ([]*models.Doctor, error)
+}
+
+type doctorService struct {
+	doctorRepo repository.DoctorRepository
+	userRepo   repository.UserRepository
+}
+
+func NewDoctorService(doctorRepo repository.DoctorRepository, userRepo repository.UserRepository) DoctorService {
+	return &doctorService{
+		doctorRepo: doctorRepo,
+		userRepo:   userRepo,
+	}
+}
+
+func (s *doctorService) CreateDoctor(req *models.DoctorRequest) (*models.Doctor, error) {
+	if req.Specialization == "" || req.LicenseNumber == "" {
+		return nil, fmt.Errorf("specialization and license_number are required")
+	}
+	if req.Experience < 0 {
+		return nil, fmt.Errorf("experience cannot be negative")
+	}
+
+	// The profile must belong to an existing doctor account
+	user, err := s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+	if user.Role != models.RoleDoctor {
+		return nil, fmt.Errorf("user is not a doctor")
+	}
+
+	existing, _ := s.doctorRepo.GetByUserID(req.UserID)
+	if existing != nil {
+		return nil, fmt.Errorf("doctor profile already exists for this user")
+	}
+
+	name := strings.TrimSpace(user.GetFullName())
+	if name == "" {
+		name = user.Name
+	}
+
+	doctor := &models.Doctor{
+		UserID:         user.ID,
+		Name:           name,
+		Email:          user.Email,
+		Phone:          req.Phone,
+		Specialization: req.Specialization,
+		Experience:     req.Experience,
+		LicenseNumber:  req.LicenseNumber,
+		Department:     req.Department,
+	}
+
+	createdDoctor, err := s.doctorRepo.Create(doctor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create doctor: %w", err)
+	}
+	createdDoctor.User = user
+
+	return createdDoctor, nil
+}
+
+func (s *doctorService) GetDoctor(userID uint) (*models.Doctor, error) {
+	doctor, err := s.doctorRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor: %w", err)
+	}
+	return doctor, nil
+}
+
+func (s *doctorService) UpdateDoctor(userID uint, req *models.DoctorUpdateRequest) (*models.Doctor, error) {
+	doctor, err := s.doctorRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("doctor not found: %w", err)
+	}
+
+	// Update fields (only update if provided)
+	if req.Phone != "" {
+		doctor.Phone = req.Phone
+	}
+	if req.Specialization != "" {
+		doctor.Specialization = req.Specialization
+	}
+	if req.Experience != nil {
+		if *req.Experience < 0 {
+			return nil, fmt.Errorf("experience cannot be negative")
+		}
+		doctor.Experience = *req.Experience
+	}
+	if req.LicenseNumber != "" {
+		doctor.LicenseNumber = req.LicenseNumber
+	}
+	if req.Department != "" {
+		doctor.Department = req.Department
+	}
+
+	updatedDoctor, err := s.doctorRepo.Update(doctor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update doctor: %w", err)
+	}
+
+	return updatedDoctor, nil
+}
+
+func (s *doctorService) DeleteDoctor(userID uint) error {
+	doctor, err := s.doctorRepo.GetByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete doctor: %w", err)
+	}
+
+	if err := s.doctorRepo.Delete(doctor.ID); err != nil {
+		return fmt.Errorf("failed to delete doctor: %w", err)
+	}
+	return nil
+}
+
+func (s *doctorService) SearchDoctors(filter *models.DoctorFilter) ([]*models.Doctor, error) {
+	doctors, err := s.doctorRepo.Search(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search doctors: %w", err)
+	}
+	return doctors, nil
+}
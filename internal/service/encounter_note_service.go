@@ -0,0 +1,227 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"strings"
+	"time"
+)
+
+type EncounterNoteService interface {
+	CreateNote(actor *models.Actor, appointmentID uint, req *models.EncounterNoteRequest) (*models.EncounterNote, error)
+	GetNote(actor *models.Actor, id uint) (*models.EncounterNote, error)
+	GetPatientNotes(actor *models.Actor, patientID uint) ([]*models.EncounterNote, error)
+	GetAppointmentNotes(actor *models.Actor, appointmentID uint) ([]*models.EncounterNote, error)
+	UpdateNote(actor *models.Actor, id uint, req *models.EncounterNoteRequest) (*models.EncounterNote, error)
+	SignNote(actor *models.Actor, id uint) (*models.EncounterNote, error)
+	AmendNote(actor *models.Actor, id uint, req *models.NoteAmendmentRequest) (*models.NoteAmendment, error)
+}
+
+type encounterNoteService struct {
+	noteRepo        repository.EncounterNoteRepository
+	appointmentRepo repository.AppointmentRepository
+	auditService    AuditService
+}
+
+func NewEncounterNoteService(noteRepo repository.EncounterNoteRepository, appointmentRepo repository.AppointmentRepository, auditService AuditService) EncounterNoteService {
+	return &encounterNoteService{
+		noteRepo:        noteRepo,
+		appointmentRepo: appointmentRepo,
+		auditService:    auditService,
+	}
+}
+
+func (s *encounterNoteService) CreateNote(actor *models.Actor, appointmentID uint, req *models.EncounterNoteRequest) (*models.EncounterNote, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("appointment not found: %w", err)
+	}
+
+	note := &models.EncounterNote{
+		PatientID:     appointment.PatientID,
+		AppointmentID: &appointment.ID,
+		AuthorID:      actor.UserID,
+		Subjective:    req.Subjective,
+		Objective:     req.Objective,
+		Assessment:    req.Assessment,
+		Plan:          req.Plan,
+		Status:        models.NoteStatusDraft,
+		Version:       1,
+	}
+
+	created, err := s.noteRepo.Create(note)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityEncounterNote, created.ID, created.PatientID, nil, created); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *encounterNoteService) GetNote(actor *models.Actor, id uint) (*models.EncounterNote, error) {
+	note, err := s.noteRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityEncounterNote, note.ID, note.PatientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return note, nil
+}
+
+func (s *encounterNoteService) GetPatientNotes(actor *models.Actor, patientID uint) ([]*models.EncounterNote, error) {
+	notes, err := s.noteRepo.GetByPatientID(patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityEncounterNote, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+func (s *encounterNoteService) GetAppointmentNotes(actor *models.Actor, appointmentID uint) ([]*models.EncounterNote, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("appointment not found: %w", err)
+	}
+
+	notes, err := s.noteRepo.GetByAppointmentID(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityEncounterNote, 0, appointment.PatientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return notes, nil
+}
+
+// UpdateNote edits a draft. The content it replaces is kept as a revision.
+func (s *encounterNoteService) UpdateNote(actor *models.Actor, id uint, req *models.EncounterNoteRequest) (*models.EncounterNote, error) {
+	note, err := s.editableDraft(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *note
+
+	revision := &models.NoteRevision{
+		NoteID:     note.ID,
+		Version:    note.Version,
+		Subjective: note.Subjective,
+		Objective:  note.Objective,
+		Assessment: note.Assessment,
+		Plan:       note.Plan,
+		EditedBy:   actor.UserID,
+	}
+
+	note.Subjective = req.Subjective
+	note.Objective = req.Objective
+	note.Assessment = req.Assessment
+	note.Plan = req.Plan
+	note.Version++
+
+	updated, err := s.noteRepo.UpdateDraft(note, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityEncounterNote, updated.ID, updated.PatientID, &before, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// SignNote locks a draft into the legal record. Only its author can sign it.
+func (s *encounterNoteService) SignNote(actor *models.Actor, id uint) (*models.EncounterNote, error) {
+	note, err := s.editableDraft(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(note.Subjective+note.Objective+note.Assessment+note.Plan) == "" {
+		return nil, fmt.Errorf("cannot sign an empty encounter note")
+	}
+	before := *note
+
+	now := time.Now()
+	note.Status = models.NoteStatusSigned
+	note.SignedAt = &now
+	note.SignedBy = &actor.UserID
+	note.UpdatedAt = now
+
+	signed, err := s.noteRepo.Sign(note)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionSign, models.AuditEntityEncounterNote, signed.ID, signed.PatientID, &before, signed); err != nil {
+		return nil, err
+	}
+
+	return signed, nil
+}
+
+// AmendNote appends to a signed note. Any clinician may amend, not only the
+// author.
+func (s *encounterNoteService) AmendNote(actor *models.Actor, id uint, req *models.NoteAmendmentRequest) (*models.NoteAmendment, error) {
+	note, err := s.noteRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if note.Status != models.NoteStatusSigned {
+		return nil, ErrNoteNotSigned
+	}
+
+	switch req.Section {
+	case models.NoteSectionSubjective, models.NoteSectionObjective, models.NoteSectionAssessment,
+		models.NoteSectionPlan, models.NoteSectionGeneral:
+	default:
+		return nil, fmt.Errorf("section must be one of: subjective objective assessment plan general")
+	}
+	if strings.TrimSpace(req.Content) == "" || strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("content and reason are required")
+	}
+
+	amendment, err := s.noteRepo.AddAmendment(&models.NoteAmendment{
+		NoteID:   note.ID,
+		AuthorID: actor.UserID,
+		Section:  req.Section,
+		Content:  req.Content,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionAmend, models.AuditEntityEncounterNote, note.ID, note.PatientID, nil, amendment); err != nil {
+		return nil, err
+	}
+
+	return amendment, nil
+}
+
+// editableDraft loads a note that actor may edit or sign: an unsigned draft
+// they wrote.
+func (s *encounterNoteService) editableDraft(actor *models.Actor, id uint) (*models.EncounterNote, error) {
+	note, err := s.noteRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if note.Status == models.NoteStatusSigned {
+		return nil, ErrNoteSigned
+	}
+	if note.AuthorID != actor.UserID {
+		return nil, ErrNotNoteAuthor
+	}
+	return note, nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"hospital-management/internal/models"
+	"time"
+)
+
+// ErrDuplicatePatient is returned when a patient is registered with the
+// phone number of an existing patient.
+var ErrDuplicatePatient = errors.New("patient with phone number already exists")
+
+// ConflictError is returned when a booking would overlap a doctor's existing
+// appointments. Conflicts can be empty when the overlap was caught by the
+// database and the other booking is no longer visible.
+type ConflictError struct {
+	Conflicts []*models.Appointment
+}
+
+func (e *ConflictError) Error() string {
+	if len(e.Conflicts) == 0 {
+		return "appointment conflicts with an existing appointment"
+	}
+	return fmt.Sprintf("appointment conflicts with %d existing appointment(s)", len(e.Conflicts))
+}
+
+// ErrOutsideAvailability is returned when a booking falls outside the
+// doctor's working hours.
+var ErrOutsideAvailability = errors.New("appointment is outside the doctor's availability")
+
+// TransitionError is returned when an appointment cannot move from its
+// current status to the requested one.
+type TransitionError struct {
+	From, To string
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("appointment cannot move from %s to %s", e.From, e.To)
+}
+
+// ErrTransitionForbidden is returned when the actor's role may not make a
+// status change that is otherwise allowed.
+var ErrTransitionForbidden = errors.New("insufficient permissions for this status change")
+
+// ErrReasonRequired is returned when an appointment is cancelled or marked as
+// a no-show without a reason.
+var ErrReasonRequired = errors.New("a reason is required to cancel an appointment or mark it as a no-show")
+
+// ErrNotReschedulable is returned when the time of an appointment that has
+// already started or ended is changed.
+var ErrNotReschedulable = errors.New("only requested, scheduled or confirmed appointments can be rescheduled")
+
+// OccurrenceConflict explains why one occurrence of a series cannot be
+// booked.
+type OccurrenceConflict struct {
+	Start     time.Time             `json:"start"`
+	Reason    string                `json:"reason"`
+	Conflicts []*models.Appointment `json:"conflicts,omitempty"`
+}
+
+// SeriesConflictError is returned when some occurrences of a recurring series
+// fall outside the doctor's availability or overlap other appointments. No
+// occurrence is booked or changed.
+type SeriesConflictError struct {
+	Occurrences []OccurrenceConflict
+}
+
+func (e *SeriesConflictError) Error() string {
+	return fmt.Sprintf("%d occurrence(s) of the series cannot be booked", len(e.Occurrences))
+}
+
+// ErrNotInSeries is returned when a series operation targets an appointment
+// that is not part of a recurring series.
+var ErrNotInSeries = errors.New("appointment is not part of a series")
+
+// ErrCheckInNotToday is returned when a patient is checked in for an
+// appointment on another day.
+var ErrCheckInNotToday = errors.New("only appointments scheduled for today can be checked in")
+
+// ErrNoteSigned is returned when a signed encounter note is edited or signed
+// again. Signed notes can only be amended.
+var ErrNoteSigned = errors.New("encounter note is signed; add an amendment instead")
+
+// ErrNoteNotSigned is returned when a draft encounter note is amended.
+var ErrNoteNotSigned = errors.New("only signed encounter notes can be amended")
+
+// ErrNotNoteAuthor is returned when someone other than the author edits or
+// signs a draft encounter note.
+var ErrNotNoteAuthor = errors.New("only the author can edit or sign a draft encounter note")
+
+// PrescriptionBlockedError is returned when a prescription raises blocking
+// alerts and no override reason was given.
+type PrescriptionBlockedError struct {
+	Alerts []models.PrescriptionAlert
+}
+
+func (e *PrescriptionBlockedError) Error() string {
+	return "prescription is blocked by allergy alerts; give an override reason to proceed"
+}
+
+// ErrNotPrescribable is returned when prescribing against a cancelled or
+// no-show appointment.
+var ErrNotPrescribable = errors.New("cannot prescribe against a cancelled or no-show appointment")
+
+// ErrPrescriptionCancelled is returned when a cancelled prescription is
+// cancelled again.
+var ErrPrescriptionCancelled = errors.New("prescription is already cancelled")
+
+// ErrUnsupportedFormat is returned for an import or export format other
+// than CSV or NDJSON.
+var ErrUnsupportedFormat = errors.New("format must be csv or ndjson")
+
+// ErrImportTooLarge is returned when an import file exceeds MaxImportSize.
+var ErrImportTooLarge = fmt.Errorf("import file is larger than %d MiB", MaxImportSize>>20)
+
+// ErrSelfMerge is returned when a patient is merged into themselves.
+var ErrSelfMerge = errors.New("a patient cannot be merged into themselves")
+
+// ErrCandidateReviewed is returned when a duplicate candidate that was
+// already dismissed or merged is dismissed.
+var ErrCandidateReviewed = errors.New("duplicate candidate has already been reviewed")
+
+// ErrEmptySearch is returned for a patient search without any usable term.
+var ErrEmptySearch = errors.New("search query has no name, phone number or date to search for")
+
+// ErrIdentifierRequired is returned for a lookup by identifier without a
+// system or value.
+var ErrIdentifierRequired = errors.New("system and value are required")
+
+// ErrInvalidMRN is returned when a medical record number looked up has the
+// wrong prefix, length or check digit.
+var ErrInvalidMRN = errors.New("not a valid medical record number")
+
+// ErrConsentRevoked is returned when a consent that was already revoked is
+// revoked again.
+var ErrConsentRevoked = errors.New("consent is already revoked")
+
+// ErrConsentNotGranted is returned when a refusal is revoked. A patient who
+// changes their mind records a new decision instead.
+var ErrConsentNotGranted = errors.New("only granted consents can be revoked; record a new decision instead")
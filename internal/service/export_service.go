@@ -0,0 +1,270 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+)
+
+// exportBatchSize is how many rows are read from the database at a time.
+const exportBatchSize = 500
+
+type ExportService interface {
+	// ExportPatients writes every patient matching the filter to w as CSV or
+	// NDJSON, in ID order. A patient export can be imported again. Patients
+	// who do not consent to data sharing are left out.
+	ExportPatients(actor *models.Actor, format string, filter *models.PatientFilter, w io.Writer) error
+	// ExportAppointments writes every appointment matching the filter to w
+	// as CSV or NDJSON, in ID order. Appointments of patients who do not
+	// consent to data sharing are left out.
+	ExportAppointments(actor *models.Actor, format string, filter *models.AppointmentFilter, w io.Writer) error
+}
+
+type exportService struct {
+	patientRepo     repository.PatientRepository
+	appointmentRepo repository.AppointmentRepository
+	auditService    AuditService
+	consents        *consentChecker
+}
+
+func NewExportService(patientRepo repository.PatientRepository, appointmentRepo repository.AppointmentRepository, consentRepo repository.ConsentRepository, auditService AuditService) ExportService {
+	return &exportService{
+		patientRepo:     patientRepo,
+		appointmentRepo: appointmentRepo,
+		auditService:    auditService,
+		consents:        &consentChecker{consentRepo: consentRepo},
+	}
+}
+
+func (s *exportService) ExportPatients(actor *models.Actor, format string, filter *models.PatientFilter, w io.Writer) error {
+	out, err := newExportWriter(format, w, patientExportColumns)
+	if err != nil {
+		return err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionExport, models.AuditEntityPatient, 0, 0, nil, nil); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.patientRepo.EachBatch(filter, exportBatchSize, func(patients []*models.Patient) error {
+		ids := make([]uint, len(patients))
+		for i, p := range patients {
+			ids[i] = p.ID
+		}
+		consented, err := s.consents.consented(models.ConsentDataSharing, ids, now)
+		if err != nil {
+			return err
+		}
+		for _, p := range patients {
+			if !consented[p.ID] {
+				continue
+			}
+			if err := out.write(newPatientExportRow(p)); err != nil {
+				return err
+			}
+		}
+		return out.flush()
+	})
+	if err != nil {
+		return err
+	}
+	return out.flush()
+}
+
+func (s *exportService) ExportAppointments(actor *models.Actor, format string, filter *models.AppointmentFilter, w io.Writer) error {
+	out, err := newExportWriter(format, w, appointmentExportColumns)
+	if err != nil {
+		return err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionExport, models.AuditEntityAppointment, 0, filter.PatientID, nil, nil); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	err = s.appointmentRepo.EachBatch(filter, exportBatchSize, func(appointments []*models.Appointment) error {
+		ids := make([]uint, 0, len(appointments))
+		seen := make(map[uint]bool, len(appointments))
+		for _, a := range appointments {
+			if !seen[a.PatientID] {
+				seen[a.PatientID] = true
+				ids = append(ids, a.PatientID)
+			}
+		}
+		consented, err := s.consents.consented(models.ConsentDataSharing, ids, now)
+		if err != nil {
+			return err
+		}
+		for _, a := range appointments {
+			if !consented[a.PatientID] {
+				continue
+			}
+			if err := out.write(newAppointmentExportRow(a)); err != nil {
+				return err
+			}
+		}
+		return out.flush()
+	})
+	if err != nil {
+		return err
+	}
+	return out.flush()
+}
+
+// exportRow is a record that can be written as a CSV row or a JSON line.
+type exportRow interface {
+	csvRecord() []string
+}
+
+// exportWriter writes rows in one of the export formats. CSV output is
+// buffered until the first flush, so an error before it can still be
+// reported to the client.
+type exportWriter struct {
+	w    io.Writer
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+func newExportWriter(format string, w io.Writer, columns []string) (*exportWriter, error) {
+	out := &exportWriter{w: w}
+	switch format {
+	case models.FormatCSV:
+		out.csv = csv.NewWriter(w)
+		if err := out.csv.Write(columns); err != nil {
+			return nil, err
+		}
+	case models.FormatNDJSON:
+		out.json = json.NewEncoder(w)
+		out.json.SetEscapeHTML(false)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	return out, nil
+}
+
+func (e *exportWriter) write(row exportRow) error {
+	if e.json != nil {
+		return e.json.Encode(row)
+	}
+	return e.csv.Write(row.csvRecord())
+}
+
+// flush pushes the rows written so far to the client.
+func (e *exportWriter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if f, ok := e.w.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+var patientExportColumns = []string{"id", "mrn", "first_name", "last_name", "email", "phone", "date_of_birth", "gender", "address", "created_at", "updated_at"}
+
+// patientExportRow uses the field names of PatientRequest, so an export can
+// be imported again.
+type patientExportRow struct {
+	ID          uint      `json:"id"`
+	MRN         string    `json:"mrn"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	DateOfBirth string    `json:"date_of_birth"`
+	Gender      string    `json:"gender"`
+	Address     string    `json:"address"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newPatientExportRow(p *models.Patient) *patientExportRow {
+	return &patientExportRow{
+		ID:          p.ID,
+		MRN:         models.StringValue(p.MRN),
+		FirstName:   p.FirstName,
+		LastName:    p.LastName,
+		Email:       models.StringValue(p.Email),
+		Phone:       p.Phone,
+		DateOfBirth: p.DateOfBirth.Format("2006-01-02"),
+		Gender:      p.Gender,
+		Address:     models.StringValue(p.Address),
+		CreatedAt:   p.CreatedAt,
+		UpdatedAt:   p.UpdatedAt,
+	}
+}
+
+func (r *patientExportRow) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(uint64(r.ID), 10),
+		r.MRN,
+		r.FirstName,
+		r.LastName,
+		r.Email,
+		r.Phone,
+		r.DateOfBirth,
+		r.Gender,
+		r.Address,
+		r.CreatedAt.Format(time.RFC3339),
+		r.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+var appointmentExportColumns = []string{"id", "patient_id", "doctor_id", "date_time", "duration", "status", "status_reason", "notes", "series_id", "created_by", "created_at"}
+
+type appointmentExportRow struct {
+	ID           uint      `json:"id"`
+	PatientID    uint      `json:"patient_id"`
+	DoctorID     uint      `json:"doctor_id"`
+	DateTime     time.Time `json:"date_time"`
+	Duration     int       `json:"duration"`
+	Status       string    `json:"status"`
+	StatusReason string    `json:"status_reason,omitempty"`
+	Notes        string    `json:"notes,omitempty"`
+	SeriesID     *uint     `json:"series_id,omitempty"`
+	CreatedBy    uint      `json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func newAppointmentExportRow(a *models.Appointment) *appointmentExportRow {
+	return &appointmentExportRow{
+		ID:           a.ID,
+		PatientID:    a.PatientID,
+		DoctorID:     a.DoctorID,
+		DateTime:     a.DateTime,
+		Duration:     a.Duration,
+		Status:       a.Status,
+		StatusReason: models.StringValue(a.StatusReason),
+		Notes:        models.StringValue(a.Notes),
+		SeriesID:     a.SeriesID,
+		CreatedBy:    a.CreatedBy,
+		CreatedAt:    a.CreatedAt,
+	}
+}
+
+func (r *appointmentExportRow) csvRecord() []string {
+	seriesID := ""
+	if r.SeriesID != nil {
+		seriesID = strconv.FormatUint(uint64(*r.SeriesID), 10)
+	}
+	return []string{
+		strconv.FormatUint(uint64(r.ID), 10),
+		strconv.FormatUint(uint64(r.PatientID), 10),
+		strconv.FormatUint(uint64(r.DoctorID), 10),
+		r.DateTime.Format(time.RFC3339),
+		strconv.Itoa(r.Duration),
+		r.Status,
+		r.StatusReason,
+		r.Notes,
+		seriesID,
+		strconv.FormatUint(uint64(r.CreatedBy), 10),
+		r.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"hospital-management/internal/hl7"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/utils"
+)
+
+// HL7InboundService applies ADT messages from other systems to the patient
+// register: A04 registers a patient and A08 updates one. Every message is
+// answered with an original-mode acknowledgement.
+type HL7InboundService interface {
+	hl7.Handler
+}
+
+type hl7InboundService struct {
+	patientService PatientService
+	patientRepo    repository.PatientRepository
+	actor          models.Actor
+	authority      string
+	location       *time.Location
+}
+
+// NewHL7InboundService returns a service that makes its changes on behalf
+// of actor. Patients are matched on their ID under authority in PID-3 or,
+// failing that, on their phone number.
+func NewHL7InboundService(patientService PatientService, patientRepo repository.PatientRepository, actor models.Actor, authority string, location *time.Location) HL7InboundService {
+	return &hl7InboundService{
+		patientService: patientService,
+		patientRepo:    patientRepo,
+		actor:          actor,
+		authority:      authority,
+		location:       location,
+	}
+}
+
+// patientFieldLocations points validation failures at the PID field they
+// came from.
+var patientFieldLocations = map[string]string{
+	"firstname":   "PID^1^5",
+	"lastname":    "PID^1^5",
+	"dateofbirth": "PID^1^7",
+	"gender":      "PID^1^8",
+	"phone":       "PID^1^13",
+	"email":       "PID^1^13",
+}
+
+func (s *hl7InboundService) ServeHL7(remoteAddr string, msg *hl7.Message) *hl7.Message {
+	code, event := msg.Type()
+	if code != "ADT" {
+		return hl7.Acknowledge(msg, hl7.AckReject, &hl7.Error{
+			Code: hl7.CodeUnsupportedMessageType, Location: "MSH^1^9", Message: fmt.Sprintf("unsupported message type %s", code),
+		})
+	}
+
+	actor := s.actor
+	actor.ClientIP = remoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		actor.ClientIP = host
+	}
+
+	var patient *models.Patient
+	var err error
+	switch event {
+	case "A04":
+		patient, err = s.registerPatient(&actor, msg)
+	case "A08":
+		patient, err = s.updatePatient(&actor, msg)
+	default:
+		return hl7.Acknowledge(msg, hl7.AckReject, &hl7.Error{
+			Code: hl7.CodeUnsupportedEvent, Location: "MSH^1^9", Message: fmt.Sprintf("unsupported event ADT^%s", event),
+		})
+	}
+
+	if err != nil {
+		var hl7Err *hl7.Error
+		if !errors.As(err, &hl7Err) {
+			log.Printf("hl7: ADT^%s %s from %s failed: %v", event, msg.ControlID(), remoteAddr, err)
+			hl7Err = &hl7.Error{Code: hl7.CodeApplicationError, Message: err.Error()}
+		}
+		return hl7.Acknowledge(msg, hl7.AckError, hl7Err)
+	}
+
+	ack := hl7.Acknowledge(msg, hl7.AckAccept)
+	ack.Segment("MSA").SetField(3, hl7.Escape(fmt.Sprintf("patient %d", patient.ID)))
+	return ack
+}
+
+func (s *hl7InboundService) registerPatient(actor *models.Actor, msg *hl7.Message) (*models.Patient, error) {
+	pid, req, err := s.patientRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := hl7.PatientID(pid, s.authority); ok {
+		return nil, &hl7.Error{Code: hl7.CodeDuplicateKey, Location: "PID^1^3", Message: fmt.Sprintf("patient %d is already registered", id)}
+	}
+	if errs := utils.ValidateStruct(req); errs != nil {
+		return nil, validationError(errs)
+	}
+
+	patient, err := s.patientService.CreatePatient(actor, req)
+	if errors.Is(err, ErrDuplicatePatient) {
+		return nil, &hl7.Error{Code: hl7.CodeDuplicateKey, Location: "PID^1^13", Message: err.Error()}
+	}
+	return patient, err
+}
+
+func (s *hl7InboundService) updatePatient(actor *models.Actor, msg *hl7.Message) (*models.Patient, error) {
+	pid, req, err := s.patientRequest(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	id, ok := hl7.PatientID(pid, s.authority)
+	if !ok && req.Phone != "" {
+		if existing, err := s.patientRepo.GetByPhone(req.Phone); err == nil {
+			id, ok = existing.ID, true
+		}
+	}
+	if !ok {
+		return nil, &hl7.Error{Code: hl7.CodeUnknownKey, Location: "PID^1^3", Message: "patient not found"}
+	}
+
+	// An update only has to carry the fields that changed, but those it
+	// carries must be valid.
+	if errs := utils.ValidateStruct(req); errs != nil {
+		for field, message := range errs {
+			if message == field+" is required" {
+				delete(errs, field)
+			}
+		}
+		if len(errs) > 0 {
+			return nil, validationError(errs)
+		}
+	}
+
+	existing, err := s.patientRepo.GetByID(int(id))
+	if err != nil {
+		return nil, &hl7.Error{Code: hl7.CodeUnknownKey, Location: "PID^1^3", Message: fmt.Sprintf("patient %d not found", id)}
+	}
+	if existing.MergedIntoID != nil {
+		return nil, &hl7.Error{Code: hl7.CodeUnknownKey, Location: "PID^1^3", Message: fmt.Sprintf("patient %d was merged into patient %d", id, *existing.MergedIntoID)}
+	}
+	return s.patientService.UpdatePatient(actor, id, req)
+}
+
+// patientRequest reads the PID segment of an ADT message.
+func (s *hl7InboundService) patientRequest(msg *hl7.Message) (*hl7.Segment, *models.PatientRequest, error) {
+	pid := msg.Segment("PID")
+	if pid == nil {
+		return nil, nil, &hl7.Error{Code: hl7.CodeSegmentSequence, Location: "PID", Message: "PID segment is required"}
+	}
+	req, hl7Err := hl7.PatientRequest(pid, s.location)
+	if hl7Err != nil {
+		return nil, nil, hl7Err
+	}
+	return pid, req, nil
+}
+
+// validationError reports the first failing field, in a stable order.
+func validationError(errs map[string]string) *hl7.Error {
+	fields := make([]string, 0, len(errs))
+	for field := range errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = errs[field]
+	}
+	code := hl7.CodeDataType
+	if strings.HasSuffix(errs[fields[0]], " is required") {
+		code = hl7.CodeRequiredFieldMissing
+	}
+	return &hl7.Error{Code: code, Location: patientFieldLocations[fields[0]], Message: strings.Join(messages, "; ")}
+}
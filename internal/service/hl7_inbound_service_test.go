@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"hospital-management/internal/hl7"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+)
+
+// hl7PatientRepository finds the patients in a map, by ID or phone number.
+type hl7PatientRepository struct {
+	repository.PatientRepository
+	patients map[uint]*models.Patient
+}
+
+func (r *hl7PatientRepository) GetByID(id int) (*models.Patient, error) {
+	if p, ok := r.patients[uint(id)]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("patient with id %d not found", id)
+}
+
+func (r *hl7PatientRepository) GetByPhone(phone string) (*models.Patient, error) {
+	for _, p := range r.patients {
+		if p.Phone == phone {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("patient with phone %s not found", phone)
+}
+
+// hl7PatientService records the requests it is given. createErr fails
+// CreatePatient.
+type hl7PatientService struct {
+	PatientService
+	createErr error
+	created   []*models.PatientRequest
+	updated   map[uint]*models.PatientRequest
+}
+
+func (s *hl7PatientService) CreatePatient(actor *models.Actor, req *models.PatientRequest) (*models.Patient, error) {
+	if s.createErr != nil {
+		return nil, s.createErr
+	}
+	s.created = append(s.created, req)
+	return &models.Patient{ID: 101, FirstName: req.FirstName, LastName: req.LastName}, nil
+}
+
+func (s *hl7PatientService) UpdatePatient(actor *models.Actor, id uint, req *models.PatientRequest) (*models.Patient, error) {
+	if s.updated == nil {
+		s.updated = make(map[uint]*models.PatientRequest)
+	}
+	s.updated[id] = req
+	return &models.Patient{ID: id}, nil
+}
+
+func adtMessage(t *testing.T, event, pid string) *hl7.Message {
+	t.Helper()
+	text := "MSH|^~\\&|REG|WARD|HMS|HOSP|20260301120000||ADT^" + event + "^ADT_A01|CTRL1|P|2.5.1\r"
+	if pid != "" {
+		text += pid + "\r"
+	}
+	msg, err := hl7.Parse([]byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestServeHL7(t *testing.T) {
+	merged := uint(8)
+	patients := map[uint]*models.Patient{
+		7: {ID: 7, Phone: "555-0107"},
+		9: {ID: 9, Phone: "555-0109", MergedIntoID: &merged},
+	}
+	const newPatient = "PID|1||||Doe^Jane||19800102|F|||||555-0100^PRN^PH"
+
+	tests := []struct {
+		name      string
+		msg       string
+		event     string
+		pid       string
+		createErr error
+		wantCode  string
+		wantError string // HL7 table 0357 code in ERR-3
+		wantText  string // MSA-3
+		updatedID uint
+	}{
+		{name: "A04 registers", event: "A04", pid: newPatient, wantCode: hl7.AckAccept, wantText: "patient 101"},
+		{name: "A04 without PID", event: "A04", wantCode: hl7.AckError, wantError: hl7.CodeSegmentSequence},
+		{name: "A04 missing name", event: "A04", pid: "PID|1||||||19800102|F|||||555-0100",
+			wantCode: hl7.AckError, wantError: hl7.CodeRequiredFieldMissing},
+		{name: "A04 invalid date of birth", event: "A04", pid: "PID|1||||Doe^Jane||1980-01-02|F|||||555-0100",
+			wantCode: hl7.AckError, wantError: hl7.CodeDataType},
+		{name: "A04 unknown sex", event: "A04", pid: "PID|1||||Doe^Jane||19800102|Q|||||555-0100",
+			wantCode: hl7.AckError, wantError: hl7.CodeTableValue},
+		{name: "A04 already identified", event: "A04", pid: "PID|1||7^^^HMS^MR||Doe^Jane||19800102|F|||||555-0100",
+			wantCode: hl7.AckError, wantError: hl7.CodeDuplicateKey},
+		{name: "A04 duplicate phone", event: "A04", pid: newPatient, createErr: ErrDuplicatePatient,
+			wantCode: hl7.AckError, wantError: hl7.CodeDuplicateKey},
+		{name: "A04 database failure", event: "A04", pid: newPatient, createErr: errors.New("connection reset"),
+			wantCode: hl7.AckError, wantError: hl7.CodeApplicationError},
+		{name: "A08 by ID", event: "A08", pid: "PID|1||7^^^HMS^MR||Doe^Janet",
+			wantCode: hl7.AckAccept, wantText: "patient 7", updatedID: 7},
+		{name: "A08 by phone", event: "A08", pid: "PID|1||||Doe^Janet||||||||555-0107",
+			wantCode: hl7.AckAccept, wantText: "patient 7", updatedID: 7},
+		{name: "A08 ID under another authority", event: "A08", pid: "PID|1||7^^^NHS^NH||Doe^Janet",
+			wantCode: hl7.AckError, wantError: hl7.CodeUnknownKey},
+		{name: "A08 unknown patient", event: "A08", pid: "PID|1||404^^^HMS^MR||Doe^Janet",
+			wantCode: hl7.AckError, wantError: hl7.CodeUnknownKey},
+		{name: "A08 merged patient", event: "A08", pid: "PID|1||9^^^HMS^MR||Doe^Janet",
+			wantCode: hl7.AckError, wantError: hl7.CodeUnknownKey},
+		{name: "A08 invalid email", event: "A08", pid: "PID|1||7^^^HMS^MR||||||||||^NET^Internet^not-an-email",
+			wantCode: hl7.AckError, wantError: hl7.CodeDataType},
+		{name: "unsupported event", event: "A99", pid: newPatient, wantCode: hl7.AckReject, wantError: hl7.CodeUnsupportedEvent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patientService := &hl7PatientService{createErr: tt.createErr}
+			s := NewHL7InboundService(patientService, &hl7PatientRepository{patients: patients}, models.Actor{UserID: 3}, "HMS", time.UTC)
+
+			ack := s.ServeHL7("10.0.0.5:40000", adtMessage(t, tt.event, tt.pid))
+
+			msa := ack.Segment("MSA")
+			if msa.Value(1) != tt.wantCode || msa.Value(2) != "CTRL1" {
+				t.Fatalf("MSA = %v, want %s for CTRL1", msa.Fields, tt.wantCode)
+			}
+			if tt.wantText != "" && msa.Value(3) != tt.wantText {
+				t.Errorf("MSA-3 = %q, want %q", msa.Value(3), tt.wantText)
+			}
+			errSeg := ack.Segment("ERR")
+			switch {
+			case tt.wantError == "" && errSeg != nil:
+				t.Errorf("unexpected ERR %v", errSeg.Fields)
+			case tt.wantError != "" && (errSeg == nil || errSeg.Component(3, 1) != tt.wantError):
+				t.Errorf("ERR = %v, want code %s", errSeg, tt.wantError)
+			}
+			if tt.updatedID != 0 && patientService.updated[tt.updatedID] == nil {
+				t.Errorf("patient %d was not updated", tt.updatedID)
+			}
+			if tt.wantCode != hl7.AckAccept && (len(patientService.created) > 0 || len(patientService.updated) > 0) {
+				t.Error("a rejected message changed the register")
+			}
+		})
+	}
+}
+
+func TestServeHL7RejectsOtherMessageTypes(t *testing.T) {
+	msg, err := hl7.Parse([]byte("MSH|^~\\&|LAB|WARD|HMS|HOSP|20260301120000||ORU^R01|CTRL2|P|2.5.1\r"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewHL7InboundService(&hl7PatientService{}, &hl7PatientRepository{}, models.Actor{UserID: 3}, "HMS", time.UTC)
+
+	ack := s.ServeHL7("10.0.0.5:40000", msg)
+
+	if code, _ := hl7.AckCode(ack); code != hl7.AckReject {
+		t.Fatalf("ack code = %s, want %s", code, hl7.AckReject)
+	}
+	if got := ack.Segment("ERR").Component(3, 1); got != hl7.CodeUnsupportedMessageType {
+		t.Fatalf("ERR code = %s, want %s", got, hl7.CodeUnsupportedMessageType)
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"hospital-management/internal/hl7"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+)
+
+// HL7 outbound delivery limits.
+const (
+	hl7QueueSize    = 256
+	hl7MaxAttempts  = 5
+	hl7RetryBackoff = 2 * time.Second
+)
+
+// HL7OutboundService announces appointments to another system: an SIU^S12
+// when one is booked and an SIU^S15 when one is cancelled. Messages are sent
+// in order from a background queue so that booking never waits on the
+// receiver. Appointments of patients who do not consent to data sharing are
+// not announced.
+type HL7OutboundService interface {
+	// Close stops sending. Messages still queued are dropped.
+	Close()
+}
+
+type hl7Delivery struct {
+	event       string
+	appointment *models.Appointment
+}
+
+type hl7OutboundService struct {
+	client      *hl7.Client
+	patientRepo repository.PatientRepository
+	userRepo    repository.UserRepository
+	consents    *consentChecker
+	header      hl7.Header
+	authority   string
+	location    *time.Location
+
+	queue chan hl7Delivery
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewHL7OutboundService(appointments AppointmentService, client *hl7.Client, patientRepo repository.PatientRepository, userRepo repository.UserRepository, consentRepo repository.ConsentRepository, header hl7.Header, authority string, location *time.Location) HL7OutboundService {
+	s := &hl7OutboundService{
+		client:      client,
+		patientRepo: patientRepo,
+		userRepo:    userRepo,
+		consents:    &consentChecker{consentRepo: consentRepo},
+		header:      header,
+		authority:   authority,
+		location:    location,
+		queue:       make(chan hl7Delivery, hl7QueueSize),
+		done:        make(chan struct{}),
+	}
+	s.wg.Add(1)
+	go s.run()
+	appointments.Subscribe(s.onAppointmentChange)
+	return s
+}
+
+func (s *hl7OutboundService) onAppointmentChange(event AppointmentEvent) {
+	var trigger string
+	switch {
+	case event.Type == AppointmentEventCreated:
+		trigger = hl7.EventNewBooking
+	case event.Type == AppointmentEventUpdated &&
+		event.Appointment.Status == models.AppointmentCancelled &&
+		event.Before.Status != models.AppointmentCancelled:
+		trigger = hl7.EventCancellation
+	default:
+		return
+	}
+
+	appointment := *event.Appointment
+	select {
+	case s.queue <- hl7Delivery{event: trigger, appointment: &appointment}:
+	default:
+		log.Printf("hl7: outbound queue full, dropping SIU^%s for appointment %d", trigger, appointment.ID)
+	}
+}
+
+func (s *hl7OutboundService) run() {
+	defer s.wg.Done()
+	for {
+		select {
+		case delivery := <-s.queue:
+			consented, err := s.consents.consented(models.ConsentDataSharing, []uint{delivery.appointment.PatientID}, time.Now())
+			if err != nil {
+				log.Printf("hl7: SIU^%s for appointment %d not delivered: %v", delivery.event, delivery.appointment.ID, err)
+				continue
+			}
+			if !consented[delivery.appointment.PatientID] {
+				log.Printf("hl7: SIU^%s for appointment %d not sent: patient has not consented to data sharing", delivery.event, delivery.appointment.ID)
+				continue
+			}
+			if err := s.deliver(delivery); err != nil {
+				log.Printf("hl7: SIU^%s for appointment %d not delivered: %v", delivery.event, delivery.appointment.ID, err)
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// deliver sends one message, retrying with the same control ID while the
+// receiver is unreachable or rejects it outright (AR). An application error
+// (AE) will not go away by resending and is not retried.
+func (s *hl7OutboundService) deliver(delivery hl7Delivery) error {
+	msg, err := s.buildMessage(delivery)
+	if err != nil {
+		return err
+	}
+
+	backoff := hl7RetryBackoff
+	for attempt := 1; ; attempt++ {
+		ack, err := s.client.Send(msg)
+		if err == nil {
+			code, text := hl7.AckCode(ack)
+			switch code {
+			case hl7.AckAccept:
+				return nil
+			case hl7.AckError:
+				return fmt.Errorf("receiver returned AE: %s", text)
+			default:
+				err = fmt.Errorf("receiver returned %s: %s", code, text)
+			}
+		}
+		if attempt == hl7MaxAttempts {
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-s.done:
+			return fmt.Errorf("stopped before delivery: %w", err)
+		}
+	}
+}
+
+func (s *hl7OutboundService) buildMessage(delivery hl7Delivery) (*hl7.Message, error) {
+	appointment := delivery.appointment
+	patient, err := s.patientRepo.GetByID(int(appointment.PatientID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get patient: %w", err)
+	}
+	doctor, err := s.userRepo.GetByID(appointment.DoctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor: %w", err)
+	}
+	return hl7.ScheduleMessage(s.header, delivery.event, appointment, patient, doctor, s.authority, s.location, time.Now()), nil
+}
+
+func (s *hl7OutboundService) Close() {
+	close(s.done)
+	s.wg.Wait()
+	s.client.Close()
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"hospital-management/internal/models"
+)
+
+// requiredImportColumns must appear in a CSV header. Columns are named
+// after the JSON fields of PatientRequest; email and address are optional
+// and any other column, such as those of an export, is ignored.
+var requiredImportColumns = []string{"first_name", "last_name", "phone", "date_of_birth", "gender"}
+
+// maxNDJSONLine bounds a single NDJSON record.
+const maxNDJSONLine = 1 << 20
+
+// patientRow is one record of an import file. Err explains why a record
+// could not be read into Request.
+type patientRow struct {
+	Number  int
+	Request *models.PatientRequest
+	Err     string
+}
+
+// patientRowReader reads the records of an import file in order. next
+// returns io.EOF after the last one; any other error ends the import.
+type patientRowReader interface {
+	next() (*patientRow, error)
+}
+
+func newPatientRowReader(format string, r io.Reader) (patientRowReader, error) {
+	switch format {
+	case models.FormatCSV:
+		return newCSVPatientRows(r)
+	case models.FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+		return &ndjsonPatientRows{scanner: scanner}, nil
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+type csvPatientRows struct {
+	reader  *csv.Reader
+	columns map[string]int
+	row     int
+}
+
+func newCSVPatientRows(r io.Reader) (*csvPatientRows, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if name == "" {
+			continue
+		}
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("column %q appears more than once", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	return &csvPatientRows{reader: reader, columns: columns}, nil
+}
+
+func (r *csvPatientRows) next() (*patientRow, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+
+	r.row++
+	row := &patientRow{Number: r.row}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		row.Err = parseErr.Err.Error()
+		return row, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	value := func(column string) string {
+		i, ok := r.columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+	row.Request = &models.PatientRequest{
+		FirstName:   value("first_name"),
+		LastName:    value("last_name"),
+		Email:       value("email"),
+		Phone:       value("phone"),
+		DateOfBirth: value("date_of_birth"),
+		Gender:      value("gender"),
+		Address:     value("address"),
+	}
+	return row, nil
+}
+
+type ndjsonPatientRows struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (r *ndjsonPatientRows) next() (*patientRow, error) {
+	for r.scanner.Scan() {
+		line := bytes.TrimSpace(bytes.TrimPrefix(r.scanner.Bytes(), []byte("\ufeff")))
+		if len(line) == 0 {
+			continue
+		}
+
+		r.row++
+		row := &patientRow{Number: r.row}
+		var req models.PatientRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			row.Err = "invalid JSON: " + err.Error()
+			return row, nil
+		}
+		row.Request = &req
+		return row, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
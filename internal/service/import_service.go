@@ -0,0 +1,273 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/utils"
+)
+
+// MaxImportSize bounds the size of an import file.
+const MaxImportSize = 64 << 20
+
+const (
+	// importWorkers is how many imports run at once; later ones wait as
+	// pending.
+	importWorkers = 2
+	// importProgressEvery is how often, in rows, a running job's counts are
+	// saved.
+	importProgressEvery = 100
+)
+
+type ImportService interface {
+	// StartPatientImport saves the CSV or NDJSON file read from r and
+	// imports it in the background. The returned job is pending; poll
+	// GetImportJob for its progress.
+	StartPatientImport(actor *models.Actor, format, fileName string, r io.Reader) (*models.ImportJob, error)
+	GetImportJob(actor *models.Actor, id uint) (*models.ImportJob, error)
+}
+
+type importService struct {
+	importRepo     repository.ImportJobRepository
+	patientRepo    repository.PatientRepository
+	patientService PatientService
+	workers        chan struct{}
+}
+
+// NewImportService returns the import service. Files of jobs interrupted by
+// a restart are gone, so those jobs are marked failed.
+func NewImportService(importRepo repository.ImportJobRepository, patientRepo repository.PatientRepository, patientService PatientService) ImportService {
+	if n, err := importRepo.FailUnfinished("interrupted by a server restart"); err != nil {
+		log.Printf("Failed to clean up import jobs: %v", err)
+	} else if n > 0 {
+		log.Printf("Marked %d interrupted import job(s) as failed", n)
+	}
+
+	return &importService{
+		importRepo:     importRepo,
+		patientRepo:    patientRepo,
+		patientService: patientService,
+		workers:        make(chan struct{}, importWorkers),
+	}
+}
+
+func (s *importService) StartPatientImport(actor *models.Actor, format, fileName string, r io.Reader) (*models.ImportJob, error) {
+	if format != models.FormatCSV && format != models.FormatNDJSON {
+		return nil, ErrUnsupportedFormat
+	}
+
+	path, err := spoolImport(r)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := s.importRepo.Create(&models.ImportJob{
+		Format:    format,
+		FileName:  fileName,
+		Status:    models.ImportJobPending,
+		RowErrors: []models.ImportRowError{},
+		CreatedBy: actor.UserID,
+	})
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	go s.run(*actor, *job, path)
+	return job, nil
+}
+
+// spoolImport copies an import file to a temporary file so the request can
+// finish before the import does.
+func spoolImport(r io.Reader) (string, error) {
+	f, err := os.CreateTemp("", "patient-import-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to store import file: %w", err)
+	}
+	defer f.Close()
+
+	n, err := io.Copy(f, io.LimitReader(r, MaxImportSize+1))
+	if err == nil && n > MaxImportSize {
+		err = ErrImportTooLarge
+	}
+	if err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		if errors.Is(err, ErrImportTooLarge) {
+			return "", err
+		}
+		return "", fmt.Errorf("failed to store import file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+func (s *importService) GetImportJob(actor *models.Actor, id uint) (*models.ImportJob, error) {
+	job, err := s.importRepo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get import job: %w", err)
+	}
+	return job, nil
+}
+
+// run processes a job once a worker slot is free. The job is owned by this
+// goroutine; the caller keeps its own copy.
+func (s *importService) run(actor models.Actor, job models.ImportJob, path string) {
+	s.workers <- struct{}{}
+	defer func() { <-s.workers }()
+	defer os.Remove(path)
+
+	started := time.Now()
+	job.Status = models.ImportJobRunning
+	job.StartedAt = &started
+	s.save(&job)
+
+	err := s.importPatients(&actor, &job, path)
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Status = models.ImportJobCompleted
+	if err != nil {
+		job.Status = models.ImportJobFailed
+		job.Error = models.StringPtr(err.Error())
+	}
+	s.save(&job)
+}
+
+func (s *importService) save(job *models.ImportJob) {
+	if _, err := s.importRepo.Update(job); err != nil {
+		log.Printf("Failed to save import job %d: %v", job.ID, err)
+	}
+}
+
+// importPatients creates a patient for every valid row that is not a
+// duplicate. Rejected rows are recorded on the job; an error reading the
+// file or creating a patient stops the import.
+func (s *importService) importPatients(actor *models.Actor, job *models.ImportJob, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer f.Close()
+
+	rows, err := newPatientRowReader(job.Format, f)
+	if err != nil {
+		return err
+	}
+
+	// phones maps each phone number seen so far to its row, so that repeats
+	// within the file are reported against the first occurrence.
+	phones := make(map[string]int)
+	for {
+		row, err := rows.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read row %d: %w", job.TotalRows+1, err)
+		}
+
+		job.TotalRows++
+		rowError, err := s.importRow(actor, row, phones)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", row.Number, err)
+		}
+		if rowError != nil {
+			job.AddRowError(*rowError)
+		} else {
+			job.CreatedRows++
+		}
+
+		if job.TotalRows%importProgressEvery == 0 {
+			s.save(job)
+		}
+	}
+}
+
+func (s *importService) importRow(actor *models.Actor, row *patientRow, phones map[string]int) (*models.ImportRowError, error) {
+	if row.Err != "" {
+		return &models.ImportRowError{Row: row.Number, Type: models.ImportRowInvalid, Errors: map[string]string{"row": row.Err}}, nil
+	}
+
+	req := normalizePatientRequest(row.Request)
+	if errs := validatePatientRequest(req); len(errs) > 0 {
+		return &models.ImportRowError{Row: row.Number, Type: models.ImportRowInvalid, Errors: errs}, nil
+	}
+
+	if first, ok := phones[req.Phone]; ok {
+		return &models.ImportRowError{
+			Row:    row.Number,
+			Type:   models.ImportRowDuplicate,
+			Errors: map[string]string{"phone": fmt.Sprintf("same phone number as row %d", first)},
+		}, nil
+	}
+	phones[req.Phone] = row.Number
+
+	_, err := s.patientService.CreatePatient(actor, req)
+	if errors.Is(err, ErrDuplicatePatient) {
+		rowError := &models.ImportRowError{
+			Row:    row.Number,
+			Type:   models.ImportRowDuplicate,
+			Errors: map[string]string{"phone": err.Error()},
+		}
+		if existing, err := s.patientRepo.GetByPhone(req.Phone); err == nil {
+			rowError.PatientID = existing.ID
+		}
+		return rowError, nil
+	}
+	return nil, err
+}
+
+// normalizePatientRequest trims every field and lower-cases the gender.
+func normalizePatientRequest(req *models.PatientRequest) *models.PatientRequest {
+	return &models.PatientRequest{
+		FirstName:   strings.TrimSpace(req.FirstName),
+		LastName:    strings.TrimSpace(req.LastName),
+		Email:       strings.TrimSpace(req.Email),
+		Phone:       strings.TrimSpace(req.Phone),
+		DateOfBirth: strings.TrimSpace(req.DateOfBirth),
+		Gender:      strings.ToLower(strings.TrimSpace(req.Gender)),
+		Address:     strings.TrimSpace(req.Address),
+	}
+}
+
+// patientRequestFields maps the field keys utils.ValidateStruct reports to
+// the JSON names of PatientRequest, which is how import files name them.
+var patientRequestFields = func() map[string]string {
+	fields := make(map[string]string)
+	t := reflect.TypeOf(models.PatientRequest{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		fields[strings.ToLower(t.Field(i).Name)] = name
+	}
+	return fields
+}()
+
+// validatePatientRequest applies the PatientRequest validation rules, plus
+// the date format CreatePatient expects, and returns the failures keyed by
+// JSON field name.
+func validatePatientRequest(req *models.PatientRequest) map[string]string {
+	errs := make(map[string]string)
+	for field, message := range utils.ValidateStruct(req) {
+		if name, ok := patientRequestFields[field]; ok {
+			message = strings.Replace(message, field, name, 1)
+			field = name
+		}
+		errs[field] = message
+	}
+	if _, ok := errs["date_of_birth"]; !ok {
+		if _, err := time.Parse("2006-01-02", req.DateOfBirth); err != nil {
+			errs["date_of_birth"] = "date_of_birth must be a date in YYYY-MM-DD format"
+		}
+	}
+	return errs
+}
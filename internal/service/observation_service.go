@@ -0,0 +1,408 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxObservationClockSkew is how far in the future an observation may be
+// timestamped, to allow for device clocks running ahead.
+const maxObservationClockSkew = 5 * time.Minute
+
+// ObservationService records vital signs and other observations and derives
+// trends from them.
+type ObservationService interface {
+	RecordObservations(actor *models.Actor, patientID uint, req *models.ObservationBatchRequest) ([]*models.Observation, error)
+	GetObservations(actor *models.Actor, patientID uint, filter *models.ObservationFilter) ([]*models.Observation, error)
+	// GetLatest returns the most recent value of each observation, plus BMI
+	// when both weight and height are known.
+	GetLatest(actor *models.Actor, patientID uint) ([]*models.Observation, error)
+	// GetTrends returns one trend per code over the filter's date range. BMI
+	// is computed from weight and height.
+	GetTrends(actor *models.Actor, patientID uint, filter *models.ObservationFilter) ([]*models.ObservationTrend, error)
+	MarkEnteredInError(actor *models.Actor, patientID, id uint, req *models.ObservationErrorRequest) (*models.Observation, error)
+}
+
+type observationService struct {
+	observationRepo repository.ObservationRepository
+	patientRepo     repository.PatientRepository
+	appointmentRepo repository.AppointmentRepository
+	auditService    AuditService
+}
+
+func NewObservationService(observationRepo repository.ObservationRepository, patientRepo repository.PatientRepository, appointmentRepo repository.AppointmentRepository, auditService AuditService) ObservationService {
+	return &observationService{
+		observationRepo: observationRepo,
+		patientRepo:     patientRepo,
+		appointmentRepo: appointmentRepo,
+		auditService:    auditService,
+	}
+}
+
+func (s *observationService) RecordObservations(actor *models.Actor, patientID uint, req *models.ObservationBatchRequest) ([]*models.Observation, error) {
+	if _, err := s.patientRepo.GetByID(int(patientID)); err != nil {
+		return nil, fmt.Errorf("patient not found: %w", err)
+	}
+	if len(req.Observations) == 0 {
+		return nil, fmt.Errorf("at least one observation is required")
+	}
+	if req.AppointmentID != nil {
+		appointment, err := s.appointmentRepo.GetByID(*req.AppointmentID)
+		if err != nil {
+			return nil, fmt.Errorf("appointment not found: %w", err)
+		}
+		if appointment.PatientID != patientID {
+			return nil, fmt.Errorf("appointment %d belongs to another patient", appointment.ID)
+		}
+	}
+
+	now := time.Now()
+	observedAt := now
+	if req.ObservedAt != nil {
+		observedAt = *req.ObservedAt
+		if observedAt.After(now.Add(maxObservationClockSkew)) {
+			return nil, fmt.Errorf("observed_at must not be in the future")
+		}
+	}
+
+	seen := make(map[string]bool, len(req.Observations))
+	observations := make([]*models.Observation, 0, len(req.Observations))
+	for _, value := range req.Observations {
+		if seen[value.Code] {
+			return nil, fmt.Errorf("%s is recorded more than once", value.Code)
+		}
+		seen[value.Code] = true
+
+		observation, err := newObservation(value)
+		if err != nil {
+			return nil, err
+		}
+		observation.PatientID = patientID
+		observation.AppointmentID = req.AppointmentID
+		observation.ObservedAt = observedAt
+		observation.RecordedBy = actor.UserID
+		observations = append(observations, observation)
+	}
+
+	created, err := s.observationRepo.CreateBatch(observations)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, observation := range created {
+		if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityObservation, observation.ID, patientID, nil, observation); err != nil {
+			return nil, err
+		}
+	}
+
+	return created, nil
+}
+
+func (s *observationService) GetObservations(actor *models.Actor, patientID uint, filter *models.ObservationFilter) ([]*models.Observation, error) {
+	observations, err := s.observationRepo.List(patientID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityObservation, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return observations, nil
+}
+
+func (s *observationService) GetLatest(actor *models.Actor, patientID uint) ([]*models.Observation, error) {
+	latest, err := s.observationRepo.Latest(patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	var weight, height *models.Observation
+	for _, observation := range latest {
+		switch observation.Code {
+		case models.ObservationWeight:
+			weight = observation
+		case models.ObservationHeight:
+			height = observation
+		}
+	}
+	if weight != nil && height != nil {
+		bmi := computedBMI(weight.Value, height.Value)
+		latest = append(latest, computedObservation(models.ObservationBMI, patientID, bmi, weight.ObservedAt))
+	}
+	sortObservationsByType(latest)
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityObservation, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return latest, nil
+}
+
+func (s *observationService) GetTrends(actor *models.Actor, patientID uint, filter *models.ObservationFilter) ([]*models.ObservationTrend, error) {
+	codes := filter.Codes
+	if len(codes) == 0 {
+		for _, t := range models.ObservationTypes {
+			codes = append(codes, t.Code)
+		}
+	}
+
+	types := make([]models.ObservationType, 0, len(codes))
+	wantBMI := false
+	query := &models.ObservationFilter{From: filter.From, To: filter.To}
+	for _, code := range codes {
+		t, ok := models.LookupObservationType(code)
+		if !ok {
+			return nil, fmt.Errorf("unknown observation code %q", code)
+		}
+		types = append(types, t)
+		if code == models.ObservationBMI {
+			wantBMI = true
+			query.Codes = append(query.Codes, models.ObservationWeight)
+		} else {
+			query.Codes = append(query.Codes, code)
+		}
+	}
+
+	observations, err := s.observationRepo.List(patientID, query)
+	if err != nil {
+		return nil, err
+	}
+	byCode := make(map[string][]models.TrendPoint)
+	for _, observation := range observations {
+		byCode[observation.Code] = append(byCode[observation.Code], models.TrendPoint{
+			ObservedAt:    observation.ObservedAt,
+			Value:         observation.Value,
+			Flag:          observation.Flag,
+			ObservationID: observation.ID,
+		})
+	}
+
+	if wantBMI {
+		// Height rarely changes, so a weight is paired with the latest height
+		// before it even when that falls outside the range.
+		heights, err := s.observationRepo.List(patientID, &models.ObservationFilter{
+			Codes: []string{models.ObservationHeight},
+			To:    filter.To,
+		})
+		if err != nil {
+			return nil, err
+		}
+		byCode[models.ObservationBMI] = bmiTrend(byCode[models.ObservationWeight], heights)
+	}
+
+	trends := make([]*models.ObservationTrend, 0, len(types))
+	for _, t := range types {
+		trends = append(trends, buildTrend(t, byCode[t.Code]))
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityObservation, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return trends, nil
+}
+
+func (s *observationService) MarkEnteredInError(actor *models.Actor, patientID, id uint, req *models.ObservationErrorRequest) (*models.Observation, error) {
+	observation, err := s.observationRepo.GetByID(patientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if observation.Status == models.ObservationEnteredInError {
+		return nil, fmt.Errorf("observation is already marked as entered in error")
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required")
+	}
+	before := *observation
+
+	observation.Status = models.ObservationEnteredInError
+	observation.ErrorReason = &reason
+
+	updated, err := s.observationRepo.Update(observation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityObservation, updated.ID, patientID, &before, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// newObservation validates a recorded value, converts it to the canonical
+// unit and flags it against the reference range.
+func newObservation(value models.ObservationValue) (*models.Observation, error) {
+	t, ok := models.LookupObservationType(value.Code)
+	if !ok {
+		return nil, fmt.Errorf("unknown observation code %q", value.Code)
+	}
+	if t.Computed {
+		return nil, fmt.Errorf("%s is computed and cannot be recorded", t.Code)
+	}
+
+	canonical, err := toCanonicalUnit(t, value.Value, value.Unit)
+	if err != nil {
+		return nil, err
+	}
+	if canonical < t.Min || canonical > t.Max {
+		return nil, fmt.Errorf("%s of %g %s is outside the plausible range %g-%g", t.Code, canonical, t.Unit, t.Min, t.Max)
+	}
+
+	observation := &models.Observation{
+		Code:   t.Code,
+		Value:  canonical,
+		Unit:   t.Unit,
+		Flag:   t.Flag(canonical),
+		Status: models.ObservationFinal,
+		Note:   value.Note,
+	}
+	if t.HasRange() {
+		low, high := t.Low, t.High
+		observation.ReferenceLow = &low
+		observation.ReferenceHigh = &high
+	}
+	return observation, nil
+}
+
+// unitConversions maps an observation code and an accepted alternative unit
+// to a conversion into the canonical unit.
+var unitConversions = map[string]map[string]func(float64) float64{
+	models.ObservationTemperature: {
+		"c":    func(v float64) float64 { return v },
+		"degc": func(v float64) float64 { return v },
+		"°f":   func(v float64) float64 { return (v - 32) * 5 / 9 },
+		"f":    func(v float64) float64 { return (v - 32) * 5 / 9 },
+		"degf": func(v float64) float64 { return (v - 32) * 5 / 9 },
+	},
+	models.ObservationWeight: {
+		"g":   func(v float64) float64 { return v / 1000 },
+		"lb":  func(v float64) float64 { return v * 0.45359237 },
+		"lbs": func(v float64) float64 { return v * 0.45359237 },
+	},
+	models.ObservationHeight: {
+		"m":  func(v float64) float64 { return v * 100 },
+		"in": func(v float64) float64 { return v * 2.54 },
+	},
+}
+
+func toCanonicalUnit(t models.ObservationType, value float64, unit string) (float64, error) {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if unit == "" || unit == strings.ToLower(t.Unit) {
+		return value, nil
+	}
+	convert, ok := unitConversions[t.Code][unit]
+	if !ok {
+		return 0, fmt.Errorf("unit %q is not accepted for %s; use %s", unit, t.Code, t.Unit)
+	}
+	return roundTo(convert(value), 2), nil
+}
+
+// bmiTrend pairs each weight with the latest height measured at or before
+// it, or failing that the earliest height measured after it.
+func bmiTrend(weights []models.TrendPoint, heights []*models.Observation) []models.TrendPoint {
+	if len(heights) == 0 {
+		return nil
+	}
+	bmiType, _ := models.LookupObservationType(models.ObservationBMI)
+
+	points := make([]models.TrendPoint, 0, len(weights))
+	for _, weight := range weights {
+		height := heights[0]
+		for _, candidate := range heights {
+			if candidate.ObservedAt.After(weight.ObservedAt) {
+				break
+			}
+			height = candidate
+		}
+		bmi := computedBMI(weight.Value, height.Value)
+		points = append(points, models.TrendPoint{
+			ObservedAt: weight.ObservedAt,
+			Value:      bmi,
+			Flag:       bmiType.Flag(bmi),
+		})
+	}
+	return points
+}
+
+// computedBMI returns weight (kg) over height (cm, converted to m) squared,
+// rounded to one decimal.
+func computedBMI(weightKg, heightCm float64) float64 {
+	meters := heightCm / 100
+	return roundTo(weightKg/(meters*meters), 1)
+}
+
+func computedObservation(code string, patientID uint, value float64, observedAt time.Time) *models.Observation {
+	t, _ := models.LookupObservationType(code)
+	observation := &models.Observation{
+		PatientID:  patientID,
+		Code:       code,
+		Value:      value,
+		Unit:       t.Unit,
+		Flag:       t.Flag(value),
+		Status:     models.ObservationFinal,
+		ObservedAt: observedAt,
+	}
+	if t.HasRange() {
+		low, high := t.Low, t.High
+		observation.ReferenceLow = &low
+		observation.ReferenceHigh = &high
+	}
+	return observation
+}
+
+func buildTrend(t models.ObservationType, points []models.TrendPoint) *models.ObservationTrend {
+	trend := &models.ObservationTrend{
+		Code:    t.Code,
+		Display: t.Display,
+		Unit:    t.Unit,
+		Low:     t.Low,
+		High:    t.High,
+		Points:  points,
+	}
+	if len(points) == 0 {
+		trend.Points = []models.TrendPoint{}
+		return trend
+	}
+
+	lowest, highest, sum := points[0].Value, points[0].Value, 0.0
+	for _, point := range points {
+		lowest = math.Min(lowest, point.Value)
+		highest = math.Max(highest, point.Value)
+		sum += point.Value
+	}
+	mean := roundTo(sum/float64(len(points)), 2)
+	latest := points[len(points)-1]
+	change := roundTo(latest.Value-points[0].Value, 2)
+
+	trend.Min = &lowest
+	trend.Max = &highest
+	trend.Mean = &mean
+	trend.Latest = &latest
+	trend.Change = &change
+	return trend
+}
+
+// sortObservationsByType orders observations as models.ObservationTypes
+// lists their codes.
+func sortObservationsByType(observations []*models.Observation) {
+	rank := make(map[string]int, len(models.ObservationTypes))
+	for i, t := range models.ObservationTypes {
+		rank[t.Code] = i
+	}
+	sort.SliceStable(observations, func(i, j int) bool {
+		return rank[observations[i].Code] < rank[observations[j].Code]
+	})
+}
+
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
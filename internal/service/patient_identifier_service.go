@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/mrn"
+	"hospital-management/internal/repository"
+	"strings"
+)
+
+// PatientIdentifierService manages the identifiers other systems have
+// issued for patients and finds patients by identifier or MRN.
+type PatientIdentifierService interface {
+	GetIdentifiers(actor *models.Actor, patientID uint) ([]*models.PatientIdentifier, error)
+	AddIdentifier(actor *models.Actor, patientID uint, req *models.PatientIdentifierRequest) (*models.PatientIdentifier, error)
+	DeleteIdentifier(actor *models.Actor, patientID, id uint) error
+	// FindPatient returns the patient holding value in system. The "mrn"
+	// system looks up medical record numbers. A merged patient is returned
+	// as is; its MergedIntoID names the surviving chart.
+	FindPatient(actor *models.Actor, system, value string) (*models.Patient, error)
+}
+
+type patientIdentifierService struct {
+	identifierRepo repository.PatientIdentifierRepository
+	patientRepo    repository.PatientRepository
+	auditService   AuditService
+	mrns           *mrn.Generator
+}
+
+func NewPatientIdentifierService(identifierRepo repository.PatientIdentifierRepository, patientRepo repository.PatientRepository, auditService AuditService, mrns *mrn.Generator) PatientIdentifierService {
+	return &patientIdentifierService{
+		identifierRepo: identifierRepo,
+		patientRepo:    patientRepo,
+		auditService:   auditService,
+		mrns:           mrns,
+	}
+}
+
+func (s *patientIdentifierService) GetIdentifiers(actor *models.Actor, patientID uint) ([]*models.PatientIdentifier, error) {
+	identifiers, err := s.identifierRepo.GetByPatientID(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityIdentifier, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+	return identifiers, nil
+}
+
+func (s *patientIdentifierService) AddIdentifier(actor *models.Actor, patientID uint, req *models.PatientIdentifierRequest) (*models.PatientIdentifier, error) {
+	patient, err := s.patientRepo.GetByID(int(patientID))
+	if err != nil {
+		return nil, fmt.Errorf("patient not found: %w", err)
+	}
+	if patient.MergedIntoID != nil {
+		return nil, repository.ErrPatientMerged
+	}
+
+	identifier := &models.PatientIdentifier{
+		PatientID: patientID,
+		Type:      req.Type,
+		System:    strings.TrimSpace(req.System),
+		Value:     strings.TrimSpace(req.Value),
+		CreatedBy: actor.UserID,
+	}
+	switch identifier.Type {
+	case models.IdentifierNationalID, models.IdentifierInsurance, models.IdentifierExternal:
+	default:
+		return nil, fmt.Errorf("type must be one of: national_id insurance external")
+	}
+	if identifier.System == "" || identifier.Value == "" {
+		return nil, fmt.Errorf("system and value are required")
+	}
+	if len(identifier.System) > 100 || len(identifier.Value) > 100 {
+		return nil, fmt.Errorf("system and value must be at most 100 characters")
+	}
+	if strings.EqualFold(identifier.System, models.IdentifierSystemMRN) {
+		return nil, fmt.Errorf("system %q is reserved for medical record numbers", identifier.System)
+	}
+
+	created, err := s.identifierRepo.Create(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityIdentifier, created.ID, patientID, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *patientIdentifierService) DeleteIdentifier(actor *models.Actor, patientID, id uint) error {
+	identifier, err := s.identifierRepo.Get(patientID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.identifierRepo.Delete(patientID, id); err != nil {
+		return err
+	}
+	return s.auditService.Record(actor, models.AuditActionDelete, models.AuditEntityIdentifier, id, patientID, identifier, nil)
+}
+
+func (s *patientIdentifierService) FindPatient(actor *models.Actor, system, value string) (*models.Patient, error) {
+	system = strings.TrimSpace(system)
+	value = strings.TrimSpace(value)
+	if system == "" || value == "" {
+		return nil, ErrIdentifierRequired
+	}
+
+	var patient *models.Patient
+	if strings.EqualFold(system, models.IdentifierSystemMRN) {
+		// A mistyped MRN is caught by its check digit instead of being
+		// reported as unknown.
+		if !s.mrns.Valid(value) {
+			return nil, ErrInvalidMRN
+		}
+		found, err := s.patientRepo.GetByMRN(value)
+		if err != nil {
+			return nil, err
+		}
+		patient = found
+	} else {
+		identifier, err := s.identifierRepo.Find(system, value)
+		if err != nil {
+			return nil, err
+		}
+		found, err := s.patientRepo.GetByID(int(identifier.PatientID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get patient: %w", err)
+		}
+		patient = found
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityPatient, patient.ID, patient.ID, nil, nil); err != nil {
+		return nil, err
+	}
+	return patient, nil
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"hospital-management/internal/models"
+)
+
+// DuplicateThreshold is the score, out of 100, from which two patients are
+// reported as likely duplicates.
+const DuplicateThreshold = 80
+
+// minFirstNameSimilarity is the first-name similarity below which two
+// patients are never reported, however much else they share: twins and
+// family members often share a birth date, phone and address but not a
+// given name.
+const minFirstNameSimilarity = 0.7
+
+// matchFields are the fields the matcher compares and their weights. A
+// field missing on either patient is left out of the score instead of
+// counting as a mismatch.
+var matchFields = []struct {
+	name       string
+	weight     float64
+	similarity func(a, b *models.Patient) (float64, bool)
+}{
+	{"first_name", 0.20, func(a, b *models.Patient) (float64, bool) { return nameSimilarity(a.FirstName, b.FirstName) }},
+	{"last_name", 0.25, func(a, b *models.Patient) (float64, bool) { return nameSimilarity(a.LastName, b.LastName) }},
+	{"date_of_birth", 0.25, dateOfBirthSimilarity},
+	{"phone", 0.12, phoneSimilarity},
+	{"email", 0.10, emailSimilarity},
+	{"address", 0.05, addressSimilarity},
+	{"gender", 0.03, func(a, b *models.Patient) (float64, bool) { return boolSimilarity(a.Gender == b.Gender), true }},
+}
+
+// matchPatients scores how likely a and b are the same person. It returns
+// the score from 0 to 100 and the similarity of each compared field.
+func matchPatients(a, b *models.Patient) (int, []models.MatchReason) {
+	var total, weight float64
+	reasons := make([]models.MatchReason, 0, len(matchFields))
+	for _, field := range matchFields {
+		similarity, ok := field.similarity(a, b)
+		if !ok {
+			continue
+		}
+		if field.name == "first_name" && similarity < minFirstNameSimilarity {
+			return 0, nil
+		}
+		total += field.weight * similarity
+		weight += field.weight
+		reasons = append(reasons, models.MatchReason{Field: field.name, Similarity: math.Round(similarity*100) / 100})
+	}
+	if weight == 0 {
+		return 0, nil
+	}
+	return int(math.Round(total / weight * 100)), reasons
+}
+
+func boolSimilarity(equal bool) float64 {
+	if equal {
+		return 1
+	}
+	return 0
+}
+
+// normalizeName lower-cases a name and drops everything but letters, so
+// "O'Neil" and "oneil" compare equal.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+func nameSimilarity(a, b string) (float64, bool) {
+	a, b = normalizeName(a), normalizeName(b)
+	if a == "" || b == "" {
+		return 0, false
+	}
+	return jaroWinkler(a, b), true
+}
+
+// dateOfBirthSimilarity counts a day and month typed the wrong way round
+// as a near match.
+func dateOfBirthSimilarity(a, b *models.Patient) (float64, bool) {
+	x, y := a.DateOfBirth, b.DateOfBirth
+	if x.IsZero() || y.IsZero() {
+		return 0, false
+	}
+	switch {
+	case x.Year() == y.Year() && x.YearDay() == y.YearDay():
+		return 1, true
+	case x.Year() == y.Year() && x.Day() == int(y.Month()) && int(x.Month()) == y.Day():
+		return 0.8, true
+	default:
+		return 0, true
+	}
+}
+
+// phoneSimilarity compares digits only and treats numbers that differ just
+// by a country or trunk prefix as equal.
+func phoneSimilarity(a, b *models.Patient) (float64, bool) {
+	x, y := digits(a.Phone), digits(b.Phone)
+	if len(x) < 7 || len(y) < 7 {
+		return 0, false
+	}
+	// Leading zeros are a trunk prefix ("020 ...") or an international
+	// call prefix ("0044 ..."), neither of which is part of the number.
+	x, y = strings.TrimLeft(x, "0"), strings.TrimLeft(y, "0")
+	if len(x) > len(y) {
+		x, y = y, x
+	}
+	return boolSimilarity(strings.HasSuffix(y, x)), true
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+func emailSimilarity(a, b *models.Patient) (float64, bool) {
+	x := strings.ToLower(strings.TrimSpace(models.StringValue(a.Email)))
+	y := strings.ToLower(strings.TrimSpace(models.StringValue(b.Email)))
+	if x == "" || y == "" {
+		return 0, false
+	}
+	return boolSimilarity(x == y), true
+}
+
+// addressSimilarity is the overlap of the words of the two addresses.
+func addressSimilarity(a, b *models.Patient) (float64, bool) {
+	x, y := addressTokens(models.StringValue(a.Address)), addressTokens(models.StringValue(b.Address))
+	if len(x) == 0 || len(y) == 0 {
+		return 0, false
+	}
+	shared := 0
+	for token := range x {
+		if y[token] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(x)+len(y)-shared), true
+}
+
+func addressTokens(address string) map[string]bool {
+	tokens := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(strings.ToLower(address), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		tokens[token] = true
+	}
+	return tokens
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings, from 0 to
+// 1, which favours strings that share a prefix.
+func jaroWinkler(a, b string) float64 {
+	x, y := []rune(a), []rune(b)
+	if len(x) == 0 && len(y) == 0 {
+		return 1
+	}
+	if len(x) == 0 || len(y) == 0 {
+		return 0
+	}
+
+	window := max(len(x), len(y))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+	xMatched := make([]bool, len(x))
+	yMatched := make([]bool, len(y))
+	matches := 0
+	for i := range x {
+		for j := max(0, i-window); j < min(len(y), i+window+1); j++ {
+			if !yMatched[j] && x[i] == y[j] {
+				xMatched[i], yMatched[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions, j := 0, 0
+	for i := range x {
+		if !xMatched[i] {
+			continue
+		}
+		for !yMatched[j] {
+			j++
+		}
+		if x[i] != y[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(x)) + m/float64(len(y)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, len(x), len(y)) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"hospital-management/internal/models"
+)
+
+func TestJaroWinklerReferenceValues(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"MARTHA", "MARHTA", 0.961},
+		{"DWAYNE", "DUANE", 0.840},
+		{"DIXON", "DICKSONX", 0.813},
+		{"JELLYFISH", "SMELLYFISH", 0.896},
+		{"jon", "john", 0.933},
+		{"same", "same", 1},
+		{"abc", "xyz", 0},
+		{"", "", 1},
+		{"abc", "", 0},
+	}
+	for _, tt := range tests {
+		got := jaroWinkler(tt.a, tt.b)
+		if math.Abs(got-tt.want) > 0.001 {
+			t.Errorf("jaroWinkler(%q, %q) = %.4f, want %.3f", tt.a, tt.b, got, tt.want)
+		}
+		if reverse := jaroWinkler(tt.b, tt.a); math.Abs(reverse-got) > 1e-9 {
+			t.Errorf("jaroWinkler is not symmetric for %q and %q: %.4f and %.4f", tt.a, tt.b, got, reverse)
+		}
+	}
+}
+
+func matchingPatient(first, last, dob, phone string) *models.Patient {
+	born, _ := time.Parse("2006-01-02", dob)
+	return &models.Patient{FirstName: first, LastName: last, DateOfBirth: born, Phone: phone, Gender: "male"}
+}
+
+func reasonFor(reasons []models.MatchReason, field string) (float64, bool) {
+	for _, r := range reasons {
+		if r.Field == field {
+			return r.Similarity, true
+		}
+	}
+	return 0, false
+}
+
+func TestMatchPatientsFindsJonAndJohn(t *testing.T) {
+	jon := matchingPatient("Jon", "Smith", "1980-02-03", "555-0100")
+	john := matchingPatient("John", "Smith", "1980-02-03", "555-0199")
+
+	score, reasons := matchPatients(jon, john)
+
+	if score < DuplicateThreshold {
+		t.Fatalf("score = %d, want at least %d: %+v", score, DuplicateThreshold, reasons)
+	}
+	if phone, ok := reasonFor(reasons, "phone"); !ok || phone != 0 {
+		t.Errorf("phone similarity = %v, %v, want the different numbers counted as a mismatch", phone, ok)
+	}
+}
+
+func TestMatchPatientsVetoesDifferentFirstNames(t *testing.T) {
+	// Twins share everything but their given name.
+	anna := matchingPatient("Anna", "Smith", "1990-06-01", "555-0100")
+	maria := matchingPatient("Maria", "Smith", "1990-06-01", "555-0100")
+	address := "1 Main Street, Springfield"
+	anna.Address, maria.Address = &address, &address
+
+	if similarity := jaroWinkler("anna", "maria"); similarity >= minFirstNameSimilarity {
+		t.Fatalf("test names are too similar: %.2f", similarity)
+	}
+	score, reasons := matchPatients(anna, maria)
+	if score != 0 || reasons != nil {
+		t.Fatalf("score = %d, reasons = %+v, want the first-name veto", score, reasons)
+	}
+}
+
+func TestMatchPatientsDateOfBirth(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"1980-02-03", "1980-02-03", 1},
+		{"1980-02-03", "1980-03-02", 0.8},
+		{"1980-02-03", "1981-03-02", 0},
+		{"1980-02-03", "1980-02-04", 0},
+	}
+	for _, tt := range tests {
+		_, reasons := matchPatients(matchingPatient("John", "Smith", tt.a, ""), matchingPatient("John", "Smith", tt.b, ""))
+		if got, _ := reasonFor(reasons, "date_of_birth"); got != tt.want {
+			t.Errorf("%s and %s: similarity = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestMatchPatientsPhone(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		want     float64
+		compared bool
+	}{
+		{"555-010-0100", "(555) 010 0100", 1, true},
+		{"+1 555 010 0100", "555-010-0100", 1, true},
+		{"+44 20 7946 0958", "020 7946 0958", 1, true},
+		{"0044 20 7946 0958", "020 7946 0958", 1, true},
+		{"555-010-0100", "555-010-0199", 0, true},
+		{"+44 20 7946 0958", "+33 20 7946 0958", 0, true},
+		{"0100", "555-010-0100", 0, false},
+	}
+	for _, tt := range tests {
+		_, reasons := matchPatients(matchingPatient("John", "Smith", "1980-02-03", tt.a), matchingPatient("John", "Smith", "1980-02-03", tt.b))
+		got, compared := reasonFor(reasons, "phone")
+		if got != tt.want || compared != tt.compared {
+			t.Errorf("%q and %q: similarity = %v (compared %v), want %v (compared %v)", tt.a, tt.b, got, compared, tt.want, tt.compared)
+		}
+	}
+}
+
+func TestMatchPatientsLeavesMissingFieldsOut(t *testing.T) {
+	email := "john@example.org"
+	a := matchingPatient("John", "Smith", "1980-02-03", "")
+	b := matchingPatient("John", "Smith", "1980-02-03", "")
+	b.Email = &email
+
+	score, reasons := matchPatients(a, b)
+
+	if score != 100 {
+		t.Errorf("score = %d, want 100 when every compared field matches", score)
+	}
+	for _, field := range []string{"phone", "email", "address"} {
+		if _, ok := reasonFor(reasons, field); ok {
+			t.Errorf("%s compared although it is missing", field)
+		}
+	}
+
+	// Without a date of birth, the names alone decide.
+	a.DateOfBirth, b.DateOfBirth = time.Time{}, time.Time{}
+	b.LastName = "Smyth"
+	score, reasons = matchPatients(a, b)
+	if _, ok := reasonFor(reasons, "date_of_birth"); ok {
+		t.Error("date of birth compared although it is missing")
+	}
+	last, _ := reasonFor(reasons, "last_name")
+	want := int(math.Round((0.20 + 0.25*jaroWinkler("smith", "smyth") + 0.03) / (0.20 + 0.25 + 0.03) * 100))
+	if score != want || last == 0 {
+		t.Errorf("score = %d, want %d from the names and gender only", score, want)
+	}
+
+	if score, _ := matchPatients(&models.Patient{}, &models.Patient{Gender: "male"}); score != 0 {
+		t.Errorf("score = %d for two empty patients, want 0", score)
+	}
+}
@@ -0,0 +1,265 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+)
+
+const (
+	// matchPoolSize bounds how many patients are scored against one patient.
+	matchPoolSize = 200
+	// scanBatchSize is how many patients a duplicate scan reads at a time.
+	scanBatchSize = 500
+)
+
+type PatientMergeService interface {
+	// FindDuplicates returns the patients that score at least
+	// DuplicateThreshold against the patient, best first, and queues them
+	// for review.
+	FindDuplicates(actor *models.Actor, patientID uint) ([]models.DuplicateMatch, error)
+	// ScanDuplicates compares every patient with their likely matches and
+	// queues the pairs that score at least DuplicateThreshold. It returns
+	// the number of pairs found.
+	ScanDuplicates() (int, error)
+	// RunDuplicateScans calls ScanDuplicates every interval. It does not
+	// return.
+	RunDuplicateScans(interval time.Duration)
+	ListDuplicateCandidates(actor *models.Actor, status string, opts *models.ListOptions) (*models.ListResult[*models.DuplicateCandidate], error)
+	// DismissCandidate records that an open candidate pair are different
+	// people; they are not queued again.
+	DismissCandidate(actor *models.Actor, id uint) (*models.DuplicateCandidate, error)
+	// MergePatients moves the appointments and clinical records of the
+	// merged patient to the survivor and retires the merged chart. The
+	// survivor keeps its own demographics.
+	MergePatients(actor *models.Actor, survivorID, mergedID uint) (*models.PatientMerge, error)
+	// UnmergePatients undoes a merge, moving the rows it moved back.
+	UnmergePatients(actor *models.Actor, mergeID uint) (*models.PatientMerge, error)
+	GetPatientMerges(actor *models.Actor, patientID uint) ([]*models.PatientMerge, error)
+}
+
+type patientMergeService struct {
+	patientRepo  repository.PatientRepository
+	mergeRepo    repository.PatientMergeRepository
+	auditService AuditService
+	duplicates   *duplicateFinder
+}
+
+func NewPatientMergeService(patientRepo repository.PatientRepository, mergeRepo repository.PatientMergeRepository, auditService AuditService) PatientMergeService {
+	return &patientMergeService{
+		patientRepo:  patientRepo,
+		mergeRepo:    mergeRepo,
+		auditService: auditService,
+		duplicates:   &duplicateFinder{patientRepo: patientRepo, mergeRepo: mergeRepo},
+	}
+}
+
+// duplicateFinder scores a patient against the patients that share a birth
+// date, phone or email with them and queues the likely duplicates. It is
+// shared by registration and the merge service.
+type duplicateFinder struct {
+	patientRepo repository.PatientRepository
+	mergeRepo   repository.PatientMergeRepository
+}
+
+func (f *duplicateFinder) find(patient *models.Patient, source string) ([]models.DuplicateMatch, error) {
+	pool, err := f.patientRepo.FindMatchCandidates(patient, matchPoolSize)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []models.DuplicateMatch{}
+	for _, other := range pool {
+		score, reasons := matchPatients(patient, other)
+		if score < DuplicateThreshold {
+			continue
+		}
+		matches = append(matches, models.DuplicateMatch{PatientID: other.ID, Score: score, Reasons: reasons})
+
+		candidate := &models.DuplicateCandidate{
+			PatientID:   min(patient.ID, other.ID),
+			CandidateID: max(patient.ID, other.ID),
+			Score:       score,
+			Reasons:     reasons,
+			Status:      models.DuplicateOpen,
+			Source:      source,
+		}
+		if err := f.mergeRepo.SaveCandidate(candidate); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches, nil
+}
+
+func (s *patientMergeService) FindDuplicates(actor *models.Actor, patientID uint) ([]models.DuplicateMatch, error) {
+	patient, err := s.patientRepo.GetByID(int(patientID))
+	if err != nil {
+		return nil, fmt.Errorf("patient not found: %w", err)
+	}
+
+	matches, err := s.duplicates.find(patient, models.DuplicateSourceScan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityDuplicate, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+func (s *patientMergeService) ScanDuplicates() (int, error) {
+	found := 0
+	err := s.patientRepo.EachBatch(&models.PatientFilter{}, scanBatchSize, func(patients []*models.Patient) error {
+		for _, patient := range patients {
+			matches, err := s.duplicates.find(patient, models.DuplicateSourceScan)
+			if err != nil {
+				return err
+			}
+			// Each pair is seen from both sides; count it once.
+			for _, match := range matches {
+				if match.PatientID > patient.ID {
+					found++
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return found, fmt.Errorf("failed to scan for duplicate patients: %w", err)
+	}
+	return found, nil
+}
+
+func (s *patientMergeService) RunDuplicateScans(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		started := time.Now()
+		found, err := s.ScanDuplicates()
+		if err != nil {
+			log.Printf("Duplicate patient scan failed: %v", err)
+			continue
+		}
+		log.Printf("Duplicate patient scan found %d likely duplicate pair(s) in %s", found, time.Since(started).Round(time.Second))
+	}
+}
+
+func (s *patientMergeService) ListDuplicateCandidates(actor *models.Actor, status string, opts *models.ListOptions) (*models.ListResult[*models.DuplicateCandidate], error) {
+	result, err := s.mergeRepo.ListCandidates(status, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list duplicate candidates: %w", err)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityDuplicate, 0, 0, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *patientMergeService) DismissCandidate(actor *models.Actor, id uint) (*models.DuplicateCandidate, error) {
+	candidate, err := s.mergeRepo.GetCandidate(id)
+	if err != nil {
+		return nil, err
+	}
+	if candidate.Status != models.DuplicateOpen {
+		return nil, ErrCandidateReviewed
+	}
+	before := *candidate
+
+	now := time.Now()
+	candidate.Status = models.DuplicateDismissed
+	candidate.ReviewedBy = models.UintPtr(actor.UserID)
+	candidate.ReviewedAt = &now
+	updated, err := s.mergeRepo.UpdateCandidate(candidate)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityDuplicate, updated.ID, updated.PatientID, &before, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *patientMergeService) MergePatients(actor *models.Actor, survivorID, mergedID uint) (*models.PatientMerge, error) {
+	if survivorID == mergedID {
+		return nil, ErrSelfMerge
+	}
+	for _, id := range []uint{survivorID, mergedID} {
+		if _, err := s.patientRepo.GetByID(int(id)); err != nil {
+			return nil, fmt.Errorf("patient not found: %w", err)
+		}
+	}
+
+	merge := &models.PatientMerge{
+		SurvivorID: survivorID,
+		MergedID:   mergedID,
+		MergedBy:   actor.UserID,
+	}
+	if err := s.mergeRepo.Merge(merge); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordMerge(actor, models.AuditActionMerge, merge); err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+func (s *patientMergeService) UnmergePatients(actor *models.Actor, mergeID uint) (*models.PatientMerge, error) {
+	merge, err := s.mergeRepo.GetMerge(mergeID)
+	if err != nil {
+		return nil, err
+	}
+	if merge.UnmergedAt != nil {
+		return nil, repository.ErrMergeUndone
+	}
+
+	now := time.Now()
+	merge.UnmergedBy = models.UintPtr(actor.UserID)
+	merge.UnmergedAt = &now
+	if err := s.mergeRepo.Unmerge(merge); err != nil {
+		return nil, err
+	}
+
+	if err := s.recordMerge(actor, models.AuditActionUnmerge, merge); err != nil {
+		return nil, err
+	}
+
+	return merge, nil
+}
+
+// recordMerge audits a merge or unmerge on both patients' trails.
+func (s *patientMergeService) recordMerge(actor *models.Actor, action string, merge *models.PatientMerge) error {
+	for _, patientID := range []uint{merge.SurvivorID, merge.MergedID} {
+		if err := s.auditService.Record(actor, action, models.AuditEntityPatientMerge, merge.ID, patientID, nil, merge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *patientMergeService) GetPatientMerges(actor *models.Actor, patientID uint) ([]*models.PatientMerge, error) {
+	merges, err := s.mergeRepo.GetMergesForPatient(patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityPatientMerge, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return merges, nil
+}
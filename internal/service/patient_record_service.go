@@ -0,0 +1,339 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"strings"
+	"time"
+)
+
+// PatientRecordService manages a patient's allergies, medications and
+// problem list.
+type PatientRecordService interface {
+	GetAllergies(actor *models.Actor, patientID uint) ([]*models.Allergy, error)
+	CreateAllergy(actor *models.Actor, patientID uint, req *models.AllergyRequest) (*models.Allergy, error)
+	UpdateAllergy(actor *models.Actor, patientID, id uint, req *models.AllergyRequest) (*models.Allergy, error)
+	DeleteAllergy(actor *models.Actor, patientID, id uint) error
+
+	GetMedications(actor *models.Actor, patientID uint) ([]*models.Medication, error)
+	CreateMedication(actor *models.Actor, patientID uint, req *models.MedicationRequest) (*models.Medication, error)
+	UpdateMedication(actor *models.Actor, patientID, id uint, req *models.MedicationRequest) (*models.Medication, error)
+	DeleteMedication(actor *models.Actor, patientID, id uint) error
+
+	GetProblems(actor *models.Actor, patientID uint) ([]*models.Problem, error)
+	CreateProblem(actor *models.Actor, patientID uint, req *models.ProblemRequest) (*models.Problem, error)
+	UpdateProblem(actor *models.Actor, patientID, id uint, req *models.ProblemRequest) (*models.Problem, error)
+	DeleteProblem(actor *models.Actor, patientID, id uint) error
+
+	GetLegacyNotes(actor *models.Actor, patientID uint) ([]*models.PatientLegacyNote, error)
+}
+
+type patientRecordService struct {
+	recordRepo   repository.PatientRecordRepository
+	patientRepo  repository.PatientRepository
+	auditService AuditService
+}
+
+func NewPatientRecordService(recordRepo repository.PatientRecordRepository, patientRepo repository.PatientRepository, auditService AuditService) PatientRecordService {
+	return &patientRecordService{
+		recordRepo:   recordRepo,
+		patientRepo:  patientRepo,
+		auditService: auditService,
+	}
+}
+
+func (s *patientRecordService) GetAllergies(actor *models.Actor, patientID uint) ([]*models.Allergy, error) {
+	allergies, err := s.recordRepo.GetAllergies(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityAllergy, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+	return allergies, nil
+}
+
+func (s *patientRecordService) CreateAllergy(actor *models.Actor, patientID uint, req *models.AllergyRequest) (*models.Allergy, error) {
+	if err := s.requirePatient(patientID); err != nil {
+		return nil, err
+	}
+
+	allergy := &models.Allergy{PatientID: patientID, RecordedBy: actor.UserID}
+	if err := applyAllergyRequest(allergy, req); err != nil {
+		return nil, err
+	}
+
+	created, err := s.recordRepo.CreateAllergy(allergy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityAllergy, created.ID, patientID, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *patientRecordService) UpdateAllergy(actor *models.Actor, patientID, id uint, req *models.AllergyRequest) (*models.Allergy, error) {
+	allergy, err := s.recordRepo.GetAllergy(patientID, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *allergy
+
+	if err := applyAllergyRequest(allergy, req); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.recordRepo.UpdateAllergy(allergy)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityAllergy, updated.ID, patientID, &before, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *patientRecordService) DeleteAllergy(actor *models.Actor, patientID, id uint) error {
+	allergy, err := s.recordRepo.GetAllergy(patientID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.recordRepo.DeleteAllergy(patientID, id); err != nil {
+		return err
+	}
+	return s.auditService.Record(actor, models.AuditActionDelete, models.AuditEntityAllergy, id, patientID, allergy, nil)
+}
+
+func (s *patientRecordService) GetMedications(actor *models.Actor, patientID uint) ([]*models.Medication, error) {
+	medications, err := s.recordRepo.GetMedications(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityMedication, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+	return medications, nil
+}
+
+func (s *patientRecordService) CreateMedication(actor *models.Actor, patientID uint, req *models.MedicationRequest) (*models.Medication, error) {
+	if err := s.requirePatient(patientID); err != nil {
+		return nil, err
+	}
+
+	medication := &models.Medication{PatientID: patientID, RecordedBy: actor.UserID}
+	if err := applyMedicationRequest(medication, req); err != nil {
+		return nil, err
+	}
+
+	created, err := s.recordRepo.CreateMedication(medication)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityMedication, created.ID, patientID, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *patientRecordService) UpdateMedication(actor *models.Actor, patientID, id uint, req *models.MedicationRequest) (*models.Medication, error) {
+	medication, err := s.recordRepo.GetMedication(patientID, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *medication
+
+	if err := applyMedicationRequest(medication, req); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.recordRepo.UpdateMedication(medication)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityMedication, updated.ID, patientID, &before, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *patientRecordService) DeleteMedication(actor *models.Actor, patientID, id uint) error {
+	medication, err := s.recordRepo.GetMedication(patientID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.recordRepo.DeleteMedication(patientID, id); err != nil {
+		return err
+	}
+	return s.auditService.Record(actor, models.AuditActionDelete, models.AuditEntityMedication, id, patientID, medication, nil)
+}
+
+func (s *patientRecordService) GetProblems(actor *models.Actor, patientID uint) ([]*models.Problem, error) {
+	problems, err := s.recordRepo.GetProblems(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityProblem, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+	return problems, nil
+}
+
+func (s *patientRecordService) CreateProblem(actor *models.Actor, patientID uint, req *models.ProblemRequest) (*models.Problem, error) {
+	if err := s.requirePatient(patientID); err != nil {
+		return nil, err
+	}
+
+	problem := &models.Problem{PatientID: patientID, RecordedBy: actor.UserID}
+	if err := applyProblemRequest(problem, req); err != nil {
+		return nil, err
+	}
+
+	created, err := s.recordRepo.CreateProblem(problem)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityProblem, created.ID, patientID, nil, created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+func (s *patientRecordService) UpdateProblem(actor *models.Actor, patientID, id uint, req *models.ProblemRequest) (*models.Problem, error) {
+	problem, err := s.recordRepo.GetProblem(patientID, id)
+	if err != nil {
+		return nil, err
+	}
+	before := *problem
+
+	if err := applyProblemRequest(problem, req); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.recordRepo.UpdateProblem(problem)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityProblem, updated.ID, patientID, &before, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (s *patientRecordService) DeleteProblem(actor *models.Actor, patientID, id uint) error {
+	problem, err := s.recordRepo.GetProblem(patientID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.recordRepo.DeleteProblem(patientID, id); err != nil {
+		return err
+	}
+	return s.auditService.Record(actor, models.AuditActionDelete, models.AuditEntityProblem, id, patientID, problem, nil)
+}
+
+func (s *patientRecordService) GetLegacyNotes(actor *models.Actor, patientID uint) ([]*models.PatientLegacyNote, error) {
+	notes, err := s.recordRepo.GetLegacyNotes(patientID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityLegacyNote, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+func (s *patientRecordService) requirePatient(patientID uint) error {
+	if _, err := s.patientRepo.GetByID(int(patientID)); err != nil {
+		return fmt.Errorf("patient not found: %w", err)
+	}
+	return nil
+}
+
+func applyAllergyRequest(allergy *models.Allergy, req *models.AllergyRequest) error {
+	if strings.TrimSpace(req.Substance) == "" {
+		return fmt.Errorf("substance is required")
+	}
+	switch req.Severity {
+	case models.SeverityMild, models.SeverityModerate, models.SeveritySevere, models.SeverityLifeThreatening:
+	default:
+		return fmt.Errorf("severity must be one of: mild moderate severe life_threatening")
+	}
+	status, err := clinicalStatus(req.Status)
+	if err != nil {
+		return err
+	}
+
+	allergy.Substance = req.Substance
+	allergy.Reaction = req.Reaction
+	allergy.Severity = req.Severity
+	allergy.Status = status
+	return nil
+}
+
+func applyMedicationRequest(medication *models.Medication, req *models.MedicationRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	start, err := parseOptionalDate("start_date", req.StartDate)
+	if err != nil {
+		return err
+	}
+	stop, err := parseOptionalDate("stop_date", req.StopDate)
+	if err != nil {
+		return err
+	}
+	if start != nil && stop != nil && stop.Before(*start) {
+		return fmt.Errorf("stop_date must not be before start_date")
+	}
+
+	medication.Name = req.Name
+	medication.Dose = req.Dose
+	medication.Route = req.Route
+	medication.Frequency = req.Frequency
+	medication.StartDate = start
+	medication.StopDate = stop
+	return nil
+}
+
+func applyProblemRequest(problem *models.Problem, req *models.ProblemRequest) error {
+	if strings.TrimSpace(req.Condition) == "" {
+		return fmt.Errorf("condition is required")
+	}
+	onset, err := parseOptionalDate("onset", req.Onset)
+	if err != nil {
+		return err
+	}
+	status, err := clinicalStatus(req.Status)
+	if err != nil {
+		return err
+	}
+
+	problem.Condition = req.Condition
+	problem.Onset = onset
+	problem.Status = status
+	return nil
+}
+
+// clinicalStatus validates an allergy or problem status, defaulting to active.
+func clinicalStatus(status string) (string, error) {
+	switch status {
+	case "":
+		return models.ClinicalStatusActive, nil
+	case models.ClinicalStatusActive, models.ClinicalStatusInactive, models.ClinicalStatusResolved:
+		return status, nil
+	default:
+		return "", fmt.Errorf("status must be one of: active inactive resolved")
+	}
+}
+
+func parseOptionalDate(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s format, expected YYYY-MM-DD", field)
+	}
+	return &date, nil
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"hospital-management/internal/models"
+)
+
+// maxSearchTerms bounds the terms of one search query.
+const maxSearchTerms = 8
+
+var (
+	isoDateTerm = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+	// dayMonthTerm is read both as day/month/year and month/day/year.
+	dayMonthTerm = regexp.MustCompile(`^(\d{1,2})[./-](\d{1,2})[./-](\d{4})$`)
+	phoneToken   = regexp.MustCompile(`^[+(]?[0-9][0-9().-]*\)?$`)
+)
+
+// parsePatientSearch splits a search query into terms: dates of birth in
+// ISO or day/month/year form, phone numbers in any format (digits split by
+// spaces are joined up), four-digit years and words of a name or email.
+func parsePatientSearch(query string) []models.PatientSearchTerm {
+	var terms []models.PatientSearchTerm
+	var phone []string
+
+	flushPhone := func() {
+		if len(phone) == 0 {
+			return
+		}
+		if term, ok := phoneTerm(phone); ok {
+			terms = append(terms, term)
+		}
+		phone = nil
+	}
+
+	for _, token := range strings.Fields(query) {
+		if dates, ok := parseDateTerm(token); ok {
+			flushPhone()
+			if len(dates) > 0 {
+				terms = append(terms, models.PatientSearchTerm{Dates: dates})
+			}
+			continue
+		}
+		if phoneToken.MatchString(token) {
+			phone = append(phone, token)
+			continue
+		}
+		flushPhone()
+		// Email addresses are split into their words, as the full-text
+		// index does.
+		for _, word := range strings.FieldsFunc(strings.ToLower(token), func(r rune) bool { return r == '@' || r == '.' || r == '_' || r == '-' }) {
+			if name := normalizeName(word); name != "" {
+				terms = append(terms, models.PatientSearchTerm{Name: name})
+			}
+		}
+	}
+	flushPhone()
+
+	if len(terms) > maxSearchTerms {
+		terms = terms[:maxSearchTerms]
+	}
+	return terms
+}
+
+// phoneTerm turns a run of numeric tokens into a phone term. A lone
+// four-digit number may also be a year of birth; numbers of fewer than three
+// digits are too vague to search for. With a country code, the national
+// number is tried too.
+func phoneTerm(tokens []string) (models.PatientSearchTerm, bool) {
+	number := digits(strings.Join(tokens, ""))
+	if len(number) < 3 {
+		return models.PatientSearchTerm{}, false
+	}
+
+	term := models.PatientSearchTerm{Digits: []string{number}}
+	if len(number) > 10 {
+		term.Digits = append(term.Digits, number[len(number)-10:])
+	}
+	if len(tokens) == 1 && len(number) == 4 && len(tokens[0]) == 4 {
+		if year, _ := strconv.Atoi(number); year >= 1900 && year <= time.Now().Year() {
+			term.Year = year
+		}
+	}
+	return term, true
+}
+
+// parseDateTerm reports whether a token is written as a date and returns
+// the real dates it can stand for, if any.
+func parseDateTerm(token string) ([]time.Time, bool) {
+	if m := isoDateTerm.FindStringSubmatch(token); m != nil {
+		return validDates([3]string{m[1], m[2], m[3]}), true
+	}
+	if m := dayMonthTerm.FindStringSubmatch(token); m != nil {
+		return validDates([3]string{m[3], m[2], m[1]}, [3]string{m[3], m[1], m[2]}), true
+	}
+	return nil, false
+}
+
+// validDates builds the real dates among year, month, day triples.
+func validDates(candidates ...[3]string) []time.Time {
+	var dates []time.Time
+	for _, c := range candidates {
+		year, _ := strconv.Atoi(c[0])
+		month, _ := strconv.Atoi(c[1])
+		day, _ := strconv.Atoi(c[2])
+		date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+		if date.Year() != year || int(date.Month()) != month || date.Day() != day {
+			continue
+		}
+		if len(dates) == 0 || !dates[0].Equal(date) {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// highlightPatient marks where each term matched the patient. A name term
+// that matched no field literally, because it was a typo or sounds alike,
+// marks the whole of the most similar name.
+func highlightPatient(patient *models.Patient, terms []models.PatientSearchTerm) map[string]string {
+	fields := map[string]string{
+		"first_name":    patient.FirstName,
+		"last_name":     patient.LastName,
+		"email":         models.StringValue(patient.Email),
+		"phone":         patient.Phone,
+		"date_of_birth": patient.DateOfBirth.Format("2006-01-02"),
+	}
+	marks := make(map[string][][2]int)
+
+	for _, term := range terms {
+		if term.Name != "" {
+			found := false
+			for _, field := range []string{"first_name", "last_name", "email"} {
+				value := fields[field]
+				lower := strings.ToLower(value)
+				if len(lower) != len(value) {
+					continue
+				}
+				if i := strings.Index(lower, term.Name); i >= 0 {
+					marks[field] = append(marks[field], [2]int{i, i + len(term.Name)})
+					found = true
+				}
+			}
+			if !found {
+				best, similarity := "first_name", jaroWinkler(normalizeName(patient.FirstName), term.Name)
+				if s := jaroWinkler(normalizeName(patient.LastName), term.Name); s > similarity {
+					best = "last_name"
+				}
+				marks[best] = append(marks[best], [2]int{0, len(fields[best])})
+			}
+		}
+
+		// A number matched only by its last digits has just those marked.
+		numbers := term.Digits
+		if len(numbers) > 0 {
+			numbers = append(append([]string{}, numbers...), models.PhoneSuffix(numbers[0]))
+		}
+		for _, number := range numbers {
+			if span, ok := digitSpan(patient.Phone, number); ok {
+				marks["phone"] = append(marks["phone"], span)
+				break
+			}
+		}
+
+		if term.Year != 0 && patient.DateOfBirth.Year() == term.Year {
+			marks["date_of_birth"] = append(marks["date_of_birth"], [2]int{0, 4})
+		}
+		for _, date := range term.Dates {
+			if date.Format("2006-01-02") == fields["date_of_birth"] {
+				marks["date_of_birth"] = append(marks["date_of_birth"], [2]int{0, len(fields["date_of_birth"])})
+			}
+		}
+	}
+
+	highlights := make(map[string]string, len(marks))
+	for field, spans := range marks {
+		highlights[field] = markSpans(fields[field], spans)
+	}
+	return highlights
+}
+
+// digitSpan finds number among the digits of phone and returns the byte
+// range of phone it covers, separators included.
+func digitSpan(phone, number string) ([2]int, bool) {
+	var positions []int
+	var phoneDigits strings.Builder
+	for i, r := range phone {
+		if r >= '0' && r <= '9' {
+			positions = append(positions, i)
+			phoneDigits.WriteRune(r)
+		}
+	}
+	i := strings.Index(phoneDigits.String(), number)
+	if i < 0 || number == "" {
+		return [2]int{}, false
+	}
+	return [2]int{positions[i], positions[i+len(number)-1] + 1}, true
+}
+
+// markSpans HTML-escapes value and wraps the given byte ranges, merged
+// where they overlap, in <mark> tags.
+func markSpans(value string, spans [][2]int) string {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var b strings.Builder
+	pos := 0
+	for i := 0; i < len(spans); i++ {
+		start, end := spans[i][0], spans[i][1]
+		for i+1 < len(spans) && spans[i+1][0] <= end {
+			i++
+			end = max(end, spans[i][1])
+		}
+		start = max(start, pos)
+		if start >= end {
+			continue
+		}
+		b.WriteString(html.EscapeString(value[pos:start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(value[start:end]))
+		b.WriteString("</mark>")
+		pos = end
+	}
+	b.WriteString(html.EscapeString(value[pos:]))
+	return b.String()
+}
+
+// roundScore keeps search scores readable.
+func roundScore(score float64) float64 {
+	return math.Round(score*100) / 100
+}
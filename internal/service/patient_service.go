@@ -4,35 +4,49 @@ package service
 import (
 	"fmt"
 	"hospital-management/internal/models"
+	"hospital-management/internal/mrn"
 	"hospital-management/internal/repository"
+	"log"
 	"time"
 )
 
 type PatientService interface {
-	CreatePatient(req *models.PatientRequest) (*models.Patient, error)
-	GetPatientByID(id uint) (*models.Patient, error)
-	GetPatientByPhone(phone string) (*models.Patient, error)
-	UpdatePatient(id uint, req *models.PatientRequest) (*models.Patient, error)
-	DeletePatient(id uint) error
-	GetAllPatients() ([]*models.Patient, error)
-	SearchPatients(query string) ([]*models.Patient, error)
+	CreatePatient(actor *models.Actor, req *models.PatientRequest) (*models.Patient, error)
+	GetPatientByID(actor *models.Actor, id uint) (*models.Patient, error)
+	GetPatientByPhone(actor *models.Actor, phone string) (*models.Patient, error)
+	UpdatePatient(actor *models.Actor, id uint, req *models.PatientRequest) (*models.Patient, error)
+	DeletePatient(actor *models.Actor, id uint) error
+	GetAllPatients(actor *models.Actor) ([]*models.Patient, error)
+	ListPatients(actor *models.Actor, filter *models.PatientFilter, opts *models.ListOptions) (*models.ListResult[*models.Patient], error)
+	// SearchPatients finds patients by name, email, phone number or date of
+	// birth, tolerating typos, and ranks them by relevance.
+	SearchPatients(actor *models.Actor, query string, opts *models.ListOptions) (*models.ListResult[*models.PatientSearchResult], error)
+	// AssignMissingMRNs gives an MRN to every patient registered before
+	// MRNs were introduced and returns how many it assigned.
+	AssignMissingMRNs() (int, error)
 }
 
 type patientService struct {
-	patientRepo repository.PatientRepository
+	patientRepo  repository.PatientRepository
+	auditService AuditService
+	duplicates   *duplicateFinder
+	mrns         *mrn.Generator
 }
 
-func NewPatientService(patientRepo repository.PatientRepository) PatientService {
+func NewPatientService(patientRepo repository.PatientRepository, mergeRepo repository.PatientMergeRepository, auditService AuditService, mrns *mrn.Generator) PatientService {
 	return &patientService{
-		patientRepo: patientRepo,
+		patientRepo:  patientRepo,
+		auditService: auditService,
+		duplicates:   &duplicateFinder{patientRepo: patientRepo, mergeRepo: mergeRepo},
+		mrns:         mrns,
 	}
 }
 
-func (s *patientService) CreatePatient(req *models.PatientRequest) (*models.Patient, error) {
+func (s *patientService) CreatePatient(actor *models.Actor, req *models.PatientRequest) (*models.Patient, error) {
 	// Check if patient with phone already exists
 	existingPatient, _ := s.patientRepo.GetByPhone(req.Phone)
 	if existingPatient != nil {
-		return nil, fmt.Errorf("patient with phone number already exists")
+		return nil, ErrDuplicatePatient
 	}
 
 	// Parse DateOfBirth from string to time.Time
@@ -41,18 +55,21 @@ func (s *patientService) CreatePatient(req *models.PatientRequest) (*models.Pati
 		return nil, fmt.Errorf("invalid date_of_birth format: %w", err)
 	}
 
+	number, err := s.nextMRN()
+	if err != nil {
+		return nil, err
+	}
+
 	patient := &models.Patient{
-		FirstName:      req.FirstName,
-		LastName:       req.LastName,
-		Email:          models.StringPtr(req.Email),
-		Phone:          req.Phone,
-		DateOfBirth:    parsedDOB,
-		Gender:         req.Gender,
-		Address:        models.StringPtr(req.Address),
-		MedicalHistory: models.StringPtr(req.MedicalHistory),
-		Allergies:      models.StringPtr(req.Allergies),
-		Medications:    models.StringPtr(req.Medications),
-		CreatedBy:      0, // Set appropriately if needed
+		MRN:         &number,
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Email:       models.StringPtr(req.Email),
+		Phone:       req.Phone,
+		DateOfBirth: parsedDOB,
+		Gender:      req.Gender,
+		Address:     models.StringPtr(req.Address),
+		CreatedBy:   actor.UserID,
 	}
 
 	createdPatient, err := s.patientRepo.Create(patient)
@@ -60,31 +77,57 @@ func (s *patientService) CreatePatient(req *models.PatientRequest) (*models.Pati
 		return nil, fmt.Errorf("failed to create patient: %w", err)
 	}
 
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityPatient, createdPatient.ID, createdPatient.ID, nil, createdPatient); err != nil {
+		return nil, err
+	}
+
+	// Likely duplicates are only flagged for review; they never block a
+	// registration.
+	matches, err := s.duplicates.find(createdPatient, models.DuplicateSourceCreate)
+	if err != nil {
+		log.Printf("Failed to check patient %d for duplicates: %v", createdPatient.ID, err)
+	}
+	createdPatient.PossibleDuplicates = matches
+
 	return createdPatient, nil
 }
 
-func (s *patientService) GetPatientByID(id uint) (*models.Patient, error) {
+func (s *patientService) GetPatientByID(actor *models.Actor, id uint) (*models.Patient, error) {
 	patient, err := s.patientRepo.GetByID(int(id))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get patient: %w", err)
 	}
+
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityPatient, patient.ID, patient.ID, nil, nil); err != nil {
+		return nil, err
+	}
+
 	return patient, nil
 }
 
-func (s *patientService) GetPatientByPhone(phone string) (*models.Patient, error) {
+func (s *patientService) GetPatientByPhone(actor *models.Actor, phone string) (*models.Patient, error) {
 	patient, err := s.patientRepo.GetByPhone(phone)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get patient by phone: %w", err)
 	}
+
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityPatient, patient.ID, patient.ID, nil, nil); err != nil {
+		return nil, err
+	}
+
 	return patient, nil
 }
 
-func (s *patientService) UpdatePatient(id uint, req *models.PatientRequest) (*models.Patient, error) {
+func (s *patientService) UpdatePatient(actor *models.Actor, id uint, req *models.PatientRequest) (*models.Patient, error) {
 	// Get existing patient
 	patient, err := s.patientRepo.GetByID(int(id))
 	if err != nil {
 		return nil, fmt.Errorf("patient not found: %w", err)
 	}
+	if patient.MergedIntoID != nil {
+		return nil, repository.ErrPatientMerged
+	}
+	before := *patient
 
 	// Update fields (only update if non-empty)
 	if req.FirstName != "" {
@@ -111,44 +154,126 @@ func (s *patientService) UpdatePatient(id uint, req *models.PatientRequest) (*mo
 	if req.Address != "" {
 		patient.Address = models.StringPtr(req.Address)
 	}
-	if req.MedicalHistory != "" {
-		patient.MedicalHistory = models.StringPtr(req.MedicalHistory)
-	}
-	if req.Allergies != "" {
-		patient.Allergies = models.StringPtr(req.Allergies)
-	}
-	if req.Medications != "" {
-		patient.Medications = models.StringPtr(req.Medications)
-	}
+
+	patient.UpdatedBy = models.UintPtr(actor.UserID)
 
 	updatedPatient, err := s.patientRepo.Update(patient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update patient: %w", err)
 	}
 
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityPatient, updatedPatient.ID, updatedPatient.ID, &before, updatedPatient); err != nil {
+		return nil, err
+	}
+
 	return updatedPatient, nil
 }
 
-func (s *patientService) DeletePatient(id uint) error {
-	err := s.patientRepo.Delete(int(id))
+func (s *patientService) DeletePatient(actor *models.Actor, id uint) error {
+	patient, err := s.patientRepo.GetByID(int(id))
 	if err != nil {
 		return fmt.Errorf("failed to delete patient: %w", err)
 	}
-	return nil
+	if patient.MergedIntoID != nil {
+		return repository.ErrPatientMerged
+	}
+
+	if err := s.patientRepo.Delete(int(id)); err != nil {
+		return fmt.Errorf("failed to delete patient: %w", err)
+	}
+
+	return s.auditService.Record(actor, models.AuditActionDelete, models.AuditEntityPatient, patient.ID, patient.ID, patient, nil)
 }
 
-func (s *patientService) GetAllPatients() ([]*models.Patient, error) {
+func (s *patientService) GetAllPatients(actor *models.Actor) ([]*models.Patient, error) {
 	patients, err := s.patientRepo.GetAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all patients: %w", err)
 	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityPatient, 0, 0, nil, nil); err != nil {
+		return nil, err
+	}
+
 	return patients, nil
 }
 
-func (s *patientService) SearchPatients(query string) ([]*models.Patient, error) {
-	patients, err := s.patientRepo.Search(query)
+func (s *patientService) ListPatients(actor *models.Actor, filter *models.PatientFilter, opts *models.ListOptions) (*models.ListResult[*models.Patient], error) {
+	result, err := s.patientRepo.List(filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list patients: %w", err)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityPatient, 0, 0, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *patientService) SearchPatients(actor *models.Actor, query string, opts *models.ListOptions) (*models.ListResult[*models.PatientSearchResult], error) {
+	terms := parsePatientSearch(query)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearch
+	}
+
+	result, err := s.patientRepo.Search(terms, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search patients: %w", err)
 	}
-	return patients, nil
+	for _, match := range result.Data {
+		match.Score = roundScore(match.Score)
+		match.Highlights = highlightPatient(match.Patient, terms)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityPatient, 0, 0, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// assignMRNBatch is how many patients without an MRN are fetched at a time
+// by AssignMissingMRNs.
+const assignMRNBatch = 500
+
+func (s *patientService) AssignMissingMRNs() (int, error) {
+	assigned := 0
+	for {
+		ids, err := s.patientRepo.GetIDsWithoutMRN(assignMRNBatch)
+		if err != nil {
+			return assigned, err
+		}
+		if len(ids) == 0 {
+			return assigned, nil
+		}
+		for _, id := range ids {
+			number, err := s.nextMRN()
+			if err != nil {
+				return assigned, err
+			}
+			// Another instance running the backfill may have got there
+			// first, in which case the drawn number is skipped.
+			ok, err := s.patientRepo.AssignMRN(id, number)
+			if err != nil {
+				return assigned, fmt.Errorf("failed to assign MRN to patient %d: %w", id, err)
+			}
+			if ok {
+				assigned++
+			}
+		}
+	}
+}
+
+// nextMRN draws the next number from this facility's MRN sequence.
+func (s *patientService) nextMRN() (string, error) {
+	seq, err := s.patientRepo.NextMRNSequence(s.mrns.Facility)
+	if err != nil {
+		return "", err
+	}
+	number, err := s.mrns.Format(seq)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate MRN: %w", err)
+	}
+	return number, nil
 }
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/drugs"
+	"hospital-management/internal/models"
+	"time"
+)
+
+// interactionChecker compares a proposed drug with what is known about the
+// patient. Drugs missing from the catalogue are compared by name only.
+type interactionChecker struct {
+	catalogue drugs.Catalogue
+}
+
+// resolve returns the catalogue entry for name, or a bare entry holding only
+// the name.
+func (c interactionChecker) resolve(name string) (*drugs.Drug, bool) {
+	if drug, ok := c.catalogue.Lookup(name); ok {
+		return drug, true
+	}
+	return &drugs.Drug{Name: name}, false
+}
+
+// check returns the alerts for prescribing drug at now, given in the clinic's
+// time zone. Active allergies block the prescription, except mild ones which
+// only warn; current medications and prescriptions sharing an ingredient or
+// therapeutic class warn of duplicate therapy.
+func (c interactionChecker) check(drug *drugs.Drug, allergies []*models.Allergy, medications []*models.Medication, prescriptions []*models.Prescription, now time.Time) []models.PrescriptionAlert {
+	alerts := []models.PrescriptionAlert{}
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	drugTerms := termSet(drug.Terms())
+	for _, allergy := range allergies {
+		if allergy.Status != models.ClinicalStatusActive {
+			continue
+		}
+		if !c.allergyMatches(allergy.Substance, drugTerms) {
+			continue
+		}
+		severity := models.AlertSeverityBlock
+		if allergy.Severity == models.SeverityMild {
+			severity = models.AlertSeverityWarn
+		}
+		message := fmt.Sprintf("patient has a %s allergy to %s", allergy.Severity, allergy.Substance)
+		if allergy.Reaction != "" {
+			message += fmt.Sprintf(" (%s)", allergy.Reaction)
+		}
+		alerts = append(alerts, models.PrescriptionAlert{
+			Type:     models.AlertTypeAllergy,
+			Severity: severity,
+			Message:  message,
+			Source:   fmt.Sprintf("allergy:%d", allergy.ID),
+		})
+	}
+
+	for _, medication := range medications {
+		if !medication.IsCurrent(today) {
+			continue
+		}
+		if message, ok := c.duplicateTherapy(drug, medication.Name); ok {
+			alerts = append(alerts, models.PrescriptionAlert{
+				Type:     models.AlertTypeDuplicateTherapy,
+				Severity: models.AlertSeverityWarn,
+				Message:  message + " on the medication list",
+				Source:   fmt.Sprintf("medication:%d", medication.ID),
+			})
+		}
+	}
+
+	for _, prescription := range prescriptions {
+		if !prescription.IsCurrent(now) {
+			continue
+		}
+		if message, ok := c.duplicateTherapy(drug, prescription.DrugName); ok {
+			alerts = append(alerts, models.PrescriptionAlert{
+				Type:     models.AlertTypeDuplicateTherapy,
+				Severity: models.AlertSeverityWarn,
+				Message:  message + " already prescribed",
+				Source:   fmt.Sprintf("prescription:%d", prescription.ID),
+			})
+		}
+	}
+
+	return alerts
+}
+
+// allergyMatches reports whether an allergy to substance covers a drug with
+// the given terms. A substance found in the catalogue also matches through
+// its ingredients and allergy groups, so a penicillin allergy recorded as
+// "amoxicillin" still flags other penicillins.
+func (c interactionChecker) allergyMatches(substance string, drugTerms map[string]bool) bool {
+	if drugTerms[drugs.Normalize(substance)] {
+		return true
+	}
+	known, ok := c.catalogue.Lookup(substance)
+	if !ok {
+		return false
+	}
+	for _, term := range known.ActiveIngredients() {
+		if drugTerms[term] {
+			return true
+		}
+	}
+	for _, group := range known.AllergyGroups {
+		if drugTerms[drugs.Normalize(group)] {
+			return true
+		}
+	}
+	return false
+}
+
+// duplicateTherapy reports whether drug repeats the drug named other, either
+// by sharing an active ingredient or by belonging to the same class.
+func (c interactionChecker) duplicateTherapy(drug *drugs.Drug, other string) (string, bool) {
+	existing, _ := c.resolve(other)
+
+	ingredients := termSet(existing.ActiveIngredients())
+	for _, ingredient := range drug.ActiveIngredients() {
+		if ingredients[ingredient] {
+			return fmt.Sprintf("%s duplicates %s", ingredient, other), true
+		}
+	}
+	if drug.Class != "" && drugs.Normalize(drug.Class) == drugs.Normalize(existing.Class) {
+		return fmt.Sprintf("%s is in the same class (%s) as %s", drug.Name, drug.Class, other), true
+	}
+	return "", false
+}
+
+// blocked reports whether any alert needs an override.
+func blocked(alerts []models.PrescriptionAlert) bool {
+	for _, alert := range alerts {
+		if alert.Severity == models.AlertSeverityBlock {
+			return true
+		}
+	}
+	return false
+}
+
+func termSet(terms []string) map[string]bool {
+	set := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		if term != "" {
+			set[term] = true
+		}
+	}
+	return set
+}
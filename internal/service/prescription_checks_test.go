@@ -0,0 +1,164 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"hospital-management/internal/drugs"
+	"hospital-management/internal/models"
+)
+
+func newTestChecker(t *testing.T) interactionChecker {
+	t.Helper()
+	catalogue, err := drugs.NewCatalogue([]*drugs.Drug{
+		{Code: "AMOX", Name: "Amoxicillin", BrandNames: []string{"Amoxil"}, Class: "Penicillin", Ingredients: []string{"amoxicillin"}, AllergyGroups: []string{"penicillins"}},
+		{Code: "COAMOX", Name: "Co-amoxiclav", BrandNames: []string{"Augmentin"}, Class: "Penicillin", Ingredients: []string{"amoxicillin", "clavulanic acid"}, AllergyGroups: []string{"penicillins"}},
+		{Code: "FLUC", Name: "Flucloxacillin", Class: "Penicillin", Ingredients: []string{"flucloxacillin"}, AllergyGroups: []string{"penicillins"}},
+		{Code: "IBU", Name: "Ibuprofen", BrandNames: []string{"Nurofen"}, Class: "NSAID", Ingredients: []string{"ibuprofen"}},
+		{Code: "NAP", Name: "Naproxen", Class: "NSAID", Ingredients: []string{"naproxen"}},
+		{Code: "PARA", Name: "Paracetamol", Class: "Analgesic", Ingredients: []string{"paracetamol"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return interactionChecker{catalogue: catalogue}
+}
+
+func checkDrug(t *testing.T, c interactionChecker, name string) *drugs.Drug {
+	t.Helper()
+	drug, ok := c.resolve(name)
+	if !ok {
+		t.Fatalf("%s is not in the test catalogue", name)
+	}
+	return drug
+}
+
+var checkTime = time.Date(2026, time.March, 10, 9, 30, 0, 0, time.UTC)
+
+func TestCheckAllergySeverity(t *testing.T) {
+	c := newTestChecker(t)
+	tests := []struct {
+		name      string
+		allergy   models.Allergy
+		wantAlert bool
+		severity  string
+	}{
+		{"severe allergy blocks", models.Allergy{Substance: "amoxicillin", Severity: models.SeveritySevere, Status: models.ClinicalStatusActive}, true, models.AlertSeverityBlock},
+		{"moderate allergy blocks", models.Allergy{Substance: "Amoxicillin", Severity: models.SeverityModerate, Status: models.ClinicalStatusActive}, true, models.AlertSeverityBlock},
+		{"mild allergy warns", models.Allergy{Substance: "amoxicillin", Severity: models.SeverityMild, Status: models.ClinicalStatusActive}, true, models.AlertSeverityWarn},
+		{"resolved allergy is ignored", models.Allergy{Substance: "amoxicillin", Severity: models.SeveritySevere, Status: models.ClinicalStatusResolved}, false, ""},
+		{"unrelated allergy is ignored", models.Allergy{Substance: "latex", Severity: models.SeveritySevere, Status: models.ClinicalStatusActive}, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allergy := tt.allergy
+			allergy.ID = 5
+			alerts := c.check(checkDrug(t, c, "Amoxil"), []*models.Allergy{&allergy}, nil, nil, checkTime)
+			if !tt.wantAlert {
+				if len(alerts) != 0 {
+					t.Fatalf("alerts = %+v, want none", alerts)
+				}
+				return
+			}
+			if len(alerts) != 1 {
+				t.Fatalf("alerts = %+v, want one", alerts)
+			}
+			alert := alerts[0]
+			if alert.Type != models.AlertTypeAllergy || alert.Severity != tt.severity || alert.Source != "allergy:5" {
+				t.Fatalf("alert = %+v, want a %s allergy alert from allergy:5", alert, tt.severity)
+			}
+		})
+	}
+}
+
+func TestCheckAllergyMatchesThroughCatalogue(t *testing.T) {
+	c := newTestChecker(t)
+	tests := []struct {
+		substance string
+		drug      string
+		want      bool
+	}{
+		// A brand name on the allergy list matches the generic.
+		{"Augmentin", "Amoxicillin", true},
+		// An allergy recorded as one penicillin covers the others.
+		{"amoxicillin", "Flucloxacillin", true},
+		// The allergy group itself matches.
+		{"Penicillins", "Co-amoxiclav", true},
+		// Shared class alone is not an allergy match.
+		{"ibuprofen", "Naproxen", false},
+		// Substances missing from the catalogue match by name only.
+		{"  PARACETAMOL ", "Paracetamol", true},
+		{"peanuts", "Paracetamol", false},
+	}
+	for _, tt := range tests {
+		allergies := []*models.Allergy{{Substance: tt.substance, Severity: models.SeveritySevere, Status: models.ClinicalStatusActive}}
+		alerts := c.check(checkDrug(t, c, tt.drug), allergies, nil, nil, checkTime)
+		if got := len(alerts) == 1 && alerts[0].Type == models.AlertTypeAllergy; got != tt.want {
+			t.Errorf("allergy to %q prescribing %s: alerted = %v, want %v (%+v)", tt.substance, tt.drug, got, tt.want, alerts)
+		}
+	}
+}
+
+func TestCheckDuplicateTherapyOnMedicationList(t *testing.T) {
+	c := newTestChecker(t)
+	yesterday := checkTime.AddDate(0, 0, -1)
+	today := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+	medications := []*models.Medication{
+		{ID: 1, Name: "Nurofen"},
+		{ID: 2, Name: "Naproxen", StopDate: &yesterday},
+		{ID: 3, Name: "amoxicillin", StopDate: &today},
+		{ID: 4, Name: "Paracetamol"},
+	}
+
+	alerts := c.check(checkDrug(t, c, "Ibuprofen"), nil, medications, nil, checkTime)
+	if len(alerts) != 1 || alerts[0].Source != "medication:1" {
+		t.Fatalf("alerts = %+v, want only the current Nurofen entry", alerts)
+	}
+	if alerts[0].Type != models.AlertTypeDuplicateTherapy || alerts[0].Severity != models.AlertSeverityWarn {
+		t.Fatalf("alert = %+v, want a duplicate therapy warning", alerts[0])
+	}
+
+	// A medication stopping today is still current, and shares a class.
+	alerts = c.check(checkDrug(t, c, "Flucloxacillin"), nil, medications, nil, checkTime)
+	if len(alerts) != 1 || alerts[0].Source != "medication:3" {
+		t.Fatalf("alerts = %+v, want the amoxicillin entry stopping today", alerts)
+	}
+}
+
+func TestCheckDuplicateTherapyAgainstPrescriptions(t *testing.T) {
+	c := newTestChecker(t)
+	prescriptions := []*models.Prescription{
+		{ID: 1, DrugName: "Co-amoxiclav", Status: models.PrescriptionActive, DurationDays: 7, CreatedAt: checkTime.AddDate(0, 0, -2)},
+		{ID: 2, DrugName: "Amoxicillin", Status: models.PrescriptionActive, DurationDays: 5, CreatedAt: checkTime.AddDate(0, 0, -5)},
+		{ID: 3, DrugName: "Ibuprofen", Status: models.PrescriptionCancelled},
+		{ID: 4, DrugName: "Naproxen", Status: models.PrescriptionActive},
+	}
+
+	alerts := c.check(checkDrug(t, c, "Amoxicillin"), nil, nil, prescriptions, checkTime)
+	if len(alerts) != 1 || alerts[0].Source != "prescription:1" {
+		t.Fatalf("alerts = %+v, want only the running co-amoxiclav course", alerts)
+	}
+
+	// Open-ended prescriptions stay current; cancelled ones do not.
+	alerts = c.check(checkDrug(t, c, "Ibuprofen"), nil, nil, prescriptions, checkTime)
+	if len(alerts) != 1 || alerts[0].Source != "prescription:4" {
+		t.Fatalf("alerts = %+v, want only the open-ended naproxen prescription", alerts)
+	}
+}
+
+func TestCheckUncataloguedDrugComparesByName(t *testing.T) {
+	c := newTestChecker(t)
+	drug, ok := c.resolve("Herbal tonic")
+	if ok {
+		t.Fatal("expected the drug to be missing from the catalogue")
+	}
+	medications := []*models.Medication{{ID: 1, Name: "herbal  TONIC"}, {ID: 2, Name: "Paracetamol"}}
+
+	alerts := c.check(drug, nil, medications, nil, checkTime)
+	if len(alerts) != 1 || alerts[0].Source != "medication:1" {
+		t.Fatalf("alerts = %+v, want a duplicate of the same-named medication", alerts)
+	}
+	if blocked(alerts) {
+		t.Fatal("duplicate therapy must not block")
+	}
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/drugs"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"hospital-management/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrescriptionService writes prescriptions against appointments, checking
+// each drug against the patient's allergies, medications and current
+// prescriptions first.
+type PrescriptionService interface {
+	// CheckPrescription runs the interaction checker for a drug without
+	// prescribing it.
+	CheckPrescription(actor *models.Actor, patientID uint, req *models.PrescriptionRequest) (*models.PrescriptionCheckResult, error)
+	CreatePrescription(actor *models.Actor, appointmentID uint, req *models.PrescriptionRequest) (*models.Prescription, error)
+	GetPrescription(actor *models.Actor, id uint) (*models.Prescription, error)
+	GetPatientPrescriptions(actor *models.Actor, patientID uint) ([]*models.Prescription, error)
+	GetAppointmentPrescriptions(actor *models.Actor, appointmentID uint) ([]*models.Prescription, error)
+	CancelPrescription(actor *models.Actor, id uint, req *models.PrescriptionCancelRequest) (*models.Prescription, error)
+	// PrintPrescription renders a prescription as a PDF.
+	PrintPrescription(actor *models.Actor, id uint) ([]byte, error)
+	SearchDrugs(query string, limit int) []*drugs.Drug
+}
+
+type prescriptionService struct {
+	prescriptionRepo repository.PrescriptionRepository
+	appointmentRepo  repository.AppointmentRepository
+	patientRepo      repository.PatientRepository
+	userRepo         repository.UserRepository
+	recordRepo       repository.PatientRecordRepository
+	auditService     AuditService
+	checker          interactionChecker
+	location         *time.Location
+}
+
+func NewPrescriptionService(prescriptionRepo repository.PrescriptionRepository, appointmentRepo repository.AppointmentRepository, patientRepo repository.PatientRepository, userRepo repository.UserRepository, recordRepo repository.PatientRecordRepository, auditService AuditService, catalogue drugs.Catalogue, location *time.Location) PrescriptionService {
+	return &prescriptionService{
+		prescriptionRepo: prescriptionRepo,
+		appointmentRepo:  appointmentRepo,
+		patientRepo:      patientRepo,
+		userRepo:         userRepo,
+		recordRepo:       recordRepo,
+		auditService:     auditService,
+		checker:          interactionChecker{catalogue: catalogue},
+		location:         location,
+	}
+}
+
+func (s *prescriptionService) CheckPrescription(actor *models.Actor, patientID uint, req *models.PrescriptionRequest) (*models.PrescriptionCheckResult, error) {
+	if _, err := s.patientRepo.GetByID(int(patientID)); err != nil {
+		return nil, fmt.Errorf("patient not found: %w", err)
+	}
+
+	drug, known, alerts, err := s.check(patientID, req.Drug)
+	if err != nil {
+		return nil, err
+	}
+
+	// The check reads the patient's allergies and medications.
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityPrescription, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return &models.PrescriptionCheckResult{
+		Drug:    drug.Name,
+		Known:   known,
+		Blocked: blocked(alerts),
+		Alerts:  alerts,
+	}, nil
+}
+
+// CreatePrescription prescribes a drug to the appointment's patient. Warnings
+// are stored with the prescription; blocking alerts reject it with a
+// PrescriptionBlockedError unless req.OverrideReason is set.
+func (s *prescriptionService) CreatePrescription(actor *models.Actor, appointmentID uint, req *models.PrescriptionRequest) (*models.Prescription, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("appointment not found: %w", err)
+	}
+	if appointment.Status == models.AppointmentCancelled || appointment.Status == models.AppointmentNoShow {
+		return nil, ErrNotPrescribable
+	}
+	if strings.TrimSpace(req.Dose) == "" || strings.TrimSpace(req.Frequency) == "" {
+		return nil, fmt.Errorf("dose and frequency are required")
+	}
+	if req.DurationDays < 0 || req.Refills < 0 {
+		return nil, fmt.Errorf("duration_days and refills must not be negative")
+	}
+
+	drug, _, alerts, err := s.check(appointment.PatientID, req.Drug)
+	if err != nil {
+		return nil, err
+	}
+
+	var override *string
+	if blocked(alerts) {
+		reason := strings.TrimSpace(req.OverrideReason)
+		if reason == "" {
+			return nil, &PrescriptionBlockedError{Alerts: alerts}
+		}
+		override = &reason
+	}
+
+	prescription := &models.Prescription{
+		PatientID:      appointment.PatientID,
+		AppointmentID:  appointment.ID,
+		PrescriberID:   actor.UserID,
+		DrugCode:       drug.Code,
+		DrugName:       drug.Name,
+		Dose:           req.Dose,
+		Route:          req.Route,
+		Frequency:      req.Frequency,
+		DurationDays:   req.DurationDays,
+		Quantity:       req.Quantity,
+		Refills:        req.Refills,
+		Instructions:   req.Instructions,
+		Status:         models.PrescriptionActive,
+		Alerts:         alerts,
+		Blocked:        blocked(alerts),
+		OverrideReason: override,
+	}
+
+	created, err := s.prescriptionRepo.Create(prescription)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionCreate, models.AuditEntityPrescription, created.ID, created.PatientID, nil, created); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (s *prescriptionService) GetPrescription(actor *models.Actor, id uint) (*models.Prescription, error) {
+	prescription, err := s.prescriptionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionView, models.AuditEntityPrescription, prescription.ID, prescription.PatientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return prescription, nil
+}
+
+func (s *prescriptionService) GetPatientPrescriptions(actor *models.Actor, patientID uint) ([]*models.Prescription, error) {
+	prescriptions, err := s.prescriptionRepo.GetByPatientID(patientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityPrescription, 0, patientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return prescriptions, nil
+}
+
+func (s *prescriptionService) GetAppointmentPrescriptions(actor *models.Actor, appointmentID uint) ([]*models.Prescription, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("appointment not found: %w", err)
+	}
+
+	prescriptions, err := s.prescriptionRepo.GetByAppointmentID(appointmentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionList, models.AuditEntityPrescription, 0, appointment.PatientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return prescriptions, nil
+}
+
+func (s *prescriptionService) CancelPrescription(actor *models.Actor, id uint, req *models.PrescriptionCancelRequest) (*models.Prescription, error) {
+	prescription, err := s.prescriptionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if prescription.Status == models.PrescriptionCancelled {
+		return nil, ErrPrescriptionCancelled
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to cancel a prescription")
+	}
+	before := *prescription
+
+	now := time.Now()
+	prescription.Status = models.PrescriptionCancelled
+	prescription.CancelledAt = &now
+	prescription.CancelledBy = &actor.UserID
+	prescription.CancelReason = &reason
+
+	updated, err := s.prescriptionRepo.Update(prescription)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionUpdate, models.AuditEntityPrescription, updated.ID, updated.PatientID, &before, updated); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (s *prescriptionService) PrintPrescription(actor *models.Actor, id uint) ([]byte, error) {
+	prescription, err := s.prescriptionRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	patient, err := s.patientRepo.GetByID(int(prescription.PatientID))
+	if err != nil {
+		return nil, fmt.Errorf("patient not found: %w", err)
+	}
+	prescriber, err := s.userRepo.GetByID(prescription.PrescriberID)
+	if err != nil {
+		return nil, fmt.Errorf("prescriber not found: %w", err)
+	}
+
+	if err := s.auditService.Record(actor, models.AuditActionPrint, models.AuditEntityPrescription, prescription.ID, prescription.PatientID, nil, nil); err != nil {
+		return nil, err
+	}
+
+	return renderPrescription(prescription, patient, prescriber, s.location), nil
+}
+
+func (s *prescriptionService) SearchDrugs(query string, limit int) []*drugs.Drug {
+	return s.checker.catalogue.Search(query, limit)
+}
+
+// check resolves name against the catalogue and runs the interaction checker
+// for the patient.
+func (s *prescriptionService) check(patientID uint, name string) (*drugs.Drug, bool, []models.PrescriptionAlert, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, false, nil, fmt.Errorf("drug is required")
+	}
+	drug, known := s.checker.resolve(strings.TrimSpace(name))
+
+	allergies, err := s.recordRepo.GetAllergies(patientID)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	medications, err := s.recordRepo.GetMedications(patientID)
+	if err != nil {
+		return nil, false, nil, err
+	}
+	prescriptions, err := s.prescriptionRepo.GetActiveByPatientID(patientID)
+	if err != nil {
+		return nil, false, nil, err
+	}
+
+	alerts := s.checker.check(drug, allergies, medications, prescriptions, time.Now().In(s.location))
+	return drug, known, alerts, nil
+}
+
+func renderPrescription(p *models.Prescription, patient *models.Patient, prescriber *models.User, location *time.Location) []byte {
+	doc := utils.NewPDFDocument(fmt.Sprintf("Prescription %d", p.ID))
+
+	doc.Heading("Prescription")
+	doc.Field("Prescription no.", strconv.FormatUint(uint64(p.ID), 10))
+	doc.Field("Date", p.CreatedAt.In(location).Format("2006-01-02"))
+	if p.Status == models.PrescriptionCancelled {
+		doc.Heading("CANCELLED - NOT VALID FOR DISPENSING")
+	}
+	doc.Space()
+
+	doc.Heading("Patient")
+	doc.Field("Name", patient.GetFullName())
+	doc.Field("Date of birth", patient.DateOfBirth.Format("2006-01-02"))
+	doc.Space()
+
+	doc.Heading("Medication")
+	drug := p.DrugName
+	if p.DrugCode != "" {
+		drug += " (" + p.DrugCode + ")"
+	}
+	doc.Field("Drug", drug)
+	doc.Field("Dose", p.Dose)
+	if p.Route != "" {
+		doc.Field("Route", p.Route)
+	}
+	doc.Field("Frequency", p.Frequency)
+	if p.DurationDays > 0 {
+		doc.Field("Duration", fmt.Sprintf("%d days", p.DurationDays))
+	}
+	if p.Quantity != "" {
+		doc.Field("Quantity", p.Quantity)
+	}
+	doc.Field("Refills", strconv.Itoa(p.Refills))
+	if p.Instructions != "" {
+		doc.Field("Instructions", p.Instructions)
+	}
+	doc.Space()
+
+	doc.Heading("Prescriber")
+	doc.Field("Name", prescriber.GetFullName())
+	doc.Space()
+	doc.Space()
+	doc.Text("Signature: ______________________________")
+
+	return doc.Bytes()
+}
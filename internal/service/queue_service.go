@@ -0,0 +1,174 @@
+package service
+
+import (
+	"fmt"
+	"hospital-management/internal/models"
+	"hospital-management/internal/repository"
+	"sync"
+	"time"
+)
+
+type QueueService interface {
+	// CheckIn marks the patient of one of today's appointments as arrived,
+	// placing them in the doctor's queue.
+	CheckIn(actor *models.Actor, appointmentID uint) (*models.Appointment, error)
+	GetQueue(doctorID uint) (*models.DoctorQueue, error)
+	// Watch returns a channel that receives a value whenever the doctor's
+	// queue may have changed, and a function that stops watching.
+	Watch(doctorID uint) (<-chan struct{}, func())
+}
+
+type queueService struct {
+	appointmentRepo repository.AppointmentRepository
+	appointments    AppointmentService
+	location        *time.Location
+
+	mu       sync.Mutex
+	watchers map[uint]map[chan struct{}]struct{}
+}
+
+func NewQueueService(appointmentRepo repository.AppointmentRepository, appointments AppointmentService, location *time.Location) QueueService {
+	s := &queueService{
+		appointmentRepo: appointmentRepo,
+		appointments:    appointments,
+		location:        location,
+		watchers:        make(map[uint]map[chan struct{}]struct{}),
+	}
+	appointments.Subscribe(s.onAppointmentChange)
+	return s
+}
+
+func (s *queueService) CheckIn(actor *models.Actor, appointmentID uint) (*models.Appointment, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("appointment not found: %w", err)
+	}
+
+	today := startOfDay(time.Now().In(s.location))
+	if !startOfDay(appointment.DateTime.In(s.location)).Equal(today) {
+		return nil, ErrCheckInNotToday
+	}
+
+	return s.appointments.ChangeAppointmentStatus(actor, appointmentID, &models.AppointmentStatusRequest{
+		Status: models.AppointmentCheckedIn,
+	})
+}
+
+func (s *queueService) GetQueue(doctorID uint) (*models.DoctorQueue, error) {
+	now := time.Now()
+	today := startOfDay(now.In(s.location))
+
+	appointments, err := s.appointmentRepo.GetDoctorQueue(doctorID, today, today.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue: %w", err)
+	}
+
+	return &models.DoctorQueue{
+		DoctorID:    doctorID,
+		GeneratedAt: now,
+		Entries:     buildQueue(appointments, now),
+	}, nil
+}
+
+func (s *queueService) Watch(doctorID uint) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	if s.watchers[doctorID] == nil {
+		s.watchers[doctorID] = make(map[chan struct{}]struct{})
+	}
+	s.watchers[doctorID][ch] = struct{}{}
+	s.mu.Unlock()
+
+	stop := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.watchers[doctorID], ch)
+		if len(s.watchers[doctorID]) == 0 {
+			delete(s.watchers, doctorID)
+		}
+	}
+	return ch, stop
+}
+
+// onAppointmentChange wakes the watchers of every doctor whose schedule the
+// change touches. Watchers that have not consumed the previous wake-up are
+// skipped; they will reload the queue anyway.
+func (s *queueService) onAppointmentChange(event AppointmentEvent) {
+	doctors := []uint{event.DoctorID()}
+	if event.Before != nil && event.Appointment != nil && event.Before.DoctorID != event.Appointment.DoctorID {
+		doctors = append(doctors, event.Before.DoctorID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, doctorID := range doctors {
+		for ch := range s.watchers[doctorID] {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// buildQueue orders the queue and estimates when each patient will be seen:
+// the consultation in progress runs for the rest of its booked duration, and
+// each waiting patient starts once everyone ahead of them has had theirs.
+func buildQueue(appointments []*models.Appointment, now time.Time) []models.QueueEntry {
+	var inProgress, waiting []*models.Appointment
+	for _, a := range appointments {
+		if a.Status == models.AppointmentInProgress {
+			inProgress = append(inProgress, a)
+		} else {
+			waiting = append(waiting, a)
+		}
+	}
+
+	entries := make([]models.QueueEntry, 0, len(appointments))
+	next := now
+	for _, a := range append(inProgress, waiting...) {
+		start := next
+		end := start.Add(time.Duration(a.Duration) * time.Minute)
+		if a.Status == models.AppointmentInProgress && a.StartedAt != nil {
+			start = *a.StartedAt
+			end = start.Add(time.Duration(a.Duration) * time.Minute)
+			if end.Before(now) {
+				end = now
+			}
+		}
+
+		wait := int(start.Sub(now).Minutes())
+		if wait < 0 {
+			wait = 0
+		}
+
+		entries = append(entries, models.QueueEntry{
+			Position:             len(entries) + 1,
+			AppointmentID:        a.ID,
+			PatientID:            a.PatientID,
+			PatientName:          queueDisplayName(a.Patient),
+			Status:               a.Status,
+			ScheduledFor:         a.DateTime,
+			CheckedInAt:          a.CheckedInAt,
+			Duration:             a.Duration,
+			EstimatedStart:       start,
+			EstimatedWaitMinutes: wait,
+		})
+		if end.After(next) {
+			next = end
+		}
+	}
+	return entries
+}
+
+// queueDisplayName shortens a patient's name to first name and last initial.
+func queueDisplayName(patient *models.Patient) string {
+	if patient == nil {
+		return ""
+	}
+	if patient.LastName == "" {
+		return patient.FirstName
+	}
+	return patient.FirstName + " " + string([]rune(patient.LastName)[0]) + "."
+}
@@ -0,0 +1,177 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and margins, in points.
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+	pdfMargin     = 50.0
+)
+
+const (
+	pdfFontRegular = "F1"
+	pdfFontBold    = "F2"
+)
+
+type pdfLine struct {
+	font string
+	size float64
+	y    float64
+	text string
+}
+
+// PDFDocument lays out plain text lines on A4 pages using the standard
+// Helvetica fonts, for printable documents that need no styling beyond
+// headings. Characters outside Latin-1 are printed as "?".
+type PDFDocument struct {
+	title string
+	pages [][]pdfLine
+	y     float64
+}
+
+// NewPDFDocument starts a document with the given title.
+func NewPDFDocument(title string) *PDFDocument {
+	d := &PDFDocument{title: title}
+	d.newPage()
+	return d
+}
+
+// Heading adds a bold line.
+func (d *PDFDocument) Heading(text string) {
+	d.write(pdfFontBold, 14, text)
+}
+
+// Text adds a paragraph, wrapped to the page width.
+func (d *PDFDocument) Text(text string) {
+	d.write(pdfFontRegular, 11, text)
+}
+
+// Field adds a "label: value" paragraph.
+func (d *PDFDocument) Field(label, value string) {
+	d.Text(label + ": " + value)
+}
+
+// Space adds a blank line.
+func (d *PDFDocument) Space() {
+	d.y -= 11
+}
+
+// Bytes renders the document.
+func (d *PDFDocument) Bytes() []byte {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+
+	// Objects 1-4 are the catalog, page tree, fonts; each page then takes a
+	// page object followed by its content stream.
+	const firstPage = 5
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range d.pages {
+		var content bytes.Buffer
+		for _, line := range lines {
+			fmt.Fprintf(&content, "BT /%s %.0f Tf %.0f %.0f Td (%s) Tj ET\n", line.font, line.size, pdfMargin, line.y, pdfEscape(line.text))
+		}
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /%s 3 0 R /%s 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, pdfFontRegular, pdfFontBold, firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+	object(fmt.Sprintf("<< /Title (%s) /Producer (hospital-management) >>", pdfEscape(d.title)))
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, len(offsets), xref)
+	return buf.Bytes()
+}
+
+func (d *PDFDocument) newPage() {
+	d.pages = append(d.pages, nil)
+	d.y = pdfPageHeight - pdfMargin
+}
+
+// write wraps text at word boundaries, estimating Helvetica's average glyph
+// width as half the font size.
+func (d *PDFDocument) write(font string, size float64, text string) {
+	maxChars := int((pdfPageWidth - 2*pdfMargin) / (size * 0.5))
+	for _, paragraph := range strings.Split(text, "\n") {
+		for _, line := range wrapWords(paragraph, maxChars) {
+			lineHeight := size * 1.4
+			if d.y-lineHeight < pdfMargin {
+				d.newPage()
+			}
+			d.y -= lineHeight
+			page := len(d.pages) - 1
+			d.pages[page] = append(d.pages[page], pdfLine{font: font, size: size, y: d.y, text: line})
+		}
+	}
+}
+
+func wrapWords(text string, maxChars int) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+	var lines []string
+	current := ""
+	for _, word := range words {
+		for len([]rune(word)) > maxChars {
+			if current != "" {
+				lines = append(lines, current)
+				current = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:maxChars]))
+			word = string(runes[maxChars:])
+		}
+		switch {
+		case current == "":
+			current = word
+		case len([]rune(current))+1+len([]rune(word)) <= maxChars:
+			current += " " + word
+		default:
+			lines = append(lines, current)
+			current = word
+		}
+	}
+	return append(lines, current)
+}
+
+// pdfEscape encodes text as a WinAnsi PDF string body.
+func pdfEscape(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}